@@ -5,6 +5,8 @@ package admin
 
 import (
 	"context"
+	"net/netip"
+	"time"
 
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/database/rpcdb"
@@ -142,3 +144,59 @@ func (c *Client) DBGet(ctx context.Context, key []byte, options ...rpc.Option) (
 	}
 	return formatting.Decode(formatting.HexNC, res.Value)
 }
+
+func (c *Client) CreateSnapshot(ctx context.Context, name string, options ...rpc.Option) (SnapshotInfo, error) {
+	res := &CreateSnapshotReply{}
+	err := c.Requester.SendRequest(ctx, "admin.createSnapshot", &CreateSnapshotArgs{
+		Name: name,
+	}, res, options...)
+	return res.Snapshot, err
+}
+
+func (c *Client) ListSnapshots(ctx context.Context, options ...rpc.Option) ([]SnapshotInfo, error) {
+	res := &ListSnapshotsReply{}
+	err := c.Requester.SendRequest(ctx, "admin.listSnapshots", struct{}{}, res, options...)
+	return res.Snapshots, err
+}
+
+// BanPeer bans [nodeID] for [duration]. If [duration] is 0, the ban doesn't
+// expire.
+func (c *Client) BanPeer(ctx context.Context, nodeID ids.NodeID, duration time.Duration, reason string, options ...rpc.Option) error {
+	args := &BanPeerArgs{
+		NodeID: nodeID,
+		Reason: reason,
+	}
+	if duration > 0 {
+		args.Duration = duration.String()
+	}
+	return c.Requester.SendRequest(ctx, "admin.banPeer", args, &api.EmptyReply{}, options...)
+}
+
+func (c *Client) UnbanPeer(ctx context.Context, nodeID ids.NodeID, options ...rpc.Option) (bool, error) {
+	res := &UnbanPeerReply{}
+	err := c.Requester.SendRequest(ctx, "admin.unbanPeer", &UnbanPeerArgs{
+		NodeID: nodeID,
+	}, res, options...)
+	return res.Unbanned, err
+}
+
+func (c *Client) ListBans(ctx context.Context, options ...rpc.Option) ([]BanInfo, error) {
+	res := &ListBansReply{}
+	err := c.Requester.SendRequest(ctx, "admin.listBans", struct{}{}, res, options...)
+	return res.Bans, err
+}
+
+func (c *Client) ConnectPeer(ctx context.Context, nodeID ids.NodeID, ip netip.AddrPort, options ...rpc.Option) error {
+	return c.Requester.SendRequest(ctx, "admin.connectPeer", &ConnectPeerArgs{
+		NodeID: nodeID,
+		IP:     ip.String(),
+	}, &api.EmptyReply{}, options...)
+}
+
+func (c *Client) DisconnectPeer(ctx context.Context, nodeID ids.NodeID, options ...rpc.Option) (bool, error) {
+	res := &DisconnectPeerReply{}
+	err := c.Requester.SendRequest(ctx, "admin.disconnectPeer", &DisconnectPeerArgs{
+		NodeID: nodeID,
+	}, res, options...)
+	return res.Disconnected, err
+}
@@ -5,9 +5,11 @@ package admin
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"path"
 	"sync"
+	"time"
 
 	"github.com/gorilla/rpc/v2"
 	"go.uber.org/zap"
@@ -17,10 +19,13 @@ import (
 	"github.com/ava-labs/avalanchego/chains"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/rpcdb"
+	"github.com/ava-labs/avalanchego/database/snapshot"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/ips"
 	"github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/perms"
@@ -41,6 +46,9 @@ const (
 var (
 	errAliasTooLong = errors.New("alias length is too long")
 	errNoLogLevel   = errors.New("need to specify either displayLevel or logLevel")
+	errNoSnapshots  = errors.New("database snapshots are not enabled")
+	errPeerBanned   = errors.New("peer is banned")
+	errBanDuration  = errors.New("ban duration must be positive")
 )
 
 type Config struct {
@@ -49,10 +57,12 @@ type Config struct {
 	LogFactory   logging.Factory
 	NodeConfig   interface{}
 	DB           database.Database
+	DBSnapshots  *snapshot.Manager
 	ChainManager chains.Manager
 	HTTPServer   server.PathAdderWithReadLock
 	VMRegistry   registry.VMRegistry
 	VMManager    *vms.Manager
+	Network      network.Network
 }
 
 // Admin is the API service for node admin management
@@ -413,3 +423,220 @@ func (a *Admin) DbGet(_ *http.Request, args *DBGetArgs, reply *DBGetReply) error
 	reply.Value, err = formatting.Encode(formatting.HexNC, value)
 	return err
 }
+
+type SnapshotInfo struct {
+	Name      string      `json:"name"`
+	DBType    string      `json:"dbType"`
+	Timestamp time.Time   `json:"timestamp"`
+	Size      json.Uint64 `json:"size"`
+}
+
+func newSnapshotInfo(info snapshot.Info) SnapshotInfo {
+	return SnapshotInfo{
+		Name:      info.Name,
+		DBType:    info.DBType,
+		Timestamp: info.Timestamp,
+		Size:      json.Uint64(info.Size),
+	}
+}
+
+type CreateSnapshotArgs struct {
+	Name string `json:"name"`
+}
+
+type CreateSnapshotReply struct {
+	Snapshot SnapshotInfo `json:"snapshot"`
+}
+
+// CreateSnapshot writes a consistent copy of the node's database to the
+// snapshot directory while the node continues to run.
+func (a *Admin) CreateSnapshot(_ *http.Request, args *CreateSnapshotArgs, reply *CreateSnapshotReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "createSnapshot"),
+		logging.UserString("name", args.Name),
+	)
+
+	if a.DBSnapshots == nil {
+		return errNoSnapshots
+	}
+
+	info, err := a.DBSnapshots.Create(args.Name)
+	if err != nil {
+		return err
+	}
+
+	a.Log.Info("created database snapshot",
+		zap.String("name", info.Name),
+		zap.Uint64("size", info.Size),
+	)
+	reply.Snapshot = newSnapshotInfo(info)
+	return nil
+}
+
+type ListSnapshotsReply struct {
+	Snapshots []SnapshotInfo `json:"snapshots"`
+}
+
+// ListSnapshots returns all the completed database snapshots, oldest first.
+func (a *Admin) ListSnapshots(_ *http.Request, _ *struct{}, reply *ListSnapshotsReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "listSnapshots"),
+	)
+
+	if a.DBSnapshots == nil {
+		return errNoSnapshots
+	}
+
+	infos, err := a.DBSnapshots.List()
+	if err != nil {
+		return err
+	}
+
+	reply.Snapshots = make([]SnapshotInfo, len(infos))
+	for i, info := range infos {
+		reply.Snapshots[i] = newSnapshotInfo(info)
+	}
+	return nil
+}
+
+type BanPeerArgs struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	// Duration of the ban, parsed by [time.ParseDuration]. If empty, the ban
+	// doesn't expire.
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+// BanPeer disconnects from a peer and rejects connections with it until the
+// ban expires or is removed. Bans are persisted across restarts.
+func (a *Admin) BanPeer(_ *http.Request, args *BanPeerArgs, _ *api.EmptyReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "banPeer"),
+		zap.Stringer("nodeID", args.NodeID),
+		logging.UserString("duration", args.Duration),
+		logging.UserString("reason", args.Reason),
+	)
+
+	var duration time.Duration
+	if len(args.Duration) > 0 {
+		var err error
+		duration, err = time.ParseDuration(args.Duration)
+		if err != nil {
+			return err
+		}
+		if duration <= 0 {
+			return fmt.Errorf("%w: %s", errBanDuration, duration)
+		}
+	}
+	return a.Network.Ban(args.NodeID, duration, args.Reason)
+}
+
+type UnbanPeerArgs struct {
+	NodeID ids.NodeID `json:"nodeID"`
+}
+
+type UnbanPeerReply struct {
+	// Unbanned is false if the peer wasn't banned.
+	Unbanned bool `json:"unbanned"`
+}
+
+// UnbanPeer removes the ban of a peer.
+func (a *Admin) UnbanPeer(_ *http.Request, args *UnbanPeerArgs, reply *UnbanPeerReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "unbanPeer"),
+		zap.Stringer("nodeID", args.NodeID),
+	)
+
+	var err error
+	reply.Unbanned, err = a.Network.Unban(args.NodeID)
+	return err
+}
+
+type BanInfo struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	Reason string     `json:"reason"`
+	// Expiry is nil if the ban doesn't expire.
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
+
+type ListBansReply struct {
+	Bans []BanInfo `json:"bans"`
+}
+
+// ListBans returns the banned peers, sorted by node ID.
+func (a *Admin) ListBans(_ *http.Request, _ *struct{}, reply *ListBansReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "listBans"),
+	)
+
+	bans := a.Network.Bans()
+	reply.Bans = make([]BanInfo, len(bans))
+	for i, ban := range bans {
+		reply.Bans[i] = BanInfo{
+			NodeID: ban.NodeID,
+			Reason: ban.Reason,
+		}
+		if !ban.Expiry.IsZero() {
+			expiry := ban.Expiry
+			reply.Bans[i].Expiry = &expiry
+		}
+	}
+	return nil
+}
+
+type ConnectPeerArgs struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	IP     string     `json:"ip"`
+}
+
+// ConnectPeer attempts to connect to a peer at the provided IP and maintains
+// the connection until the node shuts down, even if the peer isn't a
+// validator.
+func (a *Admin) ConnectPeer(_ *http.Request, args *ConnectPeerArgs, _ *api.EmptyReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "connectPeer"),
+		zap.Stringer("nodeID", args.NodeID),
+		logging.UserString("ip", args.IP),
+	)
+
+	ip, err := ips.ParseAddrPort(args.IP)
+	if err != nil {
+		return err
+	}
+	for _, ban := range a.Network.Bans() {
+		if ban.NodeID == args.NodeID {
+			return fmt.Errorf("%w: %s", errPeerBanned, args.NodeID)
+		}
+	}
+
+	a.Network.ManuallyTrack(args.NodeID, ip)
+	return nil
+}
+
+type DisconnectPeerArgs struct {
+	NodeID ids.NodeID `json:"nodeID"`
+}
+
+type DisconnectPeerReply struct {
+	// Disconnected is false if there was no connection with the peer.
+	Disconnected bool `json:"disconnected"`
+}
+
+// DisconnectPeer closes the connection with a peer. The peer may reconnect
+// unless it is banned.
+func (a *Admin) DisconnectPeer(_ *http.Request, args *DisconnectPeerArgs, reply *DisconnectPeerReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "disconnectPeer"),
+		zap.Stringer("nodeID", args.NodeID),
+	)
+
+	reply.Disconnected = a.Network.Disconnect(args.NodeID)
+	return nil
+}
@@ -7,11 +7,19 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
+	"github.com/ava-labs/avalanchego/database/snapshot"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/vms"
@@ -179,3 +187,85 @@ func TestServiceDBGet(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceSnapshots(t *testing.T) {
+	require := require.New(t)
+
+	db, err := pebbledb.New(t.TempDir(), nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+	defer func() {
+		require.NoError(db.Close())
+	}()
+
+	admin := &Admin{Config: Config{
+		Log:         logging.NoLog{},
+		DB:          db,
+		DBSnapshots: snapshot.NewManager(t.TempDir(), pebbledb.Name, db),
+	}}
+
+	createReply := &CreateSnapshotReply{}
+	require.NoError(admin.CreateSnapshot(nil, &CreateSnapshotArgs{Name: "test"}, createReply))
+	require.Equal("test", createReply.Snapshot.Name)
+	require.Equal(pebbledb.Name, createReply.Snapshot.DBType)
+
+	listReply := &ListSnapshotsReply{}
+	require.NoError(admin.ListSnapshots(nil, nil, listReply))
+	require.Equal([]SnapshotInfo{createReply.Snapshot}, listReply.Snapshots)
+}
+
+func TestServiceSnapshotsNotSupported(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	admin := &Admin{Config: Config{
+		Log:         logging.NoLog{},
+		DB:          db,
+		DBSnapshots: snapshot.NewManager(t.TempDir(), memdb.Name, db),
+	}}
+
+	err := admin.CreateSnapshot(nil, &CreateSnapshotArgs{}, &CreateSnapshotReply{})
+	require.ErrorIs(err, database.ErrSnapshotNotSupported)
+}
+
+func TestServiceBans(t *testing.T) {
+	require := require.New(t)
+
+	registry := prometheus.NewRegistry()
+	config, err := network.NewTestNetworkConfig(registry, constants.UnitTestID, validators.NewManager(), nil)
+	require.NoError(err)
+	net, err := network.NewTestNetwork(logging.NoLog{}, registry, config, nil)
+	require.NoError(err)
+	defer net.StartClose()
+
+	admin := &Admin{Config: Config{
+		Log:     logging.NoLog{},
+		Network: net,
+	}}
+
+	nodeID := ids.GenerateTestNodeID()
+	err = admin.BanPeer(nil, &BanPeerArgs{NodeID: nodeID, Duration: "-1h"}, &api.EmptyReply{})
+	require.ErrorIs(err, errBanDuration)
+
+	require.NoError(admin.BanPeer(nil, &BanPeerArgs{NodeID: nodeID, Reason: "test"}, &api.EmptyReply{}))
+
+	listReply := &ListBansReply{}
+	require.NoError(admin.ListBans(nil, &struct{}{}, listReply))
+	require.Equal([]BanInfo{{NodeID: nodeID, Reason: "test"}}, listReply.Bans)
+
+	err = admin.ConnectPeer(nil, &ConnectPeerArgs{NodeID: nodeID, IP: "127.0.0.1:9651"}, &api.EmptyReply{})
+	require.ErrorIs(err, errPeerBanned)
+
+	unbanReply := &UnbanPeerReply{}
+	require.NoError(admin.UnbanPeer(nil, &UnbanPeerArgs{NodeID: nodeID}, unbanReply))
+	require.True(unbanReply.Unbanned)
+	require.NoError(admin.UnbanPeer(nil, &UnbanPeerArgs{NodeID: nodeID}, unbanReply))
+	require.False(unbanReply.Unbanned)
+
+	listReply = &ListBansReply{}
+	require.NoError(admin.ListBans(nil, &struct{}{}, listReply))
+	require.Empty(listReply.Bans)
+
+	disconnectReply := &DisconnectPeerReply{}
+	require.NoError(admin.DisconnectPeer(nil, &DisconnectPeerArgs{NodeID: nodeID}, disconnectReply))
+	require.False(disconnectReply.Disconnected)
+}
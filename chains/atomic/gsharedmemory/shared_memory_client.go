@@ -83,6 +83,9 @@ func (c *Client) Apply(requests map[ids.ID]*atomic.Requests, batches ...database
 	}
 	for i, batch := range batches {
 		batch := batch.Inner()
+		if replayed, ok := batch.(database.ReplayedBatch); ok {
+			defer replayed.Written()
+		}
 		fb := filteredBatch{
 			writes: make(map[string][]byte),
 		}
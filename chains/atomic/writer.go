@@ -13,6 +13,9 @@ func WriteAll(baseBatch database.Batch, batches ...database.Batch) error {
 	// operations as they would be applied to the base database.
 	for _, batch := range batches {
 		batch = batch.Inner()
+		if replayed, ok := batch.(database.ReplayedBatch); ok {
+			defer replayed.Written()
+		}
 		if err := batch.Replay(baseBatch); err != nil {
 			return err
 		}
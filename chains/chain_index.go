@@ -0,0 +1,109 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/proposervm"
+)
+
+// indexChain records that [chainID] was created on [subnetID] so that its
+// data can be found if the subnet is no longer tracked.
+func (m *manager) indexChain(chainID ids.ID, subnetID ids.ID) error {
+	indexDB := prefixdb.New(ChainIndexDBPrefix, m.DB)
+	return indexDB.Put(chainID[:], subnetID[:])
+}
+
+// pruneUntrackedChains deletes the data of every indexed chain that is
+// neither on the primary network nor on a tracked subnet.
+func (m *manager) pruneUntrackedChains() error {
+	indexDB := prefixdb.New(ChainIndexDBPrefix, m.DB)
+	untracked, err := m.untrackedChains(indexDB)
+	if err != nil {
+		return err
+	}
+
+	for chainID, subnetID := range untracked {
+		m.Log.Info("pruning untracked chain",
+			zap.Stringer("subnetID", subnetID),
+			zap.Stringer("chainID", chainID),
+		)
+
+		for _, db := range chainDatabases(m.DB, chainID) {
+			if err := db.DeleteRange(nil, nil); err != nil {
+				return fmt.Errorf("failed to delete database of chain %s: %w", chainID, err)
+			}
+			if err := db.Compact(nil, nil); err != nil {
+				return fmt.Errorf("failed to compact database of chain %s: %w", chainID, err)
+			}
+		}
+
+		chainDataDir := filepath.Join(m.ChainDataDir, chainID.String())
+		if err := os.RemoveAll(chainDataDir); err != nil {
+			return fmt.Errorf("failed to delete data directory of chain %s: %w", chainID, err)
+		}
+
+		// The index entry is removed last so that an interrupted prune is
+		// retried on the next startup.
+		if err := indexDB.Delete(chainID[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// untrackedChains returns the indexed chains mapped to their subnet that are
+// not on the primary network or a tracked subnet.
+func (m *manager) untrackedChains(indexDB database.Iteratee) (map[ids.ID]ids.ID, error) {
+	it := indexDB.NewIterator()
+	defer it.Release()
+
+	untracked := make(map[ids.ID]ids.ID)
+	for it.Next() {
+		chainID, err := ids.ToID(it.Key())
+		if err != nil {
+			return nil, err
+		}
+		subnetID, err := ids.ToID(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		if subnetID == constants.PrimaryNetworkID || m.TrackedSubnets.Contains(subnetID) {
+			continue
+		}
+		untracked[chainID] = subnetID
+	}
+	return untracked, it.Error()
+}
+
+// chainDatabases returns the databases that the manager creates for the chain.
+//
+// Because nested prefixdbs hash their prefixes together, these databases do
+// not share a common key prefix and must be deleted individually. Databases
+// created by a VM running in a separate process are always nested within the
+// VM database. A VM running in this process may create databases that are
+// not returned.
+func chainDatabases(db database.Database, chainID ids.ID) []*prefixdb.Database {
+	chainDB := prefixdb.New(chainID[:], db)
+	vmDB := prefixdb.New(VMDBPrefix, chainDB)
+	return []*prefixdb.Database{
+		chainDB,
+		vmDB,
+		prefixdb.New(proposervm.DBPrefix, vmDB),
+		prefixdb.New(ChainBootstrappingDBPrefix, chainDB),
+		prefixdb.New(VertexDBPrefix, chainDB),
+		prefixdb.New(VertexBootstrappingDBPrefix, chainDB),
+		prefixdb.New(TxBootstrappingDBPrefix, chainDB),
+		prefixdb.New(BlockBootstrappingDBPrefix, chainDB),
+	}
+}
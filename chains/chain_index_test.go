@@ -0,0 +1,88 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+func TestPruneUntrackedChains(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		trackedSubnetID   = ids.GenerateTestID()
+		untrackedSubnetID = ids.GenerateTestID()
+
+		primaryChainID   = ids.GenerateTestID()
+		trackedChainID   = ids.GenerateTestID()
+		untrackedChainID = ids.GenerateTestID()
+	)
+
+	db := memdb.New()
+	m := &manager{
+		ManagerConfig: ManagerConfig{
+			Log:            logging.NoLog{},
+			DB:             db,
+			ChainDataDir:   t.TempDir(),
+			TrackedSubnets: set.Of(trackedSubnetID),
+		},
+	}
+
+	chains := map[ids.ID]ids.ID{
+		primaryChainID:   constants.PrimaryNetworkID,
+		trackedChainID:   trackedSubnetID,
+		untrackedChainID: untrackedSubnetID,
+	}
+	for chainID, subnetID := range chains {
+		require.NoError(m.indexChain(chainID, subnetID))
+
+		for _, chainDB := range chainDatabases(db, chainID) {
+			require.NoError(chainDB.Put([]byte("key"), []byte("value")))
+		}
+		require.NoError(os.MkdirAll(filepath.Join(m.ChainDataDir, chainID.String()), perms.ReadWriteExecute))
+	}
+
+	require.NoError(m.pruneUntrackedChains())
+
+	for chainID := range chains {
+		var count int
+		for _, chainDB := range chainDatabases(db, chainID) {
+			chainCount, err := database.Count(chainDB)
+			require.NoError(err)
+			count += chainCount
+		}
+
+		_, err := os.Stat(filepath.Join(m.ChainDataDir, chainID.String()))
+		if chainID == untrackedChainID {
+			require.Zero(count)
+			require.ErrorIs(err, os.ErrNotExist)
+		} else {
+			require.Equal(len(chainDatabases(db, chainID)), count)
+			require.NoError(err)
+		}
+	}
+
+	// Pruned chains are removed from the index
+	indexDB := prefixdb.New(ChainIndexDBPrefix, db)
+	has, err := indexDB.Has(untrackedChainID[:])
+	require.NoError(err)
+	require.False(has)
+
+	count, err := database.Count(indexDB)
+	require.NoError(err)
+	require.Equal(2, count)
+}
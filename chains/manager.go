@@ -21,12 +21,14 @@ import (
 	"github.com/ava-labs/avalanchego/api/server"
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/cachedb"
 	"github.com/ava-labs/avalanchego/database/meterdb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
 	"github.com/ava-labs/avalanchego/network"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/snow/engine/avalanche/bootstrap/queue"
@@ -94,6 +96,10 @@ var (
 	// Commonly shared VM DB prefix
 	VMDBPrefix = []byte("vm")
 
+	// ChainIndexDBPrefix is the prefix of the database that records the subnet
+	// of every chain that has been created on this node.
+	ChainIndexDBPrefix = []byte("chain_index")
+
 	// Bootstrapping prefixes for LinearizableVMs
 	VertexDBPrefix              = []byte("vertex")
 	VertexBootstrappingDBPrefix = []byte("vertex_bs")
@@ -223,6 +229,11 @@ type ManagerConfig struct {
 	Metrics        metrics.MultiGatherer
 	MeterDBMetrics metrics.MultiGatherer
 
+	// DBCacheBudget is the read cache shared by the databases of all chains.
+	// If nil, reads aren't cached.
+	DBCacheBudget  *cachedb.Budget
+	DBCacheMetrics metrics.MultiGatherer
+
 	FrontierPollFrequency   time.Duration
 	ConsensusAppConcurrency int
 
@@ -240,10 +251,20 @@ type ManagerConfig struct {
 	// Tracks CPU/disk usage caused by each peer.
 	ResourceTracker timetracker.ResourceTracker
 
+	// Scores peers based on their behavior.
+	ReputationTracker reputation.Tracker
+
 	StateSyncBeacons []ids.NodeID
 
 	ChainDataDir string
 
+	// TrackedSubnets are the subnets, other than the primary network, whose
+	// chains this node runs.
+	TrackedSubnets set.Set[ids.ID]
+	// PruneUntrackedChains causes the databases of previously created chains
+	// that are not in [TrackedSubnets] to be deleted on startup.
+	PruneUntrackedChains bool
+
 	Subnets *Subnets
 }
 
@@ -384,6 +405,17 @@ func (m *manager) createChain(chainParams ChainParameters) {
 
 	sb, _ := m.Subnets.GetOrCreate(chainParams.SubnetID)
 
+	// The chain is indexed before it is built so that any data written during
+	// a failed creation can still be pruned.
+	if err := m.indexChain(chainParams.ID, chainParams.SubnetID); err != nil {
+		m.Log.Error("failed to index chain",
+			zap.Stringer("subnetID", chainParams.SubnetID),
+			zap.Stringer("chainID", chainParams.ID),
+			zap.Stringer("vmID", chainParams.VMID),
+			zap.Error(err),
+		)
+	}
+
 	// Note: buildChain builds all chain's relevant objects (notably engine and handler)
 	// but does not start their operations. Starting of the handler (which could potentially
 	// issue some internal messages), is delayed until chain dispatching is started and
@@ -514,8 +546,9 @@ func (m *manager) buildChain(chainParams ChainParameters, sb subnets.Subnet) (*c
 
 			WarpSigner: warp.NewSigner(m.StakingBLSKey, m.NetworkID, chainParams.ID),
 
-			ValidatorState: m.validatorState,
-			ChainDataDir:   chainDataDir,
+			ValidatorState:    m.validatorState,
+			ReputationTracker: m.ReputationTracker,
+			ChainDataDir:      chainDataDir,
 		},
 		PrimaryAlias:   primaryAlias,
 		Registerer:     prometheus.NewRegistry(),
@@ -637,7 +670,12 @@ func (m *manager) createAvalancheChain(
 		return nil, err
 	}
 
-	prefixDB := prefixdb.New(ctx.ChainID[:], meterDB)
+	chainDB, err := m.cacheChainDB(primaryAlias, meterDB)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixDB := prefixdb.New(ctx.ChainID[:], chainDB)
 	vmDB := prefixdb.New(VMDBPrefix, prefixDB)
 	vertexDB := prefixdb.New(VertexDBPrefix, prefixDB)
 	vertexBootstrappingDB := prefixdb.New(VertexBootstrappingDBPrefix, prefixDB)
@@ -880,6 +918,7 @@ func (m *manager) createAvalancheChain(
 		p2pReg,
 		set.Of(ctx.NodeID),
 		nil,
+		m.ReputationTracker,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error creating peer tracker: %w", err)
@@ -1094,7 +1133,12 @@ func (m *manager) createSnowmanChain(
 		return nil, err
 	}
 
-	prefixDB := prefixdb.New(ctx.ChainID[:], meterDB)
+	chainDB, err := m.cacheChainDB(primaryAlias, meterDB)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixDB := prefixdb.New(ctx.ChainID[:], chainDB)
 	vmDB := prefixdb.New(VMDBPrefix, prefixDB)
 	bootstrappingDB := prefixdb.New(ChainBootstrappingDBPrefix, prefixDB)
 
@@ -1305,6 +1349,7 @@ func (m *manager) createSnowmanChain(
 		p2pReg,
 		set.Of(ctx.NodeID),
 		nil,
+		m.ReputationTracker,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error creating peer tracker: %w", err)
@@ -1515,6 +1560,12 @@ func (m *manager) registerBootstrappedHealthChecks() error {
 
 // Starts chain creation loop to process queued chains
 func (m *manager) StartChainCreator(platformParams ChainParameters) error {
+	if m.PruneUntrackedChains {
+		if err := m.pruneUntrackedChains(); err != nil {
+			return fmt.Errorf("failed to prune untracked chains: %w", err)
+		}
+	}
+
 	// Add the P-Chain to the Primary Network
 	sb, _ := m.Subnets.GetOrCreate(constants.PrimaryNetworkID)
 	sb.AddChain(platformParams.ID)
@@ -1615,6 +1666,23 @@ func (m *manager) getOrMakeVMGatherer(vmID ids.ID) (metrics.MultiGatherer, error
 	return vmGatherer, nil
 }
 
+// cacheChainDB wraps [db] with a read cache drawing from the node's cache
+// budget. If reads aren't cached, [db] is returned.
+func (m *manager) cacheChainDB(primaryAlias string, db database.Database) (database.Database, error) {
+	if m.DBCacheBudget == nil {
+		return db, nil
+	}
+
+	dbCacheReg, err := metrics.MakeAndRegister(
+		m.DBCacheMetrics,
+		primaryAlias,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cachedb.New(dbCacheReg, m.DBCacheBudget, db)
+}
+
 func getLastAcceptedHeight(vm block.ChainVM) (uint64, error) {
 	lastAcceptedBlock, err := vm.LastAccepted(context.Background())
 	if err != nil {
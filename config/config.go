@@ -24,6 +24,8 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network"
 	"github.com/ava-labs/avalanchego/network/dialer"
+	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/snow/consensus/simplex"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
@@ -57,6 +59,9 @@ const (
 	subnetConfigFileExt  = ".json"
 
 	maxDiskSpaceThreshold = 50
+
+	minZstdLevel = 1
+	maxZstdLevel = 22
 )
 
 type consensusMode int
@@ -482,6 +487,9 @@ func getNetworkConfig(
 
 		TLSKeyLogFile: v.GetString(NetworkTLSKeyLogFileKey),
 
+		QUICEnabled: v.GetBool(NetworkQUICEnabledKey),
+		QUICPort:    uint16(v.GetUint(NetworkQUICPortKey)),
+
 		TimeoutConfig: network.TimeoutConfig{
 			PingPongTimeout:      v.GetDuration(NetworkPingTimeoutKey),
 			ReadHandshakeTimeout: v.GetDuration(NetworkReadHandshakeTimeoutKey),
@@ -500,6 +508,8 @@ func getNetworkConfig(
 
 		MaxClockDifference:           v.GetDuration(NetworkMaxClockDifferenceKey),
 		CompressionType:              compressionType,
+		CompressionZstdLevel:         v.GetInt(NetworkCompressionZstdLevelKey),
+		TracePropagationEnabled:      v.GetBool(TracingPropagationEnabledKey),
 		PingFrequency:                v.GetDuration(NetworkPingFrequencyKey),
 		AllowPrivateIPs:              allowPrivateIPs,
 		UptimeMetricFreq:             v.GetDuration(UptimeMetricFreqKey),
@@ -511,6 +521,12 @@ func getNetworkConfig(
 		RequireValidatorToConnect: v.GetBool(NetworkRequireValidatorToConnectKey),
 		PeerReadBufferSize:        int(v.GetUint(NetworkPeerReadBufferSizeKey)),
 		PeerWriteBufferSize:       int(v.GetUint(NetworkPeerWriteBufferSizeKey)),
+		SendQueueWeights: peer.PriorityWeights{
+			Consensus: v.GetUint64(NetworkSendQueueConsensusWeightKey),
+			Gossip:    v.GetUint64(NetworkSendQueueGossipWeightKey),
+			Bootstrap: v.GetUint64(NetworkSendQueueBootstrapWeightKey),
+			App:       v.GetUint64(NetworkSendQueueAppWeightKey),
+		},
 	}
 
 	switch {
@@ -548,6 +564,10 @@ func getNetworkConfig(
 		return network.Config{}, fmt.Errorf("%s must be >= 0", NetworkReadHandshakeTimeoutKey)
 	case config.MaxClockDifference < 0:
 		return network.Config{}, fmt.Errorf("%s must be >= 0", NetworkMaxClockDifferenceKey)
+	case config.CompressionZstdLevel < minZstdLevel || config.CompressionZstdLevel > maxZstdLevel:
+		return network.Config{}, fmt.Errorf("%s must be in [%d, %d]", NetworkCompressionZstdLevelKey, minZstdLevel, maxZstdLevel)
+	case config.SendQueueWeights.Verify() != nil:
+		return network.Config{}, fmt.Errorf("%s, %s, %s and %s must be > 0", NetworkSendQueueConsensusWeightKey, NetworkSendQueueGossipWeightKey, NetworkSendQueueBootstrapWeightKey, NetworkSendQueueAppWeightKey)
 	}
 	return config, nil
 }
@@ -1001,6 +1021,12 @@ func getDatabaseConfig(v *viper.Viper, networkID uint32) (node.DatabaseConfig, e
 			constants.NetworkName(networkID),
 		),
 		Config: configBytes,
+		SnapshotDir: filepath.Join(
+			getExpandedArg(v, DBSnapshotDirKey),
+			constants.NetworkName(networkID),
+		),
+		RestoreSnapshot: v.GetString(DBRestoreSnapshotKey),
+		CacheSize:       v.GetUint64(DBCacheSizeKey),
 	}, nil
 }
 
@@ -1404,6 +1430,7 @@ func GetNodeConfig(v *viper.Viper) (node.Config, error) {
 	if err != nil {
 		return node.Config{}, err
 	}
+	nodeConfig.PruneUntrackedChains = v.GetBool(PruneUntrackedChainsKey)
 
 	// HTTP APIs
 	nodeConfig.HTTPConfig, err = getHTTPConfig(v)
@@ -1475,6 +1502,15 @@ func GetNodeConfig(v *viper.Viper) (node.Config, error) {
 		return node.Config{}, err
 	}
 
+	// Reputation
+	nodeConfig.ReputationConfig = reputation.DefaultConfig
+	nodeConfig.ReputationConfig.Enabled = v.GetBool(ReputationEnabledKey)
+	nodeConfig.ReputationConfig.Halflife = v.GetDuration(ReputationHalflifeKey)
+	nodeConfig.ReputationConfig.MinScore = v.GetFloat64(ReputationMinScoreKey)
+	if err := nodeConfig.ReputationConfig.Verify(); err != nil {
+		return node.Config{}, fmt.Errorf("invalid reputation config: %w", err)
+	}
+
 	// File Descriptor Limit
 	nodeConfig.FdLimit = v.GetUint64(FdLimitKey)
 
@@ -17,6 +17,7 @@ import (
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/database/pebbledb"
 	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/consensus/simplex"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
@@ -45,6 +46,7 @@ var (
 	// [defaultUnexpandedDataDir] will be expanded when reading the flags
 	defaultDataDir              = filepath.Join("$HOME", ".avalanchego")
 	defaultDBDir                = filepath.Join(defaultUnexpandedDataDir, "db")
+	defaultDBSnapshotDir        = filepath.Join(defaultUnexpandedDataDir, "db-snapshots")
 	defaultLogDir               = filepath.Join(defaultUnexpandedDataDir, "logs")
 	defaultProfileDir           = filepath.Join(defaultUnexpandedDataDir, "profiles")
 	defaultStakingPath          = filepath.Join(defaultUnexpandedDataDir, "staking")
@@ -133,6 +135,9 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.String(DBPathKey, defaultDBDir, "Path to database directory")
 	fs.String(DBConfigFileKey, "", fmt.Sprintf("Path to database config file. Ignored if %s is specified", DBConfigContentKey))
 	fs.String(DBConfigContentKey, "", "Specifies base64 encoded database config content")
+	fs.String(DBSnapshotDirKey, defaultDBSnapshotDir, "Path to the directory that database snapshots are written to")
+	fs.String(DBRestoreSnapshotKey, "", "Name of a database snapshot to restore the database from on startup. The existing database is moved aside rather than deleted, and a snapshot is only restored once")
+	fs.Uint64(DBCacheSizeKey, 0, "Size, in bytes, of the read cache shared by the databases of all chains. If 0, reads aren't cached")
 
 	// Logging
 	fs.String(LogsDirKey, defaultLogDir, "Logging directory for Avalanche")
@@ -172,7 +177,8 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.Duration(NetworkPingTimeoutKey, constants.DefaultPingPongTimeout, "Timeout value for Ping-Pong with a peer")
 	fs.Duration(NetworkPingFrequencyKey, constants.DefaultPingFrequency, "Frequency of pinging other peers")
 	fs.Duration(NetworkNoIngressValidatorConnectionsGracePeriodKey, constants.DefaultNoIngressValidatorConnectionGracePeriod, "Time after which nodes are expected to be connected to us if we are a primary network validator, otherwise a health check fails")
-	fs.String(NetworkCompressionTypeKey, constants.DefaultNetworkCompressionType.String(), fmt.Sprintf("Compression type for outbound messages. Must be one of [%s, %s]", compression.TypeZstd, compression.TypeNone))
+	fs.String(NetworkCompressionTypeKey, constants.DefaultNetworkCompressionType.String(), fmt.Sprintf("Preferred compression type for outbound messages. Peers that don't support it are sent messages compressed with a type they support. Must be one of %s", compression.Types))
+	fs.Int(NetworkCompressionZstdLevelKey, constants.DefaultNetworkCompressionZstdLevel, fmt.Sprintf("Level of zstd compression of outbound messages. Must be in [%d, %d]", minZstdLevel, maxZstdLevel))
 
 	fs.Duration(NetworkMaxClockDifferenceKey, constants.DefaultNetworkMaxClockDifference, "Max allowed clock difference value between this node and peers")
 	// Note: The default value is set to false here because the default
@@ -182,6 +188,10 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.Bool(NetworkRequireValidatorToConnectKey, constants.DefaultNetworkRequireValidatorToConnect, "If true, this node will only maintain a connection with another node if this node is a validator, the other node is a validator, or the other node is a beacon")
 	fs.Uint(NetworkPeerReadBufferSizeKey, constants.DefaultNetworkPeerReadBufferSize, "Size, in bytes, of the buffer that we read peer messages into (there is one buffer per peer)")
 	fs.Uint(NetworkPeerWriteBufferSizeKey, constants.DefaultNetworkPeerWriteBufferSize, "Size, in bytes, of the buffer that we write peer messages into (there is one buffer per peer)")
+	fs.Uint64(NetworkSendQueueConsensusWeightKey, constants.DefaultNetworkSendQueueConsensusWeight, "Relative share of the bandwidth to each peer given to consensus messages. Must be > 0")
+	fs.Uint64(NetworkSendQueueGossipWeightKey, constants.DefaultNetworkSendQueueGossipWeight, "Relative share of the bandwidth to each peer given to gossip messages. Must be > 0")
+	fs.Uint64(NetworkSendQueueBootstrapWeightKey, constants.DefaultNetworkSendQueueBootstrapWeight, "Relative share of the bandwidth to each peer given to bootstrapping and state sync messages. Must be > 0")
+	fs.Uint64(NetworkSendQueueAppWeightKey, constants.DefaultNetworkSendQueueAppWeight, "Relative share of the bandwidth to each peer given to application requests and responses. Must be > 0")
 
 	fs.Bool(NetworkTCPProxyEnabledKey, constants.DefaultNetworkTCPProxyEnabled, "Require all P2P connections to be initiated with a TCP proxy header")
 	// The PROXY protocol specification recommends setting this value to be at
@@ -193,12 +203,20 @@ func addNodeFlags(fs *pflag.FlagSet) {
 
 	fs.String(NetworkTLSKeyLogFileKey, "", "TLS key log file path. Should only be specified for debugging")
 
+	fs.Bool(NetworkQUICEnabledKey, false, "If true, accepts QUIC connections and connects over QUIC to peers that advertise QUIC support")
+	fs.Uint(NetworkQUICPortKey, 0, fmt.Sprintf("UDP port to accept QUIC connections on. If 0, the --%s is used", StakingPortKey))
+
 	// Benchlist
 	fs.Duration(BenchlistHalflifeKey, benchlist.DefaultHalflife, "Halflife of the EWMA averager used for benchlisting")
 	fs.Float64(BenchlistUnbenchProbabilityKey, benchlist.DefaultUnbenchProbability, "EWMA failure probability below which a node is unbenched")
 	fs.Float64(BenchlistBenchProbabilityKey, benchlist.DefaultBenchProbability, "EWMA failure probability above which a node is benched")
 	fs.Duration(BenchlistDurationKey, benchlist.DefaultBenchDuration, "Max amount of time a peer is benchlisted")
 
+	// Reputation
+	fs.Bool(ReputationEnabledKey, reputation.DefaultConfig.Enabled, "If true, peers are scored based on their behavior and peers with a score below the minimum score aren't trusted")
+	fs.Duration(ReputationHalflifeKey, reputation.DefaultConfig.Halflife, "Halflife of the decay of the penalties and bandwidth used to score peers")
+	fs.Float64(ReputationMinScoreKey, reputation.DefaultConfig.MinScore, "Score below which a peer isn't trusted. Untrusted peers aren't sampled for gossip and are only selected for requests if no other peer is available. Inbound connections are rejected from peers whose penalties, excluding timeouts, are below the minimum score, unless they are validators or beacons. Must be non-positive")
+
 	// Router
 	fs.Uint(ConsensusAppConcurrencyKey, constants.DefaultConsensusAppConcurrency, "Maximum number of goroutines to use when handling App messages on a chain")
 	fs.Duration(ConsensusShutdownTimeoutKey, constants.DefaultConsensusShutdownTimeout, "Timeout before killing an unresponsive chain")
@@ -293,6 +311,7 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.Uint64(StakeSupplyCapKey, genesis.LocalParams.RewardConfig.SupplyCap, "Supply cap of the staking function")
 	// Subnets
 	fs.String(TrackSubnetsKey, "", "List of subnets for the node to track. A node tracking a subnet will track the uptimes of the subnet validators and attempt to sync all the chains in the subnet. Before validating a subnet, a node should be tracking the subnet to avoid impacting their subnet validation uptime")
+	fs.Bool(PruneUntrackedChainsKey, false, "If true, the database of every chain that was previously run on a subnet that is no longer tracked is deleted on startup")
 
 	// State syncing
 	fs.String(StateSyncIPsKey, "", "Comma separated list of state sync peer ips to connect to. Example: 127.0.0.1:9630,127.0.0.1:9631")
@@ -386,6 +405,7 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.String(TracingEndpointKey, "", "The endpoint to send trace data to. If unspecified, the default endpoint will be used; depending on the exporter type")
 	fs.Bool(TracingInsecureKey, true, "If true, don't use TLS when sending trace data")
 	fs.Float64(TracingSampleRateKey, 0.1, "The fraction of traces to sample. If >= 1, always sample. If <= 0, never sample")
+	fs.Bool(TracingPropagationEnabledKey, false, "If true, propagates trace context in AppRequest and AppResponse messages exchanged with peers that also enable it, so that traces span multiple nodes")
 	fs.StringToString(TracingHeadersKey, map[string]string{}, "The headers to provide the trace indexer")
 
 	fs.String(ProcessContextFileKey, defaultProcessContextPath, "The path to write process context to (including PID, API URI, and staking address).")
@@ -53,6 +53,9 @@ const (
 	DBPathKey                                = "db-dir"
 	DBConfigFileKey                          = "db-config-file"
 	DBConfigContentKey                       = "db-config-file-content"
+	DBSnapshotDirKey                         = "db-snapshot-dir"
+	DBRestoreSnapshotKey                     = "db-restore-snapshot"
+	DBCacheSizeKey                           = "db-cache-size"
 	PublicIPKey                              = "public-ip"
 	PublicIPResolutionFreqKey                = "public-ip-resolution-frequency"
 	PublicIPResolutionServiceKey             = "public-ip-resolution-service"
@@ -111,14 +114,21 @@ const (
 	NetworkPingFrequencyKey                              = "network-ping-frequency"
 	NetworkMaxReconnectDelayKey                          = "network-max-reconnect-delay"
 	NetworkCompressionTypeKey                            = "network-compression-type"
+	NetworkCompressionZstdLevelKey                       = "network-compression-zstd-level"
 	NetworkMaxClockDifferenceKey                         = "network-max-clock-difference"
 	NetworkAllowPrivateIPsKey                            = "network-allow-private-ips"
 	NetworkRequireValidatorToConnectKey                  = "network-require-validator-to-connect"
 	NetworkPeerReadBufferSizeKey                         = "network-peer-read-buffer-size"
 	NetworkPeerWriteBufferSizeKey                        = "network-peer-write-buffer-size"
+	NetworkSendQueueConsensusWeightKey                   = "network-send-queue-consensus-weight"
+	NetworkSendQueueGossipWeightKey                      = "network-send-queue-gossip-weight"
+	NetworkSendQueueBootstrapWeightKey                   = "network-send-queue-bootstrap-weight"
+	NetworkSendQueueAppWeightKey                         = "network-send-queue-app-weight"
 	NetworkTCPProxyEnabledKey                            = "network-tcp-proxy-enabled"
 	NetworkTCPProxyReadTimeoutKey                        = "network-tcp-proxy-read-timeout"
 	NetworkTLSKeyLogFileKey                              = "network-tls-key-log-file-unsafe"
+	NetworkQUICEnabledKey                                = "network-quic-enabled"
+	NetworkQUICPortKey                                   = "network-quic-port"
 	NetworkInboundConnUpgradeThrottlerCooldownKey        = "network-inbound-connection-throttling-cooldown"
 	NetworkInboundThrottlerMaxConnsPerSecKey             = "network-inbound-connection-throttling-max-conns-per-sec"
 	NetworkOutboundConnectionThrottlingRpsKey            = "network-outbound-connection-throttling-rps"
@@ -128,6 +138,9 @@ const (
 	BenchlistUnbenchProbabilityKey                       = "benchlist-unbench-probability"
 	BenchlistBenchProbabilityKey                         = "benchlist-bench-probability"
 	BenchlistDurationKey                                 = "benchlist-duration"
+	ReputationEnabledKey                                 = "reputation-enabled"
+	ReputationHalflifeKey                                = "reputation-halflife"
+	ReputationMinScoreKey                                = "reputation-min-score"
 	LogsDirKey                                           = "log-dir"
 	LogLevelKey                                          = "log-level"
 	LogDisplayLevelKey                                   = "log-display-level"
@@ -148,6 +161,7 @@ const (
 	SnowMaxTimeProcessingKey                             = "snow-max-time-processing"
 	PartialSyncPrimaryNetworkKey                         = "partial-sync-primary-network"
 	TrackSubnetsKey                                      = "track-subnets"
+	PruneUntrackedChainsKey                              = "prune-untracked-chains"
 	AdminAPIEnabledKey                                   = "api-admin-enabled"
 	InfoAPIEnabledKey                                    = "api-info-enabled"
 	MetricsAPIEnabledKey                                 = "api-metrics-enabled"
@@ -214,5 +228,6 @@ const (
 	TracingSampleRateKey                                 = "tracing-sample-rate"
 	TracingExporterTypeKey                               = "tracing-exporter-type"
 	TracingHeadersKey                                    = "tracing-headers"
+	TracingPropagationEnabledKey                         = "tracing-propagation-enabled"
 	ProcessContextFileKey                                = "process-context-file"
 )
@@ -13,6 +13,7 @@ import (
 	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/snow/networking/tracker"
@@ -124,6 +125,17 @@ type DatabaseConfig struct {
 
 	// Path to config file
 	Config []byte `json:"-"`
+
+	// Path to the directory that database snapshots are written to
+	SnapshotDir string `json:"snapshotDir"`
+
+	// Name of the snapshot to restore the database from on startup. If empty,
+	// the existing database is used.
+	RestoreSnapshot string `json:"restoreSnapshot"`
+
+	// Size, in bytes, of the read cache shared by the databases of all chains.
+	// If 0, reads aren't cached.
+	CacheSize uint64 `json:"cacheSize"`
 }
 
 // Config contains all of the configurations of an Avalanche node.
@@ -155,6 +167,8 @@ type Config struct {
 
 	BenchlistConfig benchlist.Config `json:"benchlistConfig"`
 
+	ReputationConfig reputation.Config `json:"reputationConfig"`
+
 	ProfilerConfig profiler.Config `json:"profilerConfig"`
 
 	LoggingConfig logging.Config `json:"loggingConfig"`
@@ -176,6 +190,9 @@ type Config struct {
 	ConsensusAppConcurrency int `json:"consensusAppConcurrency"`
 
 	TrackedSubnets set.Set[ids.ID] `json:"trackedSubnets"`
+	// PruneUntrackedChains causes the data of chains on subnets that are no
+	// longer tracked to be deleted on startup.
+	PruneUntrackedChains bool `json:"pruneUntrackedChains"`
 
 	// ProposerMinBlockDelay is the minimum delay this node will enforce when
 	// building a snowman++ block on the P-chain and the X-chain. All other
@@ -34,6 +34,16 @@ type Batch interface {
 	Inner() Batch
 }
 
+// ReplayedBatch is implemented by batches that must be notified when their
+// operations were replayed onto a batch of the base database, such as when
+// shared memory is modified atomically with the batch, and that batch was
+// written.
+type ReplayedBatch interface {
+	// Written is called once the batch the operations were replayed onto was
+	// written, whether or not the write succeeded.
+	Written()
+}
+
 // Batcher wraps the NewBatch method of a backing data store.
 type Batcher interface {
 	// NewBatch creates a write-only database that buffers changes to its host db
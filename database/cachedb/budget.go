@@ -0,0 +1,103 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cachedb
+
+import (
+	"sync/atomic"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/cache/lru"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+// entryOverhead approximates the memory used by a cache entry in addition to
+// its key and value.
+const entryOverhead = 4*constants.PointerOverhead + 3*8 + 1
+
+var _ cache.Cacher[string, *entry] = (*budgetCache)(nil)
+
+// Budget is a memory budget that is shared by multiple databases. The least
+// recently used entries are evicted across all of the databases drawing from
+// the budget.
+type Budget struct {
+	cache  *lru.SizedCache[budgetKey, *entry]
+	nextID atomic.Uint64
+}
+
+// NewBudget returns a budget that caches at most [size] bytes.
+func NewBudget(size int) *Budget {
+	return &Budget{
+		cache: lru.NewSizedCache(size, entrySize),
+	}
+}
+
+type budgetKey struct {
+	// db is the ID of the database the entry belongs to.
+	db uint64
+	// generation is incremented whenever a database's entries are flushed.
+	generation uint64
+	key        string
+}
+
+// entry is a cached result of a database lookup. If exists is false, the key
+// wasn't in the database.
+type entry struct {
+	value  []byte
+	exists bool
+}
+
+func entrySize(key budgetKey, entry *entry) int {
+	return len(key.key) + len(entry.value) + entryOverhead
+}
+
+// budgetCache is the view of the budget used by a single database.
+//
+// Len and PortionFilled report the usage of the entire budget.
+type budgetCache struct {
+	budget     *Budget
+	id         uint64
+	generation atomic.Uint64
+}
+
+func (b *Budget) newCache() *budgetCache {
+	return &budgetCache{
+		budget: b,
+		id:     b.nextID.Add(1),
+	}
+}
+
+func (c *budgetCache) Put(key string, value *entry) {
+	c.budget.cache.Put(c.budgetKey(key), value)
+}
+
+func (c *budgetCache) Get(key string) (*entry, bool) {
+	return c.budget.cache.Get(c.budgetKey(key))
+}
+
+func (c *budgetCache) Evict(key string) {
+	c.budget.cache.Evict(c.budgetKey(key))
+}
+
+// Flush makes all of the entries of this database unreachable. Rather than
+// removing them from the shared cache, they are evicted once they become the
+// least recently used entries.
+func (c *budgetCache) Flush() {
+	c.generation.Add(1)
+}
+
+func (c *budgetCache) Len() int {
+	return c.budget.cache.Len()
+}
+
+func (c *budgetCache) PortionFilled() float64 {
+	return c.budget.cache.PortionFilled()
+}
+
+func (c *budgetCache) budgetKey(key string) budgetKey {
+	return budgetKey{
+		db:         c.id,
+		generation: c.generation.Load(),
+		key:        key,
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cachedb
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/cache/metercacher"
+	"github.com/ava-labs/avalanchego/database"
+)
+
+var (
+	_ database.Database              = (*Database)(nil)
+	_ database.RangeDeleter          = (*Database)(nil)
+	_ database.Batch                 = (*batch)(nil)
+	_ database.Batch                 = (*innerBatch)(nil)
+	_ database.ReplayedBatch         = (*innerBatch)(nil)
+	_ database.KeyValueWriterDeleter = (*keyWriter)(nil)
+)
+
+// Database caches the results of Get and Has calls to an underlying database,
+// including the keys that don't exist.
+//
+// All writes to the underlying database must be made through this database,
+// otherwise the cache may return stale values. Iterators are not cached.
+type Database struct {
+	// lock is held for reading while a lookup populates the cache and for
+	// writing while the underlying database is modified, so that a lookup
+	// can't cache a value that was overwritten concurrently.
+	lock  sync.RWMutex
+	db    database.Database
+	cache cache.Cacher[string, *entry]
+	// replaying counts, for every key, the batches that modify the key and
+	// were replayed onto a batch that hasn't been written yet. Lookups of
+	// these keys aren't cached, as the underlying database may be modified at
+	// any time.
+	replaying map[string]int
+	closed    bool
+}
+
+// New returns a database that caches lookups to [db] using memory from
+// [budget]. Hit rate metrics are registered with [reg].
+func New(
+	reg prometheus.Registerer,
+	budget *Budget,
+	db database.Database,
+) (*Database, error) {
+	cache, err := metercacher.New[string, *entry](
+		"",
+		reg,
+		budget.newCache(),
+	)
+	return &Database{
+		db:        db,
+		cache:     cache,
+		replaying: make(map[string]int),
+	}, err
+}
+
+func (db *Database) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return false, database.ErrClosed
+	}
+	if entry, ok := db.cache.Get(string(key)); ok {
+		return entry.exists, nil
+	}
+
+	has, err := db.db.Has(key)
+	if err != nil {
+		return false, err
+	}
+	if !has && db.cacheable(key) {
+		db.cache.Put(string(key), &entry{})
+	}
+	return has, nil
+}
+
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	if entry, ok := db.cache.Get(string(key)); ok {
+		if !entry.exists {
+			return nil, database.ErrNotFound
+		}
+		return slices.Clone(entry.value), nil
+	}
+
+	value, err := db.db.Get(key)
+	switch {
+	case !db.cacheable(key):
+	case err == nil:
+		db.cache.Put(string(key), &entry{
+			value:  slices.Clone(value),
+			exists: true,
+		})
+	case errors.Is(err, database.ErrNotFound):
+		db.cache.Put(string(key), &entry{})
+	}
+	return value, err
+}
+
+// cacheable returns false if [key] may be modified by a replayed batch.
+//
+// Assumes the lock is held.
+func (db *Database) cacheable(key []byte) bool {
+	_, ok := db.replaying[string(key)]
+	return !ok
+}
+
+func (db *Database) Put(key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	if err := db.db.Put(key, value); err != nil {
+		db.cache.Evict(string(key))
+		return err
+	}
+	db.cache.Put(string(key), &entry{
+		value:  slices.Clone(value),
+		exists: true,
+	})
+	return nil
+}
+
+func (db *Database) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	if err := db.db.Delete(key); err != nil {
+		db.cache.Evict(string(key))
+		return err
+	}
+	db.cache.Put(string(key), &entry{})
+	return nil
+}
+
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		Batch: db.db.NewBatch(),
+		db:    db,
+	}
+}
+
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return db.db.NewIteratorWithStartAndPrefix(start, prefix)
+}
+
+func (db *Database) Compact(start, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return db.db.Compact(start, limit)
+}
+
+// DeleteRange removes all keys in the range [start, limit) and flushes the
+// cache.
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	// The cache is flushed even if the deletion fails because some of the
+	// keys may have been removed.
+	defer db.cache.Flush()
+	return database.DeleteRange(db.db, start, limit)
+}
+
+// Close flushes the cache and closes the underlying database.
+func (db *Database) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	db.closed = true
+	db.cache.Flush()
+	return db.db.Close()
+}
+
+func (db *Database) HealthCheck(ctx context.Context) (interface{}, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	return db.db.HealthCheck(ctx)
+}
+
+type batch struct {
+	database.Batch
+	db *Database
+}
+
+// Write writes the batch to the underlying database and evicts every key
+// modified by the batch from the cache.
+func (b *batch) Write() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+
+	err := b.Batch.Write()
+	// The keys are evicted even if the write fails because some of the writes
+	// may have been applied.
+	b.evict()
+	return err
+}
+
+// Inner returns the batch of the underlying database, so that its operations
+// can be replayed onto a batch of the base database, such as when shared memory
+// is modified atomically with the batch.
+//
+// Lookups of the replayed keys bypass the cache until the returned batch is
+// notified with [database.ReplayedBatch.Written] that the base batch was
+// written, at which point the keys are evicted.
+func (b *batch) Inner() database.Batch {
+	return &innerBatch{
+		Batch: b.Batch.Inner(),
+		outer: b,
+	}
+}
+
+// evict evicts every key modified by the batch from the cache.
+//
+// Assumes the lock is held.
+func (b *batch) evict() {
+	b.forEachKey(b.db.cache.Evict)
+}
+
+func (b *batch) forEachKey(f func(key string)) {
+	_ = b.Batch.Replay(keyWriter(f))
+}
+
+// innerBatch is the batch of the underlying database of [outer]. Writing it
+// evicts the keys modified by [outer], and replaying it bypasses the cache for
+// those keys until it is notified that the replayed operations were written.
+type innerBatch struct {
+	database.Batch
+	outer     *batch
+	replaying bool
+}
+
+func (b *innerBatch) Write() error {
+	db := b.outer.db
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	err := b.Batch.Write()
+	b.outer.evict()
+	return err
+}
+
+func (b *innerBatch) Replay(w database.KeyValueWriterDeleter) error {
+	db := b.outer.db
+	db.lock.Lock()
+	if !b.replaying {
+		b.replaying = true
+		b.outer.forEachKey(func(key string) {
+			db.replaying[key]++
+			db.cache.Evict(key)
+		})
+	}
+	db.lock.Unlock()
+
+	return b.Batch.Replay(w)
+}
+
+func (b *innerBatch) Written() {
+	db := b.outer.db
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if !b.replaying {
+		return
+	}
+	b.replaying = false
+	b.outer.forEachKey(func(key string) {
+		db.cache.Evict(key)
+		db.replaying[key]--
+		if db.replaying[key] == 0 {
+			delete(db.replaying, key)
+		}
+	})
+}
+
+func (b *innerBatch) Inner() database.Batch {
+	return b
+}
+
+// keyWriter calls the function with every key written to it.
+type keyWriter func(key string)
+
+func (w keyWriter) Put(key, _ []byte) error {
+	w(string(key))
+	return nil
+}
+
+func (w keyWriter) Delete(key []byte) error {
+	w(string(key))
+	return nil
+}
@@ -0,0 +1,259 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cachedb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/chains/atomic"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/dbtest"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/versiondb"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+func newDB(t testing.TB, budget *Budget, db database.Database) *Database {
+	t.Helper()
+
+	cacheDB, err := New(prometheus.NewRegistry(), budget, db)
+	require.NoError(t, err)
+	return cacheDB
+}
+
+func TestInterface(t *testing.T) {
+	for name, test := range dbtest.Tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newDB(t, NewBudget(units.MiB), memdb.New()))
+		})
+	}
+}
+
+func TestCachesLookups(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB  = memdb.New()
+		reg     = prometheus.NewRegistry()
+		db, err = New(reg, NewBudget(units.MiB), baseDB)
+	)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("exists"), []byte("value")))
+
+	_, err = db.Get([]byte("missing"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Modifying the underlying database directly isn't observed because the
+	// results are cached.
+	require.NoError(baseDB.Put([]byte("missing"), []byte("value")))
+	require.NoError(baseDB.Delete([]byte("exists")))
+
+	_, err = db.Get([]byte("missing"))
+	require.ErrorIs(err, database.ErrNotFound)
+	has, err := db.Has([]byte("missing"))
+	require.NoError(err)
+	require.False(has)
+
+	value, err := db.Get([]byte("exists"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	has, err = db.Has([]byte("exists"))
+	require.NoError(err)
+	require.True(has)
+
+	// Returned values are safe to modify.
+	value[0] = 'V'
+	value, err = db.Get([]byte("exists"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	metrics, err := reg.Gather()
+	require.NoError(err)
+	var hits, misses float64
+	for _, family := range metrics {
+		if family.GetName() != "get_count" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			switch metric.GetLabel()[0].GetValue() {
+			case "hit":
+				hits = metric.GetCounter().GetValue()
+			case "miss":
+				misses = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	require.Equal(float64(5), hits)
+	require.Equal(float64(1), misses)
+}
+
+func TestBatchInvalidatesCache(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB = memdb.New()
+		db     = newDB(t, NewBudget(units.MiB), baseDB)
+	)
+	require.NoError(db.Put([]byte("put"), []byte("old")))
+	require.NoError(db.Put([]byte("deleted"), []byte("old")))
+	_, err := db.Get([]byte("created"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("put"), []byte("new")))
+	require.NoError(batch.Put([]byte("created"), []byte("new")))
+	require.NoError(batch.Delete([]byte("deleted")))
+	require.NoError(batch.Write())
+
+	for _, key := range []string{"put", "created"} {
+		value, err := db.Get([]byte(key))
+		require.NoError(err)
+		require.Equal([]byte("new"), value)
+	}
+	_, err = db.Get([]byte("deleted"))
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestWriteAllInvalidatesCache(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB   = memdb.New()
+		cacheDB  = newDB(t, NewBudget(units.MiB), prefixdb.New([]byte("node"), baseDB))
+		chainDB  = prefixdb.New([]byte("chain"), cacheDB)
+		sharedDB = prefixdb.New([]byte("shared"), baseDB)
+	)
+	_, err := chainDB.Get([]byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	vmDB := versiondb.New(chainDB)
+	require.NoError(vmDB.Put([]byte("key"), []byte("value")))
+	vmBatch, err := vmDB.CommitBatch()
+	require.NoError(err)
+
+	sharedVDB := versiondb.New(sharedDB)
+	require.NoError(sharedVDB.Put([]byte("shared"), []byte("value")))
+	sharedBatch, err := sharedVDB.CommitBatch()
+	require.NoError(err)
+
+	require.NoError(atomic.WriteAll(sharedBatch, vmBatch))
+
+	value, err := chainDB.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	value, err = sharedDB.Get([]byte("shared"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func TestReplayBypassesCache(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB   = memdb.New()
+		cacheDB  = newDB(t, NewBudget(units.MiB), prefixdb.New([]byte("node"), baseDB))
+		chainDB  = prefixdb.New([]byte("chain"), cacheDB)
+		sharedDB = prefixdb.New([]byte("shared"), baseDB)
+	)
+	_, err := chainDB.Get([]byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	vmDB := versiondb.New(chainDB)
+	require.NoError(vmDB.Put([]byte("key"), []byte("value")))
+	vmBatch, err := vmDB.CommitBatch()
+	require.NoError(err)
+	sharedBatch := sharedDB.NewBatch().Inner()
+
+	replayed := vmBatch.Inner()
+	require.IsType(&innerBatch{}, replayed)
+	require.NoError(replayed.Replay(sharedBatch))
+
+	// Lookups made before the base batch is written must not be cached.
+	_, err = chainDB.Get([]byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(sharedBatch.Write())
+	value, err := chainDB.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	replayed.(database.ReplayedBatch).Written()
+	require.Empty(cacheDB.replaying)
+	value, err = chainDB.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func TestDeleteRangeFlushesCache(t *testing.T) {
+	require := require.New(t)
+
+	db := newDB(t, NewBudget(units.MiB), memdb.New())
+	for i := 0; i < 3; i++ {
+		require.NoError(db.Put([]byte{byte(i)}, []byte{byte(i)}))
+	}
+
+	require.NoError(db.DeleteRange([]byte{0}, []byte{2}))
+
+	for i := 0; i < 2; i++ {
+		_, err := db.Get([]byte{byte(i)})
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+	value, err := db.Get([]byte{2})
+	require.NoError(err)
+	require.Equal([]byte{2}, value)
+}
+
+func TestSharedBudget(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		budget  = NewBudget(4 * (entryOverhead + 2))
+		baseDB1 = memdb.New()
+		baseDB2 = memdb.New()
+		db1     = newDB(t, budget, baseDB1)
+		db2     = newDB(t, budget, baseDB2)
+	)
+
+	// The same key in different databases is cached separately.
+	require.NoError(db1.Put([]byte{0}, []byte{1}))
+	require.NoError(db2.Put([]byte{0}, []byte{2}))
+	require.Equal(2, budget.cache.Len())
+
+	value, err := db1.Get([]byte{0})
+	require.NoError(err)
+	require.Equal([]byte{1}, value)
+	value, err = db2.Get([]byte{0})
+	require.NoError(err)
+	require.Equal([]byte{2}, value)
+
+	// Filling the budget from db2 evicts the least recently used entry of
+	// db1.
+	for i := 1; i <= 3; i++ {
+		require.NoError(db2.Put([]byte{byte(i)}, []byte{byte(i)}))
+	}
+	require.Equal(4, budget.cache.Len())
+
+	// db1's entry is no longer cached, so modifications to the underlying
+	// database are observed.
+	require.NoError(baseDB1.Put([]byte{0}, []byte{3}))
+	value, err = db1.Get([]byte{0})
+	require.NoError(err)
+	require.Equal([]byte{3}, value)
+}
+
+func BenchmarkInterface(b *testing.B) {
+	for _, size := range dbtest.BenchmarkSizes {
+		keys, values := dbtest.SetupBenchmark(b, size[0], size[1], size[2])
+		for name, bench := range dbtest.Benchmarks {
+			b.Run(fmt.Sprintf("cachedb_%d_pairs_%d_keys_%d_values_%s", size[0], size[1], size[2], name), func(b *testing.B) {
+				bench(b, newDB(b, NewBudget(units.MiB), memdb.New()), keys, values)
+			})
+		}
+	}
+}
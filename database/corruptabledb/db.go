@@ -15,8 +15,10 @@ import (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.RangeDeleter = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
+	_ database.Batch        = (*batch)(nil)
 )
 
 // CorruptableDB is a wrapper around Database
@@ -78,6 +80,27 @@ func (db *Database) Compact(start []byte, limit []byte) error {
 	return db.handleError(db.Database.Compact(start, limit))
 }
 
+func (db *Database) DeleteRange(start []byte, limit []byte) error {
+	if err := db.corrupted(); err != nil {
+		return err
+	}
+	return db.handleError(database.DeleteRange(db.Database, start, limit))
+}
+
+// Snapshot forwards the request to the underlying database if it supports
+// snapshots. Failing to write a snapshot does not imply that the database is
+// corrupted.
+func (db *Database) Snapshot(dir string) error {
+	if err := db.corrupted(); err != nil {
+		return err
+	}
+	snapshotter, ok := db.Database.(database.Snapshotter)
+	if !ok {
+		return database.ErrSnapshotNotSupported
+	}
+	return snapshotter.Snapshot(dir)
+}
+
 func (db *Database) Close() error {
 	return db.handleError(db.Database.Close())
 }
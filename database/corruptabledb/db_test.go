@@ -204,3 +204,11 @@ func TestIterator(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotNotSupported(t *testing.T) {
+	db := newDB()
+	require.ErrorIs(t, db.Snapshot(t.TempDir()), database.ErrSnapshotNotSupported)
+
+	// Failing to snapshot must not mark the database as corrupted.
+	require.NoError(t, db.Put([]byte("hello"), []byte("world")))
+}
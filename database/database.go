@@ -84,6 +84,34 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// RangeDeleter wraps the DeleteRange method of a backing data store.
+type RangeDeleter interface {
+	// DeleteRange removes all keys in the range [start, limit) from the
+	// key-value data store.
+	//
+	// A nil start is treated as a key before all keys in the data store.
+	// A nil limit is treated as a key after all keys in the data store.
+	// Therefore if both are nil then all keys are removed.
+	//
+	// Note: [start] and [limit] are safe to modify and read after calling
+	// DeleteRange.
+	DeleteRange(start []byte, limit []byte) error
+}
+
+// Snapshotter is implemented by databases that are able to write a
+// consistent, point-in-time copy of their contents to disk while they continue
+// to be used.
+type Snapshotter interface {
+	// Snapshot writes a copy of the database to [dir], which must not already
+	// exist. The resulting directory can be opened as a database of the same
+	// type.
+	//
+	// Writes that are performed concurrently with Snapshot may or may not be
+	// included in the snapshot, but the snapshot will never include a partial
+	// batch.
+	Snapshot(dir string) error
+}
+
 // Database contains all the methods required to allow handling different
 // key-value data stores backing the database.
 type Database interface {
@@ -120,6 +148,22 @@ type HeightIndex interface {
 	// data in the range [start, end].
 	Sync(start, end uint64) error
 
+	// NewIteratorFromHeight creates an iterator over the values with a height
+	// greater than or equal to [height], in increasing order of height.
+	//
+	// Values written after the iterator is created may or may not be
+	// returned by the iterator.
+	NewIteratorFromHeight(height uint64) HeightIterator
+
+	// Prune removes all values with a height less than [below].
+	//
+	// Implementations may reject writes to pruned heights.
+	Prune(below uint64) error
+
+	// Bounds returns the lowest and highest heights that have a value.
+	// Returns [ErrNotFound] if there are no values in the database.
+	Bounds() (lowest uint64, highest uint64, err error)
+
 	// Close closes the database.
 	//
 	// Calling Close after Close returns [ErrClosed].
@@ -8,6 +8,7 @@ import (
 	"io"
 	"math"
 	"math/rand"
+	"path/filepath"
 	"slices"
 	"testing"
 
@@ -62,6 +63,8 @@ var Tests = map[string]func(t *testing.T, db database.Database){
 	"Clear":                            TestClear,
 	"AtomicClearPrefix":                TestAtomicClearPrefix,
 	"ClearPrefix":                      TestClearPrefix,
+	"DeleteRange":                      TestDeleteRange,
+	"DeletePrefix":                     TestDeletePrefix,
 	"ModifyValueAfterBatchPut":         TestModifyValueAfterBatchPut,
 	"ModifyValueAfterBatchPutReplay":   TestModifyValueAfterBatchPutReplay,
 	"ConcurrentBatches":                TestConcurrentBatches,
@@ -965,6 +968,88 @@ func TestCompactNoPanic(t *testing.T, db database.Database) {
 	require.ErrorIs(err, database.ErrClosed)
 }
 
+func TestDeleteRange(t *testing.T, db database.Database) {
+	require := require.New(t)
+
+	keys := [][]byte{
+		[]byte("a"),
+		[]byte("b"),
+		[]byte("b1"),
+		[]byte("c"),
+		[]byte("d"),
+	}
+	for _, key := range keys {
+		require.NoError(db.Put(key, key))
+	}
+
+	// The limit is exclusive
+	require.NoError(database.DeleteRange(db, []byte("b"), []byte("c")))
+	require.Equal([][]byte{[]byte("a"), []byte("c"), []byte("d")}, allKeys(t, db))
+
+	// Deleting an empty range is a noop
+	require.NoError(database.DeleteRange(db, []byte("d"), []byte("c")))
+	require.NoError(database.DeleteRange(db, []byte("c"), []byte("c")))
+	require.Equal([][]byte{[]byte("a"), []byte("c"), []byte("d")}, allKeys(t, db))
+
+	// A nil start is before all keys
+	require.NoError(database.DeleteRange(db, nil, []byte("b")))
+	require.Equal([][]byte{[]byte("c"), []byte("d")}, allKeys(t, db))
+
+	// A nil limit is after all keys
+	require.NoError(database.DeleteRange(db, []byte("c1"), nil))
+	require.Equal([][]byte{[]byte("c")}, allKeys(t, db))
+
+	require.NoError(database.DeleteRange(db, nil, nil))
+	require.Empty(allKeys(t, db))
+
+	// Deleting from an empty database is a noop
+	require.NoError(database.DeleteRange(db, nil, nil))
+
+	require.NoError(db.Close())
+	err := database.DeleteRange(db, nil, nil)
+	require.ErrorIs(err, database.ErrClosed)
+}
+
+func TestDeletePrefix(t *testing.T, db database.Database) {
+	require := require.New(t)
+
+	keys := [][]byte{
+		[]byte("a"),
+		[]byte("b"),
+		[]byte("b1"),
+		{'b', 0xff},
+		[]byte("c"),
+		{0xff},
+		{0xff, 0x01},
+	}
+	for _, key := range keys {
+		require.NoError(db.Put(key, key))
+	}
+
+	require.NoError(database.DeletePrefix(db, []byte("b")))
+	require.Equal([][]byte{[]byte("a"), []byte("c"), {0xff}, {0xff, 0x01}}, allKeys(t, db))
+
+	// A prefix without a limit removes every key after it
+	require.NoError(database.DeletePrefix(db, []byte{0xff}))
+	require.Equal([][]byte{[]byte("a"), []byte("c")}, allKeys(t, db))
+
+	// An empty prefix removes every key
+	require.NoError(database.DeletePrefix(db, nil))
+	require.Empty(allKeys(t, db))
+}
+
+func allKeys(t *testing.T, db database.Iteratee) [][]byte {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var keys [][]byte
+	for it.Next() {
+		keys = append(keys, slices.Clone(it.Key()))
+	}
+	require.NoError(t, it.Error())
+	return keys
+}
+
 func TestAtomicClear(t *testing.T, db database.Database) {
 	testClear(t, db, func(db database.Database) error {
 		return database.AtomicClear(db, db)
@@ -1357,3 +1442,42 @@ func FuzzNewIteratorWithStartAndPrefix(f *testing.F, db database.Database) {
 		require.NoError(database.AtomicClear(db, db))
 	})
 }
+
+// TestSnapshot tests that a snapshot written by [db] contains exactly the
+// contents of [db] at the time the snapshot was taken. [open] must open the
+// database written to the provided directory.
+func TestSnapshot(
+	t *testing.T,
+	db database.Snapshotter,
+	open func(t *testing.T, dir string) database.Database,
+) {
+	require := require.New(t)
+
+	kv, ok := db.(database.KeyValueReaderWriter)
+	require.True(ok)
+
+	require.NoError(kv.Put([]byte("hello"), []byte("world")))
+	require.NoError(kv.Put([]byte("goodbye"), []byte("world")))
+
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	require.NoError(db.Snapshot(dir))
+
+	// Writes after the snapshot must not be included in the snapshot.
+	require.NoError(kv.Put([]byte("hello"), []byte("there")))
+
+	// Snapshotting into an existing directory must fail.
+	require.Error(db.Snapshot(dir)) //nolint:forbidigo // the error is backend specific
+
+	snapshotDB := open(t, dir)
+	defer func() {
+		require.NoError(snapshotDB.Close())
+	}()
+
+	value, err := snapshotDB.Get([]byte("hello"))
+	require.NoError(err)
+	require.Equal([]byte("world"), value)
+
+	count, err := database.Count(snapshotDB)
+	require.NoError(err)
+	require.Equal(2, count)
+}
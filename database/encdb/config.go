@@ -0,0 +1,106 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/password"
+)
+
+// saltSize is the size of the salt used to derive a key from a password.
+const saltSize = 16
+
+var (
+	errNoKeySource        = errors.New("either a key file or a password file must be provided")
+	errMultipleKeySources = errors.New("only one of a key file and a password file may be provided")
+	errNotPasswordDB      = errors.New("database wasn't encrypted with a password")
+)
+
+// Config specifies how a database is encrypted.
+//
+// Exactly one of KeyFile and PasswordFile must be provided.
+type Config struct {
+	// KeyFile is the path to a file containing the hex encoded key.
+	KeyFile string `json:"keyFile"`
+	// PasswordFile is the path to a file containing the password the key is
+	// derived from.
+	PasswordFile string `json:"passwordFile"`
+}
+
+// NewFromConfig returns a database that encrypts the data written to [db] with
+// the key specified by [config].
+func NewFromConfig(db database.Database, config Config) (*Database, error) {
+	switch {
+	case config.KeyFile == "" && config.PasswordFile == "":
+		return nil, errNoKeySource
+	case config.KeyFile != "" && config.PasswordFile != "":
+		return nil, errMultipleKeySources
+	case config.KeyFile != "":
+		key, err := readKeyFile(config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return New(db, key)
+	default:
+		passwordBytes, err := os.ReadFile(config.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read password file: %w", err)
+		}
+		pw := strings.TrimSpace(string(passwordBytes))
+		return NewWithPassword(db, pw)
+	}
+}
+
+// NewWithPassword returns a database that encrypts the data written to [db]
+// with a key derived from [pw].
+//
+// The salt used to derive the key is stored in [db]. If [db] is empty, [pw]
+// must be sufficiently strong.
+func NewWithPassword(db database.Database, pw string) (*Database, error) {
+	salt, err := db.Get(saltKey)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		hasCheck, err := db.Has(checkKey)
+		if err != nil {
+			return nil, err
+		}
+		if hasCheck {
+			return nil, errNotPasswordDB
+		}
+		if err := password.IsValid(pw, password.OK); err != nil {
+			return nil, err
+		}
+
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		if err := db.Put(saltKey, salt); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	}
+	return New(db, password.DeriveKey(pw, salt))
+}
+
+// readKeyFile reads a hex encoded key from [path].
+func readKeyFile(path string) ([]byte, error) {
+	keyBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read key file: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(keyBytes)))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse key file: %w", err)
+	}
+	return key, nil
+}
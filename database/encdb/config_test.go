@@ -0,0 +1,121 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/password"
+)
+
+const testPassword = "correct horse battery staple"
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestNewWithPassword(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	_, err := NewWithPassword(baseDB, "weak")
+	require.ErrorIs(err, password.ErrWeakPassword)
+
+	db, err := NewWithPassword(baseDB, testPassword)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	db, err = NewWithPassword(baseDB, testPassword)
+	require.NoError(err)
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	_, err = NewWithPassword(baseDB, testPassword+"!")
+	require.ErrorIs(err, ErrIncorrectKey)
+}
+
+func TestNewWithPasswordNotPasswordDB(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	_, err := New(baseDB, make([]byte, KeySize))
+	require.NoError(err)
+
+	_, err = NewWithPassword(baseDB, testPassword)
+	require.ErrorIs(err, errNotPasswordDB)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 1
+
+	tests := []struct {
+		name        string
+		config      Config
+		expectedErr error
+	}{
+		{
+			name: "key file",
+			config: Config{
+				KeyFile: writeFile(t, hex.EncodeToString(key)+"\n"),
+			},
+		},
+		{
+			name: "password file",
+			config: Config{
+				PasswordFile: writeFile(t, testPassword+"\n"),
+			},
+		},
+		{
+			name:        "no key source",
+			config:      Config{},
+			expectedErr: errNoKeySource,
+		},
+		{
+			name: "multiple key sources",
+			config: Config{
+				KeyFile:      writeFile(t, hex.EncodeToString(key)),
+				PasswordFile: writeFile(t, testPassword),
+			},
+			expectedErr: errMultipleKeySources,
+		},
+		{
+			name: "invalid key size",
+			config: Config{
+				KeyFile: writeFile(t, hex.EncodeToString(key[1:])),
+			},
+			expectedErr: ErrInvalidKeySize,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			baseDB := memdb.New()
+			db, err := NewFromConfig(baseDB, test.config)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+			require.NoError(db.Put([]byte("key"), []byte("value")))
+
+			db, err = NewFromConfig(baseDB, test.config)
+			require.NoError(err)
+			value, err := db.Get([]byte("key"))
+			require.NoError(err)
+			require.Equal([]byte("value"), value)
+		})
+	}
+}
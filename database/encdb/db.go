@@ -0,0 +1,424 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+// KeySize is the size of the key used to encrypt a database.
+const KeySize = chacha20poly1305.KeySize
+
+var (
+	_ database.Database     = (*Database)(nil)
+	_ database.RangeDeleter = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
+	_ database.Batch        = (*batch)(nil)
+	_ database.Iterator     = (*iterator)(nil)
+
+	// User data is stored under a different prefix than metadata, which is
+	// prefixed with 0, so that metadata is never returned by iterators.
+	dataPrefix = []byte{1}
+	dataLimit  = database.PrefixLimit(dataPrefix)
+
+	checkKey = []byte("\x00check")
+	saltKey  = []byte("\x00salt")
+
+	checkValue = []byte("encdb")
+
+	valueKeyLabel = []byte("encdb value key")
+
+	ErrInvalidKeySize  = fmt.Errorf("key must be %d bytes", KeySize)
+	ErrIncorrectKey    = errors.New("incorrect encryption key")
+	ErrUnencryptedData = errors.New("database contains unencrypted data")
+	errCorruptValue    = errors.New("corrupt value")
+)
+
+// Database encrypts the values written to an underlying database.
+//
+// Values are encrypted with XChaCha20-Poly1305 using a random nonce. The key is
+// authenticated along with the value, so a value can't be moved to a different
+// key without being detected.
+//
+// Keys are stored in plaintext so that the underlying database can look them up
+// and iterate over them in order. Keys must therefore not contain sensitive
+// data.
+type Database struct {
+	aead cipher.AEAD
+
+	// lock needs to be held during Close to guarantee db will not be closed
+	// concurrently with another operation. All other operations can hold RLock.
+	lock   sync.RWMutex
+	db     database.Database
+	closed bool
+}
+
+// New returns a database that encrypts the data written to [db] with [key].
+//
+// If [db] is empty, it is initialized to be encrypted with [key]. Otherwise,
+// [key] must match the key [db] was initialized with.
+func New(db database.Database, key []byte) (*Database, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	aead, err := chacha20poly1305.NewX(deriveKey(key, valueKeyLabel))
+	if err != nil {
+		return nil, err
+	}
+
+	encDB := &Database{
+		aead: aead,
+		db:   db,
+	}
+	return encDB, encDB.initialize()
+}
+
+// initialize verifies that the underlying database was encrypted with the same
+// key as this database. If the underlying database is empty, it is initialized.
+func (db *Database) initialize() error {
+	sealedCheck, err := db.db.Get(checkKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return db.initializeEmpty()
+	}
+	if err != nil {
+		return err
+	}
+
+	check, err := db.open(checkKey, sealedCheck)
+	if err != nil || !bytes.Equal(check, checkValue) {
+		return ErrIncorrectKey
+	}
+	return nil
+}
+
+func (db *Database) initializeEmpty() error {
+	it := db.db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		// The salt is written before the database is initialized when the key
+		// is derived from a password.
+		if !bytes.Equal(it.Key(), saltKey) {
+			return ErrUnencryptedData
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	sealedCheck, err := db.seal(checkKey, checkValue)
+	if err != nil {
+		return err
+	}
+	return db.db.Put(checkKey, sealedCheck)
+}
+
+func (db *Database) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return false, database.ErrClosed
+	}
+	return db.db.Has(db.dbKey(key))
+}
+
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	sealedValue, err := db.db.Get(db.dbKey(key))
+	if err != nil {
+		return nil, err
+	}
+	return db.open(key, sealedValue)
+}
+
+func (db *Database) Put(key, value []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	sealedValue, err := db.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return db.db.Put(db.dbKey(key), sealedValue)
+}
+
+func (db *Database) Delete(key []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return db.db.Delete(db.dbKey(key))
+}
+
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		Batch: db.db.NewBatch(),
+		db:    db,
+	}
+}
+
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return &iterator{
+		Iterator: db.db.NewIteratorWithStartAndPrefix(
+			db.dbKey(start),
+			db.dbKey(prefix),
+		),
+		db: db,
+	}
+}
+
+func (db *Database) Compact(start, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	dbStart, dbLimit := db.dbRange(start, limit)
+	return db.db.Compact(dbStart, dbLimit)
+}
+
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	dbStart, dbLimit := db.dbRange(start, limit)
+	return database.DeleteRange(db.db, dbStart, dbLimit)
+}
+
+// Snapshot forwards the request to the underlying database if it supports
+// snapshots. The snapshot contains the encrypted data, so it must be opened
+// with the same key.
+func (db *Database) Snapshot(dir string) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	snapshotter, ok := db.db.(database.Snapshotter)
+	if !ok {
+		return database.ErrSnapshotNotSupported
+	}
+	return snapshotter.Snapshot(dir)
+}
+
+func (db *Database) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	db.closed = true
+	return db.db.Close()
+}
+
+func (db *Database) isClosed() bool {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.closed
+}
+
+func (db *Database) HealthCheck(ctx context.Context) (interface{}, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	return db.db.HealthCheck(ctx)
+}
+
+// dbKey returns the key that [key] is stored under in the underlying database.
+func (db *Database) dbKey(key []byte) []byte {
+	dbKey := make([]byte, len(dataPrefix), len(dataPrefix)+len(key))
+	copy(dbKey, dataPrefix)
+	return append(dbKey, key...)
+}
+
+// dbRange returns the range of the underlying database that contains the keys
+// in [start, limit).
+func (db *Database) dbRange(start, limit []byte) ([]byte, []byte) {
+	if limit == nil {
+		return db.dbKey(start), dataLimit
+	}
+	return db.dbKey(start), db.dbKey(limit)
+}
+
+// seal encrypts [value] and authenticates it along with [key].
+func (db *Database) seal(key, value []byte) ([]byte, error) {
+	nonceSize := db.aead.NonceSize()
+	sealed := make([]byte, nonceSize, nonceSize+len(value)+db.aead.Overhead())
+	if _, err := rand.Read(sealed); err != nil {
+		return nil, err
+	}
+	return db.aead.Seal(sealed, sealed, value, key), nil
+}
+
+// open decrypts and authenticates a value previously returned by seal.
+func (db *Database) open(key, sealed []byte) ([]byte, error) {
+	nonceSize := db.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("%w: length %d is less than the nonce size", errCorruptValue, len(sealed))
+	}
+	value, err := db.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errCorruptValue, err)
+	}
+	if value == nil {
+		// Opening an empty value returns nil, but Get should return an empty
+		// slice.
+		value = []byte{}
+	}
+	return value, nil
+}
+
+// deriveKey returns a subkey of [key] used for the purpose described by
+// [label].
+func deriveKey(key, label []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(label)
+	return mac.Sum(nil)
+}
+
+type batch struct {
+	database.Batch
+	db *Database
+
+	// ops contains the plaintext operations so that the batch can be replayed.
+	ops database.BatchOps
+}
+
+func (b *batch) Put(key, value []byte) error {
+	sealedValue, err := b.db.seal(key, value)
+	if err != nil {
+		return err
+	}
+	_ = b.ops.Put(key, value)
+	return b.Batch.Put(b.db.dbKey(key), sealedValue)
+}
+
+func (b *batch) Delete(key []byte) error {
+	_ = b.ops.Delete(key)
+	return b.Batch.Delete(b.db.dbKey(key))
+}
+
+func (b *batch) Write() error {
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+	return b.Batch.Write()
+}
+
+func (b *batch) Reset() {
+	b.ops.Reset()
+	b.Batch.Reset()
+}
+
+func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
+	return b.ops.Replay(w)
+}
+
+type iterator struct {
+	database.Iterator
+	db *Database
+
+	key, val []byte
+	err      error
+}
+
+func (it *iterator) Next() bool {
+	it.key = nil
+	it.val = nil
+	if it.err != nil {
+		return false
+	}
+	if it.db.isClosed() {
+		it.err = database.ErrClosed
+		return false
+	}
+	if !it.Iterator.Next() {
+		return false
+	}
+
+	key := slices.Clone(it.Iterator.Key()[len(dataPrefix):])
+	val, err := it.db.open(key, it.Iterator.Value())
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.key = key
+	it.val = val
+	return true
+}
+
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+func (it *iterator) Value() []byte {
+	return it.val
+}
+
+func (it *iterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
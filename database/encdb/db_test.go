@@ -0,0 +1,151 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/dbtest"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func newDB(t testing.TB, db database.Database) *Database {
+	t.Helper()
+
+	encDB, err := New(db, bytes.Repeat([]byte{1}, KeySize))
+	require.NoError(t, err)
+	return encDB
+}
+
+func TestInterface(t *testing.T) {
+	for name, test := range dbtest.Tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newDB(t, memdb.New()))
+		})
+	}
+}
+
+func TestDataIsEncrypted(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB = memdb.New()
+		db     = newDB(t, baseDB)
+		value  = []byte("plaintext value")
+	)
+	require.NoError(db.Put([]byte("key"), value))
+
+	it := baseDB.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		require.NotContains(string(it.Value()), string(value))
+	}
+	require.NoError(it.Error())
+}
+
+func TestValueBoundToKey(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB = memdb.New()
+		db     = newDB(t, baseDB)
+		key1   = []byte("key1")
+		key2   = []byte("key2")
+	)
+	require.NoError(db.Put(key1, []byte("value1")))
+	require.NoError(db.Put(key2, []byte("value2")))
+
+	// Move the encrypted value of key1 to key2.
+	sealedValue, err := baseDB.Get(db.dbKey(key1))
+	require.NoError(err)
+	require.NoError(baseDB.Put(db.dbKey(key2), sealedValue))
+
+	_, err = db.Get(key2)
+	require.ErrorIs(err, errCorruptValue)
+
+	it := db.NewIteratorWithStart(key2)
+	defer it.Release()
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), errCorruptValue)
+}
+
+func TestReopen(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := newDB(t, baseDB)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	db = newDB(t, baseDB)
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	_, err = New(baseDB, bytes.Repeat([]byte{2}, KeySize))
+	require.ErrorIs(err, ErrIncorrectKey)
+}
+
+func TestNewErrors(t *testing.T) {
+	require := require.New(t)
+
+	_, err := New(memdb.New(), make([]byte, KeySize-1))
+	require.ErrorIs(err, ErrInvalidKeySize)
+
+	baseDB := memdb.New()
+	require.NoError(baseDB.Put([]byte("key"), []byte("value")))
+	_, err = New(baseDB, make([]byte, KeySize))
+	require.ErrorIs(err, ErrUnencryptedData)
+}
+
+func TestSnapshot(t *testing.T) {
+	dbtest.TestSnapshot(
+		t,
+		newDB(t, newPebbleDB(t, t.TempDir())),
+		func(t *testing.T, dir string) database.Database {
+			return newDB(t, newPebbleDB(t, dir))
+		},
+	)
+}
+
+func TestSnapshotNotSupported(t *testing.T) {
+	db := newDB(t, memdb.New())
+	require.ErrorIs(t, db.Snapshot(t.TempDir()), database.ErrSnapshotNotSupported)
+}
+
+func newPebbleDB(t *testing.T, dir string) database.Database {
+	db, err := pebbledb.New(dir, nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(t, err)
+	return db
+}
+
+func FuzzKeyValue(f *testing.F) {
+	dbtest.FuzzKeyValue(f, newDB(f, memdb.New()))
+}
+
+func FuzzNewIteratorWithPrefix(f *testing.F) {
+	dbtest.FuzzNewIteratorWithPrefix(f, newDB(f, memdb.New()))
+}
+
+func FuzzNewIteratorWithStartAndPrefix(f *testing.F) {
+	dbtest.FuzzNewIteratorWithStartAndPrefix(f, newDB(f, memdb.New()))
+}
+
+func BenchmarkInterface(b *testing.B) {
+	for _, size := range dbtest.BenchmarkSizes {
+		keys, values := dbtest.SetupBenchmark(b, size[0], size[1], size[2])
+		for name, bench := range dbtest.Benchmarks {
+			b.Run(fmt.Sprintf("encdb_%d_pairs_%d_keys_%d_values_%s", size[0], size[1], size[2], name), func(b *testing.B) {
+				bench(b, newDB(b, memdb.New()), keys, values)
+			})
+		}
+	}
+}
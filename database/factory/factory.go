@@ -4,22 +4,34 @@
 package factory
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/corruptabledb"
+	"github.com/ava-labs/avalanchego/database/encdb"
+	"github.com/ava-labs/avalanchego/database/heightindexdb/blockdb"
 	"github.com/ava-labs/avalanchego/database/leveldb"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/database/pebbledb"
 	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/utils/logging"
+
+	heightindexmemdb "github.com/ava-labs/avalanchego/database/heightindexdb/memdb"
 )
 
+// encryptionConfig is the subset of the database configuration that specifies
+// how the database is encrypted at rest.
+type encryptionConfig struct {
+	Encryption *encdb.Config `json:"encryption"`
+}
+
 // New creates a new database instance based on the provided configuration.
 //
-// It also wraps the database with a corruptable DB.
+// It also wraps the database with a corruptable DB. If the configuration
+// contains an "encryption" section, the database is encrypted with an encdb.
 //
 // dbName is the name of the database, either leveldb, memdb, or pebbledb.
 // dbPath is the path to the database folder.
@@ -56,9 +68,76 @@ func New(
 		return nil, fmt.Errorf("couldn't create %q at %q: %w", name, path, err)
 	}
 
+	db, err = wrapEncryption(db, config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encrypt %q at %q: %w", name, path, err)
+	}
+
 	db = corruptabledb.New(db, logger)
 	if readOnly && name != memdb.Name {
 		db = versiondb.New(db)
 	}
 	return db, nil
 }
+
+// wrapEncryption wraps [db] with an encdb if [config] contains an encryption
+// section. If the encdb can't be created, [db] is closed.
+func wrapEncryption(db database.Database, config []byte) (database.Database, error) {
+	if len(config) == 0 {
+		return db, nil
+	}
+
+	var parsedConfig encryptionConfig
+	if err := json.Unmarshal(config, &parsedConfig); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if parsedConfig.Encryption == nil {
+		return db, nil
+	}
+
+	encDB, err := encdb.NewFromConfig(db, *parsedConfig.Encryption)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return encDB, nil
+}
+
+// NewHeightIndex creates a new height index based on the provided
+// configuration.
+//
+// The node doesn't create height indexes itself, and no VM uses this function
+// yet. It is provided for VMs that store blocks by height outside of their
+// key-value database.
+//
+// name is the name of the height index, either blockdb or memdb.
+// path is the path to the height index folder.
+// config is the height index configuration in JSON format.
+func NewHeightIndex(
+	name string,
+	path string,
+	config []byte,
+	logger logging.Logger,
+) (database.HeightIndex, error) {
+	var (
+		db  database.HeightIndex
+		err error
+	)
+	switch name {
+	case blockdb.Name:
+		db, err = blockdb.New(path, config, logger)
+	case heightindexmemdb.Name:
+		db = &heightindexmemdb.Database{}
+	default:
+		err = fmt.Errorf(
+			"height index type must be one of {%s, %s}",
+			blockdb.Name,
+			heightindexmemdb.Name,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create %q at %q: %w", name, path, err)
+	}
+	return db, nil
+}
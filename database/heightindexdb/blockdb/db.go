@@ -0,0 +1,33 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package blockdb provides an on-disk database.HeightIndex backed by
+// x/blockdb.
+package blockdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/x/blockdb"
+)
+
+// Name is the name of this database for database switches
+const Name = "blockdb"
+
+// New returns a height index stored in [path].
+//
+// [configBytes] is an optional JSON encoded [blockdb.DatabaseConfig]. Any
+// fields that are not specified use the values of [blockdb.DefaultConfig]. If
+// the index and data directories are not specified, they default to [path].
+func New(path string, configBytes []byte, log logging.Logger) (database.HeightIndex, error) {
+	config := blockdb.DefaultConfig().WithDir(path)
+	if len(configBytes) > 0 {
+		if err := json.Unmarshal(configBytes, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s config: %w", Name, err)
+		}
+	}
+	return blockdb.New(config, log)
+}
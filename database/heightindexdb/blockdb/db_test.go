@@ -0,0 +1,49 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/heightindexdb/dbtest"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func TestInterface(t *testing.T) {
+	for _, test := range dbtest.Tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.Test(t, func() database.HeightIndex {
+				db, err := New(t.TempDir(), nil, logging.NoLog{})
+				require.NoError(t, err)
+				return db
+			})
+		})
+	}
+}
+
+func TestNewWithConfig(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		dir     = t.TempDir()
+		dataDir = filepath.Join(dir, "data")
+	)
+	db, err := New(dir, []byte(`{"dataDir":"`+dataDir+`","syncToDisk":false}`), logging.NoLog{})
+	require.NoError(err)
+	require.NoError(db.Put(1, []byte("block")))
+	require.NoError(db.Close())
+
+	matches, err := filepath.Glob(filepath.Join(dataDir, "*.dat"))
+	require.NoError(err)
+	require.Len(matches, 1)
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	_, err := New(t.TempDir(), []byte(`{"maxDataFiles":0}`), logging.NoLog{})
+	require.ErrorContains(t, err, "MaxDataFiles must be positive")
+}
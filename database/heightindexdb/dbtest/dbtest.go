@@ -20,10 +20,16 @@ var Tests = []struct {
 	{"TestPutGet", TestPutGet},
 	{"TestHas", TestHas},
 	{"TestSync", TestSync},
+	{"TestNewIteratorFromHeight", TestNewIteratorFromHeight},
+	{"TestPrune", TestPrune},
+	{"TestBounds", TestBounds},
 	{"TestCloseAndPut", TestCloseAndPut},
 	{"TestCloseAndGet", TestCloseAndGet},
 	{"TestCloseAndHas", TestCloseAndHas},
 	{"TestCloseAndSync", TestCloseAndSync},
+	{"TestCloseAndNewIteratorFromHeight", TestCloseAndNewIteratorFromHeight},
+	{"TestCloseAndPrune", TestCloseAndPrune},
+	{"TestCloseAndBounds", TestCloseAndBounds},
 	{"TestClose", TestClose},
 }
 
@@ -202,6 +208,37 @@ func TestCloseAndSync(t *testing.T, newDB func() database.HeightIndex) {
 	require.ErrorIs(t, err, database.ErrClosed)
 }
 
+func TestCloseAndNewIteratorFromHeight(t *testing.T, newDB func() database.HeightIndex) {
+	db := newDB()
+	require.NoError(t, db.Put(1, []byte("test")))
+	require.NoError(t, db.Close())
+
+	// Try to iterate after close - should return error
+	it := db.NewIteratorFromHeight(0)
+	defer it.Release()
+
+	require.False(t, it.Next())
+	require.ErrorIs(t, it.Error(), database.ErrClosed)
+}
+
+func TestCloseAndPrune(t *testing.T, newDB func() database.HeightIndex) {
+	db := newDB()
+	require.NoError(t, db.Close())
+
+	// Try to prune after close - should return error
+	err := db.Prune(10)
+	require.ErrorIs(t, err, database.ErrClosed)
+}
+
+func TestCloseAndBounds(t *testing.T, newDB func() database.HeightIndex) {
+	db := newDB()
+	require.NoError(t, db.Close())
+
+	// Try to get bounds after close - should return error
+	_, _, err := db.Bounds()
+	require.ErrorIs(t, err, database.ErrClosed)
+}
+
 func TestClose(t *testing.T, newDB func() database.HeightIndex) {
 	db := newDB()
 	require.NoError(t, db.Close())
@@ -269,3 +306,242 @@ func TestSync(t *testing.T, newDB func() database.HeightIndex) {
 		})
 	}
 }
+
+func TestNewIteratorFromHeight(t *testing.T, newDB func() database.HeightIndex) {
+	tests := []struct {
+		name        string
+		puts        []putArgs
+		startHeight uint64
+		want        []putArgs
+	}{
+		{
+			name: "empty database",
+		},
+		{
+			name: "all heights",
+			puts: []putArgs{
+				{1, []byte("data 1")},
+				{2, []byte("data 2")},
+				{3, []byte("data 3")},
+			},
+			want: []putArgs{
+				{1, []byte("data 1")},
+				{2, []byte("data 2")},
+				{3, []byte("data 3")},
+			},
+		},
+		{
+			name: "start height skips lower heights",
+			puts: []putArgs{
+				{1, []byte("data 1")},
+				{2, []byte("data 2")},
+				{3, []byte("data 3")},
+			},
+			startHeight: 2,
+			want: []putArgs{
+				{2, []byte("data 2")},
+				{3, []byte("data 3")},
+			},
+		},
+		{
+			name: "start height past highest height",
+			puts: []putArgs{
+				{1, []byte("data 1")},
+			},
+			startHeight: 2,
+		},
+		{
+			name: "gaps are skipped and heights are sorted",
+			puts: []putArgs{
+				{10, []byte("data 10")},
+				{3, []byte("data 3")},
+				{7, []byte("data 7")},
+			},
+			want: []putArgs{
+				{3, []byte("data 3")},
+				{7, []byte("data 7")},
+				{10, []byte("data 10")},
+			},
+		},
+		{
+			name: "overwritten heights return the latest data",
+			puts: []putArgs{
+				{1, []byte("original data")},
+				{1, []byte("overwritten data")},
+			},
+			want: []putArgs{
+				{1, []byte("overwritten data")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB()
+			defer func() {
+				require.NoError(t, db.Close())
+			}()
+
+			for _, write := range tt.puts {
+				require.NoError(t, db.Put(write.height, write.data))
+			}
+
+			it := db.NewIteratorFromHeight(tt.startHeight)
+			defer it.Release()
+
+			var got []putArgs
+			for it.Next() {
+				got = append(got, putArgs{
+					height: it.Height(),
+					data:   bytes.Clone(it.Value()),
+				})
+			}
+			require.NoError(t, it.Error())
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrune(t *testing.T, newDB func() database.HeightIndex) {
+	tests := []struct {
+		name       string
+		puts       []uint64
+		prunes     []uint64
+		wantPruned []uint64
+		wantKept   []uint64
+	}{
+		{
+			name:   "empty database",
+			prunes: []uint64{10},
+		},
+		{
+			name:       "prune some heights",
+			puts:       []uint64{1, 2, 3, 4, 5},
+			prunes:     []uint64{3},
+			wantPruned: []uint64{1, 2},
+			wantKept:   []uint64{3, 4, 5},
+		},
+		{
+			name:       "prune all heights",
+			puts:       []uint64{1, 2, 3},
+			prunes:     []uint64{10},
+			wantPruned: []uint64{1, 2, 3},
+		},
+		{
+			name:     "prune below lowest height",
+			puts:     []uint64{5, 6},
+			prunes:   []uint64{2},
+			wantKept: []uint64{5, 6},
+		},
+		{
+			name:       "prune lower height after higher height",
+			puts:       []uint64{1, 2, 3, 4, 5},
+			prunes:     []uint64{4, 2},
+			wantPruned: []uint64{1, 2, 3},
+			wantKept:   []uint64{4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB()
+			defer func() {
+				require.NoError(t, db.Close())
+			}()
+
+			for _, height := range tt.puts {
+				require.NoError(t, db.Put(height, []byte("data")))
+			}
+			for _, below := range tt.prunes {
+				require.NoError(t, db.Prune(below))
+			}
+
+			for _, height := range tt.wantPruned {
+				_, err := db.Get(height)
+				require.ErrorIs(t, err, database.ErrNotFound)
+
+				ok, err := db.Has(height)
+				require.NoError(t, err)
+				require.False(t, ok)
+			}
+			for _, height := range tt.wantKept {
+				data, err := db.Get(height)
+				require.NoError(t, err)
+				require.Equal(t, []byte("data"), data)
+			}
+
+			it := db.NewIteratorFromHeight(0)
+			defer it.Release()
+
+			var got []uint64
+			for it.Next() {
+				got = append(got, it.Height())
+			}
+			require.NoError(t, it.Error())
+			require.Equal(t, tt.wantKept, got)
+		})
+	}
+}
+
+func TestBounds(t *testing.T, newDB func() database.HeightIndex) {
+	tests := []struct {
+		name        string
+		puts        []uint64
+		prune       uint64
+		wantLowest  uint64
+		wantHighest uint64
+		wantErr     error
+	}{
+		{
+			name:    "empty database",
+			wantErr: database.ErrNotFound,
+		},
+		{
+			name:        "single height",
+			puts:        []uint64{5},
+			wantLowest:  5,
+			wantHighest: 5,
+		},
+		{
+			name:        "unordered heights",
+			puts:        []uint64{7, 3, 12},
+			wantLowest:  3,
+			wantHighest: 12,
+		},
+		{
+			name:        "pruned heights are excluded",
+			puts:        []uint64{1, 2, 3, 4},
+			prune:       3,
+			wantLowest:  3,
+			wantHighest: 4,
+		},
+		{
+			name:    "all heights pruned",
+			puts:    []uint64{1, 2, 3},
+			prune:   4,
+			wantErr: database.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB()
+			defer func() {
+				require.NoError(t, db.Close())
+			}()
+
+			for _, height := range tt.puts {
+				require.NoError(t, db.Put(height, []byte("data")))
+			}
+			require.NoError(t, db.Prune(tt.prune))
+
+			lowest, highest, err := db.Bounds()
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			require.Equal(t, tt.wantLowest, lowest)
+			require.Equal(t, tt.wantHighest, highest)
+		})
+	}
+}
@@ -4,12 +4,21 @@
 package memdb
 
 import (
+	"maps"
+	"math"
+	"slices"
 	"sync"
 
 	"github.com/ava-labs/avalanchego/database"
 )
 
-var _ database.HeightIndex = (*Database)(nil)
+// Name is the name of this database for database switches
+const Name = "memdb"
+
+var (
+	_ database.HeightIndex    = (*Database)(nil)
+	_ database.HeightIterator = (*iterator)(nil)
+)
 
 // Database is an in-memory implementation of database.HeightIndex
 type Database struct {
@@ -72,6 +81,73 @@ func (db *Database) Sync(_, _ uint64) error {
 	return nil
 }
 
+// NewIteratorFromHeight returns an iterator over a snapshot of the values
+// with a height greater than or equal to [height].
+func (db *Database) NewIteratorFromHeight(height uint64) database.HeightIterator {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return &database.HeightIteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+
+	heights := make([]uint64, 0, len(db.data))
+	for h := range db.data {
+		if h >= height {
+			heights = append(heights, h)
+		}
+	}
+	slices.Sort(heights)
+
+	values := make([][]byte, len(heights))
+	for i, h := range heights {
+		values[i] = db.data[h]
+	}
+	return &iterator{
+		db:      db,
+		heights: heights,
+		values:  values,
+	}
+}
+
+func (db *Database) Prune(below uint64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	maps.DeleteFunc(db.data, func(height uint64, _ []byte) bool {
+		return height < below
+	})
+	return nil
+}
+
+func (db *Database) Bounds() (uint64, uint64, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return 0, 0, database.ErrClosed
+	}
+	if len(db.data) == 0 {
+		return 0, 0, database.ErrNotFound
+	}
+
+	var (
+		lowest  uint64 = math.MaxUint64
+		highest uint64
+	)
+	for height := range db.data {
+		lowest = min(lowest, height)
+		highest = max(highest, height)
+	}
+	return lowest, highest, nil
+}
+
 func (db *Database) Close() error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
@@ -84,3 +160,56 @@ func (db *Database) Close() error {
 	db.data = nil
 	return nil
 }
+
+type iterator struct {
+	db      *Database
+	heights []uint64
+	values  [][]byte
+	height  uint64
+	value   []byte
+	err     error
+}
+
+func (it *iterator) Next() bool {
+	// Short-circuit and set an error if the underlying database has been closed.
+	it.db.mu.RLock()
+	closed := it.db.closed
+	it.db.mu.RUnlock()
+	if closed {
+		it.heights = nil
+		it.values = nil
+		it.height = 0
+		it.value = nil
+		it.err = database.ErrClosed
+		return false
+	}
+	if len(it.heights) == 0 {
+		it.height = 0
+		it.value = nil
+		return false
+	}
+	it.height = it.heights[0]
+	it.value = it.values[0]
+	it.heights = it.heights[1:]
+	it.values = it.values[1:]
+	return true
+}
+
+func (it *iterator) Error() error {
+	return it.err
+}
+
+func (it *iterator) Height() uint64 {
+	return it.height
+}
+
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+func (it *iterator) Release() {
+	it.heights = nil
+	it.values = nil
+	it.height = 0
+	it.value = nil
+}
@@ -15,7 +15,8 @@ import (
 const methodLabel = "method"
 
 var (
-	_ database.HeightIndex = (*Database)(nil)
+	_ database.HeightIndex    = (*Database)(nil)
+	_ database.HeightIterator = (*iterator)(nil)
 
 	methodLabels = []string{methodLabel}
 	putLabel     = prometheus.Labels{
@@ -33,6 +34,18 @@ var (
 	closeLabel = prometheus.Labels{
 		methodLabel: "close",
 	}
+	newIteratorLabel = prometheus.Labels{
+		methodLabel: "new_iterator",
+	}
+	iteratorNextLabel = prometheus.Labels{
+		methodLabel: "iterator_next",
+	}
+	pruneLabel = prometheus.Labels{
+		methodLabel: "prune",
+	}
+	boundsLabel = prometheus.Labels{
+		methodLabel: "bounds",
+	}
 )
 
 // Database tracks the amount of time each operation takes and how many bytes
@@ -126,6 +139,39 @@ func (db *Database) Sync(startHeight, endHeight uint64) error {
 	return err
 }
 
+func (db *Database) NewIteratorFromHeight(height uint64) database.HeightIterator {
+	start := time.Now()
+	it := db.heightDB.NewIteratorFromHeight(height)
+	duration := time.Since(start)
+
+	db.calls.With(newIteratorLabel).Inc()
+	db.duration.With(newIteratorLabel).Add(float64(duration.Nanoseconds()))
+	return &iterator{
+		HeightIterator: it,
+		db:             db,
+	}
+}
+
+func (db *Database) Prune(below uint64) error {
+	start := time.Now()
+	err := db.heightDB.Prune(below)
+	duration := time.Since(start)
+
+	db.calls.With(pruneLabel).Inc()
+	db.duration.With(pruneLabel).Add(float64(duration.Nanoseconds()))
+	return err
+}
+
+func (db *Database) Bounds() (uint64, uint64, error) {
+	start := time.Now()
+	lowest, highest, err := db.heightDB.Bounds()
+	duration := time.Since(start)
+
+	db.calls.With(boundsLabel).Inc()
+	db.duration.With(boundsLabel).Add(float64(duration.Nanoseconds()))
+	return lowest, highest, err
+}
+
 func (db *Database) Close() error {
 	start := time.Now()
 	err := db.heightDB.Close()
@@ -135,3 +181,19 @@ func (db *Database) Close() error {
 	db.duration.With(closeLabel).Add(float64(duration.Nanoseconds()))
 	return err
 }
+
+type iterator struct {
+	database.HeightIterator
+	db *Database
+}
+
+func (it *iterator) Next() bool {
+	start := time.Now()
+	next := it.HeightIterator.Next()
+	duration := time.Since(start)
+
+	it.db.calls.With(iteratorNextLabel).Inc()
+	it.db.duration.With(iteratorNextLabel).Add(float64(duration.Nanoseconds()))
+	it.db.size.With(iteratorNextLabel).Add(float64(len(it.HeightIterator.Value())))
+	return next
+}
@@ -95,6 +95,50 @@ func TestHas(t *testing.T) {
 	require.Greater(t, duration["has"], float64(0))
 }
 
+func TestIterator(t *testing.T) {
+	reg, db := setup(t)
+
+	const blockCount = 10
+	const blockSize = 1024
+	writeBlocks(t, db, blockCount, blockSize)
+
+	it := db.NewIteratorFromHeight(0)
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		count++
+	}
+	require.NoError(t, it.Error())
+	require.Equal(t, blockCount, count)
+
+	calls, duration, size := gatherMetrics(t, reg)
+	require.InEpsilon(t, float64(1), calls["new_iterator"], 0.01)
+	require.InEpsilon(t, float64(blockCount+1), calls["iterator_next"], 0.01)
+	require.InEpsilon(t, float64(blockCount*blockSize), size["iterator_next"], 0.01)
+	require.Greater(t, duration["iterator_next"], float64(0))
+}
+
+func TestPruneBounds(t *testing.T) {
+	reg, db := setup(t)
+
+	const blockCount = 10
+	writeBlocks(t, db, blockCount, 1)
+	require.NoError(t, db.Prune(5))
+
+	lowest, highest, err := db.Bounds()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), lowest)
+	require.Equal(t, uint64(blockCount-1), highest)
+
+	calls, duration, size := gatherMetrics(t, reg)
+	require.InEpsilon(t, float64(1), calls["prune"], 0.01)
+	require.Greater(t, duration["prune"], float64(0))
+	require.InEpsilon(t, float64(1), calls["bounds"], 0.01)
+	require.Greater(t, duration["bounds"], float64(0))
+	require.Zero(t, size["prune"])
+}
+
 func TestClose(t *testing.T) {
 	reg, db := setup(t)
 	require.NoError(t, db.Close())
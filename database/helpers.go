@@ -4,9 +4,11 @@
 package database
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -20,6 +22,10 @@ const (
 
 	// kvPairOverhead is an estimated overhead for a kv pair in a database.
 	kvPairOverhead = 8 // bytes
+
+	// deleteRangeWriteSize is the batch size used by DeleteRange when the
+	// database doesn't natively support range deletions.
+	deleteRangeWriteSize = 4 * 1024 * 1024 // bytes
 )
 
 var (
@@ -239,3 +245,80 @@ func ClearPrefix(db Database, prefix []byte, writeSize int) error {
 	}
 	return it.Error()
 }
+
+// DeleteRange removes all keys in the range [start, limit) from [db].
+//
+// If [db] is a RangeDeleter, the deletion is delegated to it. Otherwise, the
+// keys are removed with ClearRange.
+func DeleteRange(db Database, start, limit []byte) error {
+	if deleter, ok := db.(RangeDeleter); ok {
+		return deleter.DeleteRange(start, limit)
+	}
+	return ClearRange(db, start, limit, deleteRangeWriteSize)
+}
+
+// DeletePrefix removes all keys with the given [prefix] from [db] using
+// DeleteRange.
+func DeletePrefix(db Database, prefix []byte) error {
+	return DeleteRange(db, prefix, PrefixLimit(prefix))
+}
+
+// PrefixLimit returns the smallest key that is larger than every key with the
+// given [prefix]. If no such key exists, nil is returned, which is treated as a
+// key after all keys by DeleteRange.
+func PrefixLimit(prefix []byte) []byte {
+	limit := slices.Clone(prefix)
+	for i := len(limit) - 1; i >= 0; i-- {
+		limit[i]++
+		if limit[i] != 0 {
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// ClearRange removes all keys in the range [start, limit) from [db] by
+// iterating over them. A nil [limit] is treated as a key after all keys in
+// [db]. Writes each batch when it reaches [writeSize].
+func ClearRange(db Database, start, limit []byte, writeSize int) error {
+	b := db.NewBatch()
+	it := db.NewIteratorWithStart(start)
+	// Defer the release of the iterator inside a closure to guarantee that the
+	// latest, not the first, iterator is released on return.
+	defer func() {
+		it.Release()
+	}()
+
+	for it.Next() {
+		key := it.Key()
+		if limit != nil && bytes.Compare(key, limit) >= 0 {
+			break
+		}
+		if err := b.Delete(key); err != nil {
+			return err
+		}
+
+		// Avoid too much memory pressure by periodically writing to the
+		// database.
+		if b.Size() < writeSize {
+			continue
+		}
+
+		if err := b.Write(); err != nil {
+			return err
+		}
+		b.Reset()
+
+		// Reset the iterator to release references to now deleted keys.
+		if err := it.Error(); err != nil {
+			return err
+		}
+		it.Release()
+		it = db.NewIteratorWithStart(key)
+	}
+
+	if err := b.Write(); err != nil {
+		return err
+	}
+	return it.Error()
+}
@@ -4,6 +4,7 @@
 package database_test
 
 import (
+	"fmt"
 	"math/rand"
 	"slices"
 	"testing"
@@ -73,3 +74,32 @@ func TestOrDefault(t *testing.T) {
 	require.NoError(err)
 	require.Equal(uint64(2), v)
 }
+
+func TestPrefixLimit(t *testing.T) {
+	tests := []struct {
+		prefix []byte
+		want   []byte
+	}{
+		{
+			prefix: nil,
+			want:   nil,
+		},
+		{
+			prefix: []byte{0x01},
+			want:   []byte{0x02},
+		},
+		{
+			prefix: []byte{0x01, 0xff},
+			want:   []byte{0x02},
+		},
+		{
+			prefix: []byte{0xff, 0xff},
+			want:   nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%x", test.prefix), func(t *testing.T) {
+			require.Equal(t, test.want, PrefixLimit(test.prefix))
+		})
+	}
+}
@@ -7,7 +7,10 @@
 
 package database
 
-var _ Iterator = (*IteratorError)(nil)
+var (
+	_ Iterator       = (*IteratorError)(nil)
+	_ HeightIterator = (*HeightIteratorError)(nil)
+)
 
 // Iterator iterates over a database's key/value pairs.
 //
@@ -89,3 +92,61 @@ func (*IteratorError) Value() []byte {
 }
 
 func (*IteratorError) Release() {}
+
+// HeightIterator iterates over a height index's values in increasing order of
+// height.
+//
+// When it encounters an error any seek will return false and will yield no
+// values. The error can be queried by calling the Error method. Calling
+// Release is still necessary.
+//
+// An iterator must be released after use, but it is not necessary to read an
+// iterator until exhaustion. An iterator is not safe for concurrent use, but it
+// is safe to use multiple iterators concurrently.
+type HeightIterator interface {
+	// Next moves the iterator to the next value. It returns whether the
+	// iterator successfully moved to a new value.
+	// The iterator may return false if the underlying database has been closed
+	// before the iteration has completed, in which case future calls to Error()
+	// must return [ErrClosed].
+	Next() bool
+
+	// Error returns any accumulated error. Exhausting all the values is not
+	// considered to be an error.
+	Error() error
+
+	// Height returns the height of the current value, or 0 if done.
+	// Behavior is undefined after Release is called.
+	Height() uint64
+
+	// Value returns the current value, or nil if done.
+	// Behavior is undefined after Release is called.
+	Value() []byte
+
+	// Release releases associated resources. Release should always succeed and
+	// can be called multiple times without causing an error.
+	Release()
+}
+
+// HeightIteratorError does nothing and returns the provided error
+type HeightIteratorError struct {
+	Err error
+}
+
+func (*HeightIteratorError) Next() bool {
+	return false
+}
+
+func (i *HeightIteratorError) Error() error {
+	return i.Err
+}
+
+func (*HeightIteratorError) Height() uint64 {
+	return 0
+}
+
+func (*HeightIteratorError) Value() []byte {
+	return nil
+}
+
+func (*HeightIteratorError) Release() {}
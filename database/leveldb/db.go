@@ -57,15 +57,25 @@ const (
 	// metrics.
 	DefaultMetricUpdateFrequency = 10 * time.Second
 
+	// snapshotBatchSize is the number of bytes to buffer before writing them
+	// to the snapshot database.
+	snapshotBatchSize = 4 * opt.MiB
+
+	// deleteRangeBatchSize is the number of bytes of deletions to buffer
+	// before writing them to the database during DeleteRange.
+	deleteRangeBatchSize = 4 * opt.MiB
+
 	// levelDBByteOverhead is the number of bytes of constant overhead that
 	// should be added to a batch size per operation.
 	levelDBByteOverhead = 8
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iter)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.RangeDeleter = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
+	_ database.Batch        = (*batch)(nil)
+	_ database.Iterator     = (*iter)(nil)
 
 	ErrInvalidConfig = errors.New("invalid config")
 	ErrCouldNotOpen  = errors.New("could not open")
@@ -352,6 +362,69 @@ func (db *Database) Compact(start []byte, limit []byte) error {
 	return updateError(db.DB.CompactRange(util.Range{Start: start, Limit: limit}))
 }
 
+// DeleteRange removes all keys in [start, limit). leveldb does not support
+// range deletions, so the keys are iterated over and deleted in batches.
+func (db *Database) DeleteRange(start []byte, limit []byte) error {
+	if db.closed.Get() {
+		return database.ErrClosed
+	}
+	return database.ClearRange(db, start, limit, deleteRangeBatchSize)
+}
+
+// Snapshot copies the contents of a consistent leveldb snapshot into a new
+// leveldb instance at [dir].
+func (db *Database) Snapshot(dir string) error {
+	if db.closed.Get() {
+		return database.ErrClosed
+	}
+
+	snapshot, err := db.DB.GetSnapshot()
+	if err != nil {
+		return updateError(err)
+	}
+	defer snapshot.Release()
+
+	snapshotDB, err := leveldb.OpenFile(dir, &opt.Options{
+		ErrorIfExist: true,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCouldNotOpen, err)
+	}
+
+	it := snapshot.NewIterator(nil, nil)
+	defer it.Release()
+
+	var (
+		batch leveldb.Batch
+		size  int
+	)
+	for it.Next() {
+		key := it.Key()
+		value := it.Value()
+		batch.Put(key, value)
+		size += len(key) + len(value) + levelDBByteOverhead
+		if size < snapshotBatchSize {
+			continue
+		}
+
+		if err := snapshotDB.Write(&batch, nil); err != nil {
+			_ = snapshotDB.Close()
+			return err
+		}
+		batch.Reset()
+		size = 0
+	}
+	if err := it.Error(); err != nil {
+		_ = snapshotDB.Close()
+		return updateError(err)
+	}
+	if err := snapshotDB.Write(&batch, nil); err != nil {
+		_ = snapshotDB.Close()
+		return err
+	}
+	return snapshotDB.Close()
+}
+
 func (db *Database) Close() error {
 	db.closed.Set(true)
 	db.closeOnce.Do(func() {
@@ -73,3 +73,16 @@ func BenchmarkInterface(b *testing.B) {
 		}
 	}
 }
+
+func TestSnapshot(t *testing.T) {
+	db := newDB(t)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	dbtest.TestSnapshot(t, db.(*Database), func(t *testing.T, dir string) database.Database {
+		db, err := New(dir, nil, logging.NoLog{}, prometheus.NewRegistry())
+		require.NoError(t, err)
+		return db
+	})
+}
@@ -21,9 +21,10 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.RangeDeleter = (*Database)(nil)
+	_ database.Batch        = (*batch)(nil)
+	_ database.Iterator     = (*iterator)(nil)
 )
 
 // Database is an ephemeral key-value store that implements the Database
@@ -108,6 +109,24 @@ func (db *Database) Delete(key []byte) error {
 	return nil
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+
+	startString := string(start)
+	limitString := string(limit)
+	for key := range db.db {
+		if key >= startString && (limit == nil || key < limitString) {
+			delete(db.db, key)
+		}
+	}
+	return nil
+}
+
 func (db *Database) NewBatch() database.Batch {
 	return &batch{db: db}
 }
@@ -115,3 +115,17 @@ type DB[R any, C any] interface {
 	// have been are correct prior to [end].
 	CommitRangeProof(ctx context.Context, start, end maybe.Maybe[[]byte], proof R) (maybe.Maybe[[]byte], error)
 }
+
+// KeyOnlyRangeProofer is optionally implemented by a [DB] that can serve
+// range proofs whose values are replaced by their digests.
+type KeyOnlyRangeProofer[R any] interface {
+	// GetKeyOnlyRangeProofAtRoot is the same as GetRangeProofAtRoot, except
+	// that each value in the returned proof is replaced by its digest.
+	GetKeyOnlyRangeProofAtRoot(
+		ctx context.Context,
+		rootID ids.ID,
+		start maybe.Maybe[[]byte],
+		end maybe.Maybe[[]byte],
+		maxLength int,
+	) (R, error)
+}
@@ -38,7 +38,31 @@ const (
 var (
 	_ p2p.Handler = (*ProofHandler[any, any])(nil)
 
+	// ErrRootNotAvailable is returned to the requester if the requested root
+	// is not in the history of the peer.
+	ErrRootNotAvailable = &common.AppError{
+		Code:    1,
+		Message: "root not available",
+	}
+	// ErrRangeTooLarge is returned to the requester if no proof of the
+	// requested range fits within the bytes limit.
+	ErrRangeTooLarge = &common.AppError{
+		Code:    2,
+		Message: "range too large",
+	}
+	// ErrInternal is returned to the requester if the peer failed to generate
+	// a proof for a well-formed request.
+	ErrInternal = &common.AppError{
+		Code:    3,
+		Message: "internal error",
+	}
+	// ErrRateLimited is returned to the requester by a [ProofHandler] that is
+	// wrapped with [p2p.NewThrottlerHandler]. The [Syncer] retries requests
+	// that fail with it after the maximum backoff.
+	ErrRateLimited = p2p.ErrThrottled
+
 	errMinProofSizeIsTooLarge = errors.New("cannot generate any proof within the requested limit")
+	errKeyOnlyNotSupported    = errors.New("key-only range proofs are not supported")
 
 	errInvalidBytesLimit    = errors.New("bytes limit must be greater than 0")
 	errInvalidKeyLimit      = errors.New("key limit must be greater than 0")
@@ -80,26 +104,53 @@ func (h *ProofHandler[R, C]) AppRequest(ctx context.Context, _ ids.NodeID, _ tim
 	)
 	switch r := req.Request.(type) {
 	case *pb.ProofRequest_RangeProof:
+		if err := validateRangeProofRequest(r.RangeProof); err != nil {
+			return nil, &common.AppError{
+				Code:    p2p.ErrUnexpected.Code,
+				Message: fmt.Sprintf("invalid range proof request: %s", err),
+			}
+		}
 		resp, err = h.handleRangeProofRequest(ctx, r.RangeProof)
 	case *pb.ProofRequest_ChangeProof:
+		if err := validateChangeProofRequest(r.ChangeProof); err != nil {
+			return nil, &common.AppError{
+				Code:    p2p.ErrUnexpected.Code,
+				Message: fmt.Sprintf("invalid change proof request: %s", err),
+			}
+		}
 		resp, err = h.handleChangeProofRequest(ctx, r.ChangeProof)
 	default:
-		err = fmt.Errorf("unknown request type: %T", r)
-	}
-	if err != nil {
 		return nil, &common.AppError{
 			Code:    p2p.ErrUnexpected.Code,
-			Message: fmt.Sprintf("failed to handle request: %s", err),
+			Message: fmt.Sprintf("unknown request type: %T", r),
 		}
 	}
+	if err != nil {
+		return nil, toAppError(err)
+	}
 	return resp, nil
 }
 
-func (h *ProofHandler[R, C]) handleRangeProofRequest(ctx context.Context, req *pb.RangeProofRequest) ([]byte, error) {
-	if err := validateRangeProofRequest(req); err != nil {
-		return nil, err
+// toAppError converts an error that occurred while generating a proof for a
+// well-formed request into the error that is returned to the requester.
+func toAppError(err error) *common.AppError {
+	code := ErrInternal.Code
+	switch {
+	case errors.Is(err, ErrInsufficientHistory):
+		code = ErrRootNotAvailable.Code
+	case errors.Is(err, errMinProofSizeIsTooLarge):
+		code = ErrRangeTooLarge.Code
+	case errors.Is(err, errKeyOnlyNotSupported):
+		code = p2p.ErrUnexpected.Code
+	}
+	return &common.AppError{
+		Code:    code,
+		Message: fmt.Sprintf("failed to handle request: %s", err),
 	}
+}
 
+// handleRangeProofRequest assumes [req] is well-formed.
+func (h *ProofHandler[R, C]) handleRangeProofRequest(ctx context.Context, req *pb.RangeProofRequest) ([]byte, error) {
 	// override limits if they exceed caps
 	var (
 		keyLimit   = min(int(req.KeyLimit), MaxKeyValuesLimit)
@@ -113,8 +164,17 @@ func (h *ProofHandler[R, C]) handleRangeProofRequest(ctx context.Context, req *p
 		return nil, err
 	}
 
+	getRangeProofAtRoot := h.db.GetRangeProofAtRoot
+	if req.KeyOnly {
+		db, ok := h.db.(KeyOnlyRangeProofer[R])
+		if !ok {
+			return nil, errKeyOnlyNotSupported
+		}
+		getRangeProofAtRoot = db.GetKeyOnlyRangeProofAtRoot
+	}
+
 	for keyLimit > 0 {
-		rangeProof, err := h.db.GetRangeProofAtRoot(
+		rangeProof, err := getRangeProofAtRoot(
 			ctx,
 			root,
 			startKey,
@@ -122,9 +182,6 @@ func (h *ProofHandler[R, C]) handleRangeProofRequest(ctx context.Context, req *p
 			keyLimit,
 		)
 		if err != nil {
-			if errors.Is(err, ErrInsufficientHistory) {
-				return nil, nil // drop request
-			}
 			return nil, err
 		}
 
@@ -152,11 +209,8 @@ func (h *ProofHandler[R, C]) handleRangeProofRequest(ctx context.Context, req *p
 	return nil, errMinProofSizeIsTooLarge
 }
 
+// handleChangeProofRequest assumes [req] is well-formed.
 func (h *ProofHandler[R, C]) handleChangeProofRequest(ctx context.Context, req *pb.ChangeProofRequest) ([]byte, error) {
-	if err := validateChangeProofRequest(req); err != nil {
-		return nil, err
-	}
-
 	// override limits if they exceed caps
 	var (
 		keyLimit   = min(req.KeyLimit, MaxKeyValuesLimit)
@@ -181,7 +235,6 @@ func (h *ProofHandler[R, C]) handleChangeProofRequest(ctx context.Context, req *
 			if !errors.Is(err, ErrInsufficientHistory) {
 				// We should only fail to get a change proof if we have insufficient history.
 				// Other errors are unexpected.
-				// TODO define custom errors
 				return nil, err
 			}
 			if errors.Is(err, ErrNoEndRoot) {
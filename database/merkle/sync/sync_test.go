@@ -281,6 +281,70 @@ func Test_Sync_ChangeProofRequest(t *testing.T) {
 	}
 }
 
+func Test_Sync_RangeTooLarge(t *testing.T) {
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	targetRoot := ids.GenerateTestID()
+	clientDB := &db{id: ids.Empty}
+
+	var keyLimits []uint32
+	finishingResponse := marshalRangeProofResponse(t, &proofDouble{newRoot: targetRoot})
+	handler := p2p.TestHandler{
+		AppRequestF: func(_ context.Context, _ ids.NodeID, _ time.Time, requestBytes []byte) ([]byte, *common.AppError) {
+			var request pb.ProofRequest
+			if err := proto.Unmarshal(requestBytes, &request); err != nil {
+				return nil, p2p.ErrUnexpected
+			}
+			keyLimit := request.GetRangeProof().GetKeyLimit()
+			keyLimits = append(keyLimits, keyLimit)
+			if keyLimit > DefaultRequestKeyLimit/4 {
+				return nil, ErrRangeTooLarge
+			}
+			return finishingResponse, nil
+		},
+	}
+
+	syncer, err := NewSyncer(
+		clientDB,
+		Config[*proofDouble, *proofDouble]{
+			TargetRoot:            targetRoot,
+			RangeProofMarshaler:   marshaler{},
+			ChangeProofMarshaler:  marshaler{},
+			ProofClient:           p2ptest.NewSelfClient(t, ctx, ids.EmptyNodeID, handler),
+			Log:                   logging.NoLog{},
+			SimultaneousWorkLimit: 1,
+		},
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+	require.NoErrorf(syncer.Sync(ctx), "%T.Sync()", syncer)
+	require.Equal(
+		[]uint32{
+			DefaultRequestKeyLimit,
+			DefaultRequestKeyLimit / 2,
+			DefaultRequestKeyLimit / 4,
+		},
+		keyLimits,
+	)
+}
+
+func Test_WorkItem_RequestThrottled(t *testing.T) {
+	require := require.New(t)
+
+	work := newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority, time.Now())
+	work.requestThrottled()
+	require.Equal(maxRetryWait, calculateBackoff(work.attempt))
+
+	// A work item that is throttled again keeps waiting for the maximum backoff.
+	attempt := work.attempt
+	work.requestThrottled()
+	require.Greater(work.attempt, attempt)
+	require.Equal(maxRetryWait, calculateBackoff(work.attempt))
+}
+
 func Test_Sync_BusyContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(t.Context())
 	defer cancel()
@@ -68,6 +68,8 @@ const (
 // nil [end] means there is no upper bound.
 // [localRootID] is the ID of the root of this range in our database.
 // If we have no local root for this range, [localRootID] is ids.Empty.
+// [keyLimit] is the maximum number of keys to request for this range.
+// If [keyLimit] is 0, [DefaultRequestKeyLimit] is requested.
 type workItem struct {
 	start       maybe.Maybe[[]byte]
 	end         maybe.Maybe[[]byte]
@@ -75,6 +77,7 @@ type workItem struct {
 	localRootID ids.ID
 	attempt     int
 	queueTime   time.Time
+	keyLimit    uint32
 }
 
 func (w *workItem) requestFailed() {
@@ -86,6 +89,23 @@ func (w *workItem) requestFailed() {
 	}
 }
 
+// requestThrottled records that a request for the work item was rejected by a
+// rate limit. Retrying before the rate limit replenishes would be rejected
+// again, so the work item waits for the maximum backoff.
+func (w *workItem) requestThrottled() {
+	w.requestFailed()
+	for calculateBackoff(w.attempt) < maxRetryWait {
+		w.requestFailed()
+	}
+}
+
+func (w *workItem) requestKeyLimit() uint32 {
+	if w.keyLimit == 0 {
+		return DefaultRequestKeyLimit
+	}
+	return w.keyLimit
+}
+
 func newWorkItem(localRootID ids.ID, start maybe.Maybe[[]byte], end maybe.Maybe[[]byte], priority priority, queueTime time.Time) *workItem {
 	return &workItem{
 		localRootID: localRootID,
@@ -404,7 +424,7 @@ func (s *Syncer[_, _]) requestChangeProof(ctx context.Context, work *workItem) {
 		EndRootHash:   targetRootID[:],
 		StartKey:      protoutils.MaybeToProto(work.start),
 		EndKey:        protoutils.MaybeToProto(work.end),
-		KeyLimit:      DefaultRequestKeyLimit,
+		KeyLimit:      work.requestKeyLimit(),
 		BytesLimit:    DefaultRequestByteSizeLimit,
 	}
 	request := &pb.ProofRequest{
@@ -418,13 +438,17 @@ func (s *Syncer[_, _]) requestChangeProof(ctx context.Context, work *workItem) {
 		return
 	}
 
-	onResponse := func(ctx context.Context, _ ids.NodeID, responseBytes []byte, err error) {
+	onResponse := func(ctx context.Context, nodeID ids.NodeID, responseBytes []byte, err error) {
 		defer s.finishWorkItem()
 
 		if err := s.handleChangeProofResponse(ctx, targetRootID, work, changeReq, responseBytes, err); err != nil {
 			// TODO log responses
-			s.config.Log.Debug("dropping response", zap.Error(err), zap.Stringer("request", request))
-			s.retryWork(work)
+			s.config.Log.Debug("dropping response",
+				zap.Stringer("nodeID", nodeID),
+				zap.Error(err),
+				zap.Stringer("request", request),
+			)
+			s.retryWork(work, err)
 			return
 		}
 	}
@@ -459,7 +483,7 @@ func (s *Syncer[_, _]) requestRangeProof(ctx context.Context, work *workItem) {
 		RootHash:   targetRootID[:],
 		StartKey:   protoutils.MaybeToProto(work.start),
 		EndKey:     protoutils.MaybeToProto(work.end),
-		KeyLimit:   DefaultRequestKeyLimit,
+		KeyLimit:   work.requestKeyLimit(),
 		BytesLimit: DefaultRequestByteSizeLimit,
 	}
 	request := &pb.ProofRequest{
@@ -473,13 +497,17 @@ func (s *Syncer[_, _]) requestRangeProof(ctx context.Context, work *workItem) {
 		return
 	}
 
-	onResponse := func(ctx context.Context, _ ids.NodeID, responseBytes []byte, appErr error) {
+	onResponse := func(ctx context.Context, nodeID ids.NodeID, responseBytes []byte, appErr error) {
 		defer s.finishWorkItem()
 
 		if err := s.handleRangeProofResponse(ctx, targetRootID, work, rangeReq, responseBytes, appErr); err != nil {
 			// TODO log responses
-			s.config.Log.Debug("dropping response", zap.Error(err), zap.Stringer("request", request))
-			s.retryWork(work)
+			s.config.Log.Debug("dropping response",
+				zap.Stringer("nodeID", nodeID),
+				zap.Error(err),
+				zap.Stringer("request", request),
+			)
+			s.retryWork(work, err)
 			return
 		}
 	}
@@ -511,10 +539,21 @@ func (s *Syncer[_, _]) sendRequest(
 	return client.AppRequest(ctx, set.Of(nodeID), requestBytes, onResponse)
 }
 
-func (s *Syncer[_, _]) retryWork(work *workItem) {
+// retryWork re-queues [work] after a request for it failed with [err].
+// Assumes [s.workLock] is not held.
+func (s *Syncer[_, _]) retryWork(work *workItem, err error) {
 	work.priority = retryPriority
 	work.queueTime = time.Now()
-	work.requestFailed()
+	switch {
+	case errors.Is(err, ErrRangeTooLarge) && work.requestKeyLimit() > 1:
+		// Request fewer keys, without backing off, so that the proof fits
+		// within the bytes limit.
+		work.keyLimit = work.requestKeyLimit() / 2
+	case errors.Is(err, ErrRateLimited):
+		work.requestThrottled()
+	default:
+		work.requestFailed()
+	}
 
 	s.workLock.Lock()
 	s.unprocessedWork.Insert(work)
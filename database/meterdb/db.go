@@ -16,9 +16,11 @@ import (
 const methodLabel = "method"
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.RangeDeleter = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
+	_ database.Batch        = (*batch)(nil)
+	_ database.Iterator     = (*iterator)(nil)
 
 	methodLabels = []string{methodLabel}
 	hasLabel     = prometheus.Labels{
@@ -39,9 +41,15 @@ var (
 	newIteratorLabel = prometheus.Labels{
 		methodLabel: "new_iterator",
 	}
+	deleteRangeLabel = prometheus.Labels{
+		methodLabel: "delete_range",
+	}
 	compactLabel = prometheus.Labels{
 		methodLabel: "compact",
 	}
+	snapshotLabel = prometheus.Labels{
+		methodLabel: "snapshot",
+	}
 	closeLabel = prometheus.Labels{
 		methodLabel: "close",
 	}
@@ -227,6 +235,33 @@ func (db *Database) Compact(start, limit []byte) error {
 	return err
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	startTime := time.Now()
+	err := database.DeleteRange(db.db, start, limit)
+	duration := time.Since(startTime)
+
+	db.calls.With(deleteRangeLabel).Inc()
+	db.duration.With(deleteRangeLabel).Add(float64(duration))
+	return err
+}
+
+// Snapshot forwards the request to the underlying database if it supports
+// snapshots.
+func (db *Database) Snapshot(dir string) error {
+	snapshotter, ok := db.db.(database.Snapshotter)
+	if !ok {
+		return database.ErrSnapshotNotSupported
+	}
+
+	start := time.Now()
+	err := snapshotter.Snapshot(dir)
+	duration := time.Since(start)
+
+	db.calls.With(snapshotLabel).Inc()
+	db.duration.With(snapshotLabel).Add(float64(duration))
+	return err
+}
+
 func (db *Database) Close() error {
 	start := time.Now()
 	err := db.db.Close()
@@ -56,3 +56,9 @@ func BenchmarkInterface(b *testing.B) {
 		}
 	}
 }
+
+func TestSnapshotNotSupported(t *testing.T) {
+	db, err := New(prometheus.NewRegistry(), memdb.New())
+	require.NoError(t, err)
+	require.ErrorIs(t, db.Snapshot(t.TempDir()), database.ErrSnapshotNotSupported)
+}
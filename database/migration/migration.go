@@ -0,0 +1,359 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package migration copies the contents of one database.Database into another,
+// allowing a node to switch database backends without re-bootstrapping.
+package migration
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/timer"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+var (
+	// checkpointKey is written to the destination database atomically with
+	// every batch of migrated keys. It records the last key that was copied so
+	// that an interrupted migration can be resumed. The key is removed once
+	// the migration completes.
+	checkpointKey = []byte("\x00avalanchego/database/migration/checkpoint")
+
+	ErrReservedKey      = errors.New("source database contains the reserved migration checkpoint key")
+	ErrMigrationPending = errors.New("migration has not completed")
+	ErrMismatch         = errors.New("databases differ")
+)
+
+type Config struct {
+	// BatchSize is the number of bytes to buffer before writing a batch, and
+	// the checkpoint, to the destination database.
+	BatchSize int `json:"batchSize"`
+	// IteratorReleasePeriod is the number of keys to read from the source
+	// database before the iterator is released and re-created. This avoids
+	// pinning an old database revision for the duration of the migration.
+	IteratorReleasePeriod int `json:"iteratorReleasePeriod"`
+	// LogPeriod is the minimum amount of time between progress logs.
+	LogPeriod time.Duration `json:"logPeriod"`
+	// PrefixLen is the number of leading key bytes used to group keys during
+	// verification.
+	PrefixLen int `json:"prefixLen"`
+}
+
+var DefaultConfig = Config{
+	BatchSize:             4 * units.MiB,
+	IteratorReleasePeriod: 1_000_000,
+	LogPeriod:             10 * time.Second,
+	PrefixLen:             1,
+}
+
+// Stats summarizes the work performed by a migration.
+type Stats struct {
+	NumKeys  uint64
+	NumBytes uint64
+	Resumed  bool
+	Duration time.Duration
+}
+
+type Migrator struct {
+	log    logging.Logger
+	src    database.Database
+	dst    database.Database
+	config Config
+}
+
+// New returns a Migrator that copies every key in src into dst.
+//
+// Neither database should be written to by anyone else while the migration is
+// running.
+func New(
+	log logging.Logger,
+	src database.Database,
+	dst database.Database,
+	config Config,
+) *Migrator {
+	return &Migrator{
+		log:    log,
+		src:    src,
+		dst:    dst,
+		config: config,
+	}
+}
+
+// Migrate copies all the keys from the source database into the destination
+// database.
+//
+// If a previous invocation was interrupted, Migrate resumes from the last
+// checkpoint recorded in the destination database.
+func (m *Migrator) Migrate(ctx context.Context) (Stats, error) {
+	lastKey, resumed, err := m.getCheckpoint()
+	if err != nil {
+		return Stats{}, err
+	}
+
+	var (
+		stats = Stats{
+			Resumed: resumed,
+		}
+		batch     = m.dst.NewBatch()
+		startTime = time.Now()
+
+		timeOfNextLog = startTime.Add(m.config.LogPeriod)
+		etaTracker    = timer.NewEtaTracker(10, 1.2)
+
+		iterator                      = m.newIterator(lastKey, resumed)
+		processedSinceIteratorRelease int
+	)
+	defer func() {
+		iterator.Release()
+	}()
+
+	writeBatch := func() error {
+		if batch.Size() == 0 {
+			return nil
+		}
+		if err := batch.Put(checkpointKey, lastKey); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+
+	m.log.Info("starting database migration",
+		zap.Bool("resumed", resumed),
+		zap.Binary("startKey", lastKey),
+	)
+
+	// Add the first sample to the EtaTracker to establish an accurate baseline
+	etaTracker.AddSample(timer.ProgressFromHash(lastKey), math.MaxUint64, startTime)
+
+	for iterator.Next() {
+		if err := ctx.Err(); err != nil {
+			return stats, errors.Join(err, writeBatch())
+		}
+
+		key := iterator.Key()
+		if bytes.Equal(key, checkpointKey) {
+			return stats, ErrReservedKey
+		}
+
+		value := iterator.Value()
+		if err := batch.Put(key, value); err != nil {
+			return stats, err
+		}
+		lastKey = slices.Clone(key)
+		stats.NumKeys++
+		stats.NumBytes += uint64(len(key) + len(value))
+
+		if batch.Size() >= m.config.BatchSize {
+			if err := writeBatch(); err != nil {
+				return stats, err
+			}
+		}
+
+		// Periodically release and re-grab the database iterator to avoid
+		// keeping a reference to an old database revision.
+		processedSinceIteratorRelease++
+		if processedSinceIteratorRelease >= m.config.IteratorReleasePeriod {
+			if err := iterator.Error(); err != nil {
+				return stats, err
+			}
+
+			processedSinceIteratorRelease = 0
+			iterator.Release()
+			iterator = m.newIterator(lastKey, true)
+		}
+
+		if now := time.Now(); now.After(timeOfNextLog) {
+			etaPtr, progressPercentage := etaTracker.AddSample(
+				timer.ProgressFromHash(lastKey),
+				math.MaxUint64,
+				now,
+			)
+			if etaPtr != nil {
+				m.log.Info("migrating database",
+					zap.Uint64("numKeys", stats.NumKeys),
+					zap.Uint64("numBytes", stats.NumBytes),
+					zap.Duration("eta", *etaPtr),
+					zap.Float64("pctComplete", progressPercentage),
+				)
+			}
+			timeOfNextLog = now.Add(m.config.LogPeriod)
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return stats, err
+	}
+	if err := writeBatch(); err != nil {
+		return stats, err
+	}
+	if err := m.dst.Delete(checkpointKey); err != nil {
+		return stats, err
+	}
+
+	stats.Duration = time.Since(startTime)
+	m.log.Info("finished database migration",
+		zap.Uint64("numKeys", stats.NumKeys),
+		zap.Uint64("numBytes", stats.NumBytes),
+		zap.Duration("duration", stats.Duration),
+	)
+	return stats, nil
+}
+
+// Verify compares the contents of the source and destination databases.
+//
+// Keys are grouped by their first [Config.PrefixLen] bytes and a hash is
+// computed over every key/value pair in each group. If any group differs,
+// the returned error wraps [ErrMismatch] and the differing prefixes are
+// returned.
+func (m *Migrator) Verify(ctx context.Context) ([][]byte, error) {
+	if _, pending, err := m.getCheckpoint(); err != nil {
+		return nil, err
+	} else if pending {
+		return nil, ErrMigrationPending
+	}
+
+	m.log.Info("verifying database migration")
+
+	srcHashes, err := m.prefixHashes(ctx, m.src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash source database: %w", err)
+	}
+	dstHashes, err := m.prefixHashes(ctx, m.dst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash destination database: %w", err)
+	}
+
+	var mismatched [][]byte
+	for prefix, srcHash := range srcHashes {
+		if dstHash, ok := dstHashes[prefix]; !ok || dstHash != srcHash {
+			mismatched = append(mismatched, []byte(prefix))
+		}
+	}
+	for prefix := range dstHashes {
+		if _, ok := srcHashes[prefix]; !ok {
+			mismatched = append(mismatched, []byte(prefix))
+		}
+	}
+	if len(mismatched) == 0 {
+		m.log.Info("verified database migration",
+			zap.Int("numPrefixes", len(srcHashes)),
+		)
+		return nil, nil
+	}
+
+	slices.SortFunc(mismatched, bytes.Compare)
+	return mismatched, fmt.Errorf("%w: %d of %d prefixes differ",
+		ErrMismatch,
+		len(mismatched),
+		max(len(srcHashes), len(dstHashes)),
+	)
+}
+
+// getCheckpoint returns the last key copied by an interrupted migration, if
+// there was one.
+func (m *Migrator) getCheckpoint() ([]byte, bool, error) {
+	lastKey, err := m.dst.Get(checkpointKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return slices.Clone(lastKey), true, nil
+}
+
+// newIterator returns an iterator over the source database. If [skipStart] is
+// true, the key equal to [start] is skipped.
+func (m *Migrator) newIterator(start []byte, skipStart bool) database.Iterator {
+	it := m.src.NewIteratorWithStart(start)
+	if !skipStart {
+		return it
+	}
+	return &skipIterator{
+		Iterator: it,
+		skip:     start,
+	}
+}
+
+func (m *Migrator) prefixHashes(ctx context.Context, db database.Iteratee) (map[string][sha256.Size]byte, error) {
+	var (
+		hashes = make(map[string][sha256.Size]byte)
+		prefix []byte
+		hasher hash.Hash
+
+		lengthBuf [binary.MaxVarintLen64]byte
+	)
+	finishPrefix := func() {
+		if hasher != nil {
+			hashes[string(prefix)] = [sha256.Size]byte(hasher.Sum(nil))
+		}
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		key := it.Key()
+		if bytes.Equal(key, checkpointKey) {
+			continue
+		}
+
+		keyPrefix := key[:min(len(key), m.config.PrefixLen)]
+		if hasher == nil || !bytes.Equal(keyPrefix, prefix) {
+			finishPrefix()
+			prefix = slices.Clone(keyPrefix)
+			hasher = sha256.New()
+		}
+
+		value := it.Value()
+		n := binary.PutUvarint(lengthBuf[:], uint64(len(key)))
+		_, _ = hasher.Write(lengthBuf[:n])
+		_, _ = hasher.Write(key)
+		n = binary.PutUvarint(lengthBuf[:], uint64(len(value)))
+		_, _ = hasher.Write(lengthBuf[:n])
+		_, _ = hasher.Write(value)
+	}
+	finishPrefix()
+	return hashes, it.Error()
+}
+
+// skipIterator skips the first key if it is equal to [skip].
+type skipIterator struct {
+	database.Iterator
+	skip    []byte
+	started bool
+}
+
+func (it *skipIterator) Next() bool {
+	if !it.Iterator.Next() {
+		return false
+	}
+	if it.started {
+		return true
+	}
+	it.started = true
+	if !bytes.Equal(it.Iterator.Key(), it.skip) {
+		return true
+	}
+	return it.Iterator.Next()
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func newTestDB(t *testing.T, numKeys int) database.Database {
+	db := memdb.New()
+	for i := range numKeys {
+		require.NoError(t, db.Put(
+			[]byte(fmt.Sprintf("key-%05d", i)),
+			[]byte(fmt.Sprintf("value-%d", i)),
+		))
+	}
+	return db
+}
+
+func testConfig() Config {
+	config := DefaultConfig
+	config.BatchSize = 64
+	config.IteratorReleasePeriod = 7
+	return config
+}
+
+func TestMigrate(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestDB(t, 100)
+	dst := memdb.New()
+	m := New(logging.NoLog{}, src, dst, testConfig())
+
+	stats, err := m.Migrate(t.Context())
+	require.NoError(err)
+	require.Equal(uint64(100), stats.NumKeys)
+	require.False(stats.Resumed)
+
+	has, err := dst.Has(checkpointKey)
+	require.NoError(err)
+	require.False(has)
+
+	mismatched, err := m.Verify(t.Context())
+	require.NoError(err)
+	require.Empty(mismatched)
+
+	srcCount, err := database.Count(src)
+	require.NoError(err)
+	dstCount, err := database.Count(dst)
+	require.NoError(err)
+	require.Equal(srcCount, dstCount)
+}
+
+func TestMigrateResume(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestDB(t, 100)
+	dst := memdb.New()
+
+	// Simulate an interrupted migration that copied the first 40 keys.
+	it := src.NewIterator()
+	for i := range 40 {
+		require.True(it.Next())
+		require.NoError(dst.Put(it.Key(), it.Value()))
+		if i == 39 {
+			require.NoError(dst.Put(checkpointKey, it.Key()))
+		}
+	}
+	it.Release()
+
+	m := New(logging.NoLog{}, src, dst, testConfig())
+
+	_, err := m.Verify(t.Context())
+	require.ErrorIs(err, ErrMigrationPending)
+
+	stats, err := m.Migrate(t.Context())
+	require.NoError(err)
+	require.True(stats.Resumed)
+	require.Equal(uint64(60), stats.NumKeys)
+
+	mismatched, err := m.Verify(t.Context())
+	require.NoError(err)
+	require.Empty(mismatched)
+}
+
+func TestMigrateCanceled(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestDB(t, 10)
+	dst := memdb.New()
+	m := New(logging.NoLog{}, src, dst, testConfig())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := m.Migrate(ctx)
+	require.ErrorIs(err, context.Canceled)
+
+	stats, err := m.Migrate(t.Context())
+	require.NoError(err)
+	require.Equal(uint64(10), stats.NumKeys)
+}
+
+func TestMigrateReservedKey(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestDB(t, 10)
+	require.NoError(src.Put(checkpointKey, nil))
+
+	m := New(logging.NoLog{}, src, memdb.New(), testConfig())
+	_, err := m.Migrate(t.Context())
+	require.ErrorIs(err, ErrReservedKey)
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestDB(t, 10)
+	dst := newTestDB(t, 10)
+	require.NoError(dst.Put([]byte("key-00003"), []byte("modified")))
+	require.NoError(dst.Put([]byte("other"), nil))
+
+	m := New(logging.NoLog{}, src, dst, testConfig())
+	mismatched, err := m.Verify(t.Context())
+	require.ErrorIs(err, ErrMismatch)
+	require.Equal([][]byte{[]byte("k"), []byte("o")}, mismatched)
+}
@@ -31,7 +31,9 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.RangeDeleter = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
 
 	errInvalidOperation = errors.New("invalid operation")
 
@@ -120,6 +122,19 @@ func (db *Database) Close() error {
 	return updateError(db.pebbleDB.Close())
 }
 
+// Snapshot creates a pebble checkpoint of the database at [dir]. Where
+// possible, the checkpoint hard links the immutable sstables of the database
+// rather than copying them.
+func (db *Database) Snapshot(dir string) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return updateError(db.pebbleDB.Checkpoint(dir, pebble.WithFlushedWAL()))
+}
+
 func (db *Database) HealthCheck(_ context.Context) (interface{}, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -185,6 +200,58 @@ func (db *Database) Delete(key []byte) error {
 	return updateError(db.pebbleDB.Delete(key, db.writeOptions))
 }
 
+// DeleteRange removes all keys in [start, limit) with a single range deletion
+// tombstone rather than by deleting every key individually.
+func (db *Database) DeleteRange(start []byte, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	if start == nil {
+		start = []byte{}
+	}
+
+	batch := db.pebbleDB.NewBatch()
+	defer batch.Close()
+
+	if limit == nil {
+		// The database.Database spec treats a nil [limit] as a key after all
+		// keys but pebble does not support an unbounded range deletion. Use
+		// the greatest key in the database as the [limit] and delete it
+		// separately.
+		it, err := db.pebbleDB.NewIter(&pebble.IterOptions{})
+		if err != nil {
+			return updateError(err)
+		}
+
+		if !it.Last() {
+			// The database is empty.
+			return it.Close()
+		}
+
+		limit = slices.Clone(it.Key())
+		if err := it.Close(); err != nil {
+			return err
+		}
+
+		if pebble.DefaultComparer.Compare(start, limit) <= 0 {
+			if err := batch.Delete(limit, nil); err != nil {
+				return updateError(err)
+			}
+		}
+	}
+
+	if pebble.DefaultComparer.Compare(start, limit) < 0 {
+		if err := batch.DeleteRange(start, limit, nil); err != nil {
+			return updateError(err)
+		}
+	}
+	return updateError(batch.Commit(db.writeOptions))
+}
+
 func (db *Database) Compact(start []byte, end []byte) error {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -10,6 +10,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/dbtest"
 	"github.com/ava-labs/avalanchego/utils/logging"
 )
@@ -154,3 +155,16 @@ func TestKeyRange(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshot(t *testing.T) {
+	db := newDB(t)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	dbtest.TestSnapshot(t, db, func(t *testing.T, dir string) database.Database {
+		db, err := New(dir, nil, logging.NoLog{}, prometheus.NewRegistry())
+		require.NoError(t, err)
+		return db
+	})
+}
@@ -8,19 +8,27 @@ import (
 	"encoding/json"
 	"sync"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
 
 	rpcdbpb "github.com/ava-labs/avalanchego/proto/pb/rpcdb"
 )
 
+// deleteRangeWriteSize is the batch size used by DeleteRange when the server
+// doesn't support range deletions.
+const deleteRangeWriteSize = 4 * units.MiB
+
 var (
-	_ database.Database = (*DatabaseClient)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database     = (*DatabaseClient)(nil)
+	_ database.RangeDeleter = (*DatabaseClient)(nil)
+	_ database.Batch        = (*batch)(nil)
+	_ database.Iterator     = (*iterator)(nil)
 )
 
 // DatabaseClient is an implementation of database that talks over RPC.
@@ -123,6 +131,23 @@ func (db *DatabaseClient) Compact(start, limit []byte) error {
 	return ErrEnumToError[resp.Err]
 }
 
+// DeleteRange attempts to remove all keys in the provided range
+func (db *DatabaseClient) DeleteRange(start, limit []byte) error {
+	resp, err := db.client.DeleteRange(context.Background(), &rpcdbpb.DeleteRangeRequest{
+		Start: start,
+		Limit: limit,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		// Servers running an older version do not support range deletions,
+		// so the keys are removed individually.
+		return database.ClearRange(db, start, limit, deleteRangeWriteSize)
+	}
+	if err != nil {
+		return err
+	}
+	return ErrEnumToError[resp.Err]
+}
+
 // Close attempts to close the database
 func (db *DatabaseClient) Close() error {
 	db.closed.Set(true)
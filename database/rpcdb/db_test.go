@@ -4,10 +4,13 @@
 package rpcdb
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/ava-labs/avalanchego/database/corruptabledb"
 	"github.com/ava-labs/avalanchego/database/dbtest"
@@ -24,6 +27,21 @@ type testDatabase struct {
 }
 
 func setupDB(t testing.TB) *testDatabase {
+	return setupDBWithServer(t, func(s *DatabaseServer) rpcdbpb.DatabaseServer {
+		return s
+	})
+}
+
+// legacyServer emulates a server that predates the DeleteRange RPC.
+type legacyServer struct {
+	*DatabaseServer
+}
+
+func (*legacyServer) DeleteRange(context.Context, *rpcdbpb.DeleteRangeRequest) (*rpcdbpb.DeleteRangeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteRange not implemented")
+}
+
+func setupDBWithServer(t testing.TB, wrap func(*DatabaseServer) rpcdbpb.DatabaseServer) *testDatabase {
 	require := require.New(t)
 
 	db := &testDatabase{
@@ -35,7 +53,7 @@ func setupDB(t testing.TB) *testDatabase {
 	serverCloser := grpcutils.ServerCloser{}
 
 	server := grpcutils.NewServer()
-	rpcdbpb.RegisterDatabaseServer(server, NewServer(db.server))
+	rpcdbpb.RegisterDatabaseServer(server, wrap(NewServer(db.server)))
 	serverCloser.Add(server)
 
 	go grpcutils.Serve(listener, server)
@@ -63,6 +81,13 @@ func TestInterface(t *testing.T) {
 	}
 }
 
+func TestDeleteRangeUnimplemented(t *testing.T) {
+	db := setupDBWithServer(t, func(s *DatabaseServer) rpcdbpb.DatabaseServer {
+		return &legacyServer{DatabaseServer: s}
+	})
+	dbtest.TestDeleteRange(t, db.client)
+}
+
 func FuzzKeyValue(f *testing.F) {
 	db := setupDB(f)
 	dbtest.FuzzKeyValue(f, db.client)
@@ -0,0 +1,333 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package snapshot manages point-in-time copies of the node database.
+//
+// Every snapshot is stored in its own directory:
+//
+//	<dir>/<name>/db            the database files
+//	<dir>/<name>/snapshot.json the snapshot's [Info]
+//
+// The info file is written last, so a snapshot directory without one was
+// interrupted while it was being written and is ignored.
+//
+// When a snapshot is restored, its [Info] is recorded next to the database in
+// <dbPath>.restored.json so that the same snapshot isn't restored again on
+// every startup.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/perms"
+)
+
+const (
+	dbDirName    = "db"
+	infoFileName = "snapshot.json"
+
+	// restoredFileSuffix is appended to the path of a database to get the path
+	// of the file that records the snapshot it was restored from.
+	restoredFileSuffix = ".restored.json"
+
+	// timestampNameFormat is used to name snapshots that were not given an
+	// explicit name.
+	timestampNameFormat = "20060102T150405Z"
+)
+
+var (
+	validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	ErrInvalidName      = errors.New("invalid snapshot name")
+	ErrAlreadyExists    = errors.New("snapshot already exists")
+	ErrNotFound         = errors.New("snapshot not found")
+	ErrMismatchedDBType = errors.New("mismatched database type")
+	ErrAlreadyRestored  = errors.New("snapshot already restored")
+)
+
+// Info describes a snapshot.
+type Info struct {
+	Name      string    `json:"name"`
+	DBType    string    `json:"dbType"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      uint64    `json:"size"`
+}
+
+// Manager creates and lists snapshots of a database.
+type Manager struct {
+	dir    string
+	dbType string
+	db     database.Database
+
+	// lock serializes snapshot creation so that two snapshots with the same
+	// name can not be written concurrently.
+	lock sync.Mutex
+}
+
+// NewManager returns a manager that stores snapshots of [db], which is a
+// database of type [dbType], in [dir].
+func NewManager(dir string, dbType string, db database.Database) *Manager {
+	return &Manager{
+		dir:    dir,
+		dbType: dbType,
+		db:     db,
+	}
+}
+
+// Create writes a new snapshot of the database. If [name] is empty, the
+// snapshot is named after the current time.
+func (m *Manager) Create(name string) (Info, error) {
+	snapshotter, ok := m.db.(database.Snapshotter)
+	if !ok {
+		return Info{}, database.ErrSnapshotNotSupported
+	}
+
+	now := time.Now().UTC()
+	if len(name) == 0 {
+		name = now.Format(timestampNameFormat)
+	}
+	if !validName.MatchString(name) {
+		return Info{}, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	snapshotDir := filepath.Join(m.dir, name)
+	if _, err := os.Stat(snapshotDir); err == nil {
+		return Info{}, fmt.Errorf("%w: %q", ErrAlreadyExists, name)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return Info{}, err
+	}
+	if err := os.MkdirAll(snapshotDir, perms.ReadWriteExecute); err != nil {
+		return Info{}, err
+	}
+
+	dbDir := filepath.Join(snapshotDir, dbDirName)
+	if err := snapshotter.Snapshot(dbDir); err != nil {
+		return Info{}, errors.Join(err, os.RemoveAll(snapshotDir))
+	}
+
+	size, err := dirSize(dbDir)
+	if err != nil {
+		return Info{}, errors.Join(err, os.RemoveAll(snapshotDir))
+	}
+
+	info := Info{
+		Name:      name,
+		DBType:    m.dbType,
+		Timestamp: now,
+		Size:      size,
+	}
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return Info{}, errors.Join(err, os.RemoveAll(snapshotDir))
+	}
+	infoPath := filepath.Join(snapshotDir, infoFileName)
+	if err := os.WriteFile(infoPath, infoBytes, perms.ReadWrite); err != nil {
+		return Info{}, errors.Join(err, os.RemoveAll(snapshotDir))
+	}
+	return info, nil
+}
+
+// List returns all the completed snapshots, sorted by their creation time.
+func (m *Manager) List() ([]Info, error) {
+	return List(m.dir)
+}
+
+// List returns all the completed snapshots in [dir], sorted by their creation
+// time.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]Info, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		info, err := readInfo(dir, entry.Name())
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b Info) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return infos, nil
+}
+
+// Restore replaces the database at [dbPath] with the snapshot [name] stored in
+// [dir]. The snapshot must have been taken of a database of type [dbType].
+//
+// If a database already exists at [dbPath], it is moved aside rather than
+// deleted. The path it was moved to is returned.
+//
+// Restoring is one-shot: if the database at [dbPath] was already restored from
+// this snapshot, [ErrAlreadyRestored] is returned and the database is left
+// untouched.
+//
+// Restore must be called before the database at [dbPath] is opened.
+func Restore(dir string, name string, dbType string, dbPath string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	info, err := readInfo(dir, name)
+	if err != nil {
+		return "", err
+	}
+	if info.DBType != dbType {
+		return "", fmt.Errorf("%w: snapshot %q is a %s database but %s is configured",
+			ErrMismatchedDBType,
+			name,
+			info.DBType,
+			dbType,
+		)
+	}
+
+	restoredPath := dbPath + restoredFileSuffix
+	restored, err := readInfoFile(restoredPath)
+	switch {
+	case err == nil && restored.Name == info.Name && restored.Timestamp.Equal(info.Timestamp):
+		return "", fmt.Errorf("%w: %q", ErrAlreadyRestored, name)
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return "", err
+	}
+
+	var movedTo string
+	if _, err := os.Stat(dbPath); err == nil {
+		movedTo = fmt.Sprintf("%s.pre-restore-%d", dbPath, time.Now().Unix())
+		if err := os.Rename(dbPath, movedTo); err != nil {
+			return "", err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	// The snapshot is copied, rather than moved, so that it can be restored
+	// again.
+	snapshotDBDir := filepath.Join(dir, name, dbDirName)
+	if err := copyDir(snapshotDBDir, dbPath); err != nil {
+		return "", errors.Join(err, undoRestore(dbPath, movedTo))
+	}
+
+	// The restored snapshot is recorded last, so that an interrupted restore
+	// is retried.
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return "", errors.Join(err, undoRestore(dbPath, movedTo))
+	}
+	if err := os.WriteFile(restoredPath, infoBytes, perms.ReadWrite); err != nil {
+		return "", errors.Join(err, undoRestore(dbPath, movedTo))
+	}
+	return movedTo, nil
+}
+
+// undoRestore removes the partially restored database at [dbPath] and moves
+// the previous database back from [movedTo], if there was one.
+func undoRestore(dbPath string, movedTo string) error {
+	if err := os.RemoveAll(dbPath); err != nil {
+		return err
+	}
+	if len(movedTo) == 0 {
+		return nil
+	}
+	return os.Rename(movedTo, dbPath)
+}
+
+func readInfo(dir string, name string) (Info, error) {
+	info, err := readInfoFile(filepath.Join(dir, name, infoFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Info{}, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	return info, err
+}
+
+func readInfoFile(path string) (Info, error) {
+	infoBytes, err := os.ReadFile(path)
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+	if err := json.Unmarshal(infoBytes, &info); err != nil {
+		return Info{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return info, nil
+}
+
+func dirSize(dir string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += uint64(info.Size())
+		return nil
+	})
+	return size, err
+}
+
+func copyDir(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, relPath)
+		if d.IsDir() {
+			return os.MkdirAll(dstPath, perms.ReadWriteExecute)
+		}
+		return copyFile(path, dstPath)
+	})
+}
+
+func copyFile(src string, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := perms.Create(dst, perms.ReadWrite)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return errors.Join(err, dstFile.Close())
+	}
+	if err := dstFile.Sync(); err != nil {
+		return errors.Join(err, dstFile.Close())
+	}
+	return dstFile.Close()
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func newPebbleDB(t *testing.T, dir string) database.Database {
+	db, err := pebbledb.New(dir, nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(t, err)
+	return db
+}
+
+func TestCreateListRestore(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		dir         = t.TempDir()
+		dbPath      = filepath.Join(dir, "pebble")
+		snapshotDir = filepath.Join(dir, "snapshots")
+		db          = newPebbleDB(t, dbPath)
+		m           = NewManager(snapshotDir, pebbledb.Name, db)
+	)
+
+	infos, err := m.List()
+	require.NoError(err)
+	require.Empty(infos)
+
+	require.NoError(db.Put([]byte("hello"), []byte("world")))
+	info, err := m.Create("first")
+	require.NoError(err)
+	require.Equal("first", info.Name)
+	require.Equal(pebbledb.Name, info.DBType)
+	require.NotZero(info.Size)
+
+	_, err = m.Create("first")
+	require.ErrorIs(err, ErrAlreadyExists)
+
+	_, err = m.Create("../escape")
+	require.ErrorIs(err, ErrInvalidName)
+
+	require.NoError(db.Put([]byte("hello"), []byte("there")))
+	second, err := m.Create("")
+	require.NoError(err)
+
+	infos, err = m.List()
+	require.NoError(err)
+	require.Equal([]Info{info, second}, infos)
+
+	require.NoError(db.Close())
+
+	_, err = Restore(snapshotDir, "first", leveldb.Name, dbPath)
+	require.ErrorIs(err, ErrMismatchedDBType)
+
+	_, err = Restore(snapshotDir, "missing", pebbledb.Name, dbPath)
+	require.ErrorIs(err, ErrNotFound)
+
+	movedTo, err := Restore(snapshotDir, "first", pebbledb.Name, dbPath)
+	require.NoError(err)
+	require.DirExists(movedTo)
+
+	db = newPebbleDB(t, dbPath)
+	value, err := db.Get([]byte("hello"))
+	require.NoError(err)
+	require.Equal([]byte("world"), value)
+	require.NoError(db.Put([]byte("hello"), []byte("again")))
+	require.NoError(db.Close())
+
+	// Restoring the same snapshot again must not roll the database back.
+	_, err = Restore(snapshotDir, "first", pebbledb.Name, dbPath)
+	require.ErrorIs(err, ErrAlreadyRestored)
+
+	db = newPebbleDB(t, dbPath)
+	value, err = db.Get([]byte("hello"))
+	require.NoError(err)
+	require.Equal([]byte("again"), value)
+	require.NoError(db.Close())
+
+	// The restored snapshot must remain usable.
+	infos, err = List(snapshotDir)
+	require.NoError(err)
+	require.Len(infos, 2)
+}
+
+func TestCreateNotSupported(t *testing.T) {
+	m := NewManager(t.TempDir(), memdb.Name, memdb.New())
+	_, err := m.Create("")
+	require.ErrorIs(t, err, database.ErrSnapshotNotSupported)
+}
+
+func TestRestoreFailureKeepsDatabase(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		dir         = t.TempDir()
+		dbPath      = filepath.Join(dir, "pebble")
+		snapshotDir = filepath.Join(dir, "snapshots")
+		db          = newPebbleDB(t, dbPath)
+		m           = NewManager(snapshotDir, pebbledb.Name, db)
+	)
+	require.NoError(db.Put([]byte("hello"), []byte("world")))
+	_, err := m.Create("first")
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	// Removing the database files of the snapshot makes copying fail.
+	require.NoError(os.RemoveAll(filepath.Join(snapshotDir, "first", dbDirName)))
+
+	_, err = Restore(snapshotDir, "first", pebbledb.Name, dbPath)
+	require.ErrorIs(err, fs.ErrNotExist)
+
+	db = newPebbleDB(t, dbPath)
+	value, err := db.Get([]byte("hello"))
+	require.NoError(err)
+	require.Equal([]byte("world"), value)
+	require.NoError(db.Close())
+}
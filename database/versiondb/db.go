@@ -5,6 +5,7 @@ package versiondb
 
 import (
 	"context"
+	"math"
 	"slices"
 	"strings"
 	"sync"
@@ -14,10 +15,11 @@ import (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ Commitable        = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.RangeDeleter = (*Database)(nil)
+	_ Commitable            = (*Database)(nil)
+	_ database.Batch        = (*batch)(nil)
+	_ database.Iterator     = (*iterator)(nil)
 )
 
 // Commitable defines the interface that specifies that something may be
@@ -102,6 +104,12 @@ func (db *Database) Delete(key []byte) error {
 	return nil
 }
 
+// DeleteRange marks every key in [start, limit) as deleted. The deletions are
+// only written to the underlying database on Commit.
+func (db *Database) DeleteRange(start, limit []byte) error {
+	return database.ClearRange(db, start, limit, math.MaxInt)
+}
+
 func (db *Database) NewBatch() database.Batch {
 	return &batch{db: db}
 }
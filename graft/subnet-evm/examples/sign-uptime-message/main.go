@@ -20,6 +20,7 @@ import (
 	"github.com/ava-labs/avalanchego/proto/pb/sdk"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
 	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
@@ -84,6 +85,7 @@ func main() {
 	messageBuilder, err := p2pmessage.NewCreator(
 		prometheus.NewRegistry(),
 		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		time.Hour,
 	)
 	if err != nil {
@@ -105,6 +107,7 @@ func main() {
 			p2p.ProtocolPrefix(p2p.SignatureRequestHandlerID),
 			appRequestPayload,
 		),
+		nil,
 	)
 	if err != nil {
 		log.Fatalf("failed to create AppRequest: %s\n", err)
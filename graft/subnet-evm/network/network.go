@@ -152,6 +152,7 @@ func NewNetwork(
 		registerer,
 		set.Of(ctx.NodeID),
 		nil,
+		nil,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create peer tracker: %w", err)
@@ -0,0 +1,129 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/x/blockdb"
+)
+
+const (
+	blockDBCompactCommand = "blockdb-compact"
+
+	blockDBIndexDirKey = "index-dir"
+	blockDBDataDirKey  = "data-dir"
+)
+
+var errCorruptBlocks = errors.New("found corrupt blocks")
+
+// runBlockDBCompact verifies every block of the blockdb at --index-dir and
+// --data-dir and, unless --verify-only is set, rewrites its data files in
+// height order. Corrupt blocks are reported and dropped by the compaction.
+func runBlockDBCompact(args []string) int {
+	fs := pflag.NewFlagSet(blockDBCompactCommand, pflag.ContinueOnError)
+	fs.String(blockDBIndexDirKey, "", "Directory of the blockdb index file")
+	fs.String(blockDBDataDirKey, "", "Directory of the blockdb data files. Defaults to the index directory")
+	fs.Bool(verifyOnlyKey, false, "Only verify the blocks and report corrupt ranges without compacting the data files")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		fmt.Printf("couldn't parse flags: %s\n", err)
+		return 1
+	}
+
+	indexDir, _ := fs.GetString(blockDBIndexDirKey)
+	dataDir, _ := fs.GetString(blockDBDataDirKey)
+	verifyOnly, _ := fs.GetBool(verifyOnlyKey)
+	if len(indexDir) == 0 {
+		fmt.Printf("--%s is required\n", blockDBIndexDirKey)
+		return 1
+	}
+	if len(dataDir) == 0 {
+		dataDir = indexDir
+	}
+
+	logFormat, err := logging.ToFormat(logging.AutoString, os.Stdout.Fd())
+	if err != nil {
+		fmt.Printf("couldn't configure log format: %s\n", err)
+		return 1
+	}
+	log := logging.NewLogger("", logging.NewWrappedCore(
+		logging.Info,
+		os.Stdout,
+		logFormat.ConsoleEncoder(),
+	))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := blockDBCompact(ctx, log, indexDir, dataDir, verifyOnly); err != nil {
+		log.Error("blockdb compaction failed",
+			zap.Error(err),
+		)
+		return 1
+	}
+	return 0
+}
+
+func blockDBCompact(
+	ctx context.Context,
+	log logging.Logger,
+	indexDir string,
+	dataDir string,
+	verifyOnly bool,
+) error {
+	config := blockdb.DefaultConfig().
+		WithIndexDir(indexDir).
+		WithDataDir(dataDir).
+		WithBlockCacheSize(0)
+	heightIndex, err := blockdb.New(config, log)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := heightIndex.Close(); err != nil {
+			log.Error("failed to close database",
+				zap.Error(err),
+			)
+		}
+	}()
+
+	db, ok := heightIndex.(*blockdb.Database)
+	if !ok {
+		return fmt.Errorf("unexpected database type %T", heightIndex)
+	}
+
+	var corrupt []blockdb.CorruptRange
+	if verifyOnly {
+		corrupt, err = db.Verify(ctx)
+	} else {
+		corrupt, err = db.Compact(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, r := range corrupt {
+		log.Error("blocks are corrupt",
+			zap.Uint64("start", r.Start),
+			zap.Uint64("end", r.End),
+			zap.Error(r.Err),
+		)
+	}
+	if verifyOnly && len(corrupt) > 0 {
+		return fmt.Errorf("%w: %d corrupt ranges", errCorruptBlocks, len(corrupt))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database/factory"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/migration"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+const (
+	dbMigrateCommand = "db-migrate"
+
+	srcTypeKey    = "src-db-type"
+	srcPathKey    = "src-db-path"
+	dstTypeKey    = "dst-db-type"
+	dstPathKey    = "dst-db-path"
+	batchSizeKey  = "batch-size"
+	verifyOnlyKey = "verify-only"
+	skipVerifyKey = "skip-verify"
+)
+
+// runDBMigrate copies the database at --src-db-path into a new database at
+// --dst-db-path, potentially using a different database backend. The
+// migration can be interrupted and restarted with the same arguments.
+func runDBMigrate(args []string) int {
+	fs := pflag.NewFlagSet(dbMigrateCommand, pflag.ContinueOnError)
+	fs.String(srcTypeKey, leveldb.Name, "Type of the database to migrate from")
+	fs.String(srcPathKey, "", "Path to the database to migrate from")
+	fs.String(dstTypeKey, pebbledb.Name, "Type of the database to migrate to")
+	fs.String(dstPathKey, "", "Path to the database to migrate to")
+	fs.Int(batchSizeKey, migration.DefaultConfig.BatchSize, "Number of bytes to write to the destination database at a time")
+	fs.Bool(verifyOnlyKey, false, "Only verify that the databases contain the same contents")
+	fs.Bool(skipVerifyKey, false, "Skip verifying the databases after the migration")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		fmt.Printf("couldn't parse flags: %s\n", err)
+		return 1
+	}
+
+	srcType, _ := fs.GetString(srcTypeKey)
+	srcPath, _ := fs.GetString(srcPathKey)
+	dstType, _ := fs.GetString(dstTypeKey)
+	dstPath, _ := fs.GetString(dstPathKey)
+	batchSize, _ := fs.GetInt(batchSizeKey)
+	verifyOnly, _ := fs.GetBool(verifyOnlyKey)
+	skipVerify, _ := fs.GetBool(skipVerifyKey)
+	switch {
+	case len(srcPath) == 0:
+		fmt.Printf("--%s is required\n", srcPathKey)
+		return 1
+	case len(dstPath) == 0:
+		fmt.Printf("--%s is required\n", dstPathKey)
+		return 1
+	case srcPath == dstPath:
+		fmt.Printf("--%s and --%s must differ\n", srcPathKey, dstPathKey)
+		return 1
+	case batchSize <= 0:
+		fmt.Printf("--%s must be positive\n", batchSizeKey)
+		return 1
+	}
+
+	logFormat, err := logging.ToFormat(logging.AutoString, os.Stdout.Fd())
+	if err != nil {
+		fmt.Printf("couldn't configure log format: %s\n", err)
+		return 1
+	}
+	log := logging.NewLogger("", logging.NewWrappedCore(
+		logging.Info,
+		os.Stdout,
+		logFormat.ConsoleEncoder(),
+	))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := dbMigrate(ctx, log, srcType, srcPath, dstType, dstPath, batchSize, verifyOnly, skipVerify); err != nil {
+		log.Error("database migration failed",
+			zap.Error(err),
+		)
+		return 1
+	}
+	return 0
+}
+
+func dbMigrate(
+	ctx context.Context,
+	log logging.Logger,
+	srcType string,
+	srcPath string,
+	dstType string,
+	dstPath string,
+	batchSize int,
+	verifyOnly bool,
+	skipVerify bool,
+) error {
+	src, err := factory.New(srcType, srcPath, true, nil, prometheus.NewRegistry(), log)
+	if err != nil {
+		return err
+	}
+	dst, err := factory.New(dstType, dstPath, verifyOnly, nil, prometheus.NewRegistry(), log)
+	if err != nil {
+		return errors.Join(err, src.Close())
+	}
+	defer func() {
+		if err := errors.Join(src.Close(), dst.Close()); err != nil {
+			log.Error("failed to close databases",
+				zap.Error(err),
+			)
+		}
+	}()
+
+	config := migration.DefaultConfig
+	config.BatchSize = batchSize
+	m := migration.New(log, src, dst, config)
+	if !verifyOnly {
+		if _, err := m.Migrate(ctx); err != nil {
+			return err
+		}
+	}
+	if skipVerify {
+		return nil
+	}
+
+	mismatched, err := m.Verify(ctx)
+	for _, prefix := range mismatched {
+		log.Error("prefix differs between databases",
+			zap.Binary("prefix", prefix),
+		)
+	}
+	return err
+}
@@ -19,6 +19,17 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case dbMigrateCommand:
+			os.Exit(runDBMigrate(os.Args[2:]))
+		case blockDBCompactCommand:
+			os.Exit(runBlockDBCompact(os.Args[2:]))
+		case merkleDBDiffCommand:
+			os.Exit(runMerkleDBDiff(os.Args[2:]))
+		}
+	}
+
 	evm.RegisterAllLibEVMExtras()
 
 	fs := config.BuildFlagSet()
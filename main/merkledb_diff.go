@@ -0,0 +1,344 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/factory"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+const (
+	merkleDBDiffCommand = "merkledb-diff"
+
+	dbTypeKey            = "db-type"
+	dbPathKey            = "db-path"
+	otherDBPathKey       = "other-db-path"
+	prefixKey            = "prefix"
+	branchFactorKey      = "branch-factor"
+	diskHistoryLengthKey = "disk-history-length"
+	startRootKey         = "start-root"
+	endRootKey           = "end-root"
+	startKeyKey          = "start-key"
+	endKeyKey            = "end-key"
+	formatKey            = "format"
+
+	jsonFormat = "json"
+	csvFormat  = "csv"
+)
+
+var errUnknownFormat = errors.New("unknown format")
+
+type merkleDBDiffConfig struct {
+	dbType            string
+	dbPath            string
+	otherDBPath       string
+	prefix            []byte
+	branchFactor      merkledb.BranchFactor
+	diskHistoryLength uint
+	startRoot         ids.ID
+	endRoot           maybe.Maybe[ids.ID]
+	start             maybe.Maybe[[]byte]
+	end               maybe.Maybe[[]byte]
+	format            string
+}
+
+// runMerkleDBDiff writes the keys whose values differ between two merkledb
+// roots to stdout.
+//
+// If --other-db-path is set, the current root of the merkledb at --db-path is
+// compared to the current root of the merkledb at --other-db-path, for example
+// to find where the state of two nodes diverged. Otherwise, --start-root is
+// compared to --end-root using the history of the merkledb at --db-path.
+func runMerkleDBDiff(args []string) int {
+	fs := pflag.NewFlagSet(merkleDBDiffCommand, pflag.ContinueOnError)
+	fs.String(dbTypeKey, leveldb.Name, "Type of the databases")
+	fs.String(dbPathKey, "", "Path to the database")
+	fs.String(otherDBPathKey, "", "Path to the database to compare to. If set, the current roots of the databases are compared")
+	fs.String(prefixKey, "", "Hex encoded prefix of the merkledb within the databases")
+	fs.Int(branchFactorKey, int(merkledb.BranchFactor16), "Branch factor of the merkledb")
+	fs.Uint(diskHistoryLengthKey, 0, "Number of roots kept in the merkledb disk history. Must match the configuration of the merkledb. Required if --"+startRootKey+" is set")
+	fs.String(startRootKey, "", "Root to diff from. Required unless --"+otherDBPathKey+" is set")
+	fs.String(endRootKey, "", "Root to diff to. Defaults to the current root")
+	fs.String(startKeyKey, "", "Hex encoded smallest key to diff. Defaults to no lower bound")
+	fs.String(endKeyKey, "", "Hex encoded largest key to diff. Defaults to no upper bound")
+	fs.String(formatKey, jsonFormat, fmt.Sprintf("Output format. One of %q or %q", jsonFormat, csvFormat))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		fmt.Printf("couldn't parse flags: %s\n", err)
+		return 1
+	}
+
+	config, err := getMerkleDBDiffConfig(fs)
+	if err != nil {
+		fmt.Printf("couldn't parse flags: %s\n", err)
+		return 1
+	}
+
+	// The diff is written to stdout, so logs are written to stderr.
+	logFormat, err := logging.ToFormat(logging.AutoString, os.Stderr.Fd())
+	if err != nil {
+		fmt.Printf("couldn't configure log format: %s\n", err)
+		return 1
+	}
+	log := logging.NewLogger("", logging.NewWrappedCore(
+		logging.Info,
+		os.Stderr,
+		logFormat.ConsoleEncoder(),
+	))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	w := bufio.NewWriter(os.Stdout)
+	err = merkleDBDiff(ctx, log, config, w)
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		log.Error("merkledb diff failed",
+			zap.Error(err),
+		)
+		return 1
+	}
+	return 0
+}
+
+func getMerkleDBDiffConfig(fs *pflag.FlagSet) (merkleDBDiffConfig, error) {
+	var (
+		config merkleDBDiffConfig
+		err    error
+	)
+	config.dbType, _ = fs.GetString(dbTypeKey)
+	config.dbPath, _ = fs.GetString(dbPathKey)
+	config.otherDBPath, _ = fs.GetString(otherDBPathKey)
+	config.diskHistoryLength, _ = fs.GetUint(diskHistoryLengthKey)
+	config.format, _ = fs.GetString(formatKey)
+	branchFactor, _ := fs.GetInt(branchFactorKey)
+	config.branchFactor = merkledb.BranchFactor(branchFactor)
+
+	prefix, _ := fs.GetString(prefixKey)
+	config.prefix, err = hex.DecodeString(prefix)
+	if err != nil {
+		return config, fmt.Errorf("invalid --%s: %w", prefixKey, err)
+	}
+	config.start, err = getMaybeHexFlag(fs, startKeyKey)
+	if err != nil {
+		return config, err
+	}
+	config.end, err = getMaybeHexFlag(fs, endKeyKey)
+	if err != nil {
+		return config, err
+	}
+
+	startRoot, _ := fs.GetString(startRootKey)
+	endRoot, _ := fs.GetString(endRootKey)
+	switch {
+	case len(config.dbPath) == 0:
+		return config, fmt.Errorf("--%s is required", dbPathKey)
+	case config.dbPath == config.otherDBPath:
+		return config, fmt.Errorf("--%s and --%s must differ", dbPathKey, otherDBPathKey)
+	case len(config.otherDBPath) > 0 && (len(startRoot) > 0 || len(endRoot) > 0):
+		return config, fmt.Errorf("--%s and --%s can't be set with --%s", startRootKey, endRootKey, otherDBPathKey)
+	case len(config.otherDBPath) == 0 && len(startRoot) == 0:
+		return config, fmt.Errorf("--%s is required unless --%s is set", startRootKey, otherDBPathKey)
+	case len(startRoot) > 0 && config.diskHistoryLength == 0:
+		// The in-memory history of a merkledb that was just opened only
+		// contains its current root, so older roots can only be found in the
+		// disk history.
+		return config, fmt.Errorf("--%s requires --%s to be set to the disk history length of the merkledb", startRootKey, diskHistoryLengthKey)
+	case config.format != jsonFormat && config.format != csvFormat:
+		return config, fmt.Errorf("%w: %q", errUnknownFormat, config.format)
+	}
+	if err := config.branchFactor.Valid(); err != nil {
+		return config, err
+	}
+
+	if len(startRoot) > 0 {
+		config.startRoot, err = ids.FromString(startRoot)
+		if err != nil {
+			return config, fmt.Errorf("invalid --%s: %w", startRootKey, err)
+		}
+	}
+	if len(endRoot) > 0 {
+		root, err := ids.FromString(endRoot)
+		if err != nil {
+			return config, fmt.Errorf("invalid --%s: %w", endRootKey, err)
+		}
+		config.endRoot = maybe.Some(root)
+	}
+	return config, nil
+}
+
+// Returns Nothing if the flag [key] isn't set.
+func getMaybeHexFlag(fs *pflag.FlagSet, key string) (maybe.Maybe[[]byte], error) {
+	if !fs.Changed(key) {
+		return maybe.Nothing[[]byte](), nil
+	}
+	value, _ := fs.GetString(key)
+	bytes, err := hex.DecodeString(value)
+	if err != nil {
+		return maybe.Nothing[[]byte](), fmt.Errorf("invalid --%s: %w", key, err)
+	}
+	return maybe.Some(bytes), nil
+}
+
+func merkleDBDiff(
+	ctx context.Context,
+	log logging.Logger,
+	config merkleDBDiffConfig,
+	w io.Writer,
+) error {
+	db, closeDB, err := openMerkleDB(ctx, log, config, config.dbPath)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	writeDiff, err := newDiffWriter(config.format, w)
+	if err != nil {
+		return err
+	}
+
+	if len(config.otherDBPath) > 0 {
+		otherDB, closeOtherDB, err := openMerkleDB(ctx, log, config, config.otherDBPath)
+		if err != nil {
+			return err
+		}
+		defer closeOtherDB()
+
+		return merkledb.DiffTries(ctx, db, otherDB, config.start, config.end, writeDiff)
+	}
+
+	endRoot := config.endRoot
+	if endRoot.IsNothing() {
+		root, err := db.GetMerkleRoot(ctx)
+		if err != nil {
+			return err
+		}
+		endRoot = maybe.Some(root)
+	}
+	return db.Diff(ctx, config.startRoot, endRoot.Value(), config.start, config.end, writeDiff)
+}
+
+// Returns the merkledb at [path] and a function that closes it. The merkledb is
+// opened read-only, so the database at [path] isn't modified.
+func openMerkleDB(
+	ctx context.Context,
+	log logging.Logger,
+	config merkleDBDiffConfig,
+	path string,
+) (merkledb.MerkleDB, func(), error) {
+	baseDB, err := factory.New(config.dbType, path, true, nil, prometheus.NewRegistry(), log)
+	if err != nil {
+		return nil, nil, err
+	}
+	var db database.Database = baseDB
+	if len(config.prefix) > 0 {
+		db = prefixdb.New(config.prefix, baseDB)
+	}
+
+	merkleConfig := merkledb.NewConfig()
+	merkleConfig.BranchFactor = config.branchFactor
+	merkleConfig.DiskHistoryLength = config.diskHistoryLength
+	merkleConfig.ReadOnly = true
+	merkleConfig.Log = log
+	merkleDB, err := merkledb.New(ctx, db, merkleConfig)
+	if err != nil {
+		return nil, nil, errors.Join(err, baseDB.Close())
+	}
+
+	closeDB := func() {
+		if err := errors.Join(merkleDB.Close(), baseDB.Close()); err != nil {
+			log.Error("failed to close database",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		}
+	}
+	return merkleDB, closeDB, nil
+}
+
+// Returns a function that writes each diff to [w] in [format].
+func newDiffWriter(format string, w io.Writer) (func(merkledb.KeyDiff) error, error) {
+	switch format {
+	case jsonFormat:
+		encoder := json.NewEncoder(w)
+		return func(diff merkledb.KeyDiff) error {
+			return encoder.Encode(diffJSON{
+				Type:   diff.Type().String(),
+				Key:    hex.EncodeToString(diff.Key),
+				Before: maybeHex(diff.Before),
+				After:  maybeHex(diff.After),
+			})
+		}, nil
+	case csvFormat:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"type", "key", "before", "after"}); err != nil {
+			return nil, err
+		}
+		return func(diff merkledb.KeyDiff) error {
+			var before, after string
+			if diff.Before.HasValue() {
+				before = hex.EncodeToString(diff.Before.Value())
+			}
+			if diff.After.HasValue() {
+				after = hex.EncodeToString(diff.After.Value())
+			}
+			if err := writer.Write([]string{
+				diff.Type().String(),
+				hex.EncodeToString(diff.Key),
+				before,
+				after,
+			}); err != nil {
+				return err
+			}
+			// Flush each row so the output is streamed.
+			writer.Flush()
+			return writer.Error()
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownFormat, format)
+	}
+}
+
+// diffJSON is the JSON representation of a [merkledb.KeyDiff]. Each diff is
+// written on its own line.
+type diffJSON struct {
+	Type   string  `json:"type"`
+	Key    string  `json:"key"`
+	Before *string `json:"before,omitempty"`
+	After  *string `json:"after,omitempty"`
+}
+
+func maybeHex(value maybe.Maybe[[]byte]) *string {
+	if value.IsNothing() {
+		return nil
+	}
+	s := hex.EncodeToString(value.Value())
+	return &s
+}
@@ -26,10 +26,13 @@ type creator struct {
 func NewCreator(
 	metrics prometheus.Registerer,
 	compressionType compression.Type,
+	zstdLevel int,
 	maxMessageTimeout time.Duration,
 ) (Creator, error) {
 	builder, err := newMsgBuilder(
 		metrics,
+		compressionType,
+		zstdLevel,
 		maxMessageTimeout,
 	)
 	if err != nil {
@@ -0,0 +1,47 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+//go:generate go run ./dictionaries/generate -output dictionaries
+
+// ZstdDictionaryOps are the ops whose messages are compressed with a trained
+// zstd dictionary when sent to peers that support the dictionary. These
+// messages are small and repetitive, so they barely compress without a
+// dictionary.
+var ZstdDictionaryOps = set.Of(
+	PeerListOp,
+	GetOp,
+	PullQueryOp,
+	ChitsOp,
+)
+
+// The dictionary of each op in [ZstdDictionaryOps] is named after the op.
+//
+//go:embed dictionaries/*.zdict
+var zstdDictionaryFiles embed.FS
+
+// ZstdDictionaryFileName returns the name of the file of the zstd dictionary
+// of [op].
+func ZstdDictionaryFileName(op Op) string {
+	return op.String() + ".zdict"
+}
+
+func loadZstdDictionaries() (map[Op][]byte, error) {
+	dictionaries := make(map[Op][]byte, ZstdDictionaryOps.Len())
+	for op := range ZstdDictionaryOps {
+		dictionary, err := zstdDictionaryFiles.ReadFile("dictionaries/" + ZstdDictionaryFileName(op))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s dictionary: %w", op, err)
+		}
+		dictionaries[op] = dictionary
+	}
+	return dictionaries, nil
+}
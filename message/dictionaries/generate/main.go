@@ -0,0 +1,283 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// generate trains the zstd dictionaries of [message.ZstdDictionaryOps] on
+// synthetic messages that are structured like the messages sent on the primary
+// network.
+//
+// The ID of a dictionary identifies it on the wire, so a new version must be
+// used whenever the dictionaries are trained again.
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	mathrand "math/rand/v2"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls/signer/localsigner"
+	"github.com/ava-labs/avalanchego/utils/ips"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	// dictionaryIDPrefix is "AV" in the upper bytes of the dictionary IDs,
+	// which is outside of the range of IDs reserved by the zstd format.
+	dictionaryIDPrefix = 0x41560000
+
+	// numECDSACerts and numRSACerts are the number of staking certificates
+	// in the sampled peer lists. Most mainnet validators use RSA
+	// certificates.
+	numECDSACerts = 256
+	numRSACerts   = 64
+	rsaKeySize    = 4096
+)
+
+var (
+	errMissingSampler = errors.New("missing sampler")
+
+	// chainIDs are the primary network chains on mainnet and fuji.
+	chainIDs = []ids.ID{
+		constants.PlatformChainID,
+		ids.FromStringOrPanic("2oYMBNV4eNHyqk2fjjV5nVQLDbtmNJzq5s3qs3Lo6ftnC6FByM"),
+		ids.FromStringOrPanic("2q9e4r6Mu3U68nU1fYjgbR6JvwrRx36CohpAX5UQxse55x1Q5"),
+		ids.FromStringOrPanic("2JVSBoinj9C2J33VntvzYtVJNZdN2NKiwwKjcumHUWEb5DbBrm"),
+		ids.FromStringOrPanic("yH8D7ThNJkxmtkuv2jgBa4P1Rn3Qpr4pPr7QYNfcdoS6k6HWp"),
+	}
+
+	dictionarySizes = map[message.Op]int{
+		message.PeerListOp:  32 * units.KiB,
+		message.GetOp:       4 * units.KiB,
+		message.PullQueryOp: 4 * units.KiB,
+		message.ChitsOp:     4 * units.KiB,
+	}
+)
+
+func main() {
+	output := flag.String("output", "dictionaries", "directory to write the dictionaries to")
+	version := flag.Uint("version", 1, "version of the dictionaries, which must be increased when the dictionaries are trained again")
+	numSamples := flag.Int("samples", 20_000, "number of sampled messages per dictionary")
+	flag.Parse()
+
+	if err := run(*output, uint32(*version), *numSamples); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(output string, version uint32, numSamples int) error {
+	creator, err := message.NewCreator(
+		prometheus.NewRegistry(),
+		compression.TypeNone,
+		constants.DefaultNetworkCompressionZstdLevel,
+		time.Minute,
+	)
+	if err != nil {
+		return err
+	}
+	s := &sampler{
+		creator: creator,
+		rng:     mathrand.New(mathrand.NewPCG(uint64(version), 0)),
+	}
+	if err := s.generateCerts(); err != nil {
+		return err
+	}
+
+	samplers := map[message.Op]func() (*message.OutboundMessage, error){
+		message.PeerListOp:  s.peerList,
+		message.GetOp:       s.get,
+		message.PullQueryOp: s.pullQuery,
+		message.ChitsOp:     s.chits,
+	}
+	for op := range message.ZstdDictionaryOps {
+		sample, ok := samplers[op]
+		if !ok {
+			return fmt.Errorf("%w for %s", errMissingSampler, op)
+		}
+
+		samples := make([][]byte, numSamples)
+		for i := range samples {
+			msg, err := sample()
+			if err != nil {
+				return err
+			}
+			samples[i] = msg.Bytes
+		}
+
+		dictionary, err := dict.BuildZstdDict(samples, dict.Options{
+			MaxDictSize:    dictionarySizes[op],
+			HashBytes:      6,
+			ZstdDictID:     dictionaryIDPrefix | version<<8 | uint32(op),
+			ZstdDictCompat: true,
+			ZstdLevel:      zstd.EncoderLevelFromZstd(constants.DefaultNetworkCompressionZstdLevel),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build %s dictionary: %w", op, err)
+		}
+
+		path := filepath.Join(output, message.ZstdDictionaryFileName(op))
+		if err := os.WriteFile(path, dictionary, 0o644); err != nil { //#nosec G306
+			return err
+		}
+		log.Printf("wrote %d byte dictionary to %s", len(dictionary), path)
+	}
+	return nil
+}
+
+type sampler struct {
+	creator message.Creator
+	rng     *mathrand.Rand
+	peers   []*ips.ClaimedIPPort
+}
+
+// generateCerts creates the staking certificates of the sampled peers along
+// with the signatures of their IPs.
+func (s *sampler) generateCerts() error {
+	signers := make([]crypto.Signer, 0, numECDSACerts+numRSACerts)
+	for range numECDSACerts {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return err
+		}
+		signers = append(signers, key)
+	}
+	for range numRSACerts {
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+		if err != nil {
+			return err
+		}
+		signers = append(signers, key)
+	}
+
+	// BLS signatures aren't gossiped, so a single key is used for all peers.
+	blsSigner, err := localsigner.New()
+	if err != nil {
+		return err
+	}
+	for _, signer := range signers {
+		certTemplate := &x509.Certificate{
+			SerialNumber:          big.NewInt(0),
+			NotBefore:             time.Date(2000, time.January, 0, 0, 0, 0, 0, time.UTC),
+			NotAfter:              time.Now().AddDate(100, 0, 0),
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+			BasicConstraintsValid: true,
+		}
+		certBytes, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, signer.Public(), signer)
+		if err != nil {
+			return err
+		}
+		cert, err := staking.ParseCertificate(certBytes)
+		if err != nil {
+			return err
+		}
+
+		ip := netip.AddrPortFrom(
+			netip.AddrFrom4([4]byte{
+				byte(s.rng.Uint32()),
+				byte(s.rng.Uint32()),
+				byte(s.rng.Uint32()),
+				byte(s.rng.Uint32()),
+			}),
+			9651,
+		)
+		unsignedIP := peer.UnsignedIP{
+			AddrPort:  ip,
+			Timestamp: uint64(time.Now().Unix()) - s.rng.Uint64N(uint64(30*24*time.Hour/time.Second)),
+		}
+		signedIP, err := unsignedIP.Sign(signer, blsSigner)
+		if err != nil {
+			return err
+		}
+		s.peers = append(s.peers, ips.NewClaimedIPPort(
+			cert,
+			unsignedIP.AddrPort,
+			unsignedIP.Timestamp,
+			signedIP.TLSSignature,
+		))
+	}
+	return nil
+}
+
+func (s *sampler) peerList() (*message.OutboundMessage, error) {
+	peers := make([]*ips.ClaimedIPPort, 1+s.rng.IntN(constants.DefaultNetworkPeerListNumValidatorIPs))
+	for i := range peers {
+		peers[i] = s.peers[s.rng.IntN(len(s.peers))]
+	}
+	return s.creator.PeerList(peers, false)
+}
+
+func (s *sampler) get() (*message.OutboundMessage, error) {
+	return s.creator.Get(
+		s.chainID(),
+		s.rng.Uint32(),
+		s.deadline(),
+		ids.GenerateTestID(),
+	)
+}
+
+func (s *sampler) pullQuery() (*message.OutboundMessage, error) {
+	return s.creator.PullQuery(
+		s.chainID(),
+		s.rng.Uint32(),
+		s.deadline(),
+		ids.GenerateTestID(),
+		s.height(),
+	)
+}
+
+func (s *sampler) chits() (*message.OutboundMessage, error) {
+	var (
+		preferredID         = ids.GenerateTestID()
+		preferredIDAtHeight = preferredID
+		acceptedID          = ids.GenerateTestID()
+	)
+	// The preference is usually the block at the requested height.
+	if s.rng.IntN(4) == 0 {
+		preferredIDAtHeight = ids.GenerateTestID()
+	}
+	return s.creator.Chits(
+		s.chainID(),
+		s.rng.Uint32(),
+		preferredID,
+		preferredIDAtHeight,
+		acceptedID,
+		s.height(),
+	)
+}
+
+// chainID is usually one of the primary network chains.
+func (s *sampler) chainID() ids.ID {
+	if s.rng.IntN(5) == 0 {
+		return ids.GenerateTestID()
+	}
+	return chainIDs[s.rng.IntN(len(chainIDs))]
+}
+
+func (s *sampler) deadline() time.Duration {
+	return constants.DefaultNetworkMinimumTimeout + time.Duration(s.rng.Int64N(int64(constants.DefaultNetworkMaximumTimeout-constants.DefaultNetworkMinimumTimeout)))
+}
+
+func (s *sampler) height() uint64 {
+	return s.rng.Uint64N(100_000_000)
+}
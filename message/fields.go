@@ -63,6 +63,9 @@ var (
 	_ deadlineGetter = (*p2p.PushQuery)(nil)
 	_ deadlineGetter = (*p2p.PullQuery)(nil)
 	_ deadlineGetter = (*p2p.AppRequest)(nil)
+
+	_ traceContextGetter = (*p2p.AppRequest)(nil)
+	_ traceContextGetter = (*p2p.AppResponse)(nil)
 )
 
 type chainIDGetter interface {
@@ -113,3 +116,17 @@ func GetDeadline(m any) (time.Duration, bool) {
 	deadline := msg.GetDeadline()
 	return time.Duration(deadline), true
 }
+
+type traceContextGetter interface {
+	GetTraceContext() map[string]string
+}
+
+// GetTraceContext returns the W3C trace context of [m], which is nil if [m]
+// doesn't have a trace context.
+func GetTraceContext(m any) map[string]string {
+	msg, ok := m.(traceContextGetter)
+	if !ok {
+		return nil
+	}
+	return msg.GetTraceContext()
+}
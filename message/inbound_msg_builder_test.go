@@ -13,6 +13,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/proto/pb/p2p"
 	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 )
 
@@ -22,6 +23,8 @@ func Test_newMsgBuilder(t *testing.T) {
 
 	mb, err := newMsgBuilder(
 		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		10*time.Second,
 	)
 	require.NoError(err)
@@ -388,6 +391,8 @@ func TestAppError(t *testing.T) {
 
 	mb, err := newMsgBuilder(
 		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		time.Second,
 	)
 	require.NoError(err)
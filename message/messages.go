@@ -6,6 +6,8 @@ package message
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,6 +17,7 @@ import (
 	"github.com/ava-labs/avalanchego/proto/pb/p2p"
 	"github.com/ava-labs/avalanchego/utils/compression"
 	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 )
 
@@ -25,6 +28,8 @@ const (
 
 	compressionLabel   = "compression"
 	decompressionLabel = "decompression"
+
+	zstdDictLabel = "zstd_dict"
 )
 
 var (
@@ -32,7 +37,22 @@ var (
 
 	metricLabels = []string{typeLabel, opLabel, directionLabel}
 
+	// compressionFieldNumbers are the field numbers of the compressed_* fields
+	// of p2p.Message, which identify the compression types in the Handshake.
+	compressionFieldNumbers = map[compression.Type]uint32{
+		compression.TypeZstd:   2,
+		compression.TypeSnappy: 3,
+		compression.TypeLZ4:    4,
+	}
+
+	// legacyPeerCompression is the compression supported by peers that don't
+	// advertise the compression they support.
+	legacyPeerCompression = &PeerCompression{
+		Types: []compression.Type{compression.TypeZstd},
+	}
+
 	errUnknownCompressionType = errors.New("message is compressed with an unknown compression type")
+	errUnknownZstdDictionary  = errors.New("unknown zstd dictionary")
 )
 
 type InboundMessage struct {
@@ -62,32 +82,231 @@ type OutboundMessage struct {
 	// any outbound message throttling
 	BypassThrottling bool
 	Op               Op
-	Bytes            []byte
+	// Bytes of the message compressed with [compressionType], which every peer
+	// is able to parse if [compressionType] is none or zstd.
+	Bytes []byte
 	// BytesSavedCompression stores the amount of bytes that this message saved
 	// due to being compressed
 	BytesSavedCompression int
+
+	// If [builder] is non-nil, the message is compressed again for peers that
+	// negotiated a different compression than [compressionType].
+	builder           *msgBuilder
+	compressionType   compression.Type
+	uncompressedBytes []byte
+
+	// untraced is the message without its trace context. Nil if the message
+	// doesn't have a trace context.
+	untraced *OutboundMessage
+
+	lock sync.Mutex
+	// encodings of the message other than [Bytes], cached so that the message
+	// is only compressed once per encoding when it is sent to many peers.
+	encodings map[encoding]encodedMessage
+}
+
+// BytesFor returns the bytes of the message compressed for a peer that
+// supports [peer], and the amount of bytes saved by compression.
+//
+// If [peer] is nil, the peer is assumed to support the compression of peers
+// that don't negotiate compression.
+func (m *OutboundMessage) BytesFor(peer *PeerCompression) ([]byte, int, error) {
+	if m.builder == nil {
+		return m.Bytes, m.BytesSavedCompression, nil
+	}
+
+	e := m.builder.encodingFor(m.Op, m.compressionType, peer)
+	if e == (encoding{compressionType: m.compressionType}) {
+		return m.Bytes, m.BytesSavedCompression, nil
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if encoded, ok := m.encodings[e]; ok {
+		return encoded.bytes, encoded.bytesSaved, nil
+	}
+
+	b, saved, err := m.builder.compress(m.uncompressedBytes, m.Op, e)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Small messages may not compress well enough to make up for the
+	// overhead of compression, in which case the default encoding is used if
+	// the peer supports it.
+	if saved <= m.BytesSavedCompression && orLegacy(peer).supports(m.compressionType) {
+		b, saved = m.Bytes, m.BytesSavedCompression
+	}
+	if m.encodings == nil {
+		m.encodings = make(map[encoding]encodedMessage)
+	}
+	m.encodings[e] = encodedMessage{
+		bytes:      b,
+		bytesSaved: saved,
+	}
+	return b, saved, nil
+}
+
+// EncodedFor returns the message with its bytes compressed for a peer that
+// supports [peer], so that [Bytes] are the bytes that are sent to the peer.
+func (m *OutboundMessage) EncodedFor(peer *PeerCompression) (*OutboundMessage, error) {
+	if m.builder == nil {
+		return m, nil
+	}
+
+	b, saved, err := m.BytesFor(peer)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboundMessage{
+		BypassThrottling:      m.BypassThrottling,
+		Op:                    m.Op,
+		Bytes:                 b,
+		BytesSavedCompression: saved,
+	}, nil
+}
+
+// WithoutTraceContext returns the message without its trace context, which is
+// sent to peers that don't support trace propagation.
+func (m *OutboundMessage) WithoutTraceContext() *OutboundMessage {
+	if m.untraced == nil {
+		return m
+	}
+	return m.untraced
+}
+
+// encoding is how the bytes of a message are compressed.
+type encoding struct {
+	compressionType compression.Type
+	// dictionaryID is non-zero if the message is compressed with a zstd
+	// dictionary.
+	dictionaryID uint32
+}
+
+type encodedMessage struct {
+	bytes      []byte
+	bytesSaved int
+}
+
+// PeerCompression is the compression that a peer supports, as advertised in
+// its Handshake.
+type PeerCompression struct {
+	// Types that the peer can decompress, ordered by the peer's preference.
+	Types []compression.Type
+	// ZstdDictionaries are the IDs of the zstd dictionaries that the peer can
+	// decompress with.
+	ZstdDictionaries set.Set[uint32]
+}
+
+// ParsePeerCompression returns the compression advertised in [handshake].
+// Unknown compression types are ignored and zstd is always supported.
+func ParsePeerCompression(handshake *p2p.Handshake) *PeerCompression {
+	c := &PeerCompression{
+		Types:            make([]compression.Type, 0, len(handshake.SupportedCompressions)+1),
+		ZstdDictionaries: set.Of(handshake.ZstdDictionaryIds...),
+	}
+	for _, fieldNumber := range handshake.SupportedCompressions {
+		for compressionType, expectedFieldNumber := range compressionFieldNumbers {
+			if fieldNumber == expectedFieldNumber && !slices.Contains(c.Types, compressionType) {
+				c.Types = append(c.Types, compressionType)
+			}
+		}
+	}
+	if !slices.Contains(c.Types, compression.TypeZstd) {
+		c.Types = append(c.Types, compression.TypeZstd)
+	}
+	return c
+}
+
+// orLegacy returns [c], or the compression of peers that don't advertise the
+// compression they support if [c] is nil.
+func orLegacy(c *PeerCompression) *PeerCompression {
+	if c == nil {
+		return legacyPeerCompression
+	}
+	return c
+}
+
+// supports returns true if the peer can parse messages compressed with
+// [compressionType].
+func (c *PeerCompression) supports(compressionType compression.Type) bool {
+	return compressionType == compression.TypeNone || slices.Contains(c.Types, compressionType)
+}
+
+// negotiate returns [preferred] if the peer supports it. Otherwise, the type
+// that the peer prefers is returned.
+func (c *PeerCompression) negotiate(preferred compression.Type) compression.Type {
+	if c.supports(preferred) {
+		return preferred
+	}
+	return c.Types[0]
 }
 
-// TODO: add other compression algorithms with extended interface
 type msgBuilder struct {
-	zstdCompressor compression.Compressor
-	count          *prometheus.CounterVec // type + op + direction
-	duration       *prometheus.GaugeVec   // type + op + direction
+	// compressionType is the preferred compression type. If it is none,
+	// messages are never compressed for specific peers.
+	compressionType  compression.Type
+	compressors      map[compression.Type]compression.Compressor
+	zstdDictionaries *compression.ZstdDictionaries
+	// dictionaryIDs are the IDs of the zstd dictionaries of each op
+	dictionaryIDs map[Op]uint32
+	count         *prometheus.CounterVec // type + op + direction
+	duration      *prometheus.GaugeVec   // type + op + direction
 
 	maxMessageTimeout time.Duration
 }
 
 func newMsgBuilder(
 	metrics prometheus.Registerer,
+	compressionType compression.Type,
+	zstdLevel int,
 	maxMessageTimeout time.Duration,
 ) (*msgBuilder, error) {
-	zstdCompressor, err := compression.NewZstdCompressor(constants.DefaultMaxMessageSize)
+	zstdCompressor, err := compression.NewZstdCompressorWithLevel(constants.DefaultMaxMessageSize, zstdLevel)
+	if err != nil {
+		return nil, err
+	}
+	snappyCompressor, err := compression.NewSnappyCompressor(constants.DefaultMaxMessageSize)
+	if err != nil {
+		return nil, err
+	}
+	lz4Compressor, err := compression.NewLZ4Compressor(constants.DefaultMaxMessageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	dictionaries, err := loadZstdDictionaries()
+	if err != nil {
+		return nil, err
+	}
+	dictionaryIDs := make(map[Op]uint32, len(dictionaries))
+	dictionaryBytes := make([][]byte, 0, len(dictionaries))
+	for op, dictionary := range dictionaries {
+		id, err := compression.ZstdDictionaryID(dictionary)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s dictionary: %w", op, err)
+		}
+		dictionaryIDs[op] = id
+		dictionaryBytes = append(dictionaryBytes, dictionary)
+	}
+	zstdDictionaries, err := compression.NewZstdDictionaries(
+		constants.DefaultMaxMessageSize,
+		zstdLevel,
+		dictionaryBytes...,
+	)
 	if err != nil {
 		return nil, err
 	}
 
 	mb := &msgBuilder{
-		zstdCompressor: zstdCompressor,
+		compressionType: compressionType,
+		compressors: map[compression.Type]compression.Compressor{
+			compression.TypeZstd:   zstdCompressor,
+			compression.TypeSnappy: snappyCompressor,
+			compression.TypeLZ4:    lz4Compressor,
+		},
+		zstdDictionaries: zstdDictionaries,
+		dictionaryIDs:    dictionaryIDs,
 		count: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "codec_compressed_count",
@@ -111,20 +330,48 @@ func newMsgBuilder(
 	)
 }
 
-func (mb *msgBuilder) marshal(
-	uncompressedMsg *p2p.Message,
-	compressionType compression.Type,
-) ([]byte, int, Op, error) {
-	uncompressedMsgBytes, err := proto.Marshal(uncompressedMsg)
-	if err != nil {
-		return nil, 0, 0, err
+// supportedCompressions returns the field numbers of the compression types
+// that can be decompressed, ordered by preference.
+func (mb *msgBuilder) supportedCompressions() []uint32 {
+	fieldNumbers := make([]uint32, 0, len(compressionFieldNumbers))
+	if fieldNumber, ok := compressionFieldNumbers[mb.compressionType]; ok {
+		fieldNumbers = append(fieldNumbers, fieldNumber)
 	}
+	for _, compressionType := range compression.Types {
+		fieldNumber, ok := compressionFieldNumbers[compressionType]
+		if ok && compressionType != mb.compressionType {
+			fieldNumbers = append(fieldNumbers, fieldNumber)
+		}
+	}
+	return fieldNumbers
+}
 
-	op, err := ToOp(uncompressedMsg)
-	if err != nil {
-		return nil, 0, 0, err
+// supportedZstdDictionaries returns the IDs of the zstd dictionaries that can
+// be decompressed with.
+func (mb *msgBuilder) supportedZstdDictionaries() []uint32 {
+	ids := mb.zstdDictionaries.IDs()
+	slices.Sort(ids)
+	return ids
+}
+
+// encodingFor returns how a message with [op], which is compressed with
+// [compressionType] by default, is compressed for [peer].
+func (mb *msgBuilder) encodingFor(op Op, compressionType compression.Type, peer *PeerCompression) encoding {
+	peer = orLegacy(peer)
+	if id, ok := mb.dictionaryIDs[op]; ok && peer.ZstdDictionaries.Contains(id) {
+		return encoding{
+			compressionType: compression.TypeZstd,
+			dictionaryID:    id,
+		}
+	}
+	return encoding{
+		compressionType: peer.negotiate(compressionType),
 	}
+}
 
+// compress returns the bytes of a message with [op] whose uncompressed bytes
+// are [uncompressedMsgBytes], compressed with [e].
+func (mb *msgBuilder) compress(uncompressedMsgBytes []byte, op Op, e encoding) ([]byte, int, error) {
 	// If compression is enabled, we marshal twice:
 	// 1. the original message
 	// 2. the message with compressed bytes
@@ -134,32 +381,57 @@ func (mb *msgBuilder) marshal(
 	var (
 		startTime     = time.Now()
 		compressedMsg p2p.Message
+		typeLabelStr  = e.compressionType.String()
 	)
-	switch compressionType {
-	case compression.TypeNone:
-		return uncompressedMsgBytes, 0, op, nil
-	case compression.TypeZstd:
-		compressedBytes, err := mb.zstdCompressor.Compress(uncompressedMsgBytes)
+	switch {
+	case e.compressionType == compression.TypeNone:
+		return uncompressedMsgBytes, 0, nil
+	case e.dictionaryID != 0:
+		compressor, ok := mb.zstdDictionaries.Compressor(e.dictionaryID)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: %d", errUnknownZstdDictionary, e.dictionaryID)
+		}
+		compressedBytes, err := compressor.Compress(uncompressedMsgBytes)
 		if err != nil {
-			return nil, 0, 0, err
+			return nil, 0, err
 		}
-		compressedMsg = p2p.Message{
-			Message: &p2p.Message_CompressedZstd{
-				CompressedZstd: compressedBytes,
-			},
+		compressedMsg.Message = &p2p.Message_CompressedZstdDict{
+			CompressedZstdDict: compressedBytes,
 		}
+		typeLabelStr = zstdDictLabel
 	default:
-		return nil, 0, 0, errUnknownCompressionType
+		compressor, ok := mb.compressors[e.compressionType]
+		if !ok {
+			return nil, 0, errUnknownCompressionType
+		}
+		compressedBytes, err := compressor.Compress(uncompressedMsgBytes)
+		if err != nil {
+			return nil, 0, err
+		}
+		switch e.compressionType {
+		case compression.TypeZstd:
+			compressedMsg.Message = &p2p.Message_CompressedZstd{
+				CompressedZstd: compressedBytes,
+			}
+		case compression.TypeSnappy:
+			compressedMsg.Message = &p2p.Message_CompressedSnappy{
+				CompressedSnappy: compressedBytes,
+			}
+		case compression.TypeLZ4:
+			compressedMsg.Message = &p2p.Message_CompressedLz4{
+				CompressedLz4: compressedBytes,
+			}
+		}
 	}
 
 	compressedMsgBytes, err := proto.Marshal(&compressedMsg)
 	if err != nil {
-		return nil, 0, 0, err
+		return nil, 0, err
 	}
 	compressTook := time.Since(startTime)
 
 	labels := prometheus.Labels{
-		typeLabel:      compressionType.String(),
+		typeLabel:      typeLabelStr,
 		opLabel:        op.String(),
 		directionLabel: compressionLabel,
 	}
@@ -167,7 +439,7 @@ func (mb *msgBuilder) marshal(
 	mb.duration.With(labels).Add(float64(compressTook))
 
 	bytesSaved := len(uncompressedMsgBytes) - len(compressedMsgBytes)
-	return compressedMsgBytes, bytesSaved, op, nil
+	return compressedMsgBytes, bytesSaved, nil
 }
 
 func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
@@ -178,14 +450,27 @@ func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
 
 	// Figure out what compression type, if any, was used to compress the message.
 	var (
-		compressor      compression.Compressor
+		decompress      func([]byte) ([]byte, error)
 		compressedBytes []byte
-		zstdCompressed  = m.GetCompressedZstd()
+		typeLabelStr    string
 	)
-	switch {
-	case len(zstdCompressed) > 0:
-		compressor = mb.zstdCompressor
-		compressedBytes = zstdCompressed
+	switch compressed := m.GetMessage().(type) {
+	case *p2p.Message_CompressedZstd:
+		decompress = mb.compressors[compression.TypeZstd].Decompress
+		compressedBytes = compressed.CompressedZstd
+		typeLabelStr = compression.TypeZstd.String()
+	case *p2p.Message_CompressedSnappy:
+		decompress = mb.compressors[compression.TypeSnappy].Decompress
+		compressedBytes = compressed.CompressedSnappy
+		typeLabelStr = compression.TypeSnappy.String()
+	case *p2p.Message_CompressedLz4:
+		decompress = mb.compressors[compression.TypeLZ4].Decompress
+		compressedBytes = compressed.CompressedLz4
+		typeLabelStr = compression.TypeLZ4.String()
+	case *p2p.Message_CompressedZstdDict:
+		decompress = mb.zstdDictionaries.Decompress
+		compressedBytes = compressed.CompressedZstdDict
+		typeLabelStr = zstdDictLabel
 	default:
 		// The message wasn't compressed
 		op, err := ToOp(m)
@@ -194,7 +479,7 @@ func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
 
 	startTime := time.Now()
 
-	decompressed, err := compressor.Decompress(compressedBytes)
+	decompressed, err := decompress(compressedBytes)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -212,7 +497,7 @@ func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
 	}
 
 	labels := prometheus.Labels{
-		typeLabel:      compression.TypeZstd.String(),
+		typeLabel:      typeLabelStr,
 		opLabel:        op.String(),
 		directionLabel: decompressionLabel,
 	}
@@ -223,17 +508,37 @@ func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
 }
 
 func (mb *msgBuilder) createOutbound(m *p2p.Message, compressionType compression.Type, bypassThrottling bool) (*OutboundMessage, error) {
-	b, saved, op, err := mb.marshal(m, compressionType)
+	uncompressedMsgBytes, err := proto.Marshal(m)
 	if err != nil {
 		return nil, err
 	}
 
-	return &OutboundMessage{
+	op, err := ToOp(m)
+	if err != nil {
+		return nil, err
+	}
+
+	b, saved, err := mb.compress(uncompressedMsgBytes, op, encoding{
+		compressionType: compressionType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &OutboundMessage{
 		BypassThrottling:      bypassThrottling,
 		Op:                    op,
 		Bytes:                 b,
 		BytesSavedCompression: saved,
-	}, nil
+		compressionType:       compressionType,
+	}
+	// The handshake is sent before compression is negotiated, so it must
+	// always be sent as is.
+	if mb.compressionType != compression.TypeNone && op != HandshakeOp {
+		msg.builder = mb
+		msg.uncompressedBytes = uncompressedMsgBytes
+	}
+	return msg, nil
 }
 
 func (mb *msgBuilder) parseInbound(
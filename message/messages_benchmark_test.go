@@ -16,6 +16,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/proto/pb/p2p"
 	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
 )
 
 var (
@@ -61,7 +62,7 @@ func BenchmarkMarshalHandshake(b *testing.B) {
 
 	useBuilder := os.Getenv("USE_BUILDER") != ""
 
-	codec, err := newMsgBuilder(prometheus.NewRegistry(), 10*time.Second)
+	codec, err := newMsgBuilder(prometheus.NewRegistry(), compression.TypeZstd, constants.DefaultNetworkCompressionZstdLevel, 10*time.Second)
 	require.NoError(err)
 
 	b.Logf("proto length %d-byte (use builder %v)", msgLen, useBuilder)
@@ -118,7 +119,7 @@ func BenchmarkUnmarshalHandshake(b *testing.B) {
 	require.NoError(err)
 
 	useBuilder := os.Getenv("USE_BUILDER") != ""
-	codec, err := newMsgBuilder(prometheus.NewRegistry(), 10*time.Second)
+	codec, err := newMsgBuilder(prometheus.NewRegistry(), compression.TypeZstd, constants.DefaultNetworkCompressionZstdLevel, 10*time.Second)
 	require.NoError(err)
 
 	b.StartTimer()
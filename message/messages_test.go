@@ -6,6 +6,7 @@ package message
 import (
 	"bytes"
 	"net"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -17,6 +18,8 @@ import (
 	"github.com/ava-labs/avalanchego/proto/pb/p2p"
 	"github.com/ava-labs/avalanchego/staking"
 	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
 )
 
 func TestMessage(t *testing.T) {
@@ -24,6 +27,8 @@ func TestMessage(t *testing.T) {
 
 	mb, err := newMsgBuilder(
 		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		5*time.Second,
 	)
 	require.NoError(t, err)
@@ -680,6 +685,8 @@ func TestInboundMessageToString(t *testing.T) {
 
 	mb, err := newMsgBuilder(
 		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		5*time.Second,
 	)
 	require.NoError(err)
@@ -709,6 +716,8 @@ func TestEmptyInboundMessage(t *testing.T) {
 
 	mb, err := newMsgBuilder(
 		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		5*time.Second,
 	)
 	require.NoError(err)
@@ -728,6 +737,8 @@ func TestNilInboundMessage(t *testing.T) {
 
 	mb, err := newMsgBuilder(
 		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		5*time.Second,
 	)
 	require.NoError(err)
@@ -747,3 +758,298 @@ func TestNilInboundMessage(t *testing.T) {
 	pingMsg := parsedMsg.Message.(*p2p.Ping)
 	require.NotNil(pingMsg)
 }
+
+func TestParsePeerCompression(t *testing.T) {
+	tests := []struct {
+		name      string
+		handshake *p2p.Handshake
+		expected  *PeerCompression
+	}{
+		{
+			name:      "legacy peer",
+			handshake: &p2p.Handshake{},
+			expected: &PeerCompression{
+				Types:            []compression.Type{compression.TypeZstd},
+				ZstdDictionaries: set.Set[uint32]{},
+			},
+		},
+		{
+			name: "preference order",
+			handshake: &p2p.Handshake{
+				SupportedCompressions: []uint32{4, 2, 3},
+			},
+			expected: &PeerCompression{
+				Types: []compression.Type{
+					compression.TypeLZ4,
+					compression.TypeZstd,
+					compression.TypeSnappy,
+				},
+				ZstdDictionaries: set.Set[uint32]{},
+			},
+		},
+		{
+			name: "unknown and duplicate types",
+			handshake: &p2p.Handshake{
+				SupportedCompressions: []uint32{1, 3, 100, 3},
+			},
+			expected: &PeerCompression{
+				Types: []compression.Type{
+					compression.TypeSnappy,
+					compression.TypeZstd,
+				},
+				ZstdDictionaries: set.Set[uint32]{},
+			},
+		},
+		{
+			name: "zstd dictionaries",
+			handshake: &p2p.Handshake{
+				ZstdDictionaryIds: []uint32{1, 2},
+			},
+			expected: &PeerCompression{
+				Types:            []compression.Type{compression.TypeZstd},
+				ZstdDictionaries: set.Of[uint32](1, 2),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, ParsePeerCompression(test.handshake))
+		})
+	}
+}
+
+func TestOutboundMessageBytesFor(t *testing.T) {
+	mb, err := newMsgBuilder(
+		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
+		5*time.Second,
+	)
+	require.NoError(t, err)
+	builder := newOutboundBuilder(compression.TypeZstd, mb)
+
+	// The dictionary knows the P-Chain ID, and the preference is usually the
+	// block at the requested height.
+	preferredID := ids.GenerateTestID()
+	chits, err := builder.Chits(
+		constants.PlatformChainID,
+		1,
+		preferredID,
+		preferredID,
+		ids.GenerateTestID(),
+		2,
+	)
+	require.NoError(t, err)
+
+	// Random IDs don't compress.
+	randomChits, err := builder.Chits(
+		ids.GenerateTestID(),
+		1,
+		ids.GenerateTestID(),
+		ids.GenerateTestID(),
+		ids.GenerateTestID(),
+		2,
+	)
+	require.NoError(t, err)
+
+	peerList, err := builder.PeerList(nil, false)
+	require.NoError(t, err)
+
+	dictionaryPeer := ParsePeerCompression(&p2p.Handshake{
+		ZstdDictionaryIds: mb.supportedZstdDictionaries(),
+	})
+	lz4Peer := &PeerCompression{
+		Types: []compression.Type{compression.TypeLZ4},
+	}
+
+	tests := []struct {
+		name               string
+		msg                *OutboundMessage
+		peer               *PeerCompression
+		expectedOp         Op
+		expectedDefault    bool
+		expectedCompressed bool
+	}{
+		{
+			name:            "handshake not received",
+			msg:             chits,
+			peer:            nil,
+			expectedOp:      ChitsOp,
+			expectedDefault: true,
+		},
+		{
+			name:            "legacy peer",
+			msg:             chits,
+			peer:            ParsePeerCompression(&p2p.Handshake{}),
+			expectedOp:      ChitsOp,
+			expectedDefault: true,
+		},
+		{
+			name:               "peer supports the dictionary",
+			msg:                chits,
+			peer:               dictionaryPeer,
+			expectedOp:         ChitsOp,
+			expectedCompressed: true,
+		},
+		{
+			name:            "dictionary doesn't reduce the size",
+			msg:             randomChits,
+			peer:            dictionaryPeer,
+			expectedOp:      ChitsOp,
+			expectedDefault: true,
+		},
+		{
+			name:            "peer prefers another type",
+			msg:             peerList,
+			peer:            ParsePeerCompression(&p2p.Handshake{SupportedCompressions: []uint32{3}}),
+			expectedOp:      PeerListOp,
+			expectedDefault: true,
+		},
+		{
+			name:       "peer doesn't support the default type",
+			msg:        peerList,
+			peer:       lz4Peer,
+			expectedOp: PeerListOp,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			msgBytes, bytesSaved, err := test.msg.BytesFor(test.peer)
+			require.NoError(err)
+			if test.expectedDefault {
+				require.Equal(test.msg.Bytes, msgBytes)
+				require.Equal(test.msg.BytesSavedCompression, bytesSaved)
+			} else {
+				require.NotEqual(test.msg.Bytes, msgBytes)
+			}
+			if test.expectedCompressed {
+				require.Positive(bytesSaved)
+			}
+
+			// The message must be compressed only once per encoding.
+			cachedBytes, _, err := test.msg.BytesFor(test.peer)
+			require.NoError(err)
+			require.Same(&msgBytes[0], &cachedBytes[0])
+
+			parsedMsg, err := mb.parseInbound(msgBytes, ids.EmptyNodeID, func() {})
+			require.NoError(err)
+			require.Equal(test.expectedOp, parsedMsg.Op)
+
+			encodedMsg, err := test.msg.EncodedFor(test.peer)
+			require.NoError(err)
+			require.Equal(test.msg.Op, encodedMsg.Op)
+			require.Equal(msgBytes, encodedMsg.Bytes)
+			require.Equal(bytesSaved, encodedMsg.BytesSavedCompression)
+		})
+	}
+}
+
+func TestOutboundMessageBytesForCompressionDisabled(t *testing.T) {
+	require := require.New(t)
+
+	mb, err := newMsgBuilder(
+		prometheus.NewRegistry(),
+		compression.TypeNone,
+		constants.DefaultNetworkCompressionZstdLevel,
+		5*time.Second,
+	)
+	require.NoError(err)
+	builder := newOutboundBuilder(compression.TypeNone, mb)
+
+	msg, err := builder.Get(ids.GenerateTestID(), 1, time.Second, ids.GenerateTestID())
+	require.NoError(err)
+
+	msgBytes, bytesSaved, err := msg.BytesFor(ParsePeerCompression(&p2p.Handshake{
+		ZstdDictionaryIds: mb.supportedZstdDictionaries(),
+	}))
+	require.NoError(err)
+	require.Equal(msg.Bytes, msgBytes)
+	require.Zero(bytesSaved)
+}
+
+func TestHandshakeAdvertisesCompression(t *testing.T) {
+	require := require.New(t)
+
+	mb, err := newMsgBuilder(
+		prometheus.NewRegistry(),
+		compression.TypeLZ4,
+		constants.DefaultNetworkCompressionZstdLevel,
+		5*time.Second,
+	)
+	require.NoError(err)
+	builder := newOutboundBuilder(compression.TypeLZ4, mb)
+
+	msg, err := builder.Handshake(
+		constants.MainnetID,
+		1,
+		netip.AddrPortFrom(netip.IPv6Loopback(), 9651),
+		"avalanchego",
+		1,
+		2,
+		3,
+		4,
+		5,
+		nil,
+		nil,
+		0,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		false,
+		false,
+	)
+	require.NoError(err)
+
+	parsedMsg, err := mb.parseInbound(msg.Bytes, ids.EmptyNodeID, func() {})
+	require.NoError(err)
+	require.IsType(&p2p.Handshake{}, parsedMsg.Message)
+
+	peerCompression := ParsePeerCompression(parsedMsg.Message.(*p2p.Handshake))
+	require.Equal(
+		[]compression.Type{
+			compression.TypeLZ4,
+			compression.TypeZstd,
+			compression.TypeSnappy,
+		},
+		peerCompression.Types,
+	)
+	require.Equal(set.Of(mb.supportedZstdDictionaries()...), peerCompression.ZstdDictionaries)
+	require.Equal(ZstdDictionaryOps.Len(), peerCompression.ZstdDictionaries.Len())
+}
+
+func TestAppRequestTraceContext(t *testing.T) {
+	require := require.New(t)
+
+	mb, err := newMsgBuilder(
+		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
+		5*time.Second,
+	)
+	require.NoError(err)
+	builder := newOutboundBuilder(compression.TypeZstd, mb)
+
+	untracedMsg, err := builder.AppRequest(ids.GenerateTestID(), 1, time.Second, []byte("request"), nil)
+	require.NoError(err)
+	require.Same(untracedMsg, untracedMsg.WithoutTraceContext())
+
+	traceContext := map[string]string{
+		"traceparent": "00-01000000000000000000000000000000-0200000000000000-01",
+	}
+	tracedMsg, err := builder.AppRequest(ids.GenerateTestID(), 1, time.Second, []byte("request"), traceContext)
+	require.NoError(err)
+
+	parsedMsg, err := mb.parseInbound(tracedMsg.Bytes, ids.EmptyNodeID, func() {})
+	require.NoError(err)
+	require.Equal(traceContext, GetTraceContext(parsedMsg.Message))
+
+	parsedMsg, err = mb.parseInbound(tracedMsg.WithoutTraceContext().Bytes, ids.EmptyNodeID, func() {})
+	require.NoError(err)
+	require.Equal(AppRequestOp, parsedMsg.Op)
+	require.Empty(GetTraceContext(parsedMsg.Message))
+}
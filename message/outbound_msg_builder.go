@@ -31,12 +31,15 @@ type OutboundMsgBuilder interface {
 		ipSigningTime uint64,
 		ipNodeIDSig []byte,
 		ipBLSSig []byte,
+		quicPort uint16,
+		ipQUICSig []byte,
 		trackedSubnets []ids.ID,
 		supportedACPs []uint32,
 		objectedACPs []uint32,
 		knownPeersFilter []byte,
 		knownPeersSalt []byte,
 		requestAllSubnetIPs bool,
+		tracePropagation bool,
 	) (*OutboundMessage, error)
 
 	GetPeerList(
@@ -158,17 +161,23 @@ type OutboundMsgBuilder interface {
 		acceptedHeight uint64,
 	) (*OutboundMessage, error)
 
+	// AppRequest creates an AppRequest with the W3C [traceContext], which may
+	// be nil.
 	AppRequest(
 		chainID ids.ID,
 		requestID uint32,
 		deadline time.Duration,
 		msg []byte,
+		traceContext map[string]string,
 	) (*OutboundMessage, error)
 
+	// AppResponse creates an AppResponse with the W3C [traceContext], which
+	// may be nil.
 	AppResponse(
 		chainID ids.ID,
 		requestID uint32,
 		msg []byte,
+		traceContext map[string]string,
 	) (*OutboundMessage, error)
 
 	AppError(
@@ -243,12 +252,15 @@ func (b *outMsgBuilder) Handshake(
 	ipSigningTime uint64,
 	ipNodeIDSig []byte,
 	ipBLSSig []byte,
+	quicPort uint16,
+	ipQUICSig []byte,
 	trackedSubnets []ids.ID,
 	supportedACPs []uint32,
 	objectedACPs []uint32,
 	knownPeersFilter []byte,
 	knownPeersSalt []byte,
 	requestAllSubnetIPs bool,
+	tracePropagation bool,
 ) (*OutboundMessage, error) {
 	subnetIDBytes := make([][]byte, len(trackedSubnets))
 	encodeIDs(trackedSubnets, subnetIDBytes)
@@ -278,6 +290,12 @@ func (b *outMsgBuilder) Handshake(
 					},
 					IpBlsSig:   ipBLSSig,
 					AllSubnets: requestAllSubnetIPs,
+					QuicPort:   uint32(quicPort),
+					IpQuicSig:  ipQUICSig,
+
+					SupportedCompressions: b.builder.supportedCompressions(),
+					ZstdDictionaryIds:     b.builder.supportedZstdDictionaries(),
+					TracePropagation:      tracePropagation,
 				},
 			},
 		},
@@ -668,39 +686,73 @@ func (b *outMsgBuilder) AppRequest(
 	requestID uint32,
 	deadline time.Duration,
 	msg []byte,
+	traceContext map[string]string,
 ) (*OutboundMessage, error) {
-	return b.builder.createOutbound(
+	appRequest := &p2p.AppRequest{
+		ChainId:   chainID[:],
+		RequestId: requestID,
+		Deadline:  uint64(deadline),
+		AppBytes:  msg,
+	}
+	return b.createTracedOutbound(
 		&p2p.Message{
 			Message: &p2p.Message_AppRequest{
-				AppRequest: &p2p.AppRequest{
-					ChainId:   chainID[:],
-					RequestId: requestID,
-					Deadline:  uint64(deadline),
-					AppBytes:  msg,
-				},
+				AppRequest: appRequest,
 			},
 		},
-		b.compressionType,
-		false,
+		traceContext,
+		func() {
+			appRequest.TraceContext = traceContext
+		},
 	)
 }
 
-func (b *outMsgBuilder) AppResponse(chainID ids.ID, requestID uint32, msg []byte) (*OutboundMessage, error) {
-	return b.builder.createOutbound(
+func (b *outMsgBuilder) AppResponse(
+	chainID ids.ID,
+	requestID uint32,
+	msg []byte,
+	traceContext map[string]string,
+) (*OutboundMessage, error) {
+	appResponse := &p2p.AppResponse{
+		ChainId:   chainID[:],
+		RequestId: requestID,
+		AppBytes:  msg,
+	}
+	return b.createTracedOutbound(
 		&p2p.Message{
 			Message: &p2p.Message_AppResponse{
-				AppResponse: &p2p.AppResponse{
-					ChainId:   chainID[:],
-					RequestId: requestID,
-					AppBytes:  msg,
-				},
+				AppResponse: appResponse,
 			},
 		},
-		b.compressionType,
-		false,
+		traceContext,
+		func() {
+			appResponse.TraceContext = traceContext
+		},
 	)
 }
 
+// createTracedOutbound creates [m] with the trace context set by
+// [setTraceContext]. The message is also created without the trace context,
+// which is sent to peers that don't support trace propagation.
+func (b *outMsgBuilder) createTracedOutbound(
+	m *p2p.Message,
+	traceContext map[string]string,
+	setTraceContext func(),
+) (*OutboundMessage, error) {
+	untraced, err := b.builder.createOutbound(m, b.compressionType, false)
+	if err != nil || len(traceContext) == 0 {
+		return untraced, err
+	}
+
+	setTraceContext()
+	traced, err := b.builder.createOutbound(m, b.compressionType, false)
+	if err != nil {
+		return nil, err
+	}
+	traced.untraced = untraced
+	return traced, nil
+}
+
 func (b *outMsgBuilder) AppError(chainID ids.ID, requestID uint32, errorCode int32, errorMessage string) (*OutboundMessage, error) {
 	return b.builder.createOutbound(
 		&p2p.Message{
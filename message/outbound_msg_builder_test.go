@@ -12,6 +12,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
 )
 
 func Test_newOutboundBuilder(t *testing.T) {
@@ -19,6 +20,8 @@ func Test_newOutboundBuilder(t *testing.T) {
 
 	mb, err := newMsgBuilder(
 		prometheus.NewRegistry(),
+		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		10*time.Second,
 	)
 	require.NoError(t, err)
@@ -0,0 +1,185 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+const expiryLen = 8
+
+var errInvalidBan = errors.New("invalid ban")
+
+// Ban prevents connections with a peer.
+type Ban struct {
+	NodeID ids.NodeID
+	Reason string
+	// Expiry is the time the ban is lifted. If zero, the ban doesn't expire.
+	Expiry time.Time
+}
+
+func (b *Ban) expired(now time.Time) bool {
+	return !b.Expiry.IsZero() && !now.Before(b.Expiry)
+}
+
+// banList tracks the banned peers. Bans are persisted in [db] so that they
+// are kept across restarts.
+type banList struct {
+	db database.Database
+
+	numBanned     prometheus.Gauge
+	connsRejected prometheus.Counter
+
+	lock sync.RWMutex
+	bans map[ids.NodeID]Ban
+}
+
+func newBanList(db database.Database, registerer prometheus.Registerer) (*banList, error) {
+	b := &banList{
+		db: db,
+		numBanned: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "banned_peers",
+			Help: "Number of peers that are banned",
+		}),
+		connsRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "banned_conns_rejected",
+			Help: "Times a connection with a banned peer was rejected",
+		}),
+		bans: make(map[ids.NodeID]Ban),
+	}
+	err := errors.Join(
+		registerer.Register(b.numBanned),
+		registerer.Register(b.connsRejected),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		ban, err := parseBan(it.Key(), it.Value())
+		if err != nil {
+			return nil, err
+		}
+		b.bans[ban.NodeID] = ban
+	}
+	b.numBanned.Set(float64(len(b.bans)))
+	return b, it.Error()
+}
+
+// ban adds or replaces the ban of [ban.NodeID].
+func (b *banList) ban(ban Ban) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.db.Put(ban.NodeID.Bytes(), banBytes(ban)); err != nil {
+		return err
+	}
+	b.bans[ban.NodeID] = ban
+	b.numBanned.Set(float64(len(b.bans)))
+	return nil
+}
+
+// unban removes the ban of [nodeID]. Returns false if [nodeID] wasn't banned.
+func (b *banList) unban(nodeID ids.NodeID) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.bans[nodeID]; !ok {
+		return false, nil
+	}
+	if err := b.db.Delete(nodeID.Bytes()); err != nil {
+		return false, err
+	}
+	delete(b.bans, nodeID)
+	b.numBanned.Set(float64(len(b.bans)))
+	return true, nil
+}
+
+// isBanned returns true if [nodeID] has a ban that hasn't expired at [now].
+func (b *banList) isBanned(nodeID ids.NodeID, now time.Time) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	ban, ok := b.bans[nodeID]
+	return ok && !ban.expired(now)
+}
+
+// list returns the bans that haven't expired at [now], sorted by node ID.
+func (b *banList) list(now time.Time) []Ban {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	bans := make([]Ban, 0, len(b.bans))
+	for _, ban := range b.bans {
+		if !ban.expired(now) {
+			bans = append(bans, ban)
+		}
+	}
+	slices.SortFunc(bans, func(a, b Ban) int {
+		return a.NodeID.Compare(b.NodeID)
+	})
+	return bans
+}
+
+// prune removes the bans that have expired at [now].
+func (b *banList) prune(now time.Time) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for nodeID, ban := range b.bans {
+		if !ban.expired(now) {
+			continue
+		}
+		if err := b.db.Delete(nodeID.Bytes()); err != nil {
+			return err
+		}
+		delete(b.bans, nodeID)
+	}
+	b.numBanned.Set(float64(len(b.bans)))
+	return nil
+}
+
+// The value of a ban is the expiry, in unix nanoseconds, followed by the
+// reason. An expiry of 0 means that the ban doesn't expire.
+func banBytes(ban Ban) []byte {
+	var expiry uint64
+	if !ban.Expiry.IsZero() {
+		expiry = uint64(ban.Expiry.UnixNano())
+	}
+	value := make([]byte, expiryLen, expiryLen+len(ban.Reason))
+	binary.BigEndian.PutUint64(value, expiry)
+	return append(value, ban.Reason...)
+}
+
+func parseBan(key []byte, value []byte) (Ban, error) {
+	nodeID, err := ids.ToNodeID(key)
+	if err != nil {
+		return Ban{}, fmt.Errorf("%w: %w", errInvalidBan, err)
+	}
+	if len(value) < expiryLen {
+		return Ban{}, fmt.Errorf("%w: value length %d", errInvalidBan, len(value))
+	}
+
+	ban := Ban{
+		NodeID: nodeID,
+		Reason: string(value[expiryLen:]),
+	}
+	if expiry := binary.BigEndian.Uint64(value); expiry != 0 {
+		ban.Expiry = time.Unix(0, int64(expiry))
+	}
+	return ban, nil
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestBanList(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		db  = memdb.New()
+		now = time.Unix(1_000_000, 0)
+
+		permanent = Ban{
+			NodeID: ids.BuildTestNodeID([]byte{1}),
+			Reason: "permanent",
+		}
+		temporary = Ban{
+			NodeID: ids.BuildTestNodeID([]byte{2}),
+			Reason: "temporary",
+			Expiry: now.Add(time.Hour),
+		}
+	)
+
+	bans, err := newBanList(db, prometheus.NewRegistry())
+	require.NoError(err)
+	require.NoError(bans.ban(permanent))
+	require.NoError(bans.ban(temporary))
+	require.True(bans.isBanned(permanent.NodeID, now))
+	require.True(bans.isBanned(temporary.NodeID, now))
+	require.Equal([]Ban{permanent, temporary}, bans.list(now))
+	require.Equal(float64(2), testutil.ToFloat64(bans.numBanned))
+
+	// Bans are loaded from the database.
+	bans, err = newBanList(db, prometheus.NewRegistry())
+	require.NoError(err)
+	require.Equal([]Ban{permanent, temporary}, bans.list(now))
+	require.Equal(float64(2), testutil.ToFloat64(bans.numBanned))
+
+	// Expired bans are ignored until they are pruned.
+	later := temporary.Expiry
+	require.True(bans.isBanned(permanent.NodeID, later))
+	require.False(bans.isBanned(temporary.NodeID, later))
+	require.Equal([]Ban{permanent}, bans.list(later))
+	require.Equal(float64(2), testutil.ToFloat64(bans.numBanned))
+
+	require.NoError(bans.prune(later))
+	require.Equal(float64(1), testutil.ToFloat64(bans.numBanned))
+	has, err := db.Has(temporary.NodeID.Bytes())
+	require.NoError(err)
+	require.False(has)
+
+	unbanned, err := bans.unban(permanent.NodeID)
+	require.NoError(err)
+	require.True(unbanned)
+	require.False(bans.isBanned(permanent.NodeID, now))
+	require.Equal(float64(0), testutil.ToFloat64(bans.numBanned))
+
+	unbanned, err = bans.unban(permanent.NodeID)
+	require.NoError(err)
+	require.False(unbanned)
+
+	bans, err = newBanList(db, prometheus.NewRegistry())
+	require.NoError(err)
+	require.Empty(bans.list(now))
+}
+
+func TestParseBan(t *testing.T) {
+	require := require.New(t)
+
+	nodeID := ids.GenerateTestNodeID()
+	_, err := parseBan(nodeID.Bytes(), []byte{0})
+	require.ErrorIs(err, errInvalidBan)
+	_, err = parseBan([]byte{0}, make([]byte, expiryLen))
+	require.ErrorIs(err, errInvalidBan)
+}
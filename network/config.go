@@ -6,11 +6,15 @@ package network
 import (
 	"crypto"
 	"crypto/tls"
+	"net"
 	"net/netip"
 	"time"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/dialer"
+	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/snow/networking/tracker"
 	"github.com/ava-labs/avalanchego/snow/uptime"
@@ -113,6 +117,18 @@ type Config struct {
 	DialerConfig dialer.Config `json:"dialerConfig"`
 	TLSConfig    *tls.Config   `json:"-"`
 
+	// QUICEnabled, if true, accepts QUIC connections on [QUICPort] and dials
+	// peers that advertised QUIC support over QUIC.
+	QUICEnabled bool `json:"quicEnabled"`
+	// QUICPort is the UDP port to accept QUIC connections on. If 0, the port
+	// of the staking address is used.
+	QUICPort uint16 `json:"quicPort"`
+	// QUICListener accepts QUIC connections and QUICDialer dials them. Either
+	// both or neither must be provided. If provided, the port of
+	// [QUICListener] is advertised to peers in the handshake.
+	QUICListener net.Listener  `json:"-"`
+	QUICDialer   dialer.Dialer `json:"-"`
+
 	TLSKeyLogFile string `json:"tlsKeyLogFile"`
 
 	MyNodeID           ids.NodeID                    `json:"myNodeID"`
@@ -125,9 +141,19 @@ type Config struct {
 	SupportedACPs set.Set[uint32] `json:"supportedACPs"`
 	ObjectedACPs  set.Set[uint32] `json:"objectedACPs"`
 
-	// The compression type to use when compressing outbound messages.
-	// Assumes all peers support this compression type.
+	// TracePropagationEnabled, if true, advertises support for trace context
+	// propagation in the Handshake. AppRequest and AppResponse messages
+	// exchanged with peers that also advertise it carry the W3C trace context
+	// of the request, so that traces span multiple nodes.
+	TracePropagationEnabled bool `json:"tracePropagationEnabled"`
+
+	// The preferred compression type to use when compressing outbound
+	// messages. Peers that don't support this compression type are sent
+	// messages compressed with a type they support.
 	CompressionType compression.Type `json:"compressionType"`
+	// CompressionZstdLevel is the level of zstd compression of outbound
+	// messages.
+	CompressionZstdLevel int `json:"compressionZstdLevel"`
 
 	// TLSKey is this node's TLS key that is used to sign IPs.
 	TLSKey crypto.Signer `json:"-"`
@@ -171,6 +197,10 @@ type Config struct {
 	// (there is one buffer per peer)
 	PeerWriteBufferSize int `json:"peerWriteBufferSize"`
 
+	// SendQueueWeights are the shares of the bandwidth to each peer given to
+	// each priority of outbound messages.
+	SendQueueWeights peer.PriorityWeights `json:"sendQueueWeights"`
+
 	// Tracks the CPU/disk usage caused by processing messages of each peer.
 	ResourceTracker tracker.ResourceTracker `json:"-"`
 
@@ -185,4 +215,12 @@ type Config struct {
 	// If true, connects to all validators regardless of primary network validator
 	// status or of configured tracked subnets.
 	ConnectToAllValidators bool `json:"connectToAllValidators"`
+
+	// BanDB persists the banned peers. If nil, bans are only kept in memory.
+	BanDB database.Database `json:"-"`
+
+	// ReputationTracker scores peers based on their behavior. Untrusted peers
+	// aren't sampled for gossip and their inbound connections are rejected.
+	// If nil, all peers are trusted.
+	ReputationTracker reputation.Tracker `json:"-"`
 }
@@ -31,6 +31,7 @@ type metrics struct {
 	inboundConnRateLimited       prometheus.Counter
 	inboundConnAllowed           prometheus.Counter
 	tlsConnRejected              prometheus.Counter
+	untrustedConnRejected        prometheus.Counter
 	numUselessPeerListBytes      prometheus.Counter
 	nodeUptimeWeightedAverage    prometheus.Gauge
 	nodeUptimeRewardingStake     prometheus.Gauge
@@ -93,6 +94,10 @@ func newMetrics(
 			Name: "tls_conn_rejected",
 			Help: "Times this node rejected a connection due to an unsupported TLS certificate",
 		}),
+		untrustedConnRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "untrusted_conn_rejected",
+			Help: "Times this node rejected an inbound connection from a peer with a poor reputation",
+		}),
 		numUselessPeerListBytes: prometheus.NewCounter(prometheus.CounterOpts{
 			Name: "num_useless_peerlist_bytes",
 			Help: "Amount of useless bytes (i.e. information about nodes we already knew/don't want to connect to) received in PeerList messages",
@@ -130,6 +135,7 @@ func newMetrics(
 		registerer.Register(m.acceptFailed),
 		registerer.Register(m.inboundConnAllowed),
 		registerer.Register(m.tlsConnRejected),
+		registerer.Register(m.untrustedConnRejected),
 		registerer.Register(m.numUselessPeerListBytes),
 		registerer.Register(m.inboundConnRateLimited),
 		registerer.Register(m.nodeUptimeWeightedAverage),
@@ -20,11 +20,13 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
 	"github.com/ava-labs/avalanchego/network/dialer"
 	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
@@ -47,14 +49,18 @@ const (
 	TimeSinceLastMsgReceivedKey      = "timeSinceLastMsgReceived"
 	TimeSinceLastMsgSentKey          = "timeSinceLastMsgSent"
 	SendFailRateKey                  = "sendFailRate"
+
+	pruneBansFreq = time.Minute
 )
 
 var (
 	_ Network = (*network)(nil)
 
 	errNotValidator           = errors.New("node is not a validator")
+	errBanningMyself          = errors.New("cannot ban myself")
 	errExpectedProxy          = errors.New("expected proxy")
 	errExpectedTCPProtocol    = errors.New("expected TCP protocol")
+	errMissingQUICTransport   = errors.New("QUIC listener and dialer must be provided together")
 	errTrackingPrimaryNetwork = errors.New("cannot track primary network")
 )
 
@@ -81,6 +87,25 @@ type Network interface {
 	// connect to this ID.
 	ManuallyTrack(nodeID ids.NodeID, ip netip.AddrPort)
 
+	// Ban disconnects from [nodeID] and rejects connections with it until
+	// Unban is called or [duration] has passed. If [duration] is 0, the ban
+	// doesn't expire. Banning a banned peer replaces its ban.
+	Ban(nodeID ids.NodeID, duration time.Duration, reason string) error
+
+	// Unban removes the ban of [nodeID]. Returns false if [nodeID] wasn't
+	// banned.
+	Unban(nodeID ids.NodeID) (bool, error)
+
+	// Bans returns the current bans, sorted by node ID.
+	Bans() []Ban
+
+	// Disconnect closes the connection with [nodeID]. Returns false if there
+	// is no connection with [nodeID].
+	//
+	// Disconnecting doesn't prevent reconnecting to [nodeID]. To prevent
+	// connecting to [nodeID], it should be banned.
+	Disconnect(nodeID ids.NodeID) bool
+
 	// PeerInfo returns information about peers. If [nodeIDs] is empty, returns
 	// info about all peers that have finished the handshake. Otherwise, returns
 	// info about the peers in [nodeIDs] that have finished the handshake.
@@ -132,6 +157,12 @@ type network struct {
 	serverUpgrader peer.Upgrader
 	// Does TLS handshakes for outbound connections
 	clientUpgrader peer.Upgrader
+	// Accepts new inbound QUIC connections. Nil if QUIC is disabled.
+	quicListener net.Listener
+	// Makes new outbound QUIC connections. Nil if QUIC is disabled.
+	quicDialer dialer.Dialer
+	// Authenticates QUIC connections, whose TLS handshake was done by QUIC
+	quicUpgrader peer.Upgrader
 
 	// ensures the close of the network only happens once.
 	closeOnce sync.Once
@@ -142,6 +173,12 @@ type network struct {
 
 	sendFailRateCalculator safemath.Averager
 
+	// Tracks which peers are banned
+	bans *banList
+
+	// Scores peers based on their behavior
+	reputation reputation.Tracker
+
 	// Tracks which peers know about which peers
 	ipTracker *ipTracker
 	peersLock sync.RWMutex
@@ -149,7 +186,10 @@ type network struct {
 	// connect to. An entry is added to this set when we first start attempting
 	// to connect to the peer. An entry is deleted from this set once we have
 	// finished the handshake.
-	trackedIPs      map[ids.NodeID]*trackedIP
+	trackedIPs map[ids.NodeID]*trackedIP
+	// quicPorts contains the QUIC ports advertised by the peers that we want
+	// to reconnect to over QUIC. Only populated if QUIC is enabled.
+	quicPorts       map[ids.NodeID]uint16
 	connectingPeers *peer.Set
 	connectedPeers  *peer.Set
 	closing         bool
@@ -209,6 +249,22 @@ func NewNetwork(
 		return nil, errTrackingPrimaryNetwork
 	}
 
+	if err := config.SendQueueWeights.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid send queue weights: %w", err)
+	}
+
+	if (config.QUICListener == nil) != (config.QUICDialer == nil) {
+		return nil, errMissingQUICTransport
+	}
+	var quicPort uint16
+	if config.QUICListener != nil {
+		quicAddr, err := ips.ParseAddrPort(config.QUICListener.Addr().String())
+		if err != nil {
+			return nil, fmt.Errorf("parsing QUIC address failed with: %w", err)
+		}
+		quicPort = quicAddr.Port()
+	}
+
 	inboundMsgThrottler, err := throttling.NewInboundMsgThrottler(
 		log,
 		metricsRegisterer,
@@ -242,6 +298,20 @@ func NewNetwork(
 		return nil, fmt.Errorf("initializing network metrics failed with: %w", err)
 	}
 
+	banDB := config.BanDB
+	if banDB == nil {
+		banDB = memdb.New()
+	}
+	bans, err := newBanList(banDB, metricsRegisterer)
+	if err != nil {
+		return nil, fmt.Errorf("initializing ban list failed with: %w", err)
+	}
+
+	reputationTracker := config.ReputationTracker
+	if reputationTracker == nil {
+		reputationTracker = reputation.NewNoTracker()
+	}
+
 	ipTracker, err := newIPTracker(config.TrackedSubnets, log, metricsRegisterer, config.ConnectToAllValidators)
 	if err != nil {
 		return nil, fmt.Errorf("initializing ip tracker failed with: %w", err)
@@ -280,9 +350,11 @@ func NewNetwork(
 		SupportedACPs:          config.SupportedACPs.List(),
 		ObjectedACPs:           config.ObjectedACPs.List(),
 		ResourceTracker:        config.ResourceTracker,
+		ReputationTracker:      reputationTracker,
 		UptimeCalculator:       config.UptimeCalculator,
-		IPSigner:               peer.NewIPSigner(config.MyIPPort, config.TLSKey, config.BLSKey),
+		IPSigner:               peer.NewIPSigner(config.MyIPPort, quicPort, config.TLSKey, config.BLSKey),
 		ConnectToAllValidators: config.ConnectToAllValidators,
+		TracePropagation:       config.TracePropagationEnabled,
 	}
 
 	onCloseCtx, cancel := context.WithCancel(context.Background())
@@ -298,6 +370,9 @@ func NewNetwork(
 		dialer:                      dialer,
 		serverUpgrader:              peer.NewTLSServerUpgrader(config.TLSConfig, metrics.tlsConnRejected),
 		clientUpgrader:              peer.NewTLSClientUpgrader(config.TLSConfig, metrics.tlsConnRejected),
+		quicListener:                config.QUICListener,
+		quicDialer:                  config.QUICDialer,
+		quicUpgrader:                peer.NewTLSConnUpgrader(metrics.tlsConnRejected),
 
 		onCloseCtx:       onCloseCtx,
 		onCloseCtxCancel: cancel,
@@ -308,7 +383,10 @@ func NewNetwork(
 			time.Now(),
 		)),
 
+		bans:            bans,
+		reputation:      reputationTracker,
 		trackedIPs:      make(map[ids.NodeID]*trackedIP),
+		quicPorts:       make(map[ids.NodeID]uint16),
 		ipTracker:       ipTracker,
 		connectingPeers: peer.NewSet(),
 		connectedPeers:  peer.NewSet(),
@@ -470,11 +548,14 @@ func (n *network) Connected(nodeID ids.NodeID) {
 		tracked.stopTracking()
 		delete(n.trackedIPs, nodeID)
 	}
+	peerIP := peer.IP()
+	if n.quicDialer != nil && peerIP.QUICPort != 0 {
+		n.quicPorts[nodeID] = peerIP.QUICPort
+	}
 	n.connectingPeers.Remove(nodeID)
 	n.connectedPeers.Add(peer)
 	n.peersLock.Unlock()
 
-	peerIP := peer.IP()
 	newIP := ips.NewClaimedIPPort(
 		peer.Cert(),
 		peerIP.AddrPort,
@@ -496,10 +577,13 @@ func (n *network) Connected(nodeID ids.NodeID) {
 }
 
 // AllowConnection returns true if this node should have a connection to the
-// provided nodeID. If the node is attempting to connect to the minimum number
-// of peers, then it should only connect if this node is a validator, or the
-// peer is a validator/beacon.
+// provided nodeID. Banned peers are never allowed. If the node is attempting
+// to connect to the minimum number of peers, then it should only connect if
+// this node is a validator, or the peer is a validator/beacon.
 func (n *network) AllowConnection(nodeID ids.NodeID) bool {
+	if n.bans.isBanned(nodeID, n.peerConfig.Clock.Time()) {
+		return false
+	}
 	if !n.config.RequireValidatorToConnect {
 		return true
 	}
@@ -507,6 +591,19 @@ func (n *network) AllowConnection(nodeID ids.NodeID) bool {
 	return areWeAPrimaryNetworkAValidator || n.ipTracker.WantsConnection(nodeID)
 }
 
+// admissible returns true if an inbound connection from [nodeID] should be
+// accepted based on its reputation. Validators and beacons are always
+// admissible, so that a low score can't partition consensus.
+func (n *network) admissible(nodeID ids.NodeID) bool {
+	if _, ok := n.config.Validators.GetValidator(constants.PrimaryNetworkID, nodeID); ok {
+		return true
+	}
+	if _, ok := n.config.Beacons.GetValidator(constants.PrimaryNetworkID, nodeID); ok {
+		return true
+	}
+	return n.reputation.Admissible(nodeID)
+}
+
 func (n *network) Track(claimedIPPorts []*ips.ClaimedIPPort) error {
 	_, areWeAPrimaryNetworkAValidator := n.config.Validators.GetValidator(constants.PrimaryNetworkID, n.config.MyNodeID)
 	trackAllSubnets := areWeAPrimaryNetworkAValidator || n.config.ConnectToAllValidators
@@ -605,8 +702,30 @@ func (n *network) Peers(
 func (n *network) Dispatch() error {
 	go n.runTimers() // Periodically perform operations
 	go n.inboundConnUpgradeThrottler.Dispatch()
+	if n.quicListener != nil {
+		go n.accept(n.quicListener, n.quicUpgrader)
+	}
+	n.accept(n.listener, n.serverUpgrader)
+	n.inboundConnUpgradeThrottler.Stop()
+	n.StartClose()
+
+	n.peersLock.RLock()
+	connecting := n.connectingPeers.Sample(n.connectingPeers.Len(), peer.NoPrecondition)
+	connected := n.connectedPeers.Sample(n.connectedPeers.Len(), peer.NoPrecondition)
+	n.peersLock.RUnlock()
+
+	errs := wrappers.Errs{}
+	for _, peer := range append(connecting, connected...) {
+		errs.Add(peer.AwaitClosed(context.TODO()))
+	}
+	return errs.Err
+}
+
+// accept continuously accepts connections from [listener] and upgrades them
+// with [upgrader] until the network is closed.
+func (n *network) accept(listener net.Listener, upgrader peer.Upgrader) {
 	for n.onCloseCtx.Err() == nil { // Continuously accept new connections
-		conn, err := n.listener.Accept() // Returns error when n.Close() is called
+		conn, err := listener.Accept() // Returns error when n.Close() is called
 		if err != nil {
 			n.peerConfig.Log.Debug("error during server accept", zap.Error(err))
 			// Sleep for a small amount of time to try to wait for the
@@ -650,7 +769,7 @@ func (n *network) Dispatch() error {
 				zap.Stringer("peerIP", ip),
 			)
 
-			if err := n.upgrade(conn, n.serverUpgrader, true); err != nil {
+			if err := n.upgrade(conn, upgrader, true); err != nil {
 				n.peerConfig.Log.Verbo("failed to upgrade connection",
 					zap.String("direction", "inbound"),
 					zap.Error(err),
@@ -658,19 +777,6 @@ func (n *network) Dispatch() error {
 			}
 		}()
 	}
-	n.inboundConnUpgradeThrottler.Stop()
-	n.StartClose()
-
-	n.peersLock.RLock()
-	connecting := n.connectingPeers.Sample(n.connectingPeers.Len(), peer.NoPrecondition)
-	connected := n.connectedPeers.Sample(n.connectedPeers.Len(), peer.NoPrecondition)
-	n.peersLock.RUnlock()
-
-	errs := wrappers.Errs{}
-	for _, peer := range append(connecting, connected...) {
-		errs.Add(peer.AwaitClosed(context.TODO()))
-	}
-	return errs.Err
 }
 
 func (n *network) ManuallyTrack(nodeID ids.NodeID, ip netip.AddrPort) {
@@ -695,6 +801,61 @@ func (n *network) ManuallyTrack(nodeID ids.NodeID, ip netip.AddrPort) {
 	}
 }
 
+func (n *network) Ban(nodeID ids.NodeID, duration time.Duration, reason string) error {
+	if nodeID == n.config.MyNodeID {
+		return errBanningMyself
+	}
+
+	ban := Ban{
+		NodeID: nodeID,
+		Reason: reason,
+	}
+	if duration > 0 {
+		ban.Expiry = n.peerConfig.Clock.Time().Add(duration)
+	}
+	if err := n.bans.ban(ban); err != nil {
+		return err
+	}
+
+	n.peerConfig.Log.Info("banned peer",
+		zap.Stringer("nodeID", nodeID),
+		zap.Duration("duration", duration),
+		zap.String("reason", reason),
+	)
+	n.Disconnect(nodeID)
+	return nil
+}
+
+func (n *network) Unban(nodeID ids.NodeID) (bool, error) {
+	unbanned, err := n.bans.unban(nodeID)
+	if err != nil || !unbanned {
+		return false, err
+	}
+
+	n.peerConfig.Log.Info("unbanned peer",
+		zap.Stringer("nodeID", nodeID),
+	)
+	return true, nil
+}
+
+func (n *network) Bans() []Ban {
+	return n.bans.list(n.peerConfig.Clock.Time())
+}
+
+func (n *network) Disconnect(nodeID ids.NodeID) bool {
+	n.peersLock.RLock()
+	defer n.peersLock.RUnlock()
+
+	peer, ok := n.connectedPeers.GetByID(nodeID)
+	if !ok {
+		peer, ok = n.connectingPeers.GetByID(nodeID)
+	}
+	if ok {
+		peer.StartClose()
+	}
+	return ok
+}
+
 func (n *network) track(ip *ips.ClaimedIPPort, trackAllSubnets bool) error {
 	// To avoid signature verification when the IP isn't needed, we
 	// optimistically filter out IPs. This can result in us not tracking an IP
@@ -786,7 +947,8 @@ func (n *network) getPeers(
 
 // samplePeers samples connected peers attempting to align with the number of
 // requested validators, non-validators, and peers. This function will
-// explicitly ignore nodeIDs already included in the send config.
+// explicitly ignore nodeIDs already included in the send config and peers that
+// aren't trusted.
 func (n *network) samplePeers(
 	config common.SendConfig,
 	subnetID ids.ID,
@@ -815,6 +977,11 @@ func (n *network) samplePeers(
 				return false
 			}
 
+			// Don't gossip to peers that have misbehaved
+			if !n.reputation.Trusted(peerID) {
+				return false
+			}
+
 			_, areTheyAValidator := n.config.Validators.GetValidator(subnetID, peerID)
 			// check if the peer is allowed to connect to the subnet
 			if !allower.IsAllowed(peerID, areTheyAValidator) {
@@ -873,6 +1040,8 @@ func (n *network) disconnectedFromConnected(peer *peer.Peer, nodeID ids.NodeID)
 		tracked := newTrackedIP(ip.AddrPort)
 		n.trackedIPs[nodeID] = tracked
 		n.dial(nodeID, tracked)
+	} else {
+		delete(n.quicPorts, nodeID)
 	}
 
 	n.metrics.markDisconnected(peer)
@@ -932,11 +1101,13 @@ func (n *network) dial(nodeID ids.NodeID, ip *trackedIP) {
 					ip.stopTracking()
 					delete(n.trackedIPs, nodeID)
 				}
+				delete(n.quicPorts, nodeID)
 				n.peersLock.Unlock()
 				return
 			}
 			_, connecting := n.connectingPeers.GetByID(nodeID)
 			_, connected := n.connectedPeers.GetByID(nodeID)
+			quicPort, supportsQUIC := n.quicPorts[nodeID]
 			n.peersLock.Unlock()
 
 			// While it may not be strictly needed to stop attempting to connect
@@ -970,6 +1141,18 @@ func (n *network) dial(nodeID ids.NodeID, ip *trackedIP) {
 			// nodeID leaves the validator set. This is why we continue the loop
 			// rather than returning even though we will never initiate an
 			// outbound connection with this IP.
+			// Invariant: Banned peers are skipped inside of the looping
+			// goroutine for the same reason as private IPs.
+			if n.bans.isBanned(nodeID, n.peerConfig.Clock.Time()) {
+				n.peerConfig.Log.Verbo("skipping connection dial",
+					zap.String("reason", "peer is banned"),
+					zap.Stringer("nodeID", nodeID),
+					zap.Stringer("peerIP", ip.ip),
+					zap.Duration("delay", ip.delay),
+				)
+				continue
+			}
+
 			if !n.config.AllowPrivateIPs && !ips.IsPublic(ip.ip.Addr()) {
 				n.peerConfig.Log.Verbo("skipping connection dial",
 					zap.String("reason", "outbound connections to private IPs are prohibited"),
@@ -980,6 +1163,26 @@ func (n *network) dial(nodeID ids.NodeID, ip *trackedIP) {
 				continue
 			}
 
+			// Peers that advertised QUIC support are dialed over QUIC. If
+			// that fails, TCP is attempted, as the peer may have disabled
+			// QUIC since advertising it.
+			if supportsQUIC {
+				quicIP := netip.AddrPortFrom(ip.ip.Addr(), quicPort)
+				conn, err := n.quicDialer.Dial(n.onCloseCtx, quicIP)
+				if err == nil {
+					err = n.upgrade(conn, n.quicUpgrader, false)
+				}
+				if err == nil {
+					return
+				}
+				n.peerConfig.Log.Verbo(
+					"failed to connect to peer over QUIC, attempting TCP",
+					zap.Stringer("nodeID", nodeID),
+					zap.Stringer("peerIP", quicIP),
+					zap.Error(err),
+				)
+			}
+
 			conn, err := n.dialer.Dial(n.onCloseCtx, ip.ip)
 			if err != nil {
 				n.peerConfig.Log.Verbo(
@@ -1056,6 +1259,29 @@ func (n *network) upgrade(conn net.Conn, upgrader peer.Upgrader, isIngress bool)
 		return nil
 	}
 
+	if n.bans.isBanned(nodeID, n.peerConfig.Clock.Time()) {
+		_ = tlsConn.Close()
+		n.bans.connsRejected.Inc()
+		n.peerConfig.Log.Verbo(
+			"dropping connection",
+			zap.String("reason", "peer is banned"),
+			zap.Stringer("nodeID", nodeID),
+		)
+		return nil
+	}
+
+	if isIngress && !n.admissible(nodeID) {
+		_ = tlsConn.Close()
+		n.metrics.untrustedConnRejected.Inc()
+		n.peerConfig.Log.Verbo(
+			"dropping connection",
+			zap.String("reason", "peer isn't trusted"),
+			zap.Stringer("nodeID", nodeID),
+			zap.Float64("score", n.reputation.Score(nodeID)),
+		)
+		return nil
+	}
+
 	if !n.AllowConnection(nodeID) {
 		_ = tlsConn.Close()
 		n.peerConfig.Log.Verbo(
@@ -1119,6 +1345,8 @@ func (n *network) upgrade(conn net.Conn, upgrader peer.Upgrader, isIngress bool)
 			nodeID,
 			n.peerConfig.Log,
 			n.outboundMsgThrottler,
+			n.peerConfig.Metrics,
+			n.config.SendQueueWeights,
 		),
 		isIngress,
 	)
@@ -1146,6 +1374,13 @@ func (n *network) StartClose() {
 				zap.Error(err),
 			)
 		}
+		if n.quicListener != nil {
+			if err := n.quicListener.Close(); err != nil {
+				n.peerConfig.Log.Debug("closing the QUIC listener",
+					zap.Error(err),
+				)
+			}
+		}
 
 		n.peersLock.Lock()
 		defer n.peersLock.Unlock()
@@ -1221,9 +1456,11 @@ func (n *network) runTimers() {
 	pullGossipPeerlists := time.NewTicker(n.config.PeerListPullGossipFreq)
 	resetPeerListBloom := time.NewTicker(n.config.PeerListBloomResetFreq)
 	updateUptimes := time.NewTicker(n.config.UptimeMetricFreq)
+	pruneBans := time.NewTicker(pruneBansFreq)
 	defer func() {
 		resetPeerListBloom.Stop()
 		updateUptimes.Stop()
+		pruneBans.Stop()
 	}()
 
 	for {
@@ -1249,6 +1486,12 @@ func (n *network) runTimers() {
 			}
 			n.metrics.nodeUptimeWeightedAverage.Set(primaryUptime.WeightedAveragePercentage)
 			n.metrics.nodeUptimeRewardingStake.Set(primaryUptime.RewardingStakePercentage)
+		case <-pruneBans.C:
+			if err := n.bans.prune(n.peerConfig.Clock.Time()); err != nil {
+				n.peerConfig.Log.Error("failed to prune expired bans",
+					zap.Error(err),
+				)
+			}
 		}
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 
@@ -19,6 +20,9 @@ import (
 	"github.com/ava-labs/avalanchego/message"
 	"github.com/ava-labs/avalanchego/network/dialer"
 	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/network/quic"
+	"github.com/ava-labs/avalanchego/network/reputation"
+	"github.com/ava-labs/avalanchego/network/simnet"
 	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
@@ -113,7 +117,8 @@ var (
 		PingFrequency:      constants.DefaultPingFrequency,
 		AllowPrivateIPs:    true,
 
-		CompressionType: constants.DefaultNetworkCompressionType,
+		CompressionType:      constants.DefaultNetworkCompressionType,
+		CompressionZstdLevel: constants.DefaultNetworkCompressionZstdLevel,
 
 		UptimeCalculator:  uptime.NewManager(uptime.NewTestState(), &mockable.Clock{}),
 		UptimeMetricFreq:  30 * time.Second,
@@ -122,6 +127,7 @@ var (
 		RequireValidatorToConnect: false,
 
 		MaximumInboundMessageTimeout: 30 * time.Second,
+		SendQueueWeights:             peer.DefaultPriorityWeights,
 		ResourceTracker:              newDefaultResourceTracker(),
 		CPUTargeter:                  nil, // Set in init
 		DiskTargeter:                 nil, // Set in init
@@ -199,6 +205,7 @@ func newMessageCreator(t *testing.T) message.Creator {
 	mc, err := message.NewCreator(
 		prometheus.NewRegistry(),
 		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkCompressionZstdLevel,
 		10*time.Second,
 	)
 	require.NoError(t, err)
@@ -611,7 +618,7 @@ func testDialDeletesNonValidators(t *testing.T, connectToAllValidators bool) {
 	}
 
 	config := configs[0]
-	signer := peer.NewIPSigner(config.MyIPPort, config.TLSKey, config.BLSKey)
+	signer := peer.NewIPSigner(config.MyIPPort, 0, config.TLSKey, config.BLSKey)
 	ip, err := signer.GetSignedIP()
 	require.NoError(err)
 
@@ -864,3 +871,318 @@ func TestGetAllPeers(t *testing.T) {
 	}
 	require.NoError(eg.Wait())
 }
+
+func TestBan(t *testing.T) {
+	require := require.New(t)
+
+	nodeIDs, networks, eg := newFullyConnectedTestNetwork(
+		t,
+		[]router.InboundHandler{
+			nil, nil,
+		},
+	)
+	net := networks[0]
+
+	err := net.Ban(net.config.MyNodeID, 0, "")
+	require.ErrorIs(err, errBanningMyself)
+
+	require.NoError(net.Ban(nodeIDs[1], 0, "misbehaving"))
+	require.False(net.AllowConnection(nodeIDs[1]))
+	require.Equal(
+		[]Ban{
+			{
+				NodeID: nodeIDs[1],
+				Reason: "misbehaving",
+			},
+		},
+		net.Bans(),
+	)
+
+	// The banned peer is disconnected and its attempts to reconnect are
+	// rejected.
+	require.Eventually(func() bool {
+		return testutil.ToFloat64(net.bans.connsRejected) > 0
+	}, 10*time.Second, 50*time.Millisecond)
+	require.Empty(net.PeerInfo([]ids.NodeID{nodeIDs[1]}))
+
+	unbanned, err := net.Unban(nodeIDs[1])
+	require.NoError(err)
+	require.True(unbanned)
+	require.Empty(net.Bans())
+
+	// The peer reconnects once it is unbanned.
+	require.Eventually(func() bool {
+		return len(net.PeerInfo([]ids.NodeID{nodeIDs[1]})) > 0
+	}, 10*time.Second, 50*time.Millisecond)
+
+	require.True(net.Disconnect(nodeIDs[1]))
+	require.False(net.Disconnect(ids.GenerateTestNodeID()))
+
+	for _, net := range networks {
+		net.StartClose()
+	}
+	require.NoError(eg.Wait())
+}
+
+func TestRejectUntrustedInboundConnections(t *testing.T) {
+	require := require.New(t)
+
+	dialer, listeners, nodeIDs, configs := newTestNetwork(t, 2, defaultConfig)
+
+	reputationTracker, err := reputation.NewTracker(reputation.DefaultConfig, prometheus.NewRegistry())
+	require.NoError(err)
+	for reputationTracker.Admissible(nodeIDs[0]) {
+		reputationTracker.Register(nodeIDs[0], reputation.InvalidMessage)
+	}
+	configs[1].ReputationTracker = reputationTracker
+
+	// Only the peer that accepts connections is a validator, as validators
+	// are always admissible.
+	networks := make([]*network, len(configs))
+	for i, config := range configs {
+		vdrs := validators.NewManager()
+		require.NoError(vdrs.AddStaker(constants.PrimaryNetworkID, nodeIDs[1], nil, ids.GenerateTestID(), 1))
+		config.Beacons = validators.NewManager()
+		config.Validators = vdrs
+
+		net, err := NewNetwork(
+			config,
+			upgrade.InitiallyActiveTime,
+			newMessageCreator(t),
+			prometheus.NewRegistry(),
+			logging.NoLog{},
+			listeners[i],
+			dialer,
+			&testHandler{
+				ConnectedF: func(ids.NodeID, *version.Application, ids.ID) {
+					t.Fatal("unexpectedly connected to a peer")
+				},
+			},
+		)
+		require.NoError(err)
+		networks[i] = net.(*network)
+	}
+
+	eg := &errgroup.Group{}
+	for _, net := range networks {
+		eg.Go(net.Dispatch)
+	}
+
+	// The untrusted peer dials the other peer, which rejects the connection.
+	networks[0].ManuallyTrack(nodeIDs[1], configs[1].MyIPPort.Get())
+	require.Eventually(func() bool {
+		return testutil.ToFloat64(networks[1].metrics.untrustedConnRejected) > 0
+	}, 10*time.Second, 50*time.Millisecond)
+
+	for _, net := range networks {
+		net.StartClose()
+	}
+	require.NoError(eg.Wait())
+}
+
+func TestAdmissible(t *testing.T) {
+	require := require.New(t)
+
+	_, _, _, configs := newTestNetwork(t, 1, defaultConfig)
+	config := configs[0]
+
+	reputationTracker, err := reputation.NewTracker(reputation.DefaultConfig, prometheus.NewRegistry())
+	require.NoError(err)
+	config.ReputationTracker = reputationTracker
+	config.Validators = validators.NewManager()
+	config.Beacons = validators.NewManager()
+
+	var (
+		timedOut  = ids.GenerateTestNodeID()
+		validator = ids.GenerateTestNodeID()
+		beacon    = ids.GenerateTestNodeID()
+		other     = ids.GenerateTestNodeID()
+	)
+	for _, nodeID := range []ids.NodeID{validator, beacon, other} {
+		for reputationTracker.Admissible(nodeID) {
+			reputationTracker.Register(nodeID, reputation.InvalidMessage)
+		}
+	}
+	for reputationTracker.Trusted(timedOut) {
+		reputationTracker.Register(timedOut, reputation.Timeout)
+	}
+	require.NoError(config.Validators.AddStaker(constants.PrimaryNetworkID, validator, nil, ids.GenerateTestID(), 1))
+	require.NoError(config.Beacons.AddStaker(constants.PrimaryNetworkID, beacon, nil, ids.GenerateTestID(), 1))
+
+	net, err := NewNetwork(
+		config,
+		upgrade.InitiallyActiveTime,
+		newMessageCreator(t),
+		prometheus.NewRegistry(),
+		logging.NoLog{},
+		nil,
+		nil,
+		&testHandler{},
+	)
+	require.NoError(err)
+
+	n := net.(*network)
+	require.True(n.admissible(timedOut))
+	require.True(n.admissible(validator))
+	require.True(n.admissible(beacon))
+	require.False(n.admissible(other))
+}
+
+func TestQUIC(t *testing.T) {
+	require := require.New(t)
+
+	dialer, listeners, nodeIDs, configs := newTestNetwork(t, 2, defaultConfig)
+
+	quicIPs := make([]netip.AddrPort, len(configs))
+	for i, config := range configs {
+		// QUIC connections are made to the signed IP, so it must be reachable.
+		ip := netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), config.MyIPPort.Get().Port())
+		config.MyIPPort.Set(ip)
+		dialer.AddListener(ip, listeners[i])
+
+		transport, err := quic.NewTransport("127.0.0.1:0", config.TLSConfig, time.Second)
+		require.NoError(err)
+		config.QUICListener = transport
+		config.QUICDialer = transport
+
+		quicIPs[i], err = ips.ParseAddrPort(transport.Addr().String())
+		require.NoError(err)
+	}
+
+	networks := make([]*network, len(configs))
+	for i, config := range configs {
+		vdrs := validators.NewManager()
+		for _, nodeID := range nodeIDs {
+			require.NoError(vdrs.AddStaker(constants.PrimaryNetworkID, nodeID, nil, ids.GenerateTestID(), 1))
+		}
+		config.Beacons = validators.NewManager()
+		config.Validators = vdrs
+
+		net, err := NewNetwork(
+			config,
+			upgrade.InitiallyActiveTime,
+			newMessageCreator(t),
+			prometheus.NewRegistry(),
+			logging.NoLog{},
+			listeners[i],
+			dialer,
+			&testHandler{},
+		)
+		require.NoError(err)
+		networks[i] = net.(*network)
+	}
+
+	eg := &errgroup.Group{}
+	for _, net := range networks {
+		eg.Go(net.Dispatch)
+	}
+
+	// The first connection is made over TCP, as the QUIC port of the peer
+	// isn't known yet.
+	networks[1].ManuallyTrack(nodeIDs[0], configs[0].MyIPPort.Get())
+	require.Eventually(func() bool {
+		return len(networks[1].PeerInfo([]ids.NodeID{nodeIDs[0]})) > 0
+	}, 10*time.Second, 50*time.Millisecond)
+	info := networks[1].PeerInfo([]ids.NodeID{nodeIDs[0]})[0]
+	require.NotEqual(quicIPs[0], info.IP)
+
+	// After learning the QUIC port from the handshake, reconnections are made
+	// over QUIC.
+	require.True(networks[1].Disconnect(nodeIDs[0]))
+	require.Eventually(func() bool {
+		peers := networks[1].PeerInfo([]ids.NodeID{nodeIDs[0]})
+		return len(peers) > 0 && peers[0].IP == quicIPs[0]
+	}, 10*time.Second, 50*time.Millisecond)
+
+	for _, net := range networks {
+		net.StartClose()
+	}
+	require.NoError(eg.Wait())
+}
+
+func TestSimulatedNetwork(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	sim := simnet.New(0, simnet.Link{
+		Latency: 10 * time.Millisecond,
+	})
+	go sim.Run(ctx)
+
+	_, _, nodeIDs, configs := newTestNetwork(t, 2, defaultConfig)
+
+	received := make(chan *message.InboundMessage, 1)
+	networks := make([]*network, len(configs))
+	for i, config := range configs {
+		listener, err := sim.NewListener(config.MyNodeID)
+		require.NoError(err)
+		config.MyIPPort.Set(listener.AddrPort())
+
+		vdrs := validators.NewManager()
+		for _, nodeID := range nodeIDs {
+			require.NoError(vdrs.AddStaker(constants.PrimaryNetworkID, nodeID, nil, ids.GenerateTestID(), 1))
+		}
+		config.Beacons = validators.NewManager()
+		config.Validators = vdrs
+
+		net, err := NewNetwork(
+			config,
+			upgrade.InitiallyActiveTime,
+			newMessageCreator(t),
+			prometheus.NewRegistry(),
+			logging.NoLog{},
+			listener,
+			sim.NewDialer(config.MyNodeID),
+			&testHandler{
+				InboundHandler: router.InboundHandlerFunc(func(_ context.Context, msg *message.InboundMessage) {
+					received <- msg
+				}),
+			},
+		)
+		require.NoError(err)
+		networks[i] = net.(*network)
+	}
+
+	eg := &errgroup.Group{}
+	for _, net := range networks {
+		eg.Go(net.Dispatch)
+	}
+
+	networks[1].ManuallyTrack(nodeIDs[0], configs[0].MyIPPort.Get())
+	require.Eventually(func() bool {
+		return len(networks[0].PeerInfo([]ids.NodeID{nodeIDs[1]})) > 0 &&
+			len(networks[1].PeerInfo([]ids.NodeID{nodeIDs[0]})) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// Messages sent across a partition are delivered once the partition is
+	// healed.
+	sim.Partition([]ids.NodeID{nodeIDs[0]})
+
+	msg, err := newMessageCreator(t).Get(ids.Empty, 1, time.Second, ids.Empty)
+	require.NoError(err)
+	toSend := set.Of(nodeIDs[1])
+	sentTo := networks[0].Send(
+		msg,
+		common.SendConfig{
+			NodeIDs: toSend,
+		},
+		constants.PrimaryNetworkID,
+		subnets.NoOpAllower,
+	)
+	require.Equal(toSend, sentTo)
+
+	select {
+	case <-received:
+		require.FailNow("received message across partition")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	sim.Heal()
+	require.Equal(message.GetOp, (<-received).Op)
+
+	for _, net := range networks {
+		net.StartClose()
+	}
+	require.NoError(eg.Wait())
+}
@@ -0,0 +1,178 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gossip
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+const (
+	DefaultFanOutMinScale         = .25
+	DefaultFanOutMaxScale         = 2
+	DefaultFanOutStep             = .1
+	DefaultFanOutMaxDuplicateRate = .95
+	DefaultFanOutMaxNoveltyRate   = .1
+	DefaultFanOutPeriod           = 10 * time.Second
+
+	// observedRateHalflife is the halflife of the moving averages of the
+	// duplicate and novelty rates of gossip.
+	observedRateHalflife = time.Minute
+)
+
+var (
+	ErrInvalidFanOutScale  = errors.New("fan-out scale must be positive and the minimum must not exceed the maximum")
+	ErrInvalidFanOutStep   = errors.New("fan-out step must be in (0, 1)")
+	ErrInvalidFanOutRate   = errors.New("fan-out rates must be in [0, 1]")
+	ErrInvalidFanOutPeriod = errors.New("fan-out period cannot be negative")
+)
+
+// AdaptiveFanOutConfig configures a [PushGossiper] to scale its branching
+// factors based on how gossip is observed to propagate.
+//
+// The duplicate rate is the fraction of gossipables that peers pulling gossip
+// already have, as reported by their bloom filters. A high duplicate rate means
+// that the network is saturated with push gossip, so the fan-out is reduced.
+//
+// The novelty rate is the fraction of gossipables received by pull gossip that
+// were new to this node. A high novelty rate means that push gossip is failing to reach
+// this node before the mempools of its peers converge, so the fan-out is
+// increased.
+type AdaptiveFanOutConfig struct {
+	// Enabled scales the branching factors of push gossip. If false, the
+	// configured branching factors are always used.
+	Enabled bool
+	// MinScale is the minimum factor the branching factors are scaled by. If
+	// zero, [DefaultFanOutMinScale] is used.
+	MinScale float64
+	// MaxScale is the maximum factor the branching factors are scaled by. If
+	// zero, [DefaultFanOutMaxScale] is used.
+	MaxScale float64
+	// Step is the fraction the scale is changed by in each adjustment. If
+	// zero, [DefaultFanOutStep] is used.
+	Step float64
+	// MaxDuplicateRate is the duplicate rate above which the fan-out is
+	// reduced. If zero, [DefaultFanOutMaxDuplicateRate] is used.
+	MaxDuplicateRate float64
+	// MaxNoveltyRate is the novelty rate above which the fan-out is increased.
+	// If zero, [DefaultFanOutMaxNoveltyRate] is used.
+	MaxNoveltyRate float64
+	// Period is the minimum duration between adjustments of the scale. If
+	// zero, [DefaultFanOutPeriod] is used.
+	Period time.Duration
+}
+
+func (c *AdaptiveFanOutConfig) fillDefaults() {
+	if c.MinScale == 0 {
+		c.MinScale = DefaultFanOutMinScale
+	}
+	if c.MaxScale == 0 {
+		c.MaxScale = DefaultFanOutMaxScale
+	}
+	if c.Step == 0 {
+		c.Step = DefaultFanOutStep
+	}
+	if c.MaxDuplicateRate == 0 {
+		c.MaxDuplicateRate = DefaultFanOutMaxDuplicateRate
+	}
+	if c.MaxNoveltyRate == 0 {
+		c.MaxNoveltyRate = DefaultFanOutMaxNoveltyRate
+	}
+	if c.Period == 0 {
+		c.Period = DefaultFanOutPeriod
+	}
+}
+
+func (c *AdaptiveFanOutConfig) Verify() error {
+	switch {
+	case c.MinScale <= 0 || c.MaxScale < c.MinScale:
+		return ErrInvalidFanOutScale
+	case c.Step <= 0 || c.Step >= 1:
+		return ErrInvalidFanOutStep
+	case c.MaxDuplicateRate < 0 || c.MaxDuplicateRate > 1,
+		c.MaxNoveltyRate < 0 || c.MaxNoveltyRate > 1:
+		return ErrInvalidFanOutRate
+	case c.Period < 0:
+		return ErrInvalidFanOutPeriod
+	default:
+		return nil
+	}
+}
+
+// adaptiveFanOut tracks the factor that the branching factors of a
+// [PushGossiper] are scaled by.
+type adaptiveFanOut struct {
+	config       AdaptiveFanOutConfig
+	scale        float64
+	lastAdjusted time.Time
+}
+
+// adjust updates the scale based on the observed duplicate and novelty rates.
+// Slow propagation takes precedence over saturation, as missing gossip is more
+// costly than redundant gossip.
+func (a *adaptiveFanOut) adjust(now time.Time, duplicateRate, noveltyRate float64) {
+	if now.Sub(a.lastAdjusted) < a.config.Period {
+		return
+	}
+	a.lastAdjusted = now
+
+	switch {
+	case noveltyRate > a.config.MaxNoveltyRate:
+		a.scale = min(a.scale*(1+a.config.Step), a.config.MaxScale)
+	case duplicateRate > a.config.MaxDuplicateRate:
+		a.scale = max(a.scale*(1-a.config.Step), a.config.MinScale)
+	}
+}
+
+// scale returns the branching factor with every parameter multiplied by
+// [scale]. Non-zero counts are rounded up so that they are never scaled to
+// zero.
+func (b BranchingFactor) scale(scale float64) BranchingFactor {
+	return BranchingFactor{
+		StakePercentage: min(b.StakePercentage*scale, 1),
+		Validators:      scaleCount(b.Validators, scale),
+		NonValidators:   scaleCount(b.NonValidators, scale),
+		Peers:           scaleCount(b.Peers, scale),
+	}
+}
+
+func scaleCount(count int, scale float64) int {
+	return int(math.Ceil(float64(count) * scale))
+}
+
+// rateAverager tracks a moving average of a rate and reports it as a gauge.
+type rateAverager struct {
+	gauge prometheus.Gauge
+
+	lock     sync.Mutex
+	averager safemath.Averager
+}
+
+func newRateAverager(gauge prometheus.Gauge) *rateAverager {
+	return &rateAverager{
+		gauge:    gauge,
+		averager: safemath.NewUninitializedAverager(observedRateHalflife),
+	}
+}
+
+func (r *rateAverager) observe(rate float64, now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.averager.Observe(rate, now)
+	r.gauge.Set(r.averager.Read())
+}
+
+func (r *rateAverager) read() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.averager.Read()
+}
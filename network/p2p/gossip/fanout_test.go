@@ -0,0 +1,232 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gossip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/snow/engine/enginetest"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/snow/validators/validatorstest"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+func TestBranchingFactorScale(t *testing.T) {
+	tests := []struct {
+		name     string
+		scale    float64
+		expected BranchingFactor
+	}{
+		{
+			name:  "unchanged",
+			scale: 1,
+			expected: BranchingFactor{
+				StakePercentage: .5,
+				Validators:      10,
+				Peers:           1,
+			},
+		},
+		{
+			name:  "reduced",
+			scale: .25,
+			expected: BranchingFactor{
+				StakePercentage: .125,
+				Validators:      3,
+				Peers:           1,
+			},
+		},
+		{
+			name:  "increased",
+			scale: 3,
+			expected: BranchingFactor{
+				StakePercentage: 1,
+				Validators:      30,
+				Peers:           3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := BranchingFactor{
+				StakePercentage: .5,
+				Validators:      10,
+				Peers:           1,
+			}
+			require.Equal(t, tt.expected, b.scale(tt.scale))
+		})
+	}
+}
+
+func TestPushGossiperAdaptiveFanOut(t *testing.T) {
+	require := require.New(t)
+
+	validatorSet := p2p.NewValidators(
+		logging.NoLog{},
+		constants.PrimaryNetworkID,
+		&validatorstest.State{
+			GetCurrentHeightF: func(context.Context) (uint64, error) {
+				return 1, nil
+			},
+			GetValidatorSetF: func(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+				return nil, nil
+			},
+		},
+		time.Hour,
+	)
+	network, err := p2p.NewNetwork(
+		logging.NoLog{},
+		&enginetest.SenderStub{
+			SentAppGossip: make(chan []byte, 16),
+		},
+		prometheus.NewRegistry(),
+		"",
+		validatorSet,
+	)
+	require.NoError(err)
+	client := network.NewClient(0, p2p.PeerSampler{Peers: &p2p.Peers{}})
+	metrics, err := NewMetrics(prometheus.NewRegistry(), "")
+	require.NoError(err)
+
+	gossiper, err := NewPushGossiper[tx](
+		marshaller{},
+		hasFunc(func(ids.ID) bool {
+			return true
+		}),
+		validatorSet,
+		client,
+		metrics,
+		BranchingFactor{
+			Validators: 10,
+		},
+		BranchingFactor{
+			Validators: 1,
+		},
+		AdaptiveFanOutConfig{
+			Enabled:  true,
+			MinScale: .5,
+			MaxScale: 1.5,
+			Step:     .5,
+			Period:   time.Nanosecond,
+		},
+		0,
+		units.MiB,
+		time.Hour,
+	)
+	require.NoError(err)
+	require.Equal(1., testutil.ToFloat64(metrics.fanOutScale))
+
+	gossiper.Add(tx{0})
+
+	// Peers already know all the gossip, so the fan-out is reduced down to the
+	// minimum scale.
+	metrics.duplicateRate.observe(1, time.Now())
+	require.NoError(gossiper.Gossip(t.Context()))
+	require.Equal(.5, testutil.ToFloat64(metrics.fanOutScale))
+	require.Equal(1., testutil.ToFloat64(metrics.duplicateRate.gauge))
+
+	require.NoError(gossiper.Gossip(t.Context()))
+	require.Equal(.5, testutil.ToFloat64(metrics.fanOutScale))
+
+	// Gossip is only learned through pull gossip, so the fan-out is increased
+	// even though the duplicate rate is high.
+	metrics.noveltyRate.observe(1, time.Now())
+	require.NoError(gossiper.Gossip(t.Context()))
+	require.Equal(.75, testutil.ToFloat64(metrics.fanOutScale))
+	require.Equal(BranchingFactor{Validators: 8}, gossiper.gossipParams.scale(gossiper.fanOut.scale))
+
+	require.NoError(gossiper.Gossip(t.Context()))
+	require.NoError(gossiper.Gossip(t.Context()))
+	require.Equal(1.5, testutil.ToFloat64(metrics.fanOutScale))
+}
+
+func TestAddPulledGossipNoveltyRate(t *testing.T) {
+	require := require.New(t)
+
+	metrics, err := NewMetrics(prometheus.NewRegistry(), "")
+	require.NoError(err)
+
+	knownSet := &setDouble{}
+	gossip := make([][]byte, 0, 10)
+	for i := range 10 {
+		gossipable := tx{byte(i)}
+		if i > 0 {
+			require.NoError(knownSet.Add(gossipable))
+		}
+		gossip = append(gossip, gossipable[:])
+	}
+
+	// Only one of the ten received gossipables was new.
+	addPulledGossip[tx](logging.NoLog{}, marshaller{}, knownSet, metrics, nil, ids.EmptyNodeID, gossip)
+	require.Equal(.1, testutil.ToFloat64(metrics.noveltyRate.gauge))
+	require.True(knownSet.Has(ids.ID{0}))
+
+	// Empty responses aren't observed.
+	addPulledGossip[tx](logging.NoLog{}, marshaller{}, knownSet, metrics, nil, ids.EmptyNodeID, nil)
+	require.Equal(.1, testutil.ToFloat64(metrics.noveltyRate.gauge))
+}
+
+func TestAdaptiveFanOutAdjust(t *testing.T) {
+	tests := []struct {
+		name          string
+		duplicateRate float64
+		noveltyRate   float64
+		expectedScale float64
+	}{
+		{
+			name:          "no signal",
+			duplicateRate: .5,
+			noveltyRate:   .05,
+			expectedScale: 1,
+		},
+		{
+			name:          "saturated",
+			duplicateRate: .99,
+			noveltyRate:   0,
+			expectedScale: .9,
+		},
+		{
+			name:          "saturated with some novel gossip",
+			duplicateRate: .99,
+			noveltyRate:   .05,
+			expectedScale: .9,
+		},
+		{
+			name:          "saturated with mostly novel gossip",
+			duplicateRate: .99,
+			noveltyRate:   .5,
+			expectedScale: 1.1,
+		},
+		{
+			name:          "slow propagation",
+			duplicateRate: 0,
+			noveltyRate:   .5,
+			expectedScale: 1.1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := AdaptiveFanOutConfig{
+				Enabled: true,
+			}
+			config.fillDefaults()
+			fanOut := &adaptiveFanOut{
+				config: config,
+				scale:  1,
+			}
+
+			fanOut.adjust(time.Now(), tt.duplicateRate, tt.noveltyRate)
+			require.InDelta(t, tt.expectedScale, fanOut.scale, 1e-9)
+		})
+	}
+}
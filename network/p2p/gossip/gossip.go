@@ -17,6 +17,7 @@ import (
 	"github.com/ava-labs/avalanchego/cache/lru"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/bloom"
 	"github.com/ava-labs/avalanchego/utils/buffer"
@@ -111,6 +112,10 @@ type Metrics struct {
 	trackingLifetimeAverage prometheus.Gauge
 	topValidators           *prometheus.GaugeVec
 	bloomFilterHitRate      prometheus.Histogram
+	duplicateRate           *rateAverager
+	noveltyRate             *rateAverager
+	fanOutScale             prometheus.Gauge
+	reconciliationFailures  prometheus.Counter
 }
 
 // NewMetrics returns a common set of metrics
@@ -118,6 +123,16 @@ func NewMetrics(
 	metrics prometheus.Registerer,
 	namespace string,
 ) (Metrics, error) {
+	duplicateRate := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "duplicate_rate",
+		Help:      "moving average of the fraction of gossipables that peers pulling gossip already have",
+	})
+	noveltyRate := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "novelty_rate",
+		Help:      "moving average of the fraction of gossipables received by pull gossip that were new",
+	})
 	m := Metrics{
 		bloomFilterHitRate: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: namespace,
@@ -165,6 +180,18 @@ func NewMetrics(
 			},
 			typeLabels,
 		),
+		duplicateRate: newRateAverager(duplicateRate),
+		noveltyRate:   newRateAverager(noveltyRate),
+		fanOutScale: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "fan_out_scale",
+			Help:      "factor the branching factors of push gossip are scaled by",
+		}),
+		reconciliationFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reconciliation_failures",
+			Help:      "number of pull gossip requests whose set difference was too large to be decoded",
+		}),
 	}
 	err := errors.Join(
 		metrics.Register(m.bloomFilterHitRate),
@@ -173,6 +200,10 @@ func NewMetrics(
 		metrics.Register(m.tracking),
 		metrics.Register(m.trackingLifetimeAverage),
 		metrics.Register(m.topValidators),
+		metrics.Register(duplicateRate),
+		metrics.Register(noveltyRate),
+		metrics.Register(m.fanOutScale),
+		metrics.Register(m.reconciliationFailures),
 	)
 	return m, err
 }
@@ -208,6 +239,7 @@ func NewPullGossiper[T Gossipable](
 	client *p2p.Client,
 	metrics Metrics,
 	pollSize int,
+	reputationTracker reputation.Tracker,
 ) *PullGossiper[T] {
 	return &PullGossiper[T]{
 		log:        log,
@@ -216,6 +248,7 @@ func NewPullGossiper[T Gossipable](
 		client:     client,
 		metrics:    metrics,
 		pollSize:   pollSize,
+		reputation: reputationTracker,
 	}
 }
 
@@ -238,6 +271,9 @@ type PullGossiper[T Gossipable] struct {
 	client     *p2p.Client
 	metrics    Metrics
 	pollSize   int
+	// If non-nil, peers that respond with gossip that can't be parsed lose
+	// reputation.
+	reputation reputation.Tracker
 }
 
 func (p *PullGossiper[_]) Gossip(ctx context.Context) error {
@@ -275,31 +311,57 @@ func (p *PullGossiper[_]) handleResponse(
 	gossip, err := ParseAppResponse(responseBytes)
 	if err != nil {
 		p.log.Debug("failed to unmarshal gossip response", zap.Error(err))
+		reportBadGossip(p.reputation, nodeID)
 		return
 	}
 
-	receivedBytes := 0
+	addPulledGossip(p.log, p.marshaller, p.set, p.metrics, p.reputation, nodeID, gossip)
+}
+
+// gossipAdder adds received gossipables to a set.
+type gossipAdder[T Gossipable] interface {
+	// Add adds a value to the set. Returns an error if v was not added.
+	Add(v T) error
+}
+
+// addPulledGossip adds the gossipables received in response to a pull gossip
+// request to [knownSet].
+func addPulledGossip[T Gossipable](
+	log logging.Logger,
+	marshaller Marshaller[T],
+	knownSet gossipAdder[T],
+	metrics Metrics,
+	reputationTracker reputation.Tracker,
+	nodeID ids.NodeID,
+	gossip [][]byte,
+) {
+	var (
+		receivedBytes = 0
+		numNew        = 0
+		malformed     = false
+	)
 	for _, bytes := range gossip {
 		receivedBytes += len(bytes)
 
-		gossipable, err := p.marshaller.UnmarshalGossip(bytes)
+		gossipable, err := marshaller.UnmarshalGossip(bytes)
 		if err != nil {
-			p.log.Debug(
+			log.Debug(
 				"failed to unmarshal gossip",
 				zap.Stringer("nodeID", nodeID),
 				zap.Error(err),
 			)
+			malformed = true
 			continue
 		}
 
 		gossipID := gossipable.GossipID()
-		p.log.Debug(
+		log.Debug(
 			"received gossip",
 			zap.Stringer("nodeID", nodeID),
 			zap.Stringer("id", gossipID),
 		)
-		if err := p.set.Add(gossipable); err != nil {
-			p.log.Debug(
+		if err := knownSet.Add(gossipable); err != nil {
+			log.Debug(
 				"failed to add gossip to the known set",
 				zap.Stringer("nodeID", nodeID),
 				zap.Stringer("id", gossipID),
@@ -307,15 +369,35 @@ func (p *PullGossiper[_]) handleResponse(
 			)
 			continue
 		}
+		numNew++
+	}
+	if malformed {
+		reportBadGossip(reputationTracker, nodeID)
 	}
 
-	if err := p.metrics.observeMessage(receivedPullLabels, len(gossip), receivedBytes); err != nil {
-		p.log.Error("failed to update metrics",
+	// Receiving new gossip through pull gossip means that push gossip didn't
+	// deliver it to this node. Empty responses carry no signal, so they aren't
+	// observed.
+	if len(gossip) > 0 {
+		noveltyRate := float64(numNew) / float64(len(gossip))
+		metrics.noveltyRate.observe(noveltyRate, time.Now())
+	}
+
+	if err := metrics.observeMessage(receivedPullLabels, len(gossip), receivedBytes); err != nil {
+		log.Error("failed to update metrics",
 			zap.Error(err),
 		)
 	}
 }
 
+// reportBadGossip lowers the reputation of [nodeID] for sending gossip that
+// couldn't be parsed, if [tracker] is non-nil.
+func reportBadGossip(tracker reputation.Tracker, nodeID ids.NodeID) {
+	if tracker != nil {
+		tracker.Register(nodeID, reputation.BadGossip)
+	}
+}
+
 // NewPushGossiper returns an instance of PushGossiper
 func NewPushGossiper[T Gossipable](
 	marshaller Marshaller[T],
@@ -325,6 +407,7 @@ func NewPushGossiper[T Gossipable](
 	metrics Metrics,
 	gossipParams BranchingFactor,
 	regossipParams BranchingFactor,
+	fanOutConfig AdaptiveFanOutConfig,
 	discardedSize int,
 	targetGossipSize int,
 	maxRegossipFrequency time.Duration,
@@ -335,6 +418,12 @@ func NewPushGossiper[T Gossipable](
 	if err := regossipParams.Verify(); err != nil {
 		return nil, fmt.Errorf("invalid regossip params: %w", err)
 	}
+	if fanOutConfig.Enabled {
+		fanOutConfig.fillDefaults()
+		if err := fanOutConfig.Verify(); err != nil {
+			return nil, fmt.Errorf("invalid adaptive fan-out config: %w", err)
+		}
+	}
 	switch {
 	case discardedSize < 0:
 		return nil, ErrInvalidDiscardedSize
@@ -344,6 +433,7 @@ func NewPushGossiper[T Gossipable](
 		return nil, ErrInvalidRegossipFrequency
 	}
 
+	metrics.fanOutScale.Set(1)
 	return &PushGossiper[T]{
 		marshaller:           marshaller,
 		set:                  set,
@@ -355,6 +445,10 @@ func NewPushGossiper[T Gossipable](
 		targetGossipSize:     targetGossipSize,
 		maxRegossipFrequency: maxRegossipFrequency,
 
+		fanOut: adaptiveFanOut{
+			config: fanOutConfig,
+			scale:  1,
+		},
 		tracking:   make(map[ids.ID]*tracking),
 		toGossip:   buffer.NewUnboundedDeque[T](0),
 		toRegossip: buffer.NewUnboundedDeque[T](0),
@@ -385,6 +479,7 @@ type PushGossiper[T Gossipable] struct {
 	maxRegossipFrequency time.Duration
 
 	lock         sync.Mutex
+	fanOut       adaptiveFanOut
 	tracking     map[ids.ID]*tracking
 	addedTimeSum float64 // unix nanoseconds
 	toGossip     buffer.Deque[T]
@@ -446,10 +541,19 @@ func (p *PushGossiper[T]) Gossip(ctx context.Context) error {
 		return nil
 	}
 
+	if p.fanOut.config.Enabled {
+		p.fanOut.adjust(
+			now,
+			p.metrics.duplicateRate.read(),
+			p.metrics.noveltyRate.read(),
+		)
+		p.metrics.fanOutScale.Set(p.fanOut.scale)
+	}
+
 	if err := p.gossip(
 		ctx,
 		now,
-		p.gossipParams,
+		p.gossipParams.scale(p.fanOut.scale),
 		p.toGossip,
 		p.toRegossip,
 		&cache.Empty[ids.ID, struct{}]{}, // Don't mark dropped unsent transactions as discarded
@@ -461,7 +565,7 @@ func (p *PushGossiper[T]) Gossip(ctx context.Context) error {
 	if err := p.gossip(
 		ctx,
 		now,
-		p.regossipParams,
+		p.regossipParams.scale(p.fanOut.scale),
 		p.toRegossip,
 		p.toRegossip,
 		p.discarded, // Mark dropped sent transactions as discarded
@@ -16,6 +16,9 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/p2p/p2ptest"
+	"github.com/ava-labs/avalanchego/network/reputation"
+	"github.com/ava-labs/avalanchego/network/simnet"
 	"github.com/ava-labs/avalanchego/proto/pb/sdk"
 	"github.com/ava-labs/avalanchego/snow/engine/enginetest"
 	"github.com/ava-labs/avalanchego/snow/validators"
@@ -40,6 +43,7 @@ func TestGossiperShutdown(t *testing.T) {
 		nil,
 		Metrics{},
 		0,
+		nil,
 	)
 	ctx, cancel := context.WithCancel(t.Context())
 
@@ -212,6 +216,7 @@ func TestGossiperGossip(t *testing.T) {
 				responseBloomSet,
 				metrics,
 				tt.targetResponseSize,
+				nil,
 			)
 			require.NoError(err)
 			require.NoError(responseNetwork.AddHandler(0x0, handler))
@@ -251,6 +256,7 @@ func TestGossiperGossip(t *testing.T) {
 				requestClient,
 				metrics,
 				1,
+				nil,
 			)
 			require.NoError(err)
 			received := set.Set[tx]{}
@@ -276,12 +282,179 @@ func TestGossiperGossip(t *testing.T) {
 	}
 }
 
+// TestPullGossiperSimulated pulls gossip over a simulated network, so that the
+// delivery of requests and responses only depends on the simulated clock.
+func TestPullGossiperSimulated(t *testing.T) {
+	require := require.New(t)
+	ctx := t.Context()
+
+	var (
+		network = simnet.New(0, simnet.Link{
+			Latency: time.Second,
+		})
+		clientNodeID = ids.GenerateTestNodeID()
+		serverNodeID = ids.GenerateTestNodeID()
+	)
+
+	responseSet := &setDouble{}
+	require.NoError(responseSet.Add(tx{1}))
+	responseMetrics, err := NewMetrics(prometheus.NewRegistry(), "")
+	require.NoError(err)
+	handler := NewHandler[tx](
+		logging.NoLog{},
+		marshaller{},
+		responseSet,
+		responseMetrics,
+		units.MiB,
+		nil,
+	)
+
+	client := p2ptest.NewSimulatedClientWithPeers(
+		t,
+		ctx,
+		network,
+		10*time.Second,
+		clientNodeID,
+		p2p.NoOpHandler{},
+		map[ids.NodeID]p2p.Handler{
+			serverNodeID: handler,
+		},
+	)
+
+	requestSet, err := NewBloomSet[tx](&setDouble{}, BloomSetConfig{})
+	require.NoError(err)
+	requestMetrics, err := NewMetrics(prometheus.NewRegistry(), "")
+	require.NoError(err)
+	gossiper := NewPullGossiper[tx](
+		logging.NoLog{},
+		marshaller{},
+		requestSet,
+		client,
+		requestMetrics,
+		1,
+		nil,
+	)
+
+	// Requests sent across a partition are dropped and time out.
+	network.Partition([]ids.NodeID{serverNodeID})
+	require.NoError(gossiper.Gossip(ctx))
+	network.Advance(time.Minute)
+	require.False(requestSet.Has(ids.ID{1}))
+
+	// Once the partition is healed, the gossip is received after the request
+	// and the response have each traversed a link.
+	network.Heal()
+	require.NoError(gossiper.Gossip(ctx))
+	network.Advance(time.Second)
+	require.False(requestSet.Has(ids.ID{1}))
+	network.Advance(time.Second)
+	require.True(requestSet.Has(ids.ID{1}))
+}
+
 type gossiperFunc func(ctx context.Context) error
 
 func (f gossiperFunc) Gossip(ctx context.Context) error {
 	return f(ctx)
 }
 
+func TestBadGossipLowersReputation(t *testing.T) {
+	gossipID := ids.GenerateTestID()
+	validGossip, err := MarshalAppGossip([][]byte{gossipID[:]})
+	require.NoError(t, err)
+	malformedGossip, err := MarshalAppGossip([][]byte{
+		gossipID[:],
+		{1, 2, 3},
+	})
+	require.NoError(t, err)
+	validResponse, err := MarshalAppResponse([][]byte{gossipID[:]})
+	require.NoError(t, err)
+	malformedResponse, err := MarshalAppResponse([][]byte{{1, 2, 3}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		pull     bool
+		bytes    []byte
+		expected bool
+	}{
+		{
+			name:  "valid push",
+			bytes: validGossip,
+		},
+		{
+			name:     "unparsable push",
+			bytes:    []byte{0xff},
+			expected: true,
+		},
+		{
+			name:     "malformed push",
+			bytes:    malformedGossip,
+			expected: true,
+		},
+		{
+			name:  "valid pull",
+			pull:  true,
+			bytes: validResponse,
+		},
+		{
+			name:     "unparsable pull",
+			pull:     true,
+			bytes:    []byte{0xff},
+			expected: true,
+		},
+		{
+			name:     "malformed pull",
+			pull:     true,
+			bytes:    malformedResponse,
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			config := reputation.DefaultConfig
+			config.Enabled = true
+			tracker, err := reputation.NewTracker(config, prometheus.NewRegistry())
+			require.NoError(err)
+			metrics, err := NewMetrics(prometheus.NewRegistry(), "")
+			require.NoError(err)
+
+			nodeID := ids.GenerateTestNodeID()
+			if tt.pull {
+				bloomSet, err := NewBloomSet(&setDouble{}, BloomSetConfig{})
+				require.NoError(err)
+				gossiper := NewPullGossiper[tx](
+					logging.NoLog{},
+					marshaller{},
+					bloomSet,
+					nil,
+					metrics,
+					1,
+					tracker,
+				)
+				gossiper.handleResponse(t.Context(), nodeID, tt.bytes, nil)
+			} else {
+				handler := NewHandler[tx](
+					logging.NoLog{},
+					marshaller{},
+					&setDouble{},
+					metrics,
+					units.MiB,
+					tracker,
+				)
+				handler.AppGossip(t.Context(), nodeID, tt.bytes)
+			}
+
+			if tt.expected {
+				require.Negative(tracker.Score(nodeID))
+			} else {
+				require.Zero(tracker.Score(nodeID))
+			}
+		})
+	}
+}
+
 func TestEvery(t *testing.T) {
 	ctx, cancel := context.WithCancel(t.Context())
 	calls := 0
@@ -333,6 +506,7 @@ func TestPushGossiperNew(t *testing.T) {
 		name                 string
 		gossipParams         BranchingFactor
 		regossipParams       BranchingFactor
+		fanOutConfig         AdaptiveFanOutConfig
 		discardedSize        int
 		targetGossipSize     int
 		maxRegossipFrequency time.Duration
@@ -447,6 +621,35 @@ func TestPushGossiperNew(t *testing.T) {
 			maxRegossipFrequency: -1,
 			expected:             ErrInvalidRegossipFrequency,
 		},
+		{
+			name: "invalid fan-out scale",
+			gossipParams: BranchingFactor{
+				Validators: 1,
+			},
+			regossipParams: BranchingFactor{
+				Validators: 1,
+			},
+			fanOutConfig: AdaptiveFanOutConfig{
+				Enabled:  true,
+				MinScale: 2,
+				MaxScale: 1,
+			},
+			expected: ErrInvalidFanOutScale,
+		},
+		{
+			name: "invalid fan-out step",
+			gossipParams: BranchingFactor{
+				Validators: 1,
+			},
+			regossipParams: BranchingFactor{
+				Validators: 1,
+			},
+			fanOutConfig: AdaptiveFanOutConfig{
+				Enabled: true,
+				Step:    1,
+			},
+			expected: ErrInvalidFanOutStep,
+		},
 	}
 
 	for _, tt := range tests {
@@ -459,6 +662,7 @@ func TestPushGossiperNew(t *testing.T) {
 				Metrics{},
 				tt.gossipParams,
 				tt.regossipParams,
+				tt.fanOutConfig,
 				tt.discardedSize,
 				tt.targetGossipSize,
 				tt.maxRegossipFrequency,
@@ -606,6 +810,7 @@ func TestPushGossiper(t *testing.T) {
 				BranchingFactor{
 					Validators: 1,
 				},
+				AdaptiveFanOutConfig{},
 				0, // the discarded cache size doesn't matter for this test
 				units.MiB,
 				regossipTime,
@@ -8,11 +8,15 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/reputation"
+	"github.com/ava-labs/avalanchego/proto/pb/sdk"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/bloom"
+	"github.com/ava-labs/avalanchego/utils/iblt"
 	"github.com/ava-labs/avalanchego/utils/logging"
 )
 
@@ -36,6 +40,7 @@ func NewHandler[T Gossipable](
 	set HandlerSet[T],
 	metrics Metrics,
 	targetResponseSize int,
+	reputationTracker reputation.Tracker,
 ) *Handler[T] {
 	return &Handler[T]{
 		Handler:            p2p.NoOpHandler{},
@@ -44,6 +49,7 @@ func NewHandler[T Gossipable](
 		set:                set,
 		metrics:            metrics,
 		targetResponseSize: targetResponseSize,
+		reputation:         reputationTracker,
 	}
 }
 
@@ -54,10 +60,24 @@ type Handler[T Gossipable] struct {
 	set                HandlerSet[T]
 	metrics            Metrics
 	targetResponseSize int
+	// If non-nil, peers that push gossip that can't be parsed lose
+	// reputation.
+	reputation reputation.Tracker
 }
 
+// AppRequest responds with the gossipables that the requester doesn't know
+// about, based on either the bloom filter or the invertible bloom lookup table
+// of the request.
 func (h Handler[T]) AppRequest(_ context.Context, _ ids.NodeID, _ time.Time, requestBytes []byte) ([]byte, *common.AppError) {
-	filter, salt, err := ParseAppRequest(requestBytes)
+	request := &sdk.PullGossipRequest{}
+	if err := proto.Unmarshal(requestBytes, request); err != nil {
+		return nil, p2p.ErrUnexpected
+	}
+	if len(request.Table) != 0 {
+		return h.reconcile(request)
+	}
+
+	filter, salt, err := parseBloomFilterRequest(request)
 	if err != nil {
 		return nil, p2p.ErrUnexpected
 	}
@@ -98,6 +118,7 @@ func (h Handler[T]) AppRequest(_ context.Context, _ ids.NodeID, _ time.Time, req
 	if total > 0 {
 		hitRate := float64(hits) / float64(total)
 		h.metrics.bloomFilterHitRate.Observe(100 * hitRate)
+		h.metrics.duplicateRate.observe(hitRate, time.Now())
 	}
 
 	if err := h.metrics.observeMessage(sentPullLabels, len(gossipBytes), responseSize); err != nil {
@@ -112,14 +133,88 @@ func (h Handler[T]) AppRequest(_ context.Context, _ ids.NodeID, _ time.Time, req
 	return response, nil
 }
 
+func (h Handler[T]) reconcile(request *sdk.PullGossipRequest) ([]byte, *common.AppError) {
+	remote, salt, err := parseReconciliationRequest(request)
+	if err != nil {
+		return nil, p2p.ErrUnexpected
+	}
+
+	// The requester's table is subtracted from a table of the local set, so
+	// that only the gossipables known by exactly one of the peers remain.
+	table, err := iblt.New(remote.Len(), salt)
+	if err != nil {
+		return nil, p2p.ErrUnexpected
+	}
+	gossipables := make(map[uint64]T)
+	h.set.Iterate(func(gossipable T) bool {
+		key := reconciliationKey(salt, gossipable.GossipID())
+		// Gossipables whose keys collide can't be told apart, so only the
+		// first one is reconciled.
+		if _, ok := gossipables[key]; ok {
+			return true
+		}
+		gossipables[key] = gossipable
+		table.Add(key)
+		return true
+	})
+	if err := table.Subtract(remote); err != nil {
+		return nil, p2p.ErrUnexpected
+	}
+
+	missing, extra, err := table.Decode()
+	if err != nil {
+		h.metrics.reconciliationFailures.Inc()
+		return nil, ErrReconciliationFailed
+	}
+	if total := len(gossipables); total > 0 {
+		h.metrics.duplicateRate.observe(float64(total-len(missing))/float64(total), time.Now())
+	}
+
+	var (
+		responseSize int
+		gossipBytes  [][]byte
+	)
+	for _, key := range missing {
+		gossipable, ok := gossipables[key]
+		if !ok {
+			return nil, p2p.ErrUnexpected
+		}
+
+		bytes, err := h.marshaller.MarshalGossip(gossipable)
+		if err != nil {
+			return nil, p2p.ErrUnexpected
+		}
+
+		gossipBytes = append(gossipBytes, bytes)
+		responseSize += len(bytes)
+		if responseSize > h.targetResponseSize {
+			break
+		}
+	}
+
+	if err := h.metrics.observeMessage(sentPullLabels, len(gossipBytes), responseSize); err != nil {
+		return nil, p2p.ErrUnexpected
+	}
+
+	response, err := MarshalReconciliationResponse(gossipBytes, len(missing)+len(extra))
+	if err != nil {
+		return nil, p2p.ErrUnexpected
+	}
+	return response, nil
+}
+
 func (h Handler[_]) AppGossip(_ context.Context, nodeID ids.NodeID, gossipBytes []byte) {
 	gossip, err := ParseAppGossip(gossipBytes)
 	if err != nil {
 		h.log.Debug("failed to unmarshal gossip", zap.Error(err))
+		reportBadGossip(h.reputation, nodeID)
 		return
 	}
 
-	receivedBytes := 0
+	var (
+		receivedBytes = 0
+		malformed     = false
+	)
 	for _, bytes := range gossip {
 		receivedBytes += len(bytes)
 		gossipable, err := h.marshaller.UnmarshalGossip(bytes)
@@ -128,6 +223,7 @@ func (h Handler[_]) AppGossip(_ context.Context, nodeID ids.NodeID, gossipBytes
 				zap.Stringer("nodeID", nodeID),
 				zap.Error(err),
 			)
+			malformed = true
 			continue
 		}
 
@@ -140,6 +236,9 @@ func (h Handler[_]) AppGossip(_ context.Context, nodeID ids.NodeID, gossipBytes
 			)
 		}
 	}
+	if malformed {
+		reportBadGossip(h.reputation, nodeID)
+	}
 
 	if err := h.metrics.observeMessage(receivedPushLabels, len(gossip), receivedBytes); err != nil {
 		h.log.Error("failed to update metrics",
@@ -4,13 +4,19 @@
 package gossip
 
 import (
+	"errors"
+	"fmt"
+
 	"google.golang.org/protobuf/proto"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/proto/pb/sdk"
 	"github.com/ava-labs/avalanchego/utils/bloom"
+	"github.com/ava-labs/avalanchego/utils/iblt"
 )
 
+var errTableTooLarge = errors.New("table too large")
+
 func MarshalAppRequest(filter, salt []byte) ([]byte, error) {
 	request := &sdk.PullGossipRequest{
 		Filter: filter,
@@ -24,7 +30,10 @@ func ParseAppRequest(bytes []byte) (*bloom.ReadFilter, ids.ID, error) {
 	if err := proto.Unmarshal(bytes, request); err != nil {
 		return nil, ids.Empty, err
 	}
+	return parseBloomFilterRequest(request)
+}
 
+func parseBloomFilterRequest(request *sdk.PullGossipRequest) (*bloom.ReadFilter, ids.ID, error) {
 	salt, err := ids.ToID(request.Salt)
 	if err != nil {
 		return nil, ids.Empty, err
@@ -34,6 +43,27 @@ func ParseAppRequest(bytes []byte) (*bloom.ReadFilter, ids.ID, error) {
 	return filter, salt, err
 }
 
+func MarshalReconciliationRequest(table, salt []byte) ([]byte, error) {
+	request := &sdk.PullGossipRequest{
+		Table: table,
+		Salt:  salt,
+	}
+	return proto.Marshal(request)
+}
+
+func parseReconciliationRequest(request *sdk.PullGossipRequest) (*iblt.Table, ids.ID, error) {
+	salt, err := ids.ToID(request.Salt)
+	if err != nil {
+		return nil, ids.Empty, err
+	}
+
+	if len(request.Table) > MaxReconciliationCells*iblt.CellLen {
+		return nil, ids.Empty, fmt.Errorf("%w: %d bytes", errTableTooLarge, len(request.Table))
+	}
+	table, err := iblt.Parse(request.Table, salt)
+	return table, salt, err
+}
+
 func MarshalAppResponse(gossip [][]byte) ([]byte, error) {
 	return proto.Marshal(&sdk.PullGossipResponse{
 		Gossip: gossip,
@@ -46,6 +76,19 @@ func ParseAppResponse(bytes []byte) ([][]byte, error) {
 	return response.Gossip, err
 }
 
+func MarshalReconciliationResponse(gossip [][]byte, setDifference int) ([]byte, error) {
+	return proto.Marshal(&sdk.PullGossipResponse{
+		Gossip:        gossip,
+		SetDifference: uint32(setDifference),
+	})
+}
+
+func ParseReconciliationResponse(bytes []byte) ([][]byte, int, error) {
+	response := &sdk.PullGossipResponse{}
+	err := proto.Unmarshal(bytes, response)
+	return response.Gossip, int(response.SetDifference), err
+}
+
 func MarshalAppGossip(gossip [][]byte) ([]byte, error) {
 	return proto.Marshal(&sdk.PushGossip{
 		Gossip: gossip,
@@ -0,0 +1,231 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gossip
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/reputation"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/iblt"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+const (
+	// MaxReconciliationCells is the maximum number of cells of a table that
+	// is accepted in a pull gossip request.
+	MaxReconciliationCells = 3 * 4096
+
+	DefaultMinReconciliationCells = 3 * 16
+	DefaultMaxReconciliationCells = 3 * 1024
+
+	// reconciliationCellsPerDifference is the number of cells requested for
+	// every gossipable that was known by only one of the peers in the last
+	// reconciliation.
+	reconciliationCellsPerDifference = 2
+)
+
+var (
+	_ Gossiper = (*ReconciliationGossiper[Gossipable])(nil)
+
+	// ErrReconciliationFailed should be used to indicate that a pull gossip
+	// request failed due to the difference between the sets being too large
+	// to be decoded from the table of the request.
+	ErrReconciliationFailed = &common.AppError{
+		Code:    1,
+		Message: "failed to reconcile sets",
+	}
+
+	ErrInvalidReconciliationCells = errors.New("invalid number of reconciliation cells")
+)
+
+// ReconciliationConfig configures the size of the invertible bloom lookup
+// tables sent by a [ReconciliationGossiper].
+//
+// The size of the table is based on the size of the difference between the
+// sets in the last reconciliation, and is doubled whenever the difference is
+// too large to be decoded.
+type ReconciliationConfig struct {
+	// MinCells is the minimum number of cells of a table. If zero,
+	// [DefaultMinReconciliationCells] is used.
+	MinCells int
+	// MaxCells is the maximum number of cells of a table. If zero,
+	// [DefaultMaxReconciliationCells] is used.
+	MaxCells int
+}
+
+func (c *ReconciliationConfig) fillDefaults() {
+	if c.MinCells == 0 {
+		c.MinCells = DefaultMinReconciliationCells
+	}
+	if c.MaxCells == 0 {
+		c.MaxCells = DefaultMaxReconciliationCells
+	}
+}
+
+func (c *ReconciliationConfig) Verify() error {
+	if c.MinCells < iblt.NumHashes || c.MaxCells < c.MinCells || c.MaxCells > MaxReconciliationCells {
+		return ErrInvalidReconciliationCells
+	}
+	return nil
+}
+
+// NewReconciliationGossiper returns a pull gossiper that requests the
+// gossipables it is missing by reconciling its set with the sets of its peers.
+//
+// Unlike [PullGossiper], the size of the requests is proportional to the size
+// of the difference between the sets rather than to the size of the set.
+// Every gossipable known by only one of the peers costs
+// [reconciliationCellsPerDifference] cells of [iblt.CellLen] bytes, or 32
+// bytes, whereas a bloom filter costs about 1.2 bytes for every gossipable in
+// the set. Reconciliation therefore sends smaller requests as long as fewer than
+// about 4% of the gossipables differ between the peers, such as between the
+// mempools of well connected validators.
+func NewReconciliationGossiper[T Gossipable](
+	log logging.Logger,
+	marshaller Marshaller[T],
+	set HandlerSet[T],
+	client *p2p.Client,
+	metrics Metrics,
+	pollSize int,
+	config ReconciliationConfig,
+	reputationTracker reputation.Tracker,
+) (*ReconciliationGossiper[T], error) {
+	config.fillDefaults()
+	if err := config.Verify(); err != nil {
+		return nil, err
+	}
+
+	return &ReconciliationGossiper[T]{
+		log:        log,
+		marshaller: marshaller,
+		set:        set,
+		client:     client,
+		metrics:    metrics,
+		pollSize:   pollSize,
+		config:     config,
+		reputation: reputationTracker,
+		numCells:   config.MinCells,
+	}, nil
+}
+
+type ReconciliationGossiper[T Gossipable] struct {
+	log        logging.Logger
+	marshaller Marshaller[T]
+	set        HandlerSet[T]
+	client     *p2p.Client
+	metrics    Metrics
+	pollSize   int
+	config     ReconciliationConfig
+	// If non-nil, peers that respond with gossip that can't be parsed lose
+	// reputation.
+	reputation reputation.Tracker
+
+	lock     sync.Mutex
+	numCells int
+}
+
+func (p *ReconciliationGossiper[T]) Gossip(ctx context.Context) error {
+	// A new salt is used for every request so that gossipables that collide
+	// in one table are unlikely to collide in the next.
+	var salt ids.ID
+	if _, err := rand.Read(salt[:]); err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	numCells := p.numCells
+	p.lock.Unlock()
+
+	table, err := iblt.New(numCells, salt)
+	if err != nil {
+		return err
+	}
+	p.set.Iterate(func(gossipable T) bool {
+		table.Add(reconciliationKey(salt, gossipable.GossipID()))
+		return true
+	})
+
+	msgBytes, err := MarshalReconciliationRequest(table.Marshal(), salt[:])
+	if err != nil {
+		return err
+	}
+
+	onResponse := func(_ context.Context, nodeID ids.NodeID, responseBytes []byte, err error) {
+		p.handleResponse(nodeID, table.Len(), responseBytes, err)
+	}
+	for i := 0; i < p.pollSize; i++ {
+		err := p.client.AppRequestAny(ctx, msgBytes, onResponse)
+		if err != nil && !errors.Is(err, p2p.ErrNoPeers) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ReconciliationGossiper[_]) handleResponse(
+	nodeID ids.NodeID,
+	numCells int,
+	responseBytes []byte,
+	err error,
+) {
+	if errors.Is(err, ErrReconciliationFailed) {
+		p.log.Debug(
+			"failed to reconcile gossip",
+			zap.Stringer("nodeID", nodeID),
+			zap.Int("numCells", numCells),
+		)
+		p.resize(2 * numCells)
+		return
+	}
+	if err != nil {
+		p.log.Debug(
+			"failed gossip request",
+			zap.Stringer("nodeID", nodeID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	gossip, setDifference, err := ParseReconciliationResponse(responseBytes)
+	if err != nil {
+		p.log.Debug("failed to unmarshal gossip response", zap.Error(err))
+		reportBadGossip(p.reputation, nodeID)
+		return
+	}
+
+	p.resize(reconciliationCellsPerDifference * setDifference)
+	addPulledGossip(p.log, p.marshaller, p.set, p.metrics, p.reputation, nodeID, gossip)
+}
+
+// resize sets the number of cells of the next table, bounded by the config.
+func (p *ReconciliationGossiper[_]) resize(numCells int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.numCells = min(max(numCells, p.config.MinCells), p.config.MaxCells)
+}
+
+// reconciliationKey returns the key of [gossipID] in a table with [salt].
+//
+// Keys are much shorter than gossip IDs to keep tables small. Decoded keys are
+// mapped back to the gossipables of the local set, and the salt makes it
+// unlikely that gossipables whose keys collide in one request collide in the
+// next.
+func reconciliationKey(salt ids.ID, gossipID ids.ID) uint64 {
+	hash := sha256.New()
+	// sha256.Write never returns errors
+	_, _ = hash.Write(gossipID[:])
+	_, _ = hash.Write(salt[:])
+	return binary.BigEndian.Uint64(hash.Sum(make([]byte, 0, sha256.Size)))
+}
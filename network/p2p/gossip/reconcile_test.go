@@ -0,0 +1,293 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gossip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/enginetest"
+	"github.com/ava-labs/avalanchego/utils/iblt"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+func TestNewReconciliationGossiper(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ReconciliationConfig
+		expected error
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "too few cells",
+			config: ReconciliationConfig{
+				MinCells: iblt.NumHashes - 1,
+			},
+			expected: ErrInvalidReconciliationCells,
+		},
+		{
+			name: "max less than min",
+			config: ReconciliationConfig{
+				MinCells: 2 * iblt.NumHashes,
+				MaxCells: iblt.NumHashes,
+			},
+			expected: ErrInvalidReconciliationCells,
+		},
+		{
+			name: "too many cells",
+			config: ReconciliationConfig{
+				MaxCells: MaxReconciliationCells + 1,
+			},
+			expected: ErrInvalidReconciliationCells,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReconciliationGossiper[tx](
+				logging.NoLog{},
+				marshaller{},
+				&setDouble{},
+				nil,
+				Metrics{},
+				1,
+				tt.config,
+				nil,
+			)
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestReconciliationGossiper(t *testing.T) {
+	tests := []struct {
+		name                   string
+		config                 ReconciliationConfig
+		requester              []tx
+		responder              []tx
+		expected               []tx
+		expectedNumCells       int
+		expectedFailures       float64
+		expectedDuplicateRate  float64
+		expectDuplicateUpdated bool
+	}{
+		{
+			name: "no gossip - sets are equal",
+			config: ReconciliationConfig{
+				MinCells: 300,
+			},
+			requester:              []tx{{0}, {1}},
+			responder:              []tx{{0}, {1}},
+			expected:               []tx{{0}, {1}},
+			expectedNumCells:       300,
+			expectedDuplicateRate:  1,
+			expectDuplicateUpdated: true,
+		},
+		{
+			name: "gossip - requester knows less than responder",
+			config: ReconciliationConfig{
+				MinCells: 300,
+			},
+			requester:              []tx{{0}, {3}},
+			responder:              []tx{{0}, {1}, {2}},
+			expected:               []tx{{0}, {1}, {2}, {3}},
+			expectedNumCells:       300,
+			expectedDuplicateRate:  1. / 3,
+			expectDuplicateUpdated: true,
+		},
+		{
+			name: "failure - difference too large",
+			config: ReconciliationConfig{
+				MinCells: iblt.NumHashes,
+				MaxCells: 4 * iblt.NumHashes,
+			},
+			responder:        makeTxs(20),
+			expectedNumCells: 2 * iblt.NumHashes,
+			expectedFailures: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := t.Context()
+
+			responseSender := &enginetest.SenderStub{
+				SentAppResponse: make(chan []byte, 1),
+				SentAppError:    make(chan *common.AppError, 1),
+			}
+			responseNetwork, err := p2p.NewNetwork(
+				logging.NoLog{},
+				responseSender,
+				prometheus.NewRegistry(),
+				"",
+			)
+			require.NoError(err)
+
+			responseSet := &setDouble{}
+			for _, item := range tt.responder {
+				require.NoError(responseSet.Add(item))
+			}
+			responseMetrics, err := NewMetrics(prometheus.NewRegistry(), "")
+			require.NoError(err)
+			handler := NewHandler[tx](
+				logging.NoLog{},
+				marshaller{},
+				responseSet,
+				responseMetrics,
+				units.MiB,
+				nil,
+			)
+			require.NoError(responseNetwork.AddHandler(0x0, handler))
+
+			requestSender := &enginetest.SenderStub{
+				SentAppRequest: make(chan []byte, 1),
+			}
+			peers := &p2p.Peers{}
+			requestNetwork, err := p2p.NewNetwork(
+				logging.NoLog{},
+				requestSender,
+				prometheus.NewRegistry(),
+				"",
+				peers,
+			)
+			require.NoError(err)
+			require.NoError(requestNetwork.Connected(ctx, ids.EmptyNodeID, nil))
+
+			requestSet := &setDouble{}
+			for _, item := range tt.requester {
+				require.NoError(requestSet.Add(item))
+			}
+			requestMetrics, err := NewMetrics(prometheus.NewRegistry(), "")
+			require.NoError(err)
+			gossiper, err := NewReconciliationGossiper[tx](
+				logging.NoLog{},
+				marshaller{},
+				requestSet,
+				requestNetwork.NewClient(0x0, p2p.PeerSampler{Peers: peers}),
+				requestMetrics,
+				1,
+				tt.config,
+				nil,
+			)
+			require.NoError(err)
+
+			require.NoError(gossiper.Gossip(ctx))
+			require.NoError(responseNetwork.AppRequest(ctx, ids.EmptyNodeID, 1, time.Time{}, <-requestSender.SentAppRequest))
+			select {
+			case response := <-responseSender.SentAppResponse:
+				require.NoError(requestNetwork.AppResponse(ctx, ids.EmptyNodeID, 1, response))
+			case appErr := <-responseSender.SentAppError:
+				require.NoError(requestNetwork.AppRequestFailed(ctx, ids.EmptyNodeID, 1, appErr))
+			}
+
+			require.ElementsMatch(tt.expected, requestSet.txs.List())
+			require.Equal(tt.expectedNumCells, gossiper.numCells)
+			require.Equal(tt.expectedFailures, testutil.ToFloat64(responseMetrics.reconciliationFailures))
+			if tt.expectDuplicateUpdated {
+				require.InDelta(tt.expectedDuplicateRate, responseMetrics.duplicateRate.read(), 1e-9)
+			}
+		})
+	}
+}
+
+// makeTxs returns [n] distinct txs that are generated deterministically so
+// that decoding the tables doesn't depend on random IDs.
+func makeTxs(n int) []tx {
+	txs := make([]tx, n)
+	for i := range txs {
+		binary.BigEndian.PutUint64(txs[i][:], uint64(i)+1)
+	}
+	return txs
+}
+
+// BenchmarkPullGossipBytes compares the number of bytes sent by bloom filter
+// pull gossip with the number of bytes sent by set reconciliation pull gossip.
+func BenchmarkPullGossipBytes(b *testing.B) {
+	for _, setSize := range []int{1024, 8 * 1024} {
+		for _, difference := range []int{16, 256} {
+			var (
+				responder = makeTxs(setSize)
+				requester = responder[difference:]
+			)
+
+			responseSet := &setDouble{}
+			for _, item := range responder {
+				require.NoError(b, responseSet.Add(item))
+			}
+			metrics, err := NewMetrics(prometheus.NewRegistry(), "")
+			require.NoError(b, err)
+			handler := NewHandler[tx](
+				logging.NoLog{},
+				marshaller{},
+				responseSet,
+				metrics,
+				units.MiB,
+				nil,
+			)
+
+			// The bloom filter is sized for the set, as it would be by a
+			// mempool that expects to hold [setSize] items.
+			requestBloomSet, err := NewBloomSet(&setDouble{}, BloomSetConfig{
+				MinTargetElements: setSize,
+			})
+			require.NoError(b, err)
+			for _, item := range requester {
+				require.NoError(b, requestBloomSet.Add(item))
+			}
+			bloomFilter, salt := requestBloomSet.BloomFilter()
+			bloomRequest, err := MarshalAppRequest(bloomFilter.Marshal(), salt[:])
+			require.NoError(b, err)
+
+			// The table is sized as it would be after reconciling a
+			// difference of the same size.
+			table, err := iblt.New(
+				max(reconciliationCellsPerDifference*difference, DefaultMinReconciliationCells),
+				ids.Empty,
+			)
+			require.NoError(b, err)
+			for _, item := range requester {
+				table.Add(reconciliationKey(ids.Empty, item.GossipID()))
+			}
+			reconciliationRequest, err := MarshalReconciliationRequest(table.Marshal(), ids.Empty[:])
+			require.NoError(b, err)
+
+			for _, protocol := range []struct {
+				name    string
+				request []byte
+			}{
+				{
+					name:    "bloom",
+					request: bloomRequest,
+				},
+				{
+					name:    "reconciliation",
+					request: reconciliationRequest,
+				},
+			} {
+				name := fmt.Sprintf("%s/set=%d/difference=%d", protocol.name, setSize, difference)
+				b.Run(name, func(b *testing.B) {
+					var response []byte
+					for b.Loop() {
+						var appErr *common.AppError
+						response, appErr = handler.AppRequest(b.Context(), ids.EmptyNodeID, time.Time{}, protocol.request)
+						require.Nil(b, appErr)
+					}
+					b.ReportMetric(float64(len(protocol.request)), "request-bytes")
+					b.ReportMetric(float64(len(response)), "response-bytes")
+				})
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/units"
@@ -34,11 +35,25 @@ type SystemConfig struct {
 	ThrottlingPeriod time.Duration // Defaults to one hour
 	RequestPeriod    time.Duration // Defaults to one request per second
 
+	// PullReconciliation requests pull gossip by reconciling sets with
+	// invertible bloom lookup tables rather than by sending bloom filters.
+	// Disabled by default.
+	PullReconciliation       bool
+	PullReconciliationConfig ReconciliationConfig
+
 	PushGossipParams   BranchingFactor // Defaults to 100 validators and top 90% of stake
 	PushRegossipParams BranchingFactor // Defaults to 10 validators
 
+	// AdaptiveFanOut scales the push gossip params based on the observed
+	// propagation of gossip. Disabled by default.
+	AdaptiveFanOut AdaptiveFanOutConfig
+
 	DiscardedPushCacheSize int           // Defaults to 16,384
 	RegossipPeriod         time.Duration // Defaults to 30 seconds
+
+	// ReputationTracker lowers the reputation of peers that send gossip that
+	// can't be parsed. Defaults to not tracking reputation.
+	ReputationTracker reputation.Tracker
 }
 
 func (c *SystemConfig) setDefaults() {
@@ -111,6 +126,7 @@ func NewSystem[T Gossipable](
 		set,
 		metrics,
 		c.TargetMessageSize,
+		c.ReputationTracker,
 	)
 
 	requestsPerPeerPerPeriod := float64(c.ThrottlingPeriod / c.RequestPeriod)
@@ -152,14 +168,30 @@ func NewSystem[T Gossipable](
 
 	client := network.NewClient(c.HandlerID, validatorPeers)
 	const pollSize = 1
-	pullGossiper := NewPullGossiper[T](
+	var pullGossiper Gossiper = NewPullGossiper[T](
 		c.Log,
 		marshaller,
 		set,
 		client,
 		metrics,
 		pollSize,
+		c.ReputationTracker,
 	)
+	if c.PullReconciliation {
+		pullGossiper, err = NewReconciliationGossiper[T](
+			c.Log,
+			marshaller,
+			set,
+			client,
+			metrics,
+			pollSize,
+			c.PullReconciliationConfig,
+			c.ReputationTracker,
+		)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
 	pullGossiperWhenValidator := &ValidatorGossiper{
 		Gossiper:   pullGossiper,
 		NodeID:     nodeID,
@@ -174,6 +206,7 @@ func NewSystem[T Gossipable](
 		metrics,
 		c.PushGossipParams,
 		c.PushRegossipParams,
+		c.AdaptiveFanOut,
 		c.DiscardedPushCacheSize,
 		c.TargetMessageSize,
 		c.RegossipPeriod,
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/simnet"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/snow/engine/enginetest"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -53,6 +54,89 @@ func NewClientWithPeers(
 	clientHandler p2p.Handler,
 	peers map[ids.NodeID]p2p.Handler,
 ) *p2p.Client {
+	return newClientWithPeers(t, ctx, clientNodeID, clientHandler, peers, directTransport{}, true)
+}
+
+// NewSimulatedClientWithPeers generates a client to communicate to a set of
+// peers over [network]. Messages are only delivered as the clock of [network]
+// is advanced. Requests whose request or response is dropped fail with
+// [common.ErrTimeout] after [requestTimeout]. Unlike [NewClientWithPeers], the
+// client only samples [peers], so that requests sent to any peer traverse
+// [network].
+func NewSimulatedClientWithPeers(
+	t *testing.T,
+	ctx context.Context,
+	network *simnet.Network,
+	requestTimeout time.Duration,
+	clientNodeID ids.NodeID,
+	clientHandler p2p.Handler,
+	peers map[ids.NodeID]p2p.Handler,
+) *p2p.Client {
+	return newClientWithPeers(
+		t,
+		ctx,
+		clientNodeID,
+		clientHandler,
+		peers,
+		simulatedTransport{
+			network:        network,
+			requestTimeout: requestTimeout,
+		},
+		false,
+	)
+}
+
+// transport delivers messages between the networks of the peers.
+type transport interface {
+	// send executes [deliver] once a message of [numBytes] would arrive at
+	// [to]. Returns false if the message was dropped.
+	send(from, to ids.NodeID, numBytes int, deliver func()) bool
+	// expire executes [onTimeout] once a request that was dropped would have
+	// timed out.
+	expire(onTimeout func())
+}
+
+type directTransport struct{}
+
+func (directTransport) send(_, _ ids.NodeID, _ int, deliver func()) bool {
+	// Send the message asynchronously to avoid deadlock when the server sends
+	// the response back to the client
+	go deliver()
+	return true
+}
+
+func (directTransport) expire(func()) {}
+
+type simulatedTransport struct {
+	network        *simnet.Network
+	requestTimeout time.Duration
+}
+
+func (s simulatedTransport) send(from, to ids.NodeID, numBytes int, deliver func()) bool {
+	return s.network.Send(from, to, numBytes, deliver)
+}
+
+func (s simulatedTransport) expire(onTimeout func()) {
+	s.network.After(s.requestTimeout, onTimeout)
+}
+
+func newClientWithPeers(
+	t *testing.T,
+	ctx context.Context,
+	clientNodeID ids.NodeID,
+	clientHandler p2p.Handler,
+	peers map[ids.NodeID]p2p.Handler,
+	transport transport,
+	sampleSelf bool,
+) *p2p.Client {
+	peerSampler := p2p.PeerSampler{Peers: &p2p.Peers{}}
+	for nodeID := range peers {
+		peerSampler.Peers.Connected(nodeID)
+	}
+	if sampleSelf {
+		peerSampler.Peers.Connected(clientNodeID)
+	}
+
 	peers[clientNodeID] = clientHandler
 
 	peerSenders := make(map[ids.NodeID]*enginetest.Sender)
@@ -69,13 +153,18 @@ func NewClientWithPeers(
 		peerNetworks[nodeID] = peerNetwork
 	}
 
+	// requestFailed notifies the client that a request was dropped.
+	requestFailed := func(ctx context.Context, nodeID ids.NodeID, requestID uint32) {
+		transport.expire(func() {
+			_ = peerNetworks[clientNodeID].AppRequestFailed(ctx, nodeID, requestID, common.ErrTimeout)
+		})
+	}
+
 	peerSenders[clientNodeID].SendAppGossipF = func(ctx context.Context, sendConfig common.SendConfig, gossipBytes []byte) error {
-		// Send the request asynchronously to avoid deadlock when the server
-		// sends the response back to the client
 		for nodeID := range sendConfig.NodeIDs {
-			go func() {
+			transport.send(clientNodeID, nodeID, len(gossipBytes), func() {
 				_ = peerNetworks[nodeID].AppGossip(ctx, nodeID, gossipBytes)
-			}()
+			})
 		}
 
 		return nil
@@ -88,11 +177,12 @@ func NewClientWithPeers(
 				return fmt.Errorf("%s is not connected", nodeID)
 			}
 
-			// Send the request asynchronously to avoid deadlock when the server
-			// sends the response back to the client
-			go func() {
+			sent := transport.send(clientNodeID, nodeID, len(requestBytes), func() {
 				_ = network.AppRequest(ctx, clientNodeID, requestID, time.Time{}, requestBytes)
-			}()
+			})
+			if !sent {
+				requestFailed(ctx, nodeID, requestID)
+			}
 		}
 
 		return nil
@@ -100,11 +190,12 @@ func NewClientWithPeers(
 
 	for nodeID := range peers {
 		peerSenders[nodeID].SendAppResponseF = func(ctx context.Context, _ ids.NodeID, requestID uint32, responseBytes []byte) error {
-			// Send the request asynchronously to avoid deadlock when the server
-			// sends the response back to the client
-			go func() {
+			sent := transport.send(nodeID, clientNodeID, len(responseBytes), func() {
 				_ = peerNetworks[clientNodeID].AppResponse(ctx, nodeID, requestID, responseBytes)
-			}()
+			})
+			if !sent {
+				requestFailed(ctx, nodeID, requestID)
+			}
 
 			return nil
 		}
@@ -112,12 +203,15 @@ func NewClientWithPeers(
 
 	for nodeID := range peers {
 		peerSenders[nodeID].SendAppErrorF = func(ctx context.Context, _ ids.NodeID, requestID uint32, errorCode int32, errorMessage string) error {
-			go func() {
+			sent := transport.send(nodeID, clientNodeID, len(errorMessage), func() {
 				_ = peerNetworks[clientNodeID].AppRequestFailed(ctx, nodeID, requestID, &common.AppError{
 					Code:    errorCode,
 					Message: errorMessage,
 				})
-			}()
+			})
+			if !sent {
+				requestFailed(ctx, nodeID, requestID)
+			}
 
 			return nil
 		}
@@ -129,10 +223,5 @@ func NewClientWithPeers(
 		require.NoError(t, peerNetworks[nodeID].AddHandler(0, peers[nodeID]))
 	}
 
-	peerSampler := p2p.PeerSampler{Peers: &p2p.Peers{}}
-	for nodeID := range peers {
-		peerSampler.Peers.Connected(nodeID)
-	}
-
 	return peerNetworks[clientNodeID].NewClient(0, peerSampler)
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/simnet"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/set"
 )
@@ -119,3 +120,80 @@ func TestClient_AppRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestSimulatedClient_AppRequest(t *testing.T) {
+	tests := []struct {
+		name             string
+		partition        bool
+		expectedResponse []byte
+		expectedErr      error
+		expectedLatency  time.Duration
+	}{
+		{
+			name:             "response",
+			expectedResponse: []byte("foobar"),
+			expectedLatency:  2 * time.Second,
+		},
+		{
+			name:            "partitioned",
+			partition:       true,
+			expectedErr:     common.ErrTimeout,
+			expectedLatency: 10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := t.Context()
+
+			var (
+				network = simnet.New(0, simnet.Link{
+					Latency: time.Second,
+				})
+				clientNodeID = ids.GenerateTestNodeID()
+				serverNodeID = ids.GenerateTestNodeID()
+			)
+			if tt.partition {
+				network.Partition([]ids.NodeID{serverNodeID})
+			}
+
+			client := NewSimulatedClientWithPeers(
+				t,
+				ctx,
+				network,
+				10*time.Second,
+				clientNodeID,
+				p2p.NoOpHandler{},
+				map[ids.NodeID]p2p.Handler{
+					serverNodeID: p2p.TestHandler{
+						AppRequestF: func(context.Context, ids.NodeID, time.Time, []byte) ([]byte, *common.AppError) {
+							return []byte("foobar"), nil
+						},
+					},
+				},
+			)
+
+			var (
+				start     = network.Now()
+				responded bool
+			)
+			require.NoError(client.AppRequest(
+				ctx,
+				set.Of(serverNodeID),
+				[]byte("foo"),
+				func(_ context.Context, _ ids.NodeID, responseBytes []byte, err error) {
+					responded = true
+					require.ErrorIs(err, tt.expectedErr)
+					require.Equal(tt.expectedResponse, responseBytes)
+					require.Equal(tt.expectedLatency, network.Now().Sub(start))
+				},
+			))
+
+			// Messages are only delivered as the clock is advanced.
+			require.False(responded)
+			network.Advance(time.Minute)
+			require.True(responded)
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/utils/heap"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
@@ -33,11 +34,18 @@ const (
 	// The probability that, when we select a peer, we select randomly rather
 	// than based on their performance.
 	randomPeerProbability = 0.2
+
+	// How often peers that weren't trusted are checked to see if their
+	// reputation has recovered.
+	untrustedRecheckFrequency = 10 * time.Second
 )
 
 // Tracks the bandwidth of responses coming from peers,
 // preferring to contact peers with known good bandwidth, connecting
 // to new peers with an exponentially decaying probability.
+//
+// If a reputation tracker is provided, peers that aren't trusted are only
+// selected if no trusted peer can be selected.
 type PeerTracker struct {
 	// Lock to protect concurrent access to the peer tracker
 	lock sync.RWMutex
@@ -57,12 +65,19 @@ type PeerTracker struct {
 	bandwidthHeap heap.Map[ids.NodeID, safemath.Averager]
 	// Average bandwidth is only used for metrics.
 	averageBandwidth safemath.Averager
+	// Peers that we're connected to that weren't trusted when they were last
+	// checked. Peers are checked when they are selected, and peers in this set
+	// are rechecked every [untrustedRecheckFrequency].
+	untrustedPeers set.Set[ids.NodeID]
+	// Last time [untrustedPeers] were rechecked.
+	lastUntrustedRecheck time.Time
 
 	// The below fields are assumed to be constant and are not protected by the
 	// lock.
 	log          logging.Logger
 	ignoredNodes set.Set[ids.NodeID]
 	minVersion   *version.Application
+	reputation   reputation.Tracker
 	metrics      peerTrackerMetrics
 }
 
@@ -78,6 +93,7 @@ func NewPeerTracker(
 	registerer prometheus.Registerer,
 	ignoredNodes set.Set[ids.NodeID],
 	minVersion *version.Application,
+	reputation reputation.Tracker,
 ) (*PeerTracker, error) {
 	t := &PeerTracker{
 		peerBandwidth: make(map[ids.NodeID]safemath.Averager),
@@ -88,6 +104,7 @@ func NewPeerTracker(
 		log:              log,
 		ignoredNodes:     ignoredNodes,
 		minVersion:       minVersion,
+		reputation:       reputation,
 		metrics: peerTrackerMetrics{
 			numTrackedPeers: prometheus.NewGauge(
 				prometheus.GaugeOpts{
@@ -160,13 +177,33 @@ func (p *PeerTracker) shouldSelectUntrackedPeer() bool {
 // With probability [1-randomPeerProbability] returns the peer in
 // [p.bandwidthHeap] with the highest bandwidth.
 //
+// Peers that aren't trusted are skipped unless every peer is untrusted.
+//
 // Returns false if there are no connected peers.
 func (p *PeerTracker) SelectPeer() (ids.NodeID, bool) {
-	p.lock.RLock()
-	defer p.lock.RUnlock()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.recheckUntrustedPeers()
+	for {
+		nodeID, ok := p.selectPeer()
+		if !ok || p.untrustedPeers.Contains(nodeID) || p.isTrusted(nodeID) {
+			return nodeID, ok
+		}
+
+		// The reputation of the peer changed since it was last selected, so
+		// another peer is selected instead. This terminates because the peer
+		// is skipped by later iterations.
+		p.untrustedPeers.Add(nodeID)
+	}
+}
 
+// Selects a peer, preferring peers that aren't in [p.untrustedPeers].
+//
+// Assumes the lock is held.
+func (p *PeerTracker) selectPeer() (ids.NodeID, bool) {
 	if p.shouldSelectUntrackedPeer() {
-		if nodeID, ok := p.untrackedPeers.Peek(); ok {
+		if nodeID, ok := p.peekTrusted(p.untrackedPeers); ok {
 			p.log.Debug("selecting peer",
 				zap.String("reason", "untracked"),
 				zap.Stringer("nodeID", nodeID),
@@ -179,7 +216,7 @@ func (p *PeerTracker) SelectPeer() (ids.NodeID, bool) {
 
 	useBandwidthHeap := rand.Float64() > randomPeerProbability // #nosec G404
 	if useBandwidthHeap {
-		if nodeID, bandwidth, ok := p.bandwidthHeap.Peek(); ok {
+		if nodeID, bandwidth, ok := p.bandwidthHeap.Peek(); ok && !p.untrustedPeers.Contains(nodeID) {
 			p.log.Debug("selecting peer",
 				zap.String("reason", "bandwidth"),
 				zap.Stringer("nodeID", nodeID),
@@ -188,7 +225,7 @@ func (p *PeerTracker) SelectPeer() (ids.NodeID, bool) {
 			return nodeID, true
 		}
 	} else {
-		if nodeID, ok := p.responsivePeers.Peek(); ok {
+		if nodeID, ok := p.peekTrusted(p.responsivePeers); ok {
 			p.log.Debug("selecting peer",
 				zap.String("reason", "responsive"),
 				zap.Stringer("nodeID", nodeID),
@@ -197,7 +234,7 @@ func (p *PeerTracker) SelectPeer() (ids.NodeID, bool) {
 		}
 	}
 
-	if nodeID, ok := p.trackedPeers.Peek(); ok {
+	if nodeID, ok := p.peekTrusted(p.trackedPeers); ok {
 		p.log.Debug("selecting peer",
 			zap.String("reason", "tracked"),
 			zap.Stringer("nodeID", nodeID),
@@ -206,10 +243,59 @@ func (p *PeerTracker) SelectPeer() (ids.NodeID, bool) {
 		return nodeID, true
 	}
 
+	// Fall back to peers that aren't trusted.
+	if nodeID, ok := p.untrustedPeers.Peek(); ok {
+		p.log.Debug("selecting peer",
+			zap.String("reason", "untrusted"),
+			zap.Stringer("nodeID", nodeID),
+		)
+		return nodeID, true
+	}
+
 	// We're not connected to any peers.
 	return ids.EmptyNodeID, false
 }
 
+// Returns true if [nodeID] is trusted by the reputation tracker, if any.
+func (p *PeerTracker) isTrusted(nodeID ids.NodeID) bool {
+	return p.reputation == nil || p.reputation.Trusted(nodeID)
+}
+
+// Returns a peer in [peers] that isn't in [p.untrustedPeers], if any. Only
+// the untrusted peers are skipped, so this doesn't iterate over all of
+// [peers].
+//
+// Assumes the lock is held.
+func (p *PeerTracker) peekTrusted(peers set.Set[ids.NodeID]) (ids.NodeID, bool) {
+	if p.untrustedPeers.Len() == 0 {
+		return peers.Peek()
+	}
+	for nodeID := range peers {
+		if !p.untrustedPeers.Contains(nodeID) {
+			return nodeID, true
+		}
+	}
+	return ids.EmptyNodeID, false
+}
+
+// Removes the peers in [p.untrustedPeers] that are trusted again, at most once
+// every [untrustedRecheckFrequency].
+//
+// Assumes the lock is held.
+func (p *PeerTracker) recheckUntrustedPeers() {
+	now := time.Now()
+	if p.untrustedPeers.Len() == 0 || now.Sub(p.lastUntrustedRecheck) < untrustedRecheckFrequency {
+		return
+	}
+	p.lastUntrustedRecheck = now
+
+	for nodeID := range p.untrustedPeers {
+		if p.isTrusted(nodeID) {
+			p.untrustedPeers.Remove(nodeID)
+		}
+	}
+}
+
 // Record that we sent a request to [nodeID].
 //
 // Removes the peer's bandwidth averager from the bandwidth heap.
@@ -228,6 +314,9 @@ func (p *PeerTracker) RegisterRequest(nodeID ids.NodeID) {
 //
 // Adds the peer's bandwidth averager to the bandwidth heap.
 func (p *PeerTracker) RegisterResponse(nodeID ids.NodeID, bandwidth float64) {
+	if p.reputation != nil {
+		p.reputation.RegisterBandwidth(nodeID, bandwidth)
+	}
 	p.updateBandwidth(nodeID, bandwidth, true)
 }
 
@@ -301,6 +390,7 @@ func (p *PeerTracker) Disconnected(nodeID ids.NodeID) {
 	p.untrackedPeers.Remove(nodeID)
 	p.trackedPeers.Remove(nodeID)
 	p.responsivePeers.Remove(nodeID)
+	p.untrustedPeers.Remove(nodeID)
 	delete(p.peerBandwidth, nodeID)
 	p.bandwidthHeap.Remove(nodeID)
 
@@ -5,12 +5,15 @@ package p2p
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/version"
 )
 
@@ -22,6 +25,7 @@ func TestPeerTracker(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		nil,
+		nil,
 	)
 	require.NoError(err)
 
@@ -102,3 +106,101 @@ func TestPeerTracker(t *testing.T) {
 	require.True(ok)
 	require.Falsef(responsive, "expected connecting to a non-responsive peer, but got a peer that was responsive: peer %s", peer)
 }
+
+func TestPeerTrackerReputation(t *testing.T) {
+	require := require.New(t)
+
+	reputationTracker, err := reputation.NewTracker(reputation.DefaultConfig, prometheus.NewRegistry())
+	require.NoError(err)
+	p, err := NewPeerTracker(
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+		nil,
+		nil,
+		reputationTracker,
+	)
+	require.NoError(err)
+
+	var (
+		trusted   = ids.GenerateTestNodeID()
+		untrusted = ids.GenerateTestNodeID()
+	)
+	p.Connected(trusted, version.Current)
+	p.Connected(untrusted, version.Current)
+	for reputationTracker.Trusted(untrusted) {
+		reputationTracker.Register(untrusted, reputation.InvalidMessage)
+	}
+
+	// Untrusted peers aren't selected while a trusted peer is available.
+	for range 10 {
+		nodeID, ok := p.SelectPeer()
+		require.True(ok)
+		require.Equal(trusted, nodeID)
+
+		p.RegisterRequest(nodeID)
+		p.RegisterResponse(nodeID, 10)
+	}
+
+	// Untrusted peers are selected if no other peer is available.
+	p.Disconnected(trusted)
+	nodeID, ok := p.SelectPeer()
+	require.True(ok)
+	require.Equal(untrusted, nodeID)
+}
+
+func TestPeerTrackerReputationRecovers(t *testing.T) {
+	require := require.New(t)
+
+	reputationTracker := &testReputationTracker{
+		untrusted: set.Set[ids.NodeID]{},
+	}
+	p, err := NewPeerTracker(
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+		nil,
+		nil,
+		reputationTracker,
+	)
+	require.NoError(err)
+
+	nodeID := ids.GenerateTestNodeID()
+	p.Connected(nodeID, version.Current)
+	reputationTracker.untrusted.Add(nodeID)
+
+	selected, ok := p.SelectPeer()
+	require.True(ok)
+	require.Equal(nodeID, selected)
+	require.True(p.untrustedPeers.Contains(nodeID))
+
+	// The peer is only rechecked once [untrustedRecheckFrequency] has passed.
+	p.lastUntrustedRecheck = time.Now()
+	reputationTracker.untrusted.Remove(nodeID)
+	_, _ = p.SelectPeer()
+	require.True(p.untrustedPeers.Contains(nodeID))
+
+	p.lastUntrustedRecheck = time.Time{}
+	_, _ = p.SelectPeer()
+	require.False(p.untrustedPeers.Contains(nodeID))
+}
+
+type testReputationTracker struct {
+	untrusted set.Set[ids.NodeID]
+}
+
+func (*testReputationTracker) Register(ids.NodeID, reputation.Event) {}
+
+func (*testReputationTracker) RegisterBandwidth(ids.NodeID, float64) {}
+
+func (*testReputationTracker) Score(ids.NodeID) float64 {
+	return 0
+}
+
+func (t *testReputationTracker) Trusted(nodeID ids.NodeID) bool {
+	return !t.untrusted.Contains(nodeID)
+}
+
+func (t *testReputationTracker) Admissible(nodeID ids.NodeID) bool {
+	return t.Trusted(nodeID)
+}
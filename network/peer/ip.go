@@ -6,6 +6,7 @@ package peer
 import (
 	"crypto"
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
@@ -21,6 +22,7 @@ import (
 var (
 	errTimestampTooFarInFuture = errors.New("timestamp too far in the future")
 	errInvalidTLSSignature     = errors.New("invalid TLS signature")
+	errInvalidQUICSignature    = errors.New("invalid QUIC signature")
 )
 
 // UnsignedIP is used for a validator to claim an IP. The [Timestamp] is used to
@@ -29,6 +31,9 @@ var (
 type UnsignedIP struct {
 	AddrPort  netip.AddrPort
 	Timestamp uint64
+	// QUICPort is the UDP port that QUIC connections are accepted on, on the
+	// same address as [AddrPort]. Zero if QUIC isn't supported.
+	QUICPort uint16
 }
 
 // Sign this IP with the provided signer and return the signed IP.
@@ -48,11 +53,26 @@ func (ip *UnsignedIP) Sign(tlsSigner crypto.Signer, blsSigner bls.Signer) (*Sign
 		return nil, err
 	}
 
+	// The QUIC port is signed separately so that the signatures of nodes
+	// that support QUIC can still be verified by nodes that don't.
+	var quicSignature []byte
+	if ip.QUICPort != 0 {
+		quicSignature, err = tlsSigner.Sign(
+			rand.Reader,
+			hashing.ComputeHash256(ip.quicBytes()),
+			crypto.SHA256,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &SignedIP{
 		UnsignedIP:        *ip,
 		TLSSignature:      tlsSignature,
 		BLSSignature:      blsSignature,
 		BLSSignatureBytes: bls.SignatureToBytes(blsSignature),
+		QUICSignature:     quicSignature,
 	}, nil
 }
 
@@ -67,17 +87,26 @@ func (ip *UnsignedIP) bytes() []byte {
 	return p.Bytes
 }
 
+func (ip *UnsignedIP) quicBytes() []byte {
+	return binary.BigEndian.AppendUint16(ip.bytes(), ip.QUICPort)
+}
+
 // SignedIP is a wrapper of an UnsignedIP with the signature from a signer.
 type SignedIP struct {
 	UnsignedIP
 	TLSSignature      []byte
 	BLSSignature      *bls.Signature
 	BLSSignatureBytes []byte
+	// QUICSignature is the signature over [UnsignedIP] including the QUIC
+	// port. Nil if the QUIC port isn't set.
+	QUICSignature []byte
 }
 
 // Returns nil if:
 // * [ip.Timestamp] is not after [maxTimestamp].
 // * [ip.TLSSignature] is a valid signature over [ip.UnsignedIP] from [cert].
+// * [ip.QUICSignature] is a valid signature over [ip.UnsignedIP], including
+// the QUIC port, from [cert] if the QUIC port is set.
 func (ip *SignedIP) Verify(
 	cert *staking.Certificate,
 	maxTimestamp time.Time,
@@ -94,5 +123,16 @@ func (ip *SignedIP) Verify(
 	); err != nil {
 		return fmt.Errorf("%w: %w", errInvalidTLSSignature, err)
 	}
+
+	if ip.QUICPort == 0 {
+		return nil
+	}
+	if err := staking.CheckSignature(
+		cert,
+		ip.UnsignedIP.quicBytes(),
+		ip.QUICSignature,
+	); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidQUICSignature, err)
+	}
 	return nil
 }
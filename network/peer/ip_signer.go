@@ -16,6 +16,7 @@ import (
 // IPSigner will return a signedIP for the current value of our dynamic IP.
 type IPSigner struct {
 	ip        *utils.Atomic[netip.AddrPort]
+	quicPort  uint16
 	clock     mockable.Clock
 	tlsSigner crypto.Signer
 	blsSigner bls.Signer
@@ -27,13 +28,17 @@ type IPSigner struct {
 	signedIP *SignedIP
 }
 
+// NewIPSigner returns a signer of [ip]. If [quicPort] is non-zero, it is
+// signed along with [ip] to advertise QUIC support.
 func NewIPSigner(
 	ip *utils.Atomic[netip.AddrPort],
+	quicPort uint16,
 	tlsSigner crypto.Signer,
 	blsSigner bls.Signer,
 ) *IPSigner {
 	return &IPSigner{
 		ip:        ip,
+		quicPort:  quicPort,
 		tlsSigner: tlsSigner,
 		blsSigner: blsSigner,
 	}
@@ -71,6 +76,7 @@ func (s *IPSigner) GetSignedIP() (*SignedIP, error) {
 	unsignedIP := UnsignedIP{
 		AddrPort:  ip,
 		Timestamp: s.clock.Unix(),
+		QUICPort:  s.quicPort,
 	}
 	signedIP, err := unsignedIP.Sign(s.tlsSigner, s.blsSigner)
 	if err != nil {
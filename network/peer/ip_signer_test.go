@@ -31,7 +31,7 @@ func TestIPSigner(t *testing.T) {
 	blsKey, err := localsigner.New()
 	require.NoError(err)
 
-	s := NewIPSigner(dynIP, tlsKey, blsKey)
+	s := NewIPSigner(dynIP, 0, tlsKey, blsKey)
 
 	s.clock.Set(time.Unix(10, 0))
 
@@ -106,3 +106,39 @@ func TestSignedIpVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedIPVerifyQUIC(t *testing.T) {
+	require := require.New(t)
+
+	tlsCert, err := staking.NewTLSCert()
+	require.NoError(err)
+	cert, err := staking.ParseCertificate(tlsCert.Leaf.Raw)
+	require.NoError(err)
+	blsKey, err := localsigner.New()
+	require.NoError(err)
+
+	now := time.Now()
+	ip := UnsignedIP{
+		AddrPort: netip.AddrPortFrom(
+			netip.AddrFrom4([4]byte{1, 2, 3, 4}),
+			1,
+		),
+		Timestamp: uint64(now.Unix()),
+		QUICPort:  2,
+	}
+	signedIP, err := ip.Sign(tlsCert.PrivateKey.(crypto.Signer), blsKey)
+	require.NoError(err)
+	require.NoError(signedIP.Verify(cert, now))
+
+	// Nodes that don't support QUIC ignore the QUIC port and signature.
+	legacyIP := *signedIP
+	legacyIP.QUICPort = 0
+	legacyIP.QUICSignature = nil
+	require.NoError(legacyIP.Verify(cert, now))
+
+	// The QUIC port can't be modified.
+	modifiedIP := *signedIP
+	modifiedIP.QUICPort = 3
+	err = modifiedIP.Verify(cert, now)
+	require.ErrorIs(err, errInvalidQUICSignature)
+}
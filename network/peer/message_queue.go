@@ -6,7 +6,9 @@ package peer
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -51,8 +53,40 @@ type MessageQueue interface {
 	Close()
 }
 
+// queuedMessage is a message in a throttledMessageQueue.
+type queuedMessage struct {
+	msg    *message.OutboundMessage
+	pushed time.Time
+}
+
+// priorityQueue is the queue of messages of a single priority.
+type priorityQueue struct {
+	weight uint64
+	// pass is the virtual time at which the next message of this priority
+	// starts being sent.
+	pass  float64
+	bytes int
+	queue buffer.Deque[queuedMessage]
+
+	numMsgs   prometheus.Gauge
+	numBytes  prometheus.Gauge
+	waitCount prometheus.Counter
+	waitSum   prometheus.Counter
+}
+
+// cost returns the virtual time it takes to send [msg].
+func (pq *priorityQueue) cost(msg *message.OutboundMessage) float64 {
+	return float64(max(len(msg.Bytes), 1)) / float64(pq.weight)
+}
+
+// throttledMessageQueue maintains a queue per Priority. Messages of the same
+// priority are popped in the order they were pushed. Messages of different
+// priorities are popped by weighted fair queuing, so that each priority with
+// queued messages is given a share of the bandwidth proportional to its
+// weight.
 type throttledMessageQueue struct {
 	onFailed SendFailedCallback
+	metrics  *Metrics
 	// [id] of the peer we're sending messages to
 	id                   ids.NodeID
 	log                  logging.Logger
@@ -66,25 +100,48 @@ type throttledMessageQueue struct {
 	// [cond.L] must be held while accessing [closed].
 	closed bool
 
-	// queue of the messages
-	// [cond.L] must be held while accessing [queue].
-	queue buffer.Deque[*message.OutboundMessage]
+	// [cond.L] must be held while accessing the fields below.
+	numMsgs int
+	// virtual time of the last popped message
+	vtime  float64
+	queues [numPriorities]*priorityQueue
 }
 
+// NewThrottledMessageQueue returns a queue that acquires space from
+// [outboundMsgThrottler] for every pushed message and schedules the messages
+// by the priority of their op, using [weights]. The size of the queue and the
+// time messages wait in it are reported to [metrics].
 func NewThrottledMessageQueue(
 	onFailed SendFailedCallback,
 	id ids.NodeID,
 	log logging.Logger,
 	outboundMsgThrottler throttling.OutboundMsgThrottler,
+	metrics *Metrics,
+	weights PriorityWeights,
 ) MessageQueue {
-	return &throttledMessageQueue{
+	q := &throttledMessageQueue{
 		onFailed:             onFailed,
+		metrics:              metrics,
 		id:                   id,
 		log:                  log,
 		outboundMsgThrottler: outboundMsgThrottler,
 		cond:                 sync.NewCond(&sync.Mutex{}),
-		queue:                buffer.NewUnboundedDeque[*message.OutboundMessage](initialQueueSize),
 	}
+	for i := range q.queues {
+		priority := Priority(i)
+		labels := prometheus.Labels{
+			priorityLabel: priority.String(),
+		}
+		q.queues[i] = &priorityQueue{
+			weight:    weights.weight(priority),
+			queue:     buffer.NewUnboundedDeque[queuedMessage](initialQueueSize),
+			numMsgs:   metrics.SendQueueMessages.With(labels),
+			numBytes:  metrics.SendQueueBytes.With(labels),
+			waitCount: metrics.SendQueueWaitCount.With(labels),
+			waitSum:   metrics.SendQueueWaitSum.With(labels),
+		}
+	}
+	return q
 }
 
 func (q *throttledMessageQueue) Push(ctx context.Context, msg *message.OutboundMessage) bool {
@@ -130,7 +187,20 @@ func (q *throttledMessageQueue) Push(ctx context.Context, msg *message.OutboundM
 		return false
 	}
 
-	q.queue.PushRight(msg)
+	pq := q.queues[PriorityOf(msg.Op)]
+	if pq.queue.Len() == 0 {
+		// A priority that had no queued messages must not be able to use the
+		// bandwidth it didn't use while it was idle.
+		pq.pass = max(pq.pass, q.vtime)
+	}
+	pq.queue.PushRight(queuedMessage{
+		msg:    msg,
+		pushed: time.Now(),
+	})
+	pq.bytes += len(msg.Bytes)
+	pq.numMsgs.Inc()
+	pq.numBytes.Add(float64(len(msg.Bytes)))
+	q.numMsgs++
 	q.cond.Signal()
 	return true
 }
@@ -143,7 +213,7 @@ func (q *throttledMessageQueue) Pop() (*message.OutboundMessage, bool) {
 		if q.closed {
 			return nil, false
 		}
-		if q.queue.Len() > 0 {
+		if q.numMsgs > 0 {
 			// There is a message
 			break
 		}
@@ -158,7 +228,7 @@ func (q *throttledMessageQueue) PopNow() (*message.OutboundMessage, bool) {
 	q.cond.L.Lock()
 	defer q.cond.L.Unlock()
 
-	if q.closed || q.queue.Len() == 0 {
+	if q.closed || q.numMsgs == 0 {
 		// There isn't a message
 		return nil, false
 	}
@@ -166,8 +236,45 @@ func (q *throttledMessageQueue) PopNow() (*message.OutboundMessage, bool) {
 	return q.pop(), true
 }
 
+// pop assumes that [cond.L] is held and that there is a message.
 func (q *throttledMessageQueue) pop() *message.OutboundMessage {
-	msg, _ := q.queue.PopLeft()
+	// Pop from the priority whose next message finishes being sent the
+	// earliest. Ties are broken in favor of the higher priority.
+	var (
+		next       *priorityQueue
+		nextFinish float64
+	)
+	for _, pq := range q.queues {
+		head, ok := pq.queue.PeekLeft()
+		if !ok {
+			continue
+		}
+		finish := pq.pass + pq.cost(head.msg)
+		if next == nil || finish < nextFinish {
+			next = pq
+			nextFinish = finish
+		}
+	}
+
+	queued, _ := next.queue.PopLeft()
+	msg := queued.msg
+	msgLen := len(msg.Bytes)
+	q.vtime = next.pass
+	next.pass = nextFinish
+	next.bytes -= msgLen
+	next.numMsgs.Dec()
+	next.numBytes.Sub(float64(msgLen))
+	next.waitCount.Inc()
+	next.waitSum.Add(float64(time.Since(queued.pushed).Milliseconds()))
+
+	q.numMsgs--
+	if q.numMsgs == 0 {
+		// Nothing is queued, so the virtual time can be restarted.
+		q.vtime = 0
+		for _, pq := range q.queues {
+			pq.pass = 0
+		}
+	}
 
 	q.outboundMsgThrottler.Release(msg, q.id)
 	return msg
@@ -183,12 +290,18 @@ func (q *throttledMessageQueue) Close() {
 
 	q.closed = true
 
-	for q.queue.Len() > 0 {
-		msg, _ := q.queue.PopLeft()
-		q.outboundMsgThrottler.Release(msg, q.id)
-		q.onFailed.SendFailed(msg)
+	for _, pq := range q.queues {
+		pq.numMsgs.Sub(float64(pq.queue.Len()))
+		for pq.queue.Len() > 0 {
+			queued, _ := pq.queue.PopLeft()
+			q.outboundMsgThrottler.Release(queued.msg, q.id)
+			q.onFailed.SendFailed(queued.msg)
+		}
+		pq.queue = nil
+		pq.numBytes.Sub(float64(pq.bytes))
+		pq.bytes = 0
 	}
-	q.queue = nil
+	q.numMsgs = 0
 
 	q.cond.Broadcast()
 }
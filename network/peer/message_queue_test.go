@@ -7,9 +7,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/utils/logging"
 )
 
@@ -73,3 +77,122 @@ func TestMessageQueue(t *testing.T) {
 	_, ok = q.Pop()
 	require.False(ok)
 }
+
+func newTestThrottledMessageQueue(t *testing.T, weights PriorityWeights) (MessageQueue, *Metrics) {
+	t.Helper()
+
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+	return NewThrottledMessageQueue(
+		metrics,
+		ids.EmptyNodeID,
+		logging.NoLog{},
+		throttling.NewNoOutboundThrottler(),
+		metrics,
+		weights,
+	), metrics
+}
+
+func newTestMessage(op message.Op, size int) *message.OutboundMessage {
+	return &message.OutboundMessage{
+		Op:    op,
+		Bytes: make([]byte, size),
+	}
+}
+
+func TestThrottledMessageQueuePriority(t *testing.T) {
+	require := require.New(t)
+
+	q, metrics := newTestThrottledMessageQueue(t, DefaultPriorityWeights)
+
+	ancestors := newTestMessage(message.AncestorsOp, 1024)
+	appResponse := newTestMessage(message.AppResponseOp, 1024)
+	chits := newTestMessage(message.ChitsOp, 64)
+	pushQuery := newTestMessage(message.PushQueryOp, 64)
+	for _, msg := range []*message.OutboundMessage{ancestors, appResponse, chits, pushQuery} {
+		require.True(q.Push(t.Context(), msg))
+	}
+
+	bootstrapLabels := prometheus.Labels{priorityLabel: BootstrapPriority.String()}
+	consensusLabels := prometheus.Labels{priorityLabel: ConsensusPriority.String()}
+	require.Equal(float64(2), testutil.ToFloat64(metrics.SendQueueMessages.With(consensusLabels)))
+	require.Equal(float64(128), testutil.ToFloat64(metrics.SendQueueBytes.With(consensusLabels)))
+	require.Equal(float64(1024), testutil.ToFloat64(metrics.SendQueueBytes.With(bootstrapLabels)))
+
+	// Consensus messages are sent before the large messages that were queued
+	// earlier, and messages of the same priority keep their order.
+	for _, expected := range []*message.OutboundMessage{chits, pushQuery, appResponse, ancestors} {
+		msg, ok := q.PopNow()
+		require.True(ok)
+		require.Equal(expected, msg)
+	}
+
+	_, ok := q.PopNow()
+	require.False(ok)
+	require.Zero(testutil.ToFloat64(metrics.SendQueueMessages.With(consensusLabels)))
+	require.Zero(testutil.ToFloat64(metrics.SendQueueBytes.With(bootstrapLabels)))
+	require.Equal(float64(2), testutil.ToFloat64(metrics.SendQueueWaitCount.With(consensusLabels)))
+}
+
+func TestThrottledMessageQueueNoStarvation(t *testing.T) {
+	require := require.New(t)
+
+	q, _ := newTestThrottledMessageQueue(t, PriorityWeights{
+		Consensus: 4,
+		Gossip:    1,
+		Bootstrap: 1,
+		App:       1,
+	})
+
+	const numMsgs = 20
+	for range numMsgs {
+		require.True(q.Push(t.Context(), newTestMessage(message.AncestorsOp, 100)))
+		require.True(q.Push(t.Context(), newTestMessage(message.ChitsOp, 100)))
+	}
+
+	// While both priorities have queued messages, 4 consensus messages are
+	// sent for every bootstrap message.
+	counts := make(map[message.Op]int)
+	for range 10 {
+		msg, ok := q.PopNow()
+		require.True(ok)
+		counts[msg.Op]++
+	}
+	require.Equal(map[message.Op]int{
+		message.ChitsOp:     8,
+		message.AncestorsOp: 2,
+	}, counts)
+}
+
+func TestThrottledMessageQueueClose(t *testing.T) {
+	require := require.New(t)
+
+	q, metrics := newTestThrottledMessageQueue(t, DefaultPriorityWeights)
+
+	msg := newTestMessage(message.AppRequestOp, 10)
+	require.True(q.Push(t.Context(), msg))
+	require.True(q.Push(t.Context(), msg))
+
+	// Closing the queue drops the queued messages
+	q.Close()
+	require.False(q.Push(t.Context(), msg))
+	_, ok := q.Pop()
+	require.False(ok)
+
+	appLabels := prometheus.Labels{priorityLabel: AppPriority.String()}
+	require.Zero(testutil.ToFloat64(metrics.SendQueueMessages.With(appLabels)))
+	require.Zero(testutil.ToFloat64(metrics.SendQueueBytes.With(appLabels)))
+	require.Equal(float64(3), testutil.ToFloat64(metrics.NumSendFailed.With(prometheus.Labels{
+		opLabel: message.AppRequestOp.String(),
+	})))
+}
+
+func TestPriorityWeightsVerify(t *testing.T) {
+	require := require.New(t)
+
+	weights := DefaultPriorityWeights
+	require.NoError(weights.Verify())
+
+	weights.Bootstrap = 0
+	require.ErrorIs(weights.Verify(), errZeroPriorityWeight)
+}
@@ -15,6 +15,7 @@ import (
 const (
 	ioLabel         = "io"
 	opLabel         = "op"
+	priorityLabel   = "priority"
 	compressedLabel = "compressed"
 
 	sentLabel     = "sent"
@@ -23,6 +24,7 @@ const (
 
 var (
 	opLabels             = []string{opLabel}
+	priorityLabels       = []string{priorityLabel}
 	ioOpLabels           = []string{ioLabel, opLabel}
 	ioOpCompressedLabels = []string{ioLabel, opLabel, compressedLabel}
 )
@@ -40,6 +42,11 @@ type Metrics struct {
 	Messages   *prometheus.CounterVec // io + op + compressed
 	Bytes      *prometheus.CounterVec // io + op
 	BytesSaved *prometheus.GaugeVec   // io + op
+
+	SendQueueMessages  *prometheus.GaugeVec   // priority
+	SendQueueBytes     *prometheus.GaugeVec   // priority
+	SendQueueWaitCount *prometheus.CounterVec // priority
+	SendQueueWaitSum   *prometheus.CounterVec // priority
 }
 
 func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
@@ -92,6 +99,34 @@ func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
 			},
 			ioOpLabels,
 		),
+		SendQueueMessages: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "send_queue_msgs",
+				Help: "number of messages queued to be sent",
+			},
+			priorityLabels,
+		),
+		SendQueueBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "send_queue_bytes",
+				Help: "number of message bytes queued to be sent",
+			},
+			priorityLabels,
+		),
+		SendQueueWaitCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "send_queue_wait_count",
+				Help: "number of messages removed from the send queue (n)",
+			},
+			priorityLabels,
+		),
+		SendQueueWaitSum: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "send_queue_wait_sum",
+				Help: "sum of the time messages spent in the send queue (ms)",
+			},
+			priorityLabels,
+		),
 	}
 	return m, errors.Join(
 		registerer.Register(m.RTTCount),
@@ -103,27 +138,31 @@ func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
 		registerer.Register(m.Messages),
 		registerer.Register(m.Bytes),
 		registerer.Register(m.BytesSaved),
+		registerer.Register(m.SendQueueMessages),
+		registerer.Register(m.SendQueueBytes),
+		registerer.Register(m.SendQueueWaitCount),
+		registerer.Register(m.SendQueueWaitSum),
 	)
 }
 
-// Sent updates the metrics for having sent [msg].
-func (m *Metrics) Sent(msg *message.OutboundMessage) {
-	op := msg.Op.String()
-	saved := msg.BytesSavedCompression
-	compressed := saved != 0 // assume that if [saved] == 0, [msg] wasn't compressed
+// Sent updates the metrics for having sent a message with [op] of [numBytes]
+// bytes, which saved [saved] bytes due to being compressed.
+func (m *Metrics) Sent(op message.Op, numBytes uint32, saved int) {
+	opStr := op.String()
+	compressed := saved != 0 // assume that if [saved] == 0, the message wasn't compressed
 	compressedStr := strconv.FormatBool(compressed)
 
 	m.Messages.With(prometheus.Labels{
 		ioLabel:         sentLabel,
-		opLabel:         op,
+		opLabel:         opStr,
 		compressedLabel: compressedStr,
 	}).Inc()
 
 	bytesLabel := prometheus.Labels{
 		ioLabel: sentLabel,
-		opLabel: op,
+		opLabel: opStr,
 	}
-	m.Bytes.With(bytesLabel).Add(float64(len(msg.Bytes)))
+	m.Bytes.With(bytesLabel).Add(float64(numBytes))
 	m.BytesSaved.With(bytesLabel).Add(float64(saved))
 }
 
@@ -144,7 +183,7 @@ func (m *Metrics) SendFailed(msg *message.OutboundMessage) {
 func (m *Metrics) Received(msg *message.InboundMessage, msgLen uint32) {
 	op := msg.Op.String()
 	saved := msg.BytesSavedCompression
-	compressed := saved != 0 // assume that if [saved] == 0, [msg] wasn't compressed
+	compressed := saved != 0 // assume that if [saved] == 0, the message wasn't compressed
 	compressedStr := strconv.FormatBool(compressed)
 
 	m.Messages.With(prometheus.Labels{
@@ -19,8 +19,10 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/proto/pb/p2p"
 	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/bloom"
 	"github.com/ava-labs/avalanchego/utils/constants"
@@ -49,6 +51,12 @@ const (
 
 var errClosed = errors.New("closed")
 
+// bulkConn is implemented by connections that can send large messages on a
+// separate stream, so that they don't delay the other messages.
+type bulkConn interface {
+	BulkWriter() io.Writer
+}
+
 // Peer encapsulates all of the functionality required to send and receive
 // messages with a remote peer.
 type Peer struct {
@@ -93,6 +101,14 @@ type Peer struct {
 	// Our primary network uptime perceived by the peer
 	observedUptime utils.Atomic[uint32]
 
+	// compression the peer advertised in its Handshake. Nil until the
+	// Handshake is received.
+	compression utils.Atomic[*message.PeerCompression]
+
+	// tracePropagation is true if both this node and the peer propagate trace
+	// context in AppRequest and AppResponse messages.
+	tracePropagation utils.Atomic[bool]
+
 	// True if this peer has sent us a valid Handshake message and
 	// is running a compatible version.
 	// Only modified on the connection's reader routine.
@@ -271,7 +287,23 @@ func (p *Peer) ObservedUptime() uint32 {
 // for reference counting. This returns false if the message is guaranteed not
 // to be delivered to the peer.
 func (p *Peer) Send(ctx context.Context, msg *message.OutboundMessage) bool {
-	return p.messageQueue.Push(ctx, msg)
+	if !p.tracePropagation.Get() {
+		msg = msg.WithoutTraceContext()
+	}
+
+	// The message is compressed before it is queued so that the outbound
+	// throttler accounts for the bytes that are sent to the peer.
+	encoded, err := msg.EncodedFor(p.compression.Get())
+	if err != nil {
+		p.Log.Verbo("error compressing message",
+			zap.Stringer("op", msg.Op),
+			zap.Stringer("nodeID", p.id),
+			zap.Error(err),
+		)
+		p.Metrics.SendFailed(msg)
+		return false
+	}
+	return p.messageQueue.Push(ctx, encoded)
 }
 
 // StartSendGetPeerList attempts to send a GetPeerList message to this peer
@@ -451,6 +483,7 @@ func (p *Peer) readMessages() {
 			)
 
 			p.Metrics.NumFailedToParse.Inc()
+			p.ReputationTracker.Register(p.id, reputation.InvalidMessage)
 
 			// Couldn't parse the message. Read the next one.
 			onFinishedHandling()
@@ -476,6 +509,17 @@ func (p *Peer) writeMessages() {
 	}()
 
 	writer := bufio.NewWriterSize(p.conn, p.Config.WriteBufferSize)
+	bulkWriter := writer
+	if conn, ok := p.conn.(bulkConn); ok {
+		bulkWriter = bufio.NewWriterSize(conn.BulkWriter(), p.Config.WriteBufferSize)
+	}
+	writerFor := func(msg *message.OutboundMessage) io.Writer {
+		// Ancestors messages are large bootstrapping responses.
+		if msg.Op == message.AncestorsOp {
+			return bulkWriter
+		}
+		return writer
+	}
 
 	// Make sure that the Handshake is the first message sent
 	mySignedIP, err := p.IPSigner.GetSignedIP()
@@ -511,12 +555,15 @@ func (p *Peer) writeMessages() {
 		mySignedIP.Timestamp,
 		mySignedIP.TLSSignature,
 		mySignedIP.BLSSignatureBytes,
+		mySignedIP.QUICPort,
+		mySignedIP.QUICSignature,
 		p.MySubnets.List(),
 		p.SupportedACPs,
 		p.ObjectedACPs,
 		knownPeersFilter,
 		knownPeersSalt,
 		requestAllSubnetIPs,
+		p.TracePropagation,
 	)
 	if err != nil {
 		p.Log.Error(failedToCreateMessageLog,
@@ -532,13 +579,13 @@ func (p *Peer) writeMessages() {
 	for {
 		msg, ok := p.messageQueue.PopNow()
 		if ok {
-			p.writeMessage(writer, msg)
+			p.writeMessage(writerFor(msg), msg)
 			continue
 		}
 
 		// Make sure the peer was fully sent all prior messages before
 		// blocking.
-		if err := writer.Flush(); err != nil {
+		if err := errors.Join(writer.Flush(), bulkWriter.Flush()); err != nil {
 			p.Log.Verbo("failed to flush writer",
 				zap.Stringer("nodeID", p.id),
 				zap.Error(err),
@@ -552,12 +599,22 @@ func (p *Peer) writeMessages() {
 			return
 		}
 
-		p.writeMessage(writer, msg)
+		p.writeMessage(writerFor(msg), msg)
 	}
 }
 
 func (p *Peer) writeMessage(writer io.Writer, msg *message.OutboundMessage) {
-	msgBytes := msg.Bytes
+	msgBytes, bytesSaved, err := msg.BytesFor(p.compression.Get())
+	if err != nil {
+		p.Log.Verbo("error compressing message",
+			zap.Stringer("op", msg.Op),
+			zap.Stringer("nodeID", p.id),
+			zap.Error(err),
+		)
+		p.Metrics.SendFailed(msg)
+		return
+	}
+
 	p.Log.Verbo("sending message",
 		zap.Stringer("op", msg.Op),
 		zap.Stringer("nodeID", p.id),
@@ -570,6 +627,7 @@ func (p *Peer) writeMessage(writer io.Writer, msg *message.OutboundMessage) {
 			zap.String("direction", "write"),
 			zap.Error(err),
 		)
+		p.Metrics.SendFailed(msg)
 		return
 	}
 
@@ -580,6 +638,7 @@ func (p *Peer) writeMessage(writer io.Writer, msg *message.OutboundMessage) {
 			zap.Stringer("nodeID", p.id),
 			zap.Error(err),
 		)
+		p.Metrics.SendFailed(msg)
 		return
 	}
 
@@ -594,12 +653,13 @@ func (p *Peer) writeMessage(writer io.Writer, msg *message.OutboundMessage) {
 			zap.Stringer("nodeID", p.id),
 			zap.Error(err),
 		)
+		p.Metrics.SendFailed(msg)
 		return
 	}
 
 	now := p.Clock.Time()
 	p.storeLastSent(now)
-	p.Metrics.Sent(msg)
+	p.Metrics.Sent(msg.Op, msgLen, bytesSaved)
 }
 
 func (p *Peer) sendNetworkMessages() {
@@ -746,7 +806,11 @@ func (p *Peer) handle(msg *message.InboundMessage) {
 	}
 
 	// Consensus and app-level messages
-	p.Router.HandleInbound(context.Background(), msg)
+	ctx := context.Background()
+	if p.tracePropagation.Get() {
+		ctx = trace.Extract(ctx, message.GetTraceContext(msg.Message))
+	}
+	p.Router.HandleInbound(ctx, msg)
 }
 
 func (p *Peer) handlePing(msg *p2p.Ping) {
@@ -757,6 +821,7 @@ func (p *Peer) handlePing(msg *p2p.Ping) {
 			zap.Stringer("subnetID", constants.PrimaryNetworkID),
 			zap.Uint32("uptime", msg.Uptime),
 		)
+		p.ReputationTracker.Register(p.id, reputation.InvalidMessage)
 		p.StartClose()
 		return
 	}
@@ -801,6 +866,7 @@ func (p *Peer) handlePong(*p2p.Pong) {
 			zap.Stringer("messageOp", message.PongOp),
 			zap.String("reason", "received unexpected pong"),
 		)
+		p.ReputationTracker.Register(p.id, reputation.InvalidMessage)
 		p.StartClose()
 		return
 	}
@@ -818,10 +884,17 @@ func (p *Peer) handleHandshake(msg *p2p.Handshake) {
 			zap.Stringer("messageOp", message.HandshakeOp),
 			zap.String("reason", "already received handshake"),
 		)
+		p.ReputationTracker.Register(p.id, reputation.InvalidMessage)
 		p.StartClose()
 		return
 	}
 
+	defer func() {
+		if !p.gotHandshake.Get() {
+			p.ReputationTracker.Register(p.id, reputation.HandshakeFailure)
+		}
+	}()
+
 	if msg.NetworkId != p.NetworkID {
 		p.Log.Debug(malformedMessageLog,
 			zap.Stringer("nodeID", p.id),
@@ -984,6 +1057,17 @@ func (p *Peer) handleHandshake(msg *p2p.Handshake) {
 		return
 	}
 
+	if msg.QuicPort > math.MaxUint16 {
+		p.Log.Debug(malformedMessageLog,
+			zap.Stringer("nodeID", p.id),
+			zap.Stringer("messageOp", message.HandshakeOp),
+			zap.String("field", "quicPort"),
+			zap.Uint32("quicPort", msg.QuicPort),
+		)
+		p.StartClose()
+		return
+	}
+
 	p.ip = &SignedIP{
 		UnsignedIP: UnsignedIP{
 			AddrPort: netip.AddrPortFrom(
@@ -991,8 +1075,10 @@ func (p *Peer) handleHandshake(msg *p2p.Handshake) {
 				port,
 			),
 			Timestamp: msg.IpSigningTime,
+			QUICPort:  uint16(msg.QuicPort),
 		},
-		TLSSignature: msg.IpNodeIdSig,
+		TLSSignature:  msg.IpNodeIdSig,
+		QUICSignature: msg.IpQuicSig,
 	}
 	maxTimestamp := localTime.Add(p.MaxClockDifference)
 	if err := p.ip.Verify(p.cert, maxTimestamp); err != nil {
@@ -1036,6 +1122,8 @@ func (p *Peer) handleHandshake(msg *p2p.Handshake) {
 		return
 	}
 
+	p.compression.Set(message.ParsePeerCompression(msg))
+	p.tracePropagation.Set(p.TracePropagation && msg.TracePropagation)
 	p.gotHandshake.Set(true)
 
 	peerIPs := p.Network.Peers(p.id, p.trackedSubnets, msg.AllSubnets, knownPeers, salt)
@@ -1084,6 +1172,7 @@ func (p *Peer) handleGetPeerList(msg *p2p.GetPeerList) {
 			zap.String("field", "knownPeers.filter"),
 			zap.Error(err),
 		)
+		p.ReputationTracker.Register(p.id, reputation.InvalidMessage)
 		p.StartClose()
 		return
 	}
@@ -1096,6 +1185,7 @@ func (p *Peer) handleGetPeerList(msg *p2p.GetPeerList) {
 			zap.String("field", "knownPeers.salt"),
 			zap.Int("saltLen", saltLen),
 		)
+		p.ReputationTracker.Register(p.id, reputation.InvalidMessage)
 		p.StartClose()
 		return
 	}
@@ -1144,6 +1234,7 @@ func (p *Peer) handlePeerList(msg *p2p.PeerList) {
 				zap.String("field", "cert"),
 				zap.Error(err),
 			)
+			p.ReputationTracker.Register(p.id, reputation.BadGossip)
 			p.StartClose()
 			return
 		}
@@ -1156,6 +1247,7 @@ func (p *Peer) handlePeerList(msg *p2p.PeerList) {
 				zap.String("field", "ip"),
 				zap.Int("ipLen", len(claimedIPPort.IpAddr)),
 			)
+			p.ReputationTracker.Register(p.id, reputation.BadGossip)
 			p.StartClose()
 			return
 		}
@@ -1168,6 +1260,7 @@ func (p *Peer) handlePeerList(msg *p2p.PeerList) {
 				zap.String("field", "port"),
 				zap.Uint16("port", port),
 			)
+			p.ReputationTracker.Register(p.id, reputation.BadGossip)
 			p.StartClose()
 			return
 		}
@@ -1190,6 +1283,7 @@ func (p *Peer) handlePeerList(msg *p2p.PeerList) {
 			zap.String("field", "claimedIP"),
 			zap.Error(err),
 		)
+		p.ReputationTracker.Register(p.id, reputation.BadGossip)
 		p.StartClose()
 	}
 }
@@ -16,6 +16,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/snow/networking/tracker"
@@ -24,6 +25,7 @@ import (
 	"github.com/ava-labs/avalanchego/staking"
 	"github.com/ava-labs/avalanchego/upgrade"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/compression"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls/signer/localsigner"
@@ -32,6 +34,8 @@ import (
 	"github.com/ava-labs/avalanchego/utils/resource"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/version"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 type testPeer struct {
@@ -51,6 +55,7 @@ func newMessageCreator(t *testing.T) message.Creator {
 	mc, err := message.NewCreator(
 		prometheus.NewRegistry(),
 		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkCompressionZstdLevel,
 		10*time.Second,
 	)
 	require.NoError(t, err)
@@ -91,6 +96,7 @@ func newConfig(t *testing.T) *Config {
 		PongTimeout:          constants.DefaultPingPongTimeout,
 		MaxClockDifference:   time.Minute,
 		ResourceTracker:      resourceTracker,
+		ReputationTracker:    reputation.NewNoTracker(),
 		UptimeCalculator:     uptime.TestCalculator{},
 		IPSigner:             nil,
 	}
@@ -114,7 +120,7 @@ func newRawTestPeer(t *testing.T, config *Config) *rawTestPeer {
 	bls, err := localsigner.New()
 	require.NoError(err)
 
-	config.IPSigner = NewIPSigner(ip, tls, bls)
+	config.IPSigner = NewIPSigner(ip, 0, tls, bls)
 
 	inboundMsgChan := make(chan *message.InboundMessage)
 	config.Router = router.InboundHandlerFunc(func(_ context.Context, msg *message.InboundMessage) {
@@ -140,6 +146,8 @@ func startTestPeer(self *rawTestPeer, peer *rawTestPeer, conn net.Conn) *testPee
 				peer.config.MyNodeID,
 				logging.NoLog{},
 				throttling.NewNoOutboundThrottler(),
+				self.config.Metrics,
+				DefaultPriorityWeights,
 			),
 			false,
 		),
@@ -205,6 +213,12 @@ func TestSend(t *testing.T) {
 	peer0, peer1 := startTestPeers(rawPeer0, rawPeer1)
 	awaitReady(t, peer0, peer1)
 
+	// Get messages are compressed with the zstd dictionary advertised by the
+	// peer.
+	peerCompression := peer0.compression.Get()
+	require.NotNil(peerCompression)
+	require.Equal(message.ZstdDictionaryOps.Len(), peerCompression.ZstdDictionaries.Len())
+
 	outboundGetMsg, err := config0.MessageCreator.Get(ids.Empty, 1, time.Second, ids.Empty)
 	require.NoError(err)
 
@@ -218,6 +232,134 @@ func TestSend(t *testing.T) {
 	require.NoError(peer1.AwaitClosed(t.Context()))
 }
 
+// sizeRecordingThrottler records the size of the messages it acquires.
+type sizeRecordingThrottler struct {
+	acquired []int
+}
+
+func (t *sizeRecordingThrottler) Acquire(msg *message.OutboundMessage, _ ids.NodeID) bool {
+	t.acquired = append(t.acquired, len(msg.Bytes))
+	return true
+}
+
+func (*sizeRecordingThrottler) Release(*message.OutboundMessage, ids.NodeID) {}
+
+// Messages must be compressed for the peer before they are queued, so that
+// the outbound throttler accounts for the bytes that are sent.
+func TestSendThrottlesCompressedBytes(t *testing.T) {
+	require := require.New(t)
+
+	config := newConfig(t)
+	throttler := &sizeRecordingThrottler{}
+	nodeID := ids.GenerateTestNodeID()
+	p := &Peer{
+		Config: config,
+		id:     nodeID,
+		messageQueue: NewThrottledMessageQueue(
+			config.Metrics,
+			nodeID,
+			logging.NoLog{},
+			throttler,
+			config.Metrics,
+			DefaultPriorityWeights,
+		),
+	}
+	peerCompression := &message.PeerCompression{
+		Types: []compression.Type{compression.TypeLZ4},
+	}
+	p.compression.Set(peerCompression)
+
+	msg, err := config.MessageCreator.PeerList(nil, false)
+	require.NoError(err)
+	require.True(p.Send(t.Context(), msg))
+
+	sentBytes, _, err := msg.BytesFor(peerCompression)
+	require.NoError(err)
+	require.NotEqual(msg.Bytes, sentBytes)
+	require.Equal([]int{len(sentBytes)}, throttler.acquired)
+
+	queuedMsg, ok := p.messageQueue.PopNow()
+	require.True(ok)
+	queuedBytes, _, err := queuedMsg.BytesFor(p.compression.Get())
+	require.NoError(err)
+	require.Equal(sentBytes, queuedBytes)
+}
+
+func TestTracePropagation(t *testing.T) {
+	traceContext := map[string]string{
+		"traceparent": "00-01000000000000000000000000000000-0200000000000000-01",
+	}
+	tests := []struct {
+		name                 string
+		senderPropagation    bool
+		receiverPropagation  bool
+		expectedTraceContext map[string]string
+		expectedTraceID      oteltrace.TraceID
+	}{
+		{
+			name:                 "both propagate",
+			senderPropagation:    true,
+			receiverPropagation:  true,
+			expectedTraceContext: traceContext,
+			expectedTraceID:      oteltrace.TraceID{1},
+		},
+		{
+			name:              "only sender propagates",
+			senderPropagation: true,
+		},
+		{
+			name:                "only receiver propagates",
+			receiverPropagation: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			config0 := newConfig(t)
+			config0.TracePropagation = test.senderPropagation
+			config1 := newConfig(t)
+			config1.TracePropagation = test.receiverPropagation
+
+			rawPeer0 := newRawTestPeer(t, config0)
+			rawPeer1 := newRawTestPeer(t, config1)
+
+			inboundCtxs := make(chan context.Context, 1)
+			inboundMsgs := make(chan *message.InboundMessage, 1)
+			config1.Router = router.InboundHandlerFunc(func(ctx context.Context, msg *message.InboundMessage) {
+				inboundCtxs <- ctx
+				inboundMsgs <- msg
+			})
+
+			peer0, peer1 := startTestPeers(rawPeer0, rawPeer1)
+			awaitReady(t, peer0, peer1)
+
+			outboundMsg, err := config0.MessageCreator.AppRequest(
+				ids.Empty,
+				1,
+				time.Second,
+				[]byte("request"),
+				traceContext,
+			)
+			require.NoError(err)
+			require.True(peer0.Send(t.Context(), outboundMsg))
+
+			inboundCtx := <-inboundCtxs
+			inboundMsg := <-inboundMsgs
+			require.Equal(message.AppRequestOp, inboundMsg.Op)
+			require.Equal(test.expectedTraceContext, message.GetTraceContext(inboundMsg.Message))
+
+			// The inbound message continues the trace of the sender.
+			spanContext := oteltrace.SpanContextFromContext(inboundCtx)
+			require.Equal(test.expectedTraceID, spanContext.TraceID())
+
+			peer0.StartClose()
+			require.NoError(peer0.AwaitClosed(t.Context()))
+			require.NoError(peer1.AwaitClosed(t.Context()))
+		})
+	}
+}
+
 func TestPingUptimes(t *testing.T) {
 	config0 := newConfig(t)
 	config1 := newConfig(t)
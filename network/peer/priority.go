@@ -0,0 +1,106 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peer
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+const (
+	// ConsensusPriority is the priority of consensus and peer liveness
+	// messages.
+	ConsensusPriority Priority = iota
+	// GossipPriority is the priority of peer list and application gossip.
+	GossipPriority
+	// BootstrapPriority is the priority of bootstrapping and state sync
+	// messages.
+	BootstrapPriority
+	// AppPriority is the priority of application requests and responses.
+	AppPriority
+
+	numPriorities = iota
+)
+
+var (
+	DefaultPriorityWeights = PriorityWeights{
+		Consensus: constants.DefaultNetworkSendQueueConsensusWeight,
+		Gossip:    constants.DefaultNetworkSendQueueGossipWeight,
+		Bootstrap: constants.DefaultNetworkSendQueueBootstrapWeight,
+		App:       constants.DefaultNetworkSendQueueAppWeight,
+	}
+
+	errZeroPriorityWeight = errors.New("priority weights must be positive")
+)
+
+// Priority is the class of an outbound message that determines its share of
+// the bandwidth to a peer.
+type Priority byte
+
+// PriorityOf returns the priority of messages with [op].
+func PriorityOf(op message.Op) Priority {
+	switch op {
+	case message.PingOp, message.PongOp, message.HandshakeOp,
+		message.GetOp, message.PutOp, message.PushQueryOp,
+		message.PullQueryOp, message.ChitsOp, message.SimplexOp:
+		return ConsensusPriority
+	case message.GetPeerListOp, message.PeerListOp, message.AppGossipOp:
+		return GossipPriority
+	case message.AppRequestOp, message.AppResponseOp, message.AppErrorOp:
+		return AppPriority
+	default:
+		return BootstrapPriority
+	}
+}
+
+func (p Priority) String() string {
+	switch p {
+	case ConsensusPriority:
+		return "consensus"
+	case GossipPriority:
+		return "gossip"
+	case BootstrapPriority:
+		return "bootstrap"
+	case AppPriority:
+		return "app"
+	default:
+		return "unknown"
+	}
+}
+
+// PriorityWeights are the relative shares of the bandwidth to a peer that are
+// given to each priority when messages of multiple priorities are queued.
+//
+// For example, if consensus messages have a weight of 4 and bootstrap
+// messages have a weight of 1, 4 bytes of consensus messages are sent for
+// every byte of bootstrap messages. Because every weight is positive, messages
+// of a low priority are never starved by messages of a high priority.
+type PriorityWeights struct {
+	Consensus uint64 `json:"consensus"`
+	Gossip    uint64 `json:"gossip"`
+	Bootstrap uint64 `json:"bootstrap"`
+	App       uint64 `json:"app"`
+}
+
+func (w *PriorityWeights) Verify() error {
+	if w.Consensus == 0 || w.Gossip == 0 || w.Bootstrap == 0 || w.App == 0 {
+		return errZeroPriorityWeight
+	}
+	return nil
+}
+
+func (w *PriorityWeights) weight(p Priority) uint64 {
+	switch p {
+	case ConsensusPriority:
+		return w.Consensus
+	case GossipPriority:
+		return w.Gossip
+	case BootstrapPriority:
+		return w.Bootstrap
+	default:
+		return w.App
+	}
+}
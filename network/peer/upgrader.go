@@ -15,10 +15,12 @@ import (
 )
 
 var (
-	errNoCert = errors.New("tls handshake finished with no peer certificate")
+	errNoCert     = errors.New("tls handshake finished with no peer certificate")
+	errNotTLSConn = errors.New("connection didn't perform a tls handshake")
 
 	_ Upgrader = (*tlsServerUpgrader)(nil)
 	_ Upgrader = (*tlsClientUpgrader)(nil)
+	_ Upgrader = (*tlsConnUpgrader)(nil)
 )
 
 type Upgrader interface {
@@ -58,12 +60,41 @@ func (t *tlsClientUpgrader) Upgrade(conn net.Conn) (ids.NodeID, net.Conn, *staki
 	return connToIDAndCert(tls.Client(conn, t.config), t.invalidCerts)
 }
 
+// tlsConn is a connection that performed a TLS handshake, such as a QUIC
+// connection.
+type tlsConn interface {
+	net.Conn
+	ConnectionState() tls.ConnectionState
+}
+
+type tlsConnUpgrader struct {
+	invalidCerts prometheus.Counter
+}
+
+// NewTLSConnUpgrader returns an upgrader of connections whose transport already
+// performed the TLS handshake, such as QUIC connections.
+func NewTLSConnUpgrader(invalidCerts prometheus.Counter) Upgrader {
+	return &tlsConnUpgrader{
+		invalidCerts: invalidCerts,
+	}
+}
+
+func (t *tlsConnUpgrader) Upgrade(conn net.Conn) (ids.NodeID, net.Conn, *staking.Certificate, error) {
+	c, ok := conn.(tlsConn)
+	if !ok {
+		return ids.EmptyNodeID, nil, nil, errNotTLSConn
+	}
+	return stateToIDAndCert(c, c.ConnectionState(), t.invalidCerts)
+}
+
 func connToIDAndCert(conn *tls.Conn, invalidCerts prometheus.Counter) (ids.NodeID, net.Conn, *staking.Certificate, error) {
 	if err := conn.Handshake(); err != nil {
 		return ids.EmptyNodeID, nil, nil, err
 	}
+	return stateToIDAndCert(conn, conn.ConnectionState(), invalidCerts)
+}
 
-	state := conn.ConnectionState()
+func stateToIDAndCert(conn net.Conn, state tls.ConnectionState, invalidCerts prometheus.Counter) (ids.NodeID, net.Conn, *staking.Certificate, error) {
 	if len(state.PeerCertificates) == 0 {
 		return ids.EmptyNodeID, nil, nil, errNoCert
 	}
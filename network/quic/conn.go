@@ -0,0 +1,243 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// numStreams is the number of unidirectional streams that each side of a
+// connection opens. The first stream carries most messages and the second
+// carries large messages, so that they aren't queued behind each other while
+// they are sent.
+const numStreams = 2
+
+var (
+	_ net.Conn = (*Conn)(nil)
+
+	errMaxMessageLengthExceeded = errors.New("maximum message length exceeded")
+)
+
+// Conn is a peer connection over QUIC.
+//
+// Messages must be written as a 4 byte big-endian length followed by the
+// message. Reads return the messages received on all the streams, and a
+// message is never interleaved with another one.
+//
+// Nothing is read from the peer until the first call to Read, so that the
+// connection can be upgraded and rejected before the peer can send messages.
+type Conn struct {
+	conn    *quic.Conn
+	control *quic.SendStream
+	bulk    *quic.SendStream
+
+	startReading sync.Once
+	readDeadline utils.Atomic[time.Time]
+	// messages whose length was received on any of the streams
+	messages chan *message
+	// message that is being read. Only accessed by Read.
+	current *message
+}
+
+// message is a message that is being received on a stream. Nothing else is
+// read from the stream until the whole message has been read.
+type message struct {
+	stream *quic.ReceiveStream
+	// unread bytes of the length of the message
+	msgLenBytes []byte
+	// number of unread bytes of the message after its length
+	remaining int
+	// closed once the whole message has been read
+	done chan struct{}
+}
+
+func newConn(conn *quic.Conn) (*Conn, error) {
+	control, err := conn.OpenUniStream()
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		return nil, err
+	}
+	bulk, err := conn.OpenUniStream()
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		return nil, err
+	}
+
+	return &Conn{
+		conn:     conn,
+		control:  control,
+		bulk:     bulk,
+		messages: make(chan *message),
+	}, nil
+}
+
+// Read reads the messages received on all the streams.
+//
+// The length of a message is returned before the rest of the message is read
+// from its stream, so the reader can wait to read a message until it is
+// allowed to.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.startReading.Do(func() {
+		go c.acceptStreams()
+	})
+
+	if c.current == nil {
+		var timeout <-chan time.Time
+		if deadline := c.readDeadline.Get(); !deadline.IsZero() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case c.current = <-c.messages:
+		case <-timeout:
+			return 0, os.ErrDeadlineExceeded
+		case <-c.conn.Context().Done():
+			return 0, io.EOF
+		}
+	}
+
+	m := c.current
+	var n int
+	if len(m.msgLenBytes) > 0 {
+		n = copy(b, m.msgLenBytes)
+		m.msgLenBytes = m.msgLenBytes[n:]
+	} else {
+		if err := m.stream.SetReadDeadline(c.readDeadline.Get()); err != nil {
+			return 0, err
+		}
+
+		var err error
+		n, err = m.stream.Read(b[:min(len(b), m.remaining)])
+		m.remaining -= n
+		if err != nil {
+			return n, err
+		}
+	}
+
+	if len(m.msgLenBytes) == 0 && m.remaining == 0 {
+		close(m.done)
+		c.current = nil
+	}
+	return n, nil
+}
+
+// Write writes to the stream that carries most messages.
+func (c *Conn) Write(b []byte) (int, error) {
+	return c.control.Write(b)
+}
+
+// BulkWriter returns the writer of the stream that carries large messages.
+func (c *Conn) BulkWriter() io.Writer {
+	return c.bulk
+}
+
+func (c *Conn) Close() error {
+	return c.conn.CloseWithError(0, "")
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *Conn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.Set(t)
+	return nil
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	if err := c.control.SetWriteDeadline(t); err != nil {
+		return err
+	}
+	return c.bulk.SetWriteDeadline(t)
+}
+
+// ConnectionState returns the state of the TLS handshake performed by QUIC.
+func (c *Conn) ConnectionState() tls.ConnectionState {
+	return c.conn.ConnectionState().TLS
+}
+
+func (c *Conn) acceptStreams() {
+	ctx := c.conn.Context()
+	for range numStreams {
+		stream, err := c.conn.AcceptUniStream(ctx)
+		if err != nil {
+			return
+		}
+		go c.readStream(ctx, stream)
+	}
+}
+
+// readStream reads the length of each message on [stream] and waits for the
+// message to be read by Read before reading the next one, so that messages
+// from different streams are never interleaved. The connection is closed if
+// the stream fails.
+func (c *Conn) readStream(ctx context.Context, stream *quic.ReceiveStream) {
+	defer func() {
+		_ = c.Close()
+	}()
+
+	for {
+		// Read may have left a deadline on the stream.
+		if err := stream.SetReadDeadline(time.Time{}); err != nil {
+			return
+		}
+
+		msgLenBytes := make([]byte, wrappers.IntLen)
+		if _, err := io.ReadFull(stream, msgLenBytes); err != nil {
+			return
+		}
+
+		msgLen := binary.BigEndian.Uint32(msgLenBytes)
+		if msgLen > constants.DefaultMaxMessageSize {
+			_ = c.conn.CloseWithError(0, fmt.Sprintf("%s: %d", errMaxMessageLengthExceeded, msgLen))
+			return
+		}
+
+		m := &message{
+			stream:      stream,
+			msgLenBytes: msgLenBytes,
+			remaining:   int(msgLen),
+			done:        make(chan struct{}),
+		}
+		select {
+		case c.messages <- m:
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-m.done:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
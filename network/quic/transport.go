@@ -0,0 +1,121 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/ava-labs/avalanchego/network/dialer"
+)
+
+const (
+	// nextProto is the application protocol negotiated during the TLS
+	// handshake.
+	nextProto = "avalanche"
+
+	// keepAlivePeriod is the frequency that packets are sent to keep idle
+	// connections open.
+	keepAlivePeriod = 15 * time.Second
+)
+
+var (
+	_ net.Listener  = (*Transport)(nil)
+	_ dialer.Dialer = (*Transport)(nil)
+)
+
+// Transport accepts and dials peer connections over QUIC. A single UDP socket
+// is used for both inbound and outbound connections.
+//
+// The TLS handshake is performed by QUIC, so the returned connections must not
+// be upgraded with TLS again.
+type Transport struct {
+	udpConn   *net.UDPConn
+	transport *quic.Transport
+	listener  *quic.Listener
+	tlsConfig *tls.Config
+	config    *quic.Config
+}
+
+// NewTransport binds a UDP socket to [addr] and starts accepting QUIC
+// connections.
+//
+// [tlsConfig] is the TLS config that authenticates peers, which should use the
+// staking certificate. [handshakeTimeout] bounds the time to establish a
+// connection.
+func NewTransport(
+	addr string,
+	tlsConfig *tls.Config,
+	handshakeTimeout time.Duration,
+) (*Transport, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	udpConn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.NextProtos = []string{nextProto}
+	t := &Transport{
+		udpConn: udpConn,
+		transport: &quic.Transport{
+			Conn: udpConn,
+		},
+		tlsConfig: tlsConfig,
+		config: &quic.Config{
+			HandshakeIdleTimeout: handshakeTimeout,
+			KeepAlivePeriod:      keepAlivePeriod,
+			// Peers only communicate over unidirectional streams.
+			MaxIncomingStreams:    -1,
+			MaxIncomingUniStreams: numStreams,
+		},
+	}
+	t.listener, err = t.transport.Listen(t.tlsConfig, t.config)
+	if err != nil {
+		return nil, errors.Join(err, t.transport.Close(), udpConn.Close())
+	}
+	return t, nil
+}
+
+// Accept waits for the next inbound connection.
+func (t *Transport) Accept() (net.Conn, error) {
+	conn, err := t.listener.Accept(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return newConn(conn)
+}
+
+// Dial establishes a connection with [ip].
+func (t *Transport) Dial(ctx context.Context, ip netip.AddrPort) (net.Conn, error) {
+	conn, err := t.transport.Dial(ctx, net.UDPAddrFromAddrPort(ip), t.tlsConfig, t.config)
+	if err != nil {
+		return nil, fmt.Errorf("error while dialing %s: %w", ip, err)
+	}
+	return newConn(conn)
+}
+
+// Addr returns the address of the UDP socket.
+func (t *Transport) Addr() net.Addr {
+	return t.udpConn.LocalAddr()
+}
+
+// Close stops accepting connections and closes all the connections.
+func (t *Transport) Close() error {
+	return errors.Join(
+		t.listener.Close(),
+		t.transport.Close(),
+		t.udpConn.Close(),
+	)
+}
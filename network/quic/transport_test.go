@@ -0,0 +1,175 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quic
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/ips"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+func newTestTransport(t *testing.T) (*Transport, ids.NodeID) {
+	require := require.New(t)
+
+	tlsCert, err := staking.NewTLSCert()
+	require.NoError(err)
+	cert, err := staking.ParseCertificate(tlsCert.Leaf.Raw)
+	require.NoError(err)
+
+	transport, err := NewTransport("127.0.0.1:0", peer.TLSConfig(*tlsCert, nil), time.Second)
+	require.NoError(err)
+	t.Cleanup(func() {
+		_ = transport.Close()
+	})
+	return transport, ids.NodeIDFromCert(cert)
+}
+
+func connect(t *testing.T) (*Conn, *Conn, ids.NodeID, ids.NodeID) {
+	require := require.New(t)
+
+	server, serverID := newTestTransport(t)
+	client, clientID := newTestTransport(t)
+
+	serverIP, err := ips.ParseAddrPort(server.Addr().String())
+	require.NoError(err)
+	clientConn, err := client.Dial(t.Context(), serverIP)
+	require.NoError(err)
+	serverConn, err := server.Accept()
+	require.NoError(err)
+	return clientConn.(*Conn), serverConn.(*Conn), clientID, serverID
+}
+
+func frame(msg []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(msg)))
+	return append(b, msg...)
+}
+
+func writeMessage(t *testing.T, w io.Writer, msg []byte) {
+	_, err := w.Write(frame(msg))
+	require.NoError(t, err)
+}
+
+func readMessage(t *testing.T, r io.Reader) []byte {
+	require := require.New(t)
+
+	msgLenBytes := make([]byte, wrappers.IntLen)
+	_, err := io.ReadFull(r, msgLenBytes)
+	require.NoError(err)
+	msg := make([]byte, binary.BigEndian.Uint32(msgLenBytes))
+	_, err = io.ReadFull(r, msg)
+	require.NoError(err)
+	return msg
+}
+
+func TestTransport(t *testing.T) {
+	require := require.New(t)
+
+	clientConn, serverConn, clientID, serverID := connect(t)
+
+	// Both sides are authenticated with their staking certificates.
+	for _, test := range []struct {
+		conn     *Conn
+		expected ids.NodeID
+	}{
+		{conn: clientConn, expected: serverID},
+		{conn: serverConn, expected: clientID},
+	} {
+		state := test.conn.ConnectionState()
+		require.Equal(nextProto, state.NegotiatedProtocol)
+		require.Equal(uint16(tls.VersionTLS13), state.Version)
+		require.Len(state.PeerCertificates, 1)
+		cert, err := staking.ParseCertificate(state.PeerCertificates[0].Raw)
+		require.NoError(err)
+		require.Equal(test.expected, ids.NodeIDFromCert(cert))
+	}
+
+	writeMessage(t, clientConn, []byte("control"))
+	require.Equal([]byte("control"), readMessage(t, serverConn))
+
+	writeMessage(t, clientConn.BulkWriter(), []byte("bulk"))
+	require.Equal([]byte("bulk"), readMessage(t, serverConn))
+
+	writeMessage(t, serverConn, []byte("response"))
+	require.Equal([]byte("response"), readMessage(t, clientConn))
+
+	require.NoError(clientConn.Close())
+	_, err := serverConn.Read(make([]byte, 1))
+	require.ErrorIs(err, io.EOF)
+}
+
+// A large message on the bulk stream must not delay the messages on the
+// control stream.
+func TestTransportBulkDoesNotBlockControl(t *testing.T) {
+	require := require.New(t)
+
+	clientConn, serverConn, _, _ := connect(t)
+
+	large := make([]byte, 1024*1024)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := clientConn.BulkWriter().Write(frame(large))
+		errs <- err
+	}()
+	writeMessage(t, clientConn, []byte("control"))
+
+	msgs := [][]byte{
+		readMessage(t, serverConn),
+		readMessage(t, serverConn),
+	}
+	require.Contains(msgs, []byte("control"))
+	require.Contains(msgs, large)
+	require.NoError(<-errs)
+}
+
+func TestConnReadDeadline(t *testing.T) {
+	require := require.New(t)
+
+	_, serverConn, _, _ := connect(t)
+
+	require.NoError(serverConn.SetReadDeadline(time.Now().Add(10 * time.Millisecond)))
+	_, err := serverConn.Read(make([]byte, 1))
+	require.ErrorIs(err, os.ErrDeadlineExceeded)
+
+	var netErr net.Error
+	require.ErrorAs(err, &netErr)
+	require.True(netErr.Timeout())
+}
+
+// The length of a message must be returned before the message is received, so
+// that the reader can wait to read the message until it is allowed to.
+func TestConnReadsLengthBeforeMessage(t *testing.T) {
+	require := require.New(t)
+
+	clientConn, serverConn, _, _ := connect(t)
+
+	msg := []byte("message")
+	msgLenBytes := binary.BigEndian.AppendUint32(nil, uint32(len(msg)))
+	_, err := clientConn.Write(msgLenBytes)
+	require.NoError(err)
+
+	readMsgLenBytes := make([]byte, wrappers.IntLen)
+	_, err = io.ReadFull(serverConn, readMsgLenBytes)
+	require.NoError(err)
+	require.Equal(msgLenBytes, readMsgLenBytes)
+
+	_, err = clientConn.Write(msg)
+	require.NoError(err)
+
+	readMsg := make([]byte, len(msg))
+	_, err = io.ReadFull(serverConn, readMsg)
+	require.NoError(err)
+	require.Equal(msg, readMsg)
+}
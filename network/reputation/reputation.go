@@ -0,0 +1,302 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reputation
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/cache/lru"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+const (
+	InvalidMessage Event = iota
+	Timeout
+	BadGossip
+	HandshakeFailure
+
+	numEvents = iota
+
+	// maxTrackedPeers bounds the memory used to track scores. Peers that
+	// haven't been seen recently are forgotten, which resets their score.
+	maxTrackedPeers = 16384
+
+	eventLabel = "event"
+)
+
+var (
+	_ Tracker = (*tracker)(nil)
+	_ Tracker = noTracker{}
+
+	DefaultConfig = Config{
+		Halflife:                10 * time.Minute,
+		InvalidMessagePenalty:   1,
+		TimeoutPenalty:          .25,
+		BadGossipPenalty:        2,
+		HandshakeFailurePenalty: 2,
+		BandwidthWeight:         1,
+		BandwidthHalfScore:      1024 * 1024,
+		MinScore:                -10,
+	}
+
+	errNonPositiveHalflife  = errors.New("halflife must be positive")
+	errNegativePenalty      = errors.New("penalties must be non-negative")
+	errNegativeBandwidth    = errors.New("bandwidth weight must be non-negative")
+	errNonPositiveHalfScore = errors.New("bandwidth half score must be positive")
+	errPositiveMinScore     = errors.New("min score must be non-positive")
+)
+
+// Event is a misbehavior of a peer that lowers its reputation.
+type Event byte
+
+func (e Event) String() string {
+	switch e {
+	case InvalidMessage:
+		return "invalid_message"
+	case Timeout:
+		return "timeout"
+	case BadGossip:
+		return "bad_gossip"
+	case HandshakeFailure:
+		return "handshake_failure"
+	default:
+		return "unknown"
+	}
+}
+
+type Config struct {
+	// Enabled enables scoring peers. If false, every peer is trusted.
+	Enabled bool `json:"enabled"`
+
+	// Halflife of the decay of the penalties and the bandwidth of peers.
+	Halflife time.Duration `json:"halflife"`
+
+	// Penalty added to the score of a peer for each event.
+	InvalidMessagePenalty   float64 `json:"invalidMessagePenalty"`
+	TimeoutPenalty          float64 `json:"timeoutPenalty"`
+	BadGossipPenalty        float64 `json:"badGossipPenalty"`
+	HandshakeFailurePenalty float64 `json:"handshakeFailurePenalty"`
+
+	// BandwidthWeight is the largest amount that the bandwidth of a peer can
+	// add to its score.
+	BandwidthWeight float64 `json:"bandwidthWeight"`
+	// BandwidthHalfScore is the bandwidth, in bytes per second, that adds
+	// half of [BandwidthWeight] to the score of a peer.
+	BandwidthHalfScore float64 `json:"bandwidthHalfScore"`
+
+	// MinScore is the lowest score of a trusted peer.
+	MinScore float64 `json:"minScore"`
+}
+
+func (c *Config) Verify() error {
+	switch {
+	case c.Halflife <= 0:
+		return errNonPositiveHalflife
+	case c.InvalidMessagePenalty < 0, c.TimeoutPenalty < 0, c.BadGossipPenalty < 0, c.HandshakeFailurePenalty < 0:
+		return errNegativePenalty
+	case c.BandwidthWeight < 0:
+		return errNegativeBandwidth
+	case c.BandwidthHalfScore <= 0:
+		return errNonPositiveHalfScore
+	case c.MinScore > 0:
+		return errPositiveMinScore
+	default:
+		return nil
+	}
+}
+
+func (c *Config) penalty(event Event) float64 {
+	switch event {
+	case InvalidMessage:
+		return c.InvalidMessagePenalty
+	case Timeout:
+		return c.TimeoutPenalty
+	case BadGossip:
+		return c.BadGossipPenalty
+	case HandshakeFailure:
+		return c.HandshakeFailurePenalty
+	default:
+		return 0
+	}
+}
+
+// Tracker scores peers based on their behavior. Peers start with a score of 0.
+// Each event lowers the score of a peer by the penalty of the event, and the
+// bandwidth of a peer raises its score. Both decay over time, so the score of
+// a peer that stops misbehaving recovers.
+type Tracker interface {
+	// Register records that [nodeID] caused [event].
+	Register(nodeID ids.NodeID, event Event)
+	// RegisterBandwidth records that [nodeID] responded to a request with
+	// [bandwidth] bytes per second.
+	RegisterBandwidth(nodeID ids.NodeID, bandwidth float64)
+	// Score returns the current score of [nodeID].
+	Score(nodeID ids.NodeID) float64
+	// Trusted returns true if the score of [nodeID] isn't below the minimum
+	// score.
+	Trusted(nodeID ids.NodeID) bool
+	// Admissible returns true if the penalties of the events that [nodeID]
+	// caused, other than timeouts, aren't below the minimum score. Timeouts
+	// are excluded because they may be caused by this node being overloaded,
+	// which shouldn't prevent peers from connecting to it.
+	Admissible(nodeID ids.NodeID) bool
+}
+
+type peerScore struct {
+	// timeouts are penalized separately from the other events so that they
+	// don't affect whether the peer is admissible.
+	timeouts    penalty
+	misbehavior penalty
+	// nil if the bandwidth of the peer was never registered
+	bandwidth safemath.Averager
+}
+
+type penalty struct {
+	// value as of [last]
+	value float64
+	last  time.Time
+}
+
+type tracker struct {
+	config Config
+	clock  mockable.Clock
+	events *prometheus.CounterVec
+
+	lock  sync.Mutex
+	peers *lru.Cache[ids.NodeID, *peerScore]
+}
+
+func NewTracker(config Config, registerer prometheus.Registerer) (Tracker, error) {
+	if err := config.Verify(); err != nil {
+		return nil, err
+	}
+
+	t := &tracker{
+		config: config,
+		events: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "reputation_events",
+				Help: "Number of events that lowered the reputation of a peer",
+			},
+			[]string{eventLabel},
+		),
+		peers: lru.NewCache[ids.NodeID, *peerScore](maxTrackedPeers),
+	}
+	for event := range Event(numEvents) {
+		t.events.WithLabelValues(event.String())
+	}
+	return t, registerer.Register(t.events)
+}
+
+func (t *tracker) Register(nodeID ids.NodeID, event Event) {
+	t.events.WithLabelValues(event.String()).Inc()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Time()
+	score := t.getScore(nodeID)
+	p := &score.misbehavior
+	if event == Timeout {
+		p = &score.timeouts
+	}
+	p.value = t.decay(*p, now) + t.config.penalty(event)
+	p.last = now
+}
+
+func (t *tracker) RegisterBandwidth(nodeID ids.NodeID, bandwidth float64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Time()
+	score := t.getScore(nodeID)
+	if score.bandwidth == nil {
+		score.bandwidth = safemath.NewAverager(bandwidth, t.config.Halflife, now)
+		return
+	}
+	score.bandwidth.Observe(bandwidth, now)
+}
+
+func (t *tracker) Score(nodeID ids.NodeID) float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	score, ok := t.peers.Get(nodeID)
+	if !ok {
+		return 0
+	}
+
+	var bandwidthScore float64
+	if score.bandwidth != nil {
+		bandwidth := max(score.bandwidth.Read(), 0)
+		bandwidthScore = t.config.BandwidthWeight * bandwidth / (bandwidth + t.config.BandwidthHalfScore)
+	}
+	now := t.clock.Time()
+	return bandwidthScore - t.decay(score.misbehavior, now) - t.decay(score.timeouts, now)
+}
+
+func (t *tracker) Trusted(nodeID ids.NodeID) bool {
+	return t.Score(nodeID) >= t.config.MinScore
+}
+
+func (t *tracker) Admissible(nodeID ids.NodeID) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	score, ok := t.peers.Get(nodeID)
+	if !ok {
+		return true
+	}
+	return -t.decay(score.misbehavior, t.clock.Time()) >= t.config.MinScore
+}
+
+// Assumes [t.lock] is held.
+func (t *tracker) getScore(nodeID ids.NodeID) *peerScore {
+	score, ok := t.peers.Get(nodeID)
+	if !ok {
+		score = &peerScore{}
+		t.peers.Put(nodeID, score)
+	}
+	return score
+}
+
+// Returns the value of [p] at [now].
+func (t *tracker) decay(p penalty, now time.Time) float64 {
+	if p.value == 0 {
+		return 0
+	}
+	elapsed := max(now.Sub(p.last), 0)
+	return p.value * math.Exp2(-float64(elapsed)/float64(t.config.Halflife))
+}
+
+type noTracker struct{}
+
+// NewNoTracker returns a tracker that trusts every peer.
+func NewNoTracker() Tracker {
+	return noTracker{}
+}
+
+func (noTracker) Register(ids.NodeID, Event) {}
+
+func (noTracker) RegisterBandwidth(ids.NodeID, float64) {}
+
+func (noTracker) Score(ids.NodeID) float64 {
+	return 0
+}
+
+func (noTracker) Trusted(ids.NodeID) bool {
+	return true
+}
+
+func (noTracker) Admissible(ids.NodeID) bool {
+	return true
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reputation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestTracker(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig
+	config.MinScore = -3
+	trackerIntf, err := NewTracker(config, prometheus.NewRegistry())
+	require.NoError(err)
+	tracker := trackerIntf.(*tracker)
+
+	now := time.Unix(1_000_000, 0)
+	tracker.clock.Set(now)
+
+	nodeID := ids.GenerateTestNodeID()
+	require.Zero(tracker.Score(nodeID))
+	require.True(tracker.Trusted(nodeID))
+
+	tracker.Register(nodeID, HandshakeFailure)
+	tracker.Register(nodeID, InvalidMessage)
+	require.InDelta(-3, tracker.Score(nodeID), 1e-9)
+	require.True(tracker.Trusted(nodeID))
+
+	require.True(tracker.Admissible(nodeID))
+
+	tracker.Register(nodeID, Timeout)
+	require.InDelta(-3.25, tracker.Score(nodeID), 1e-9)
+	require.False(tracker.Trusted(nodeID))
+	require.Equal(float64(1), testutil.ToFloat64(tracker.events.WithLabelValues(Timeout.String())))
+
+	// Timeouts don't affect whether the peer is admissible.
+	require.True(tracker.Admissible(nodeID))
+	tracker.Register(nodeID, BadGossip)
+	require.False(tracker.Admissible(nodeID))
+
+	// Penalties decay over time.
+	tracker.clock.Set(now.Add(config.Halflife))
+	require.InDelta(-2.625, tracker.Score(nodeID), 1e-9)
+	require.True(tracker.Trusted(nodeID))
+	require.True(tracker.Admissible(nodeID))
+
+	// Bandwidth raises the score.
+	tracker.RegisterBandwidth(nodeID, config.BandwidthHalfScore)
+	require.InDelta(-2.625+config.BandwidthWeight/2, tracker.Score(nodeID), 1e-9)
+}
+
+func TestConfigVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr error
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "zero halflife",
+			modify: func(c *Config) {
+				c.Halflife = 0
+			},
+			expectedErr: errNonPositiveHalflife,
+		},
+		{
+			name: "negative penalty",
+			modify: func(c *Config) {
+				c.BadGossipPenalty = -1
+			},
+			expectedErr: errNegativePenalty,
+		},
+		{
+			name: "negative bandwidth weight",
+			modify: func(c *Config) {
+				c.BandwidthWeight = -1
+			},
+			expectedErr: errNegativeBandwidth,
+		},
+		{
+			name: "zero bandwidth half score",
+			modify: func(c *Config) {
+				c.BandwidthHalfScore = 0
+			},
+			expectedErr: errNonPositiveHalfScore,
+		},
+		{
+			name: "positive min score",
+			modify: func(c *Config) {
+				c.MinScore = 1
+			},
+			expectedErr: errPositiveMinScore,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig
+			test.modify(&config)
+			require.ErrorIs(t, config.Verify(), test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package simnet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/dialer"
+)
+
+var (
+	_ net.Conn      = (*conn)(nil)
+	_ net.Listener  = (*Listener)(nil)
+	_ dialer.Dialer = (*Dialer)(nil)
+
+	ErrListenerExists = errors.New("listener already exists")
+
+	errRefused     = errors.New("connection refused")
+	errUnreachable = errors.New("host unreachable")
+)
+
+// NewListener returns a listener that accepts the connections dialed to
+// [nodeID]. The address of the listener should be used as the IP of the node.
+func (n *Network) NewListener(nodeID ids.NodeID) (*Listener, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.listeners[nodeID]; ok {
+		return nil, ErrListenerExists
+	}
+
+	l := &Listener{
+		network: n,
+		nodeID:  nodeID,
+		addr:    n.addr(nodeID),
+		inbound: make(chan net.Conn),
+		closed:  make(chan struct{}),
+	}
+	n.listeners[nodeID] = l
+	return l, nil
+}
+
+// NewDialer returns a dialer that connects [nodeID] to the listeners of the
+// network.
+//
+// Connections are streams: chunks that are dropped are delayed as if they were
+// retransmitted, and chunks sent across a partition are delivered once the
+// partition is healed. Deadlines of connections are evaluated against the
+// clock of the network, so they should be set relative to [Network.Now].
+func (n *Network) NewDialer(nodeID ids.NodeID) *Dialer {
+	return &Dialer{
+		network: n,
+		nodeID:  nodeID,
+	}
+}
+
+type Listener struct {
+	network   *Network
+	nodeID    ids.NodeID
+	addr      netip.AddrPort
+	inbound   chan net.Conn
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// AddrPort returns the address that the listener accepts connections on.
+func (l *Listener) AddrPort() netip.AddrPort {
+	return l.addr
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.inbound:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops accepting connections and unregisters the listener, so that a
+// new listener can be created for the node.
+func (l *Listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+
+		n := l.network
+		n.lock.Lock()
+		defer n.lock.Unlock()
+
+		if n.listeners[l.nodeID] == l {
+			delete(n.listeners, l.nodeID)
+		}
+	})
+	return nil
+}
+
+func (l *Listener) Addr() net.Addr {
+	return net.TCPAddrFromAddrPort(l.addr)
+}
+
+type Dialer struct {
+	network *Network
+	nodeID  ids.NodeID
+}
+
+// Dial connects to the listener at [ip]. The connection is established after
+// the handshake has traversed the link to the listener.
+func (d *Dialer) Dial(ctx context.Context, ip netip.AddrPort) (net.Conn, error) {
+	n := d.network
+
+	n.lock.Lock()
+	remoteNodeID, ok := n.nodes[ip]
+	listener := n.listeners[remoteNodeID]
+	if !ok || listener == nil {
+		n.lock.Unlock()
+		return nil, errRefused
+	}
+	if !n.connected(d.nodeID, remoteNodeID) {
+		n.lock.Unlock()
+		return nil, errUnreachable
+	}
+	localAddr := netip.AddrPortFrom(n.addr(d.nodeID).Addr(), n.nextPort)
+	n.nextPort++
+	n.lock.Unlock()
+
+	client, server := newConnPair(n, d.nodeID, localAddr, remoteNodeID, ip)
+	established := make(chan struct{})
+	n.sendStream(d.nodeID, remoteNodeID, client.out, 0, func() {
+		close(established)
+	})
+
+	select {
+	case <-established:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case listener.inbound <- server:
+		return client, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-listener.closed:
+		return nil, errRefused
+	}
+}
+
+// conn is one end of a simulated stream connection.
+type conn struct {
+	network    *Network
+	nodeID     ids.NodeID
+	localAddr  netip.AddrPort
+	remoteAddr netip.AddrPort
+	out        *stream
+	remote     *conn
+
+	lock          sync.Mutex
+	buffer        []byte
+	remoteClosed  bool
+	closed        bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+	// wakeAt is when the earliest scheduled wake up of reads is executed, or
+	// zero if none is scheduled.
+	wakeAt time.Time
+	// ready is signalled whenever a blocked read may be able to make progress.
+	ready chan struct{}
+}
+
+func newConnPair(
+	n *Network,
+	clientNodeID ids.NodeID,
+	clientAddr netip.AddrPort,
+	serverNodeID ids.NodeID,
+	serverAddr netip.AddrPort,
+) (*conn, *conn) {
+	client := &conn{
+		network:    n,
+		nodeID:     clientNodeID,
+		localAddr:  clientAddr,
+		remoteAddr: serverAddr,
+		out:        &stream{},
+		ready:      make(chan struct{}, 1),
+	}
+	server := &conn{
+		network:    n,
+		nodeID:     serverNodeID,
+		localAddr:  serverAddr,
+		remoteAddr: clientAddr,
+		out:        &stream{},
+		ready:      make(chan struct{}, 1),
+	}
+	client.remote = server
+	server.remote = client
+	return client, server
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	for {
+		c.lock.Lock()
+		switch {
+		case c.closed:
+			c.lock.Unlock()
+			return 0, net.ErrClosed
+		case len(c.buffer) > 0:
+			n := copy(b, c.buffer)
+			c.buffer = c.buffer[n:]
+			c.lock.Unlock()
+			return n, nil
+		case c.remoteClosed:
+			c.lock.Unlock()
+			return 0, io.EOF
+		}
+		deadline := c.readDeadline
+		c.lock.Unlock()
+
+		if !deadline.IsZero() && !c.network.Now().Before(deadline) {
+			return 0, os.ErrDeadlineExceeded
+		}
+		// The read is woken up by [SetReadDeadline] once the deadline is
+		// reached.
+		<-c.ready
+	}
+}
+
+// Write never blocks, as if the connection had an unbounded send buffer.
+func (c *conn) Write(b []byte) (int, error) {
+	now := c.network.Now()
+
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return 0, net.ErrClosed
+	}
+	if !c.writeDeadline.IsZero() && !now.Before(c.writeDeadline) {
+		c.lock.Unlock()
+		return 0, os.ErrDeadlineExceeded
+	}
+	c.lock.Unlock()
+
+	chunk := make([]byte, len(b))
+	copy(chunk, b)
+	c.network.sendStream(c.nodeID, c.remote.nodeID, c.out, len(chunk), func() {
+		c.remote.receive(chunk)
+	})
+	return len(b), nil
+}
+
+// Close closes the connection. The remote end reads [io.EOF] after it has read
+// all the chunks that were written before the connection was closed.
+func (c *conn) Close() error {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.lock.Unlock()
+	c.signal()
+
+	c.network.sendStream(c.nodeID, c.remote.nodeID, c.out, 0, c.remote.closeRemote)
+	return nil
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return net.TCPAddrFromAddrPort(c.localAddr)
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return net.TCPAddrFromAddrPort(c.remoteAddr)
+}
+
+func (c *conn) SetDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.writeDeadline = t
+	c.lock.Unlock()
+	return c.SetReadDeadline(t)
+}
+
+// SetReadDeadline wakes up blocked reads now and once the clock of the network
+// reaches [t], so that they can re-evaluate the deadline.
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.readDeadline = t
+	// Deadlines are usually extended, so at most one wake up is scheduled
+	// unless the deadline is moved earlier.
+	schedule := !t.IsZero() && (c.wakeAt.IsZero() || t.Before(c.wakeAt))
+	if schedule {
+		c.wakeAt = t
+	}
+	c.lock.Unlock()
+	c.signal()
+
+	if schedule {
+		c.network.at(t, c.wake)
+	}
+	return nil
+}
+
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.writeDeadline = t
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *conn) receive(chunk []byte) {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return
+	}
+	c.buffer = append(c.buffer, chunk...)
+	c.lock.Unlock()
+	c.signal()
+}
+
+func (c *conn) closeRemote() {
+	c.lock.Lock()
+	c.remoteClosed = true
+	c.lock.Unlock()
+	c.signal()
+}
+
+// wake wakes up blocked reads and schedules the next wake up if the read
+// deadline was extended.
+func (c *conn) wake() {
+	now := c.network.Now()
+
+	c.lock.Lock()
+	c.wakeAt = time.Time{}
+	schedule := !c.readDeadline.IsZero() && c.readDeadline.After(now)
+	if schedule {
+		c.wakeAt = c.readDeadline
+	}
+	deadline := c.readDeadline
+	c.lock.Unlock()
+	c.signal()
+
+	if schedule {
+		c.network.at(deadline, c.wake)
+	}
+}
+
+func (c *conn) signal() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
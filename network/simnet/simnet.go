@@ -0,0 +1,381 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package simnet implements a simulated network that delivers messages
+// between nodes according to per-link latency, bandwidth, and drop rules, and
+// that can be partitioned and healed by scripted events.
+//
+// Time is simulated: messages are only delivered when the clock of the
+// network is advanced, either manually with [Network.Advance] or in real time
+// with [Network.Run]. Drop and jitter decisions are made with a random source
+// that is seeded per link, so a test that sends the same messages over a link
+// observes the same outcomes on every run.
+package simnet
+
+import (
+	"context"
+	"encoding/binary"
+	"maps"
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/heap"
+)
+
+const (
+	// retransmissionTimeout is the delay added to a stream chunk that is
+	// dropped, as a stream retransmits lost chunks rather than losing them.
+	retransmissionTimeout = 200 * time.Millisecond
+
+	// tickFrequency is how often [Network.Run] advances the clock.
+	tickFrequency = time.Millisecond
+
+	// firstPort is the port of the address of every node.
+	firstPort = 9651
+)
+
+// Link describes how messages are delivered from one node to another.
+type Link struct {
+	// Latency is the minimum time it takes for a message to be delivered.
+	Latency time.Duration
+	// Jitter is the maximum random delay added to the latency.
+	Jitter time.Duration
+	// Bandwidth is the number of bytes per second that can be sent over the
+	// link. If zero, the bandwidth is unlimited.
+	Bandwidth uint64
+	// DropRate is the probability that a message is dropped.
+	DropRate float64
+}
+
+type linkID struct {
+	from ids.NodeID
+	to   ids.NodeID
+}
+
+type link struct {
+	Link
+
+	rng       *rand.Rand
+	busyUntil time.Time
+	// held are the stream chunks sent while the link was partitioned. They
+	// are resent when the link is healed.
+	held []heldChunk
+}
+
+type heldChunk struct {
+	numBytes int
+	stream   *stream
+	deliver  func()
+}
+
+// stream orders the chunks sent in one direction of a connection.
+type stream struct {
+	lastDelivery time.Time
+}
+
+type event struct {
+	at  time.Time
+	seq uint64
+	f   func()
+}
+
+// Network simulates the links between nodes.
+//
+// Callbacks passed to the network are executed by the goroutine advancing the
+// clock, without any locks held, so they may send further messages.
+type Network struct {
+	seed        uint64
+	defaultLink Link
+
+	lock    sync.Mutex
+	now     time.Time
+	nextSeq uint64
+	events  heap.Queue[*event]
+	links   map[linkID]*link
+	// groups maps nodes to their partition. Nodes that aren't in any
+	// partition are in group 0.
+	groups    map[ids.NodeID]int
+	addrs     map[ids.NodeID]netip.AddrPort
+	nodes     map[netip.AddrPort]ids.NodeID
+	listeners map[ids.NodeID]*Listener
+	nextPort  uint16
+}
+
+// New returns a network whose links use [defaultLink] unless overridden with
+// [Network.SetLink]. All random decisions are derived from [seed].
+func New(seed uint64, defaultLink Link) *Network {
+	return &Network{
+		seed:        seed,
+		defaultLink: defaultLink,
+		now:         time.Unix(0, 0),
+		events: heap.NewQueue(func(a, b *event) bool {
+			if a.at.Equal(b.at) {
+				return a.seq < b.seq
+			}
+			return a.at.Before(b.at)
+		}),
+		links:     make(map[linkID]*link),
+		groups:    make(map[ids.NodeID]int),
+		addrs:     make(map[ids.NodeID]netip.AddrPort),
+		nodes:     make(map[netip.AddrPort]ids.NodeID),
+		listeners: make(map[ids.NodeID]*Listener),
+		nextPort:  firstPort + 1,
+	}
+}
+
+// Now returns the current time of the network.
+func (n *Network) Now() time.Time {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.now
+}
+
+// SetLink sets the rules of messages sent from [from] to [to]. Links are
+// directional, so the rules of messages sent from [to] to [from] are
+// unchanged.
+func (n *Network) SetLink(from, to ids.NodeID, l Link) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.getLink(from, to).Link = l
+}
+
+// Partition splits the network into isolated groups of nodes. Nodes can only
+// communicate with nodes in the same group. Nodes that aren't in any of
+// [groups] form an additional group.
+//
+// Messages that are already in flight are still delivered. Datagrams sent
+// across a partition are dropped, while stream chunks are held until the
+// partition is healed.
+func (n *Network) Partition(groups ...[]ids.NodeID) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	clear(n.groups)
+	for i, group := range groups {
+		for _, nodeID := range group {
+			n.groups[nodeID] = i + 1
+		}
+	}
+	n.resendHeld()
+}
+
+// Heal removes all partitions.
+func (n *Network) Heal() {
+	n.Partition()
+}
+
+// After schedules [f] to be executed once the clock has advanced by [d]. This
+// can be used to script changes to the network, such as partitions.
+func (n *Network) After(d time.Duration, f func()) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.schedule(n.now.Add(d), f)
+}
+
+// at schedules [f] to be executed once the clock reaches [t]. If [t] has
+// already passed, [f] is executed the next time the clock is advanced.
+func (n *Network) at(t time.Time, f func()) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if t.Before(n.now) {
+		t = n.now
+	}
+	n.schedule(t, f)
+}
+
+// Advance moves the clock forward by [d], executing every event that is due
+// in order. Advance must not be called concurrently.
+func (n *Network) Advance(d time.Duration) {
+	n.lock.Lock()
+	target := n.now.Add(d)
+	n.lock.Unlock()
+
+	for {
+		n.lock.Lock()
+		next, ok := n.events.Peek()
+		if !ok || next.at.After(target) {
+			n.now = target
+			n.lock.Unlock()
+			return
+		}
+		_, _ = n.events.Pop()
+		n.now = next.at
+		n.lock.Unlock()
+
+		next.f()
+	}
+}
+
+// Run advances the clock in real time until [ctx] is cancelled. This allows
+// the network to be used by code that relies on real time, such as
+// connections passed to [network.NewNetwork].
+func (n *Network) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickFrequency)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n.Advance(now.Sub(last))
+			last = now
+		}
+	}
+}
+
+// Send schedules [deliver] to be executed once a datagram of [numBytes] sent
+// from [from] would arrive at [to]. Returns false if the datagram was dropped,
+// in which case [deliver] is never executed.
+func (n *Network) Send(from, to ids.NodeID, numBytes int, deliver func()) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if !n.connected(from, to) {
+		return false
+	}
+
+	l := n.getLink(from, to)
+	deliverAt, dropped := n.transmit(l, numBytes)
+	if dropped {
+		return false
+	}
+	n.schedule(deliverAt, deliver)
+	return true
+}
+
+// sendStream schedules [deliver] to be executed once a chunk of [numBytes]
+// sent over [s] would arrive at [to]. Chunks of a stream are never dropped and
+// are delivered in order.
+func (n *Network) sendStream(from, to ids.NodeID, s *stream, numBytes int, deliver func()) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	l := n.getLink(from, to)
+	if !n.connected(from, to) {
+		l.held = append(l.held, heldChunk{
+			numBytes: numBytes,
+			stream:   s,
+			deliver:  deliver,
+		})
+		return
+	}
+	n.sendStreamLocked(l, s, numBytes, deliver)
+}
+
+func (n *Network) sendStreamLocked(l *link, s *stream, numBytes int, deliver func()) {
+	deliverAt, dropped := n.transmit(l, numBytes)
+	if dropped {
+		deliverAt = deliverAt.Add(retransmissionTimeout)
+	}
+	if deliverAt.Before(s.lastDelivery) {
+		deliverAt = s.lastDelivery
+	}
+	s.lastDelivery = deliverAt
+	n.schedule(deliverAt, deliver)
+}
+
+// resendHeld resends the held stream chunks of every link that is no longer
+// partitioned.
+func (n *Network) resendHeld() {
+	// Links are iterated in a deterministic order so that the chunks are
+	// scheduled in the same order on every run.
+	linkIDs := slices.SortedFunc(maps.Keys(n.links), func(a, b linkID) int {
+		if c := a.from.Compare(b.from); c != 0 {
+			return c
+		}
+		return a.to.Compare(b.to)
+	})
+	for _, id := range linkIDs {
+		l := n.links[id]
+		if len(l.held) == 0 || !n.connected(id.from, id.to) {
+			continue
+		}
+		held := l.held
+		l.held = nil
+		for _, chunk := range held {
+			n.sendStreamLocked(l, chunk.stream, chunk.numBytes, chunk.deliver)
+		}
+	}
+}
+
+// transmit returns when a message of [numBytes] sent over [l] now would be
+// delivered and whether it was dropped.
+func (n *Network) transmit(l *link, numBytes int) (time.Time, bool) {
+	// Random values are always drawn in the same order so that the outcome of
+	// a message only depends on the messages previously sent over the link.
+	var (
+		dropped = l.rng.Float64() < l.DropRate
+		jitter  = time.Duration(l.rng.Int64N(int64(l.Jitter) + 1))
+	)
+
+	departAt := n.now
+	if departAt.Before(l.busyUntil) {
+		departAt = l.busyUntil
+	}
+	if l.Bandwidth > 0 {
+		transmission := time.Duration(uint64(numBytes) * uint64(time.Second) / l.Bandwidth)
+		departAt = departAt.Add(transmission)
+	}
+	l.busyUntil = departAt
+	return departAt.Add(l.Latency + jitter), dropped
+}
+
+func (n *Network) connected(from, to ids.NodeID) bool {
+	return n.groups[from] == n.groups[to]
+}
+
+func (n *Network) getLink(from, to ids.NodeID) *link {
+	id := linkID{
+		from: from,
+		to:   to,
+	}
+	l, ok := n.links[id]
+	if !ok {
+		// Every link is seeded independently so that the order in which links
+		// are used doesn't change their outcomes.
+		l = &link{
+			Link: n.defaultLink,
+			rng: rand.New(rand.NewPCG(
+				n.seed,
+				binary.BigEndian.Uint64(from[:])^binary.BigEndian.Uint64(to[ids.NodeIDLen-8:]),
+			)),
+		}
+		n.links[id] = l
+	}
+	return l
+}
+
+func (n *Network) schedule(at time.Time, f func()) {
+	n.events.Push(&event{
+		at:  at,
+		seq: n.nextSeq,
+		f:   f,
+	})
+	n.nextSeq++
+}
+
+// addr returns the address of [nodeID], assigning one if needed.
+func (n *Network) addr(nodeID ids.NodeID) netip.AddrPort {
+	if addr, ok := n.addrs[nodeID]; ok {
+		return addr
+	}
+	// Private IPs are used so that tests can enable AllowPrivateIPs.
+	i := len(n.addrs) + 1
+	addr := netip.AddrPortFrom(
+		netip.AddrFrom4([4]byte{10, 0, byte(i >> 8), byte(i)}),
+		firstPort,
+	)
+	n.addrs[nodeID] = addr
+	n.nodes[addr] = nodeID
+	return addr
+}
@@ -0,0 +1,240 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package simnet
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     Link
+		numBytes []int
+		expected []time.Duration
+	}{
+		{
+			name: "latency",
+			link: Link{
+				Latency: time.Second,
+			},
+			numBytes: []int{100, 100},
+			expected: []time.Duration{time.Second, time.Second},
+		},
+		{
+			name: "bandwidth",
+			link: Link{
+				Latency:   time.Second,
+				Bandwidth: 100,
+			},
+			numBytes: []int{100, 50},
+			expected: []time.Duration{2 * time.Second, 2500 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			var (
+				n     = New(0, tt.link)
+				start = n.Now()
+				from  = ids.GenerateTestNodeID()
+				to    = ids.GenerateTestNodeID()
+			)
+			var delivered []time.Duration
+			for _, numBytes := range tt.numBytes {
+				require.True(n.Send(from, to, numBytes, func() {
+					delivered = append(delivered, n.Now().Sub(start))
+				}))
+			}
+
+			n.Advance(time.Hour)
+			require.Equal(tt.expected, delivered)
+		})
+	}
+}
+
+func TestSendDeterministic(t *testing.T) {
+	var (
+		from = ids.GenerateTestNodeID()
+		to   = ids.GenerateTestNodeID()
+		link = Link{
+			Latency:  time.Second,
+			Jitter:   time.Second,
+			DropRate: .5,
+		}
+	)
+	send := func(seed uint64) []time.Duration {
+		var (
+			n         = New(seed, link)
+			start     = n.Now()
+			delivered []time.Duration
+		)
+		for range 100 {
+			n.Send(from, to, 1, func() {
+				delivered = append(delivered, n.Now().Sub(start))
+			})
+		}
+		n.Advance(time.Hour)
+		return delivered
+	}
+
+	require := require.New(t)
+	delivered := send(1)
+	require.Equal(delivered, send(1))
+	require.NotEqual(delivered, send(2))
+	require.NotEmpty(delivered)
+	require.Less(len(delivered), 100)
+}
+
+func TestPartition(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		n     = New(0, Link{Latency: time.Second})
+		node0 = ids.GenerateTestNodeID()
+		node1 = ids.GenerateTestNodeID()
+		node2 = ids.GenerateTestNodeID()
+	)
+	n.After(time.Second, func() {
+		n.Partition([]ids.NodeID{node0})
+	})
+	n.After(3*time.Second, n.Heal)
+
+	n.Advance(2 * time.Second)
+	require.False(n.Send(node0, node1, 1, func() {}))
+	require.False(n.Send(node1, node0, 1, func() {}))
+	require.True(n.Send(node1, node2, 1, func() {}))
+
+	var (
+		s        = &stream{}
+		received bool
+	)
+	n.sendStream(node0, node1, s, 1, func() {
+		received = true
+	})
+	n.Advance(time.Second)
+	require.False(received)
+
+	// The held chunk is resent once the partition is healed.
+	n.Advance(time.Second)
+	require.True(received)
+	require.True(n.Send(node0, node1, 1, func() {}))
+}
+
+func TestConn(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var (
+		n      = New(0, Link{Latency: time.Millisecond})
+		client = ids.GenerateTestNodeID()
+		server = ids.GenerateTestNodeID()
+	)
+	go n.Run(ctx)
+
+	listener, err := n.NewListener(server)
+	require.NoError(err)
+	_, err = n.NewListener(server)
+	require.ErrorIs(err, ErrListenerExists)
+
+	accepted := make(chan io.ReadWriteCloser, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	clientConn, err := n.NewDialer(client).Dial(ctx, listener.AddrPort())
+	require.NoError(err)
+	serverConn := <-accepted
+
+	_, err = clientConn.Write([]byte("hello"))
+	require.NoError(err)
+	_, err = clientConn.Write([]byte(" world"))
+	require.NoError(err)
+	require.NoError(clientConn.Close())
+
+	msg, err := io.ReadAll(serverConn)
+	require.NoError(err)
+	require.Equal("hello world", string(msg))
+
+	n.Partition([]ids.NodeID{client})
+	_, err = n.NewDialer(client).Dial(ctx, listener.AddrPort())
+	require.ErrorIs(err, errUnreachable)
+	n.Heal()
+
+	// Closing the listener refuses new connections and allows the node to
+	// listen again.
+	require.NoError(listener.Close())
+	_, err = n.NewDialer(client).Dial(ctx, listener.AddrPort())
+	require.ErrorIs(err, errRefused)
+
+	listener, err = n.NewListener(server)
+	require.NoError(err)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	_, err = n.NewDialer(client).Dial(ctx, listener.AddrPort())
+	require.NoError(err)
+	require.NotNil(<-accepted)
+}
+
+func TestConnDeadline(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		n      = New(0, Link{Latency: time.Millisecond})
+		client = ids.GenerateTestNodeID()
+		server = ids.GenerateTestNodeID()
+	)
+	clientConn, _ := newConnPair(n, client, n.addr(client), server, n.addr(server))
+
+	// Deadlines are evaluated against the clock of the network rather than
+	// real time.
+	require.NoError(clientConn.SetDeadline(n.Now().Add(time.Second)))
+	_, err := clientConn.Write([]byte("hello"))
+	require.NoError(err)
+
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := clientConn.Read(make([]byte, 1))
+		readErr <- err
+	}()
+
+	n.Advance(500 * time.Millisecond)
+	select {
+	case err := <-readErr:
+		require.FailNow("read returned before the deadline", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Extending the deadline delays the timeout of the blocked read.
+	require.NoError(clientConn.SetReadDeadline(n.Now().Add(time.Second)))
+	n.Advance(500 * time.Millisecond)
+	select {
+	case err := <-readErr:
+		require.FailNow("read returned before the deadline", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	n.Advance(500 * time.Millisecond)
+	require.ErrorIs(<-readErr, os.ErrDeadlineExceeded)
+
+	_, err = clientConn.Write([]byte("hello"))
+	require.ErrorIs(err, os.ErrDeadlineExceeded)
+}
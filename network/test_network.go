@@ -170,6 +170,7 @@ func NewTestNetworkConfig(
 		PingFrequency:                constants.DefaultPingFrequency,
 		AllowPrivateIPs:              !constants.ProductionNetworkIDs.Contains(networkID),
 		CompressionType:              constants.DefaultNetworkCompressionType,
+		CompressionZstdLevel:         constants.DefaultNetworkCompressionZstdLevel,
 		TLSKey:                       tlsCert.PrivateKey.(crypto.Signer),
 		BLSKey:                       blsKey,
 		TrackedSubnets:               trackedSubnets,
@@ -181,6 +182,7 @@ func NewTestNetworkConfig(
 		MaximumInboundMessageTimeout: constants.DefaultNetworkMaximumInboundTimeout,
 		PeerReadBufferSize:           constants.DefaultNetworkPeerReadBufferSize,
 		PeerWriteBufferSize:          constants.DefaultNetworkPeerWriteBufferSize,
+		SendQueueWeights:             peer.DefaultPriorityWeights,
 		ResourceTracker:              resourceTracker,
 		CPUTargeter: tracker.NewTargeter(
 			logging.NoLog{},
@@ -214,6 +216,7 @@ func NewTestNetwork(
 	msgCreator, err := message.NewCreator(
 		metrics,
 		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkCompressionZstdLevel,
 		constants.DefaultNetworkMaximumInboundTimeout,
 	)
 	if err != nil {
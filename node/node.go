@@ -37,10 +37,12 @@ import (
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/config/node"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/cachedb"
 	"github.com/ava-labs/avalanchego/database/leveldb"
 	"github.com/ava-labs/avalanchego/database/meterdb"
 	"github.com/ava-labs/avalanchego/database/pebbledb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/snapshot"
 	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/indexer"
@@ -49,6 +51,8 @@ import (
 	"github.com/ava-labs/avalanchego/network"
 	"github.com/ava-labs/avalanchego/network/dialer"
 	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/network/quic"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
@@ -99,6 +103,7 @@ const (
 
 	apiNamespace             = constants.PlatformName + metric.NamespaceSeparator + "api"
 	benchlistNamespace       = constants.PlatformName + metric.NamespaceSeparator + "benchlist"
+	dbCacheNamespace         = constants.PlatformName + metric.NamespaceSeparator + "db_cache"
 	dbNamespace              = constants.PlatformName + metric.NamespaceSeparator + "db"
 	healthNamespace          = constants.PlatformName + metric.NamespaceSeparator + "health"
 	meterDBNamespace         = constants.PlatformName + metric.NamespaceSeparator + "meterdb"
@@ -117,6 +122,7 @@ var (
 	ungracefulShutdown = []byte("ungracefulShutdown")
 
 	indexerDBPrefix = []byte{0x00}
+	bansDBPrefix    = []byte("bans")
 
 	errInvalidTLSKey        = errors.New("invalid TLS key")
 	errShuttingDown         = errors.New("server shutting down")
@@ -227,6 +233,7 @@ func New(
 	n.msgCreator, err = message.NewCreator(
 		networkRegisterer,
 		n.Config.NetworkConfig.CompressionType,
+		n.Config.NetworkConfig.CompressionZstdLevel,
 		n.Config.NetworkConfig.MaximumInboundMessageTimeout,
 	)
 	if err != nil {
@@ -306,6 +313,9 @@ type Node struct {
 
 	// Storage for this node
 	DB database.Database
+	// Read cache shared by the databases of all chains. Nil if reads aren't
+	// cached.
+	DBCacheBudget *cachedb.Budget
 
 	router     nat.Router
 	portMapper *nat.Mapper
@@ -384,6 +394,7 @@ type Node struct {
 	// Metrics Registerer
 	MetricsGatherer        metrics.MultiGatherer
 	MeterDBMetricsGatherer metrics.MultiGatherer
+	DBCacheMetricsGatherer metrics.MultiGatherer
 
 	VMAliaser ids.Aliaser
 	VMManager *vms.Manager
@@ -400,6 +411,9 @@ type Node struct {
 	// messages of each peer.
 	resourceTracker tracker.ResourceTracker
 
+	// Scores peers based on their behavior.
+	reputationTracker reputation.Tracker
+
 	// Specifies how much CPU usage each peer can cause before
 	// we rate-limit them.
 	cpuTargeter tracker.Targeter
@@ -569,6 +583,15 @@ func (n *Node) initNetworking(reg prometheus.Registerer) error {
 	}
 	n.benchlistManager = benchlist.NewManager(n.chainRouter, n.vdrs, benchlistReg, n.Config.BenchlistConfig)
 
+	if n.Config.ReputationConfig.Enabled {
+		n.reputationTracker, err = reputation.NewTracker(n.Config.ReputationConfig, reg)
+		if err != nil {
+			return fmt.Errorf("couldn't initialize reputation tracker: %w", err)
+		}
+	} else {
+		n.reputationTracker = reputation.NewNoTracker()
+	}
+
 	n.uptimeCalculator = uptime.NewLockedCalculator()
 
 	var consensusRouter router.ExternalHandler = n.chainRouter
@@ -628,6 +651,28 @@ func (n *Node) initNetworking(reg prometheus.Registerer) error {
 	n.Config.NetworkConfig.ResourceTracker = n.resourceTracker
 	n.Config.NetworkConfig.CPUTargeter = n.cpuTargeter
 	n.Config.NetworkConfig.DiskTargeter = n.diskTargeter
+	n.Config.NetworkConfig.BanDB = prefixdb.New(bansDBPrefix, n.DB)
+	n.Config.NetworkConfig.ReputationTracker = n.reputationTracker
+
+	if n.Config.NetworkConfig.QUICEnabled {
+		quicPort := n.Config.NetworkConfig.QUICPort
+		if quicPort == 0 {
+			quicPort = stakingPort
+		}
+		quicAddress := net.JoinHostPort(n.Config.ListenHost, strconv.FormatUint(uint64(quicPort), 10))
+		quicTransport, err := quic.NewTransport(
+			quicAddress,
+			tlsConfig,
+			n.Config.NetworkConfig.DialerConfig.ConnectionTimeout,
+		)
+		if err != nil {
+			return fmt.Errorf("couldn't initialize QUIC transport: %w", err)
+		}
+		// Wrap listener so it will only accept a certain number of incoming
+		// connections per second
+		n.Config.NetworkConfig.QUICListener = throttling.NewThrottledListener(quicTransport, n.Config.NetworkConfig.ThrottlerConfig.MaxInboundConnsPerSec)
+		n.Config.NetworkConfig.QUICDialer = quicTransport
+	}
 
 	n.Net, err = network.NewNetwork(
 		&n.Config.NetworkConfig,
@@ -771,6 +816,29 @@ func (n *Node) initDatabase() error {
 	// dbFolderName is appended to the database path given in the config
 	dbFullPath := filepath.Join(n.Config.DatabaseConfig.Path, dbFolderName)
 
+	if snapshotName := n.Config.DatabaseConfig.RestoreSnapshot; len(snapshotName) != 0 {
+		movedTo, err := snapshot.Restore(
+			n.Config.DatabaseConfig.SnapshotDir,
+			snapshotName,
+			n.Config.DatabaseConfig.Name,
+			dbFullPath,
+		)
+		switch {
+		case errors.Is(err, snapshot.ErrAlreadyRestored):
+			n.Log.Info("skipping restore of database snapshot",
+				zap.String("snapshot", snapshotName),
+				zap.String("reason", "snapshot was already restored"),
+			)
+		case err != nil:
+			return fmt.Errorf("couldn't restore database snapshot %q: %w", snapshotName, err)
+		default:
+			n.Log.Warn("restored database from snapshot",
+				zap.String("snapshot", snapshotName),
+				zap.String("previousDatabase", movedTo),
+			)
+		}
+	}
+
 	dbReg, err := metrics.MakeAndRegister(
 		n.MetricsGatherer,
 		dbNamespace,
@@ -804,6 +872,10 @@ func (n *Node) initDatabase() error {
 		return err
 	}
 
+	if cacheSize := n.Config.DatabaseConfig.CacheSize; cacheSize > 0 {
+		n.DBCacheBudget = cachedb.NewBudget(int(cacheSize))
+	}
+
 	rawExpectedGenesisHash := hashing.ComputeHash256(n.Config.GenesisBytes)
 
 	rawGenesisHash, err := n.DB.Get(genesisHashKey)
@@ -923,9 +995,16 @@ func (n *Node) initChains(genesisBytes []byte) error {
 func (n *Node) initMetrics() error {
 	n.MetricsGatherer = metrics.NewPrefixGatherer()
 	n.MeterDBMetricsGatherer = metrics.NewLabelGatherer(chains.ChainLabel)
-	return n.MetricsGatherer.Register(
-		meterDBNamespace,
-		n.MeterDBMetricsGatherer,
+	n.DBCacheMetricsGatherer = metrics.NewLabelGatherer(chains.ChainLabel)
+	return errors.Join(
+		n.MetricsGatherer.Register(
+			meterDBNamespace,
+			n.MeterDBMetricsGatherer,
+		),
+		n.MetricsGatherer.Register(
+			dbCacheNamespace,
+			n.DBCacheMetricsGatherer,
+		),
 	)
 }
 
@@ -1094,6 +1173,7 @@ func (n *Node) initChainManager(avaxAssetID ids.ID) error {
 	n.timeoutManager, err = timeout.NewManager(
 		&n.Config.AdaptiveTimeoutConfig,
 		n.benchlistManager,
+		n.reputationTracker,
 		requestsReg,
 		responseReg,
 	)
@@ -1156,6 +1236,8 @@ func (n *Node) initChainManager(avaxAssetID ids.ID) error {
 			MeterVMEnabled:                          n.Config.MeterVMEnabled,
 			Metrics:                                 n.MetricsGatherer,
 			MeterDBMetrics:                          n.MeterDBMetricsGatherer,
+			DBCacheBudget:                           n.DBCacheBudget,
+			DBCacheMetrics:                          n.DBCacheMetricsGatherer,
 			ProposerMinBlockDelay:                   n.Config.ProposerMinBlockDelay,
 			SubnetConfigs:                           n.Config.SubnetConfigs,
 			ChainConfigs:                            n.Config.ChainConfigs,
@@ -1166,10 +1248,13 @@ func (n *Node) initChainManager(avaxAssetID ids.ID) error {
 			BootstrapAncestorsMaxContainersReceived: n.Config.BootstrapAncestorsMaxContainersReceived,
 			Upgrades:                                n.Config.UpgradeConfig,
 			ResourceTracker:                         n.resourceTracker,
+			ReputationTracker:                       n.reputationTracker,
 			StateSyncBeacons:                        n.Config.StateSyncIDs,
 			TracingEnabled:                          n.Config.TraceConfig.ExporterConfig.Type != trace.Disabled,
 			Tracer:                                  n.tracer,
 			ChainDataDir:                            n.Config.ChainDataDir,
+			TrackedSubnets:                          n.Config.TrackedSubnets,
+			PruneUntrackedChains:                    n.Config.PruneUntrackedChains,
 			Subnets:                                 subnets,
 		},
 	)
@@ -1339,10 +1424,17 @@ func (n *Node) initAdminAPI() error {
 		return nil
 	}
 	n.Log.Info("initializing admin API")
+	dbSnapshots := snapshot.NewManager(
+		n.Config.DatabaseConfig.SnapshotDir,
+		n.Config.DatabaseConfig.Name,
+		n.DB,
+	)
 	service, err := admin.NewService(
 		admin.Config{
 			Log:          n.Log,
 			DB:           n.DB,
+			DBSnapshots:  dbSnapshots,
+			Network:      n.Net,
 			ChainManager: n.chainManager,
 			HTTPServer:   n.APIServer,
 			ProfileDir:   n.Config.ProfilerConfig.Dir,
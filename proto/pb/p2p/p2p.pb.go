@@ -83,6 +83,9 @@ type Message struct {
 	// Types that are valid to be assigned to Message:
 	//
 	//	*Message_CompressedZstd
+	//	*Message_CompressedSnappy
+	//	*Message_CompressedLz4
+	//	*Message_CompressedZstdDict
 	//	*Message_Ping
 	//	*Message_Pong
 	//	*Message_Handshake
@@ -159,6 +162,33 @@ func (x *Message) GetCompressedZstd() []byte {
 	return nil
 }
 
+func (x *Message) GetCompressedSnappy() []byte {
+	if x != nil {
+		if x, ok := x.Message.(*Message_CompressedSnappy); ok {
+			return x.CompressedSnappy
+		}
+	}
+	return nil
+}
+
+func (x *Message) GetCompressedLz4() []byte {
+	if x != nil {
+		if x, ok := x.Message.(*Message_CompressedLz4); ok {
+			return x.CompressedLz4
+		}
+	}
+	return nil
+}
+
+func (x *Message) GetCompressedZstdDict() []byte {
+	if x != nil {
+		if x, ok := x.Message.(*Message_CompressedZstdDict); ok {
+			return x.CompressedZstdDict
+		}
+	}
+	return nil
+}
+
 func (x *Message) GetPing() *Ping {
 	if x != nil {
 		if x, ok := x.Message.(*Message_Ping); ok {
@@ -395,6 +425,24 @@ type Message_CompressedZstd struct {
 	CompressedZstd []byte `protobuf:"bytes,2,opt,name=compressed_zstd,json=compressedZstd,proto3,oneof"`
 }
 
+type Message_CompressedSnappy struct {
+	// snappy-compressed bytes of a "p2p.Message", only sent to peers that
+	// support snappy.
+	CompressedSnappy []byte `protobuf:"bytes,3,opt,name=compressed_snappy,json=compressedSnappy,proto3,oneof"`
+}
+
+type Message_CompressedLz4 struct {
+	// lz4-compressed bytes of a "p2p.Message", prefixed with the uvarint
+	// length of the decompressed bytes. Only sent to peers that support lz4.
+	CompressedLz4 []byte `protobuf:"bytes,4,opt,name=compressed_lz4,json=compressedLz4,proto3,oneof"`
+}
+
+type Message_CompressedZstdDict struct {
+	// zstd-compressed bytes of a "p2p.Message", compressed with a zstd
+	// dictionary. Only sent to peers that support the dictionary.
+	CompressedZstdDict []byte `protobuf:"bytes,5,opt,name=compressed_zstd_dict,json=compressedZstdDict,proto3,oneof"`
+}
+
 type Message_Ping struct {
 	// Network messages:
 	Ping *Ping `protobuf:"bytes,11,opt,name=ping,proto3,oneof"`
@@ -503,6 +551,12 @@ type Message_Simplex struct {
 
 func (*Message_CompressedZstd) isMessage_Message() {}
 
+func (*Message_CompressedSnappy) isMessage_Message() {}
+
+func (*Message_CompressedLz4) isMessage_Message() {}
+
+func (*Message_CompressedZstdDict) isMessage_Message() {}
+
 func (*Message_Ping) isMessage_Message() {}
 
 func (*Message_Pong) isMessage_Message() {}
@@ -674,9 +728,24 @@ type Handshake struct {
 	IpBlsSig []byte `protobuf:"bytes,13,opt,name=ip_bls_sig,json=ipBlsSig,proto3" json:"ip_bls_sig,omitempty"`
 	// To avoid sending IPs that the client isn't interested in tracking, the
 	// server expects the client to confirm that it is tracking all subnets.
-	AllSubnets    bool `protobuf:"varint,14,opt,name=all_subnets,json=allSubnets,proto3" json:"all_subnets,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	AllSubnets bool `protobuf:"varint,14,opt,name=all_subnets,json=allSubnets,proto3" json:"all_subnets,omitempty"`
+	// UDP port that the peer accepts QUIC connections on, on the same IP
+	// address. Zero if the peer doesn't support QUIC.
+	QuicPort uint32 `protobuf:"varint,15,opt,name=quic_port,json=quicPort,proto3" json:"quic_port,omitempty"`
+	// Signature of the peer IP port pair and the QUIC port at the provided
+	// timestamp with the TLS key.
+	IpQuicSig []byte `protobuf:"bytes,16,opt,name=ip_quic_sig,json=ipQuicSig,proto3" json:"ip_quic_sig,omitempty"`
+	// Compression algorithms that the peer can decompress, ordered by the
+	// peer's preference. Each algorithm is identified by the field number of its
+	// compressed_* field in Message. zstd is always supported.
+	SupportedCompressions []uint32 `protobuf:"varint,17,rep,packed,name=supported_compressions,json=supportedCompressions,proto3" json:"supported_compressions,omitempty"`
+	// IDs of the zstd dictionaries that the peer can decompress with.
+	ZstdDictionaryIds []uint32 `protobuf:"varint,18,rep,packed,name=zstd_dictionary_ids,json=zstdDictionaryIds,proto3" json:"zstd_dictionary_ids,omitempty"`
+	// Signals that the peer propagates trace context in AppRequest and
+	// AppResponse messages. Trace context is only sent to peers that set this.
+	TracePropagation bool `protobuf:"varint,19,opt,name=trace_propagation,json=tracePropagation,proto3" json:"trace_propagation,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Handshake) Reset() {
@@ -807,6 +876,41 @@ func (x *Handshake) GetAllSubnets() bool {
 	return false
 }
 
+func (x *Handshake) GetQuicPort() uint32 {
+	if x != nil {
+		return x.QuicPort
+	}
+	return 0
+}
+
+func (x *Handshake) GetIpQuicSig() []byte {
+	if x != nil {
+		return x.IpQuicSig
+	}
+	return nil
+}
+
+func (x *Handshake) GetSupportedCompressions() []uint32 {
+	if x != nil {
+		return x.SupportedCompressions
+	}
+	return nil
+}
+
+func (x *Handshake) GetZstdDictionaryIds() []uint32 {
+	if x != nil {
+		return x.ZstdDictionaryIds
+	}
+	return nil
+}
+
+func (x *Handshake) GetTracePropagation() bool {
+	if x != nil {
+		return x.TracePropagation
+	}
+	return false
+}
+
 // Metadata about a peer's P2P client used to determine compatibility
 type Client struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -2238,7 +2342,9 @@ type AppRequest struct {
 	// Timeout (ns) for this request
 	Deadline uint64 `protobuf:"varint,3,opt,name=deadline,proto3" json:"deadline,omitempty"`
 	// Request body
-	AppBytes      []byte `protobuf:"bytes,4,opt,name=app_bytes,json=appBytes,proto3" json:"app_bytes,omitempty"`
+	AppBytes []byte `protobuf:"bytes,4,opt,name=app_bytes,json=appBytes,proto3" json:"app_bytes,omitempty"`
+	// W3C trace context of the request
+	TraceContext  map[string]string `protobuf:"bytes,5,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -2301,6 +2407,13 @@ func (x *AppRequest) GetAppBytes() []byte {
 	return nil
 }
 
+func (x *AppRequest) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
 // AppResponse is a VM-defined response sent in response to AppRequest
 type AppResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -2309,7 +2422,9 @@ type AppResponse struct {
 	// Request id of the original AppRequest
 	RequestId uint32 `protobuf:"varint,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	// Response body
-	AppBytes      []byte `protobuf:"bytes,3,opt,name=app_bytes,json=appBytes,proto3" json:"app_bytes,omitempty"`
+	AppBytes []byte `protobuf:"bytes,3,opt,name=app_bytes,json=appBytes,proto3" json:"app_bytes,omitempty"`
+	// W3C trace context of the response
+	TraceContext  map[string]string `protobuf:"bytes,4,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -2365,6 +2480,13 @@ func (x *AppResponse) GetAppBytes() []byte {
 	return nil
 }
 
+func (x *AppResponse) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
 // AppError is a VM-defined error sent in response to AppRequest
 type AppError struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -3376,9 +3498,12 @@ var File_p2p_p2p_proto protoreflect.FileDescriptor
 
 const file_p2p_p2p_proto_rawDesc = "" +
 	"\n" +
-	"\rp2p/p2p.proto\x12\x03p2p\"\x9d\v\n" +
+	"\rp2p/p2p.proto\x12\x03p2p\"\xa9\f\n" +
 	"\aMessage\x12)\n" +
-	"\x0fcompressed_zstd\x18\x02 \x01(\fH\x00R\x0ecompressedZstd\x12\x1f\n" +
+	"\x0fcompressed_zstd\x18\x02 \x01(\fH\x00R\x0ecompressedZstd\x12-\n" +
+	"\x11compressed_snappy\x18\x03 \x01(\fH\x00R\x10compressedSnappy\x12'\n" +
+	"\x0ecompressed_lz4\x18\x04 \x01(\fH\x00R\rcompressedLz4\x122\n" +
+	"\x14compressed_zstd_dict\x18\x05 \x01(\fH\x00R\x12compressedZstdDict\x12\x1f\n" +
 	"\x04ping\x18\v \x01(\v2\t.p2p.PingH\x00R\x04ping\x12\x1f\n" +
 	"\x04pong\x18\f \x01(\v2\t.p2p.PongH\x00R\x04pong\x12.\n" +
 	"\thandshake\x18\r \x01(\v2\x0e.p2p.HandshakeH\x00R\thandshake\x126\n" +
@@ -3412,7 +3537,7 @@ const file_p2p_p2p_proto_rawDesc = "" +
 	"\amessageJ\x04\b\x01\x10\x02J\x04\b%\x10&\"$\n" +
 	"\x04Ping\x12\x16\n" +
 	"\x06uptime\x18\x01 \x01(\rR\x06uptimeJ\x04\b\x02\x10\x03\"\x12\n" +
-	"\x04PongJ\x04\b\x01\x10\x02J\x04\b\x02\x10\x03\"\xf1\x03\n" +
+	"\x04PongJ\x04\b\x01\x10\x02J\x04\b\x02\x10\x03\"\xc2\x05\n" +
 	"\tHandshake\x12\x1d\n" +
 	"\n" +
 	"network_id\x18\x01 \x01(\rR\tnetworkId\x12\x17\n" +
@@ -3432,7 +3557,12 @@ const file_p2p_p2p_proto_rawDesc = "" +
 	"\n" +
 	"ip_bls_sig\x18\r \x01(\fR\bipBlsSig\x12\x1f\n" +
 	"\vall_subnets\x18\x0e \x01(\bR\n" +
-	"allSubnets\"^\n" +
+	"allSubnets\x12\x1b\n" +
+	"\tquic_port\x18\x0f \x01(\rR\bquicPort\x12\x1e\n" +
+	"\vip_quic_sig\x18\x10 \x01(\fR\tipQuicSig\x125\n" +
+	"\x16supported_compressions\x18\x11 \x03(\rR\x15supportedCompressions\x12.\n" +
+	"\x13zstd_dictionary_ids\x18\x12 \x03(\rR\x11zstdDictionaryIds\x12+\n" +
+	"\x11trace_propagation\x18\x13 \x01(\bR\x10tracePropagation\"^\n" +
 	"\x06Client\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05major\x18\x02 \x01(\rR\x05major\x12\x14\n" +
@@ -3546,19 +3676,27 @@ const file_p2p_p2p_proto_rawDesc = "" +
 	"\vaccepted_id\x18\x04 \x01(\fR\n" +
 	"acceptedId\x123\n" +
 	"\x16preferred_id_at_height\x18\x05 \x01(\fR\x13preferredIdAtHeight\x12'\n" +
-	"\x0faccepted_height\x18\x06 \x01(\x04R\x0eacceptedHeight\"\x7f\n" +
+	"\x0faccepted_height\x18\x06 \x01(\x04R\x0eacceptedHeight\"\x88\x02\n" +
 	"\n" +
 	"AppRequest\x12\x19\n" +
 	"\bchain_id\x18\x01 \x01(\fR\achainId\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x02 \x01(\rR\trequestId\x12\x1a\n" +
 	"\bdeadline\x18\x03 \x01(\x04R\bdeadline\x12\x1b\n" +
-	"\tapp_bytes\x18\x04 \x01(\fR\bappBytes\"d\n" +
+	"\tapp_bytes\x18\x04 \x01(\fR\bappBytes\x12F\n" +
+	"\rtrace_context\x18\x05 \x03(\v2!.p2p.AppRequest.TraceContextEntryR\ftraceContext\x1a?\n" +
+	"\x11TraceContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xee\x01\n" +
 	"\vAppResponse\x12\x19\n" +
 	"\bchain_id\x18\x01 \x01(\fR\achainId\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x02 \x01(\rR\trequestId\x12\x1b\n" +
-	"\tapp_bytes\x18\x03 \x01(\fR\bappBytes\"\x88\x01\n" +
+	"\tapp_bytes\x18\x03 \x01(\fR\bappBytes\x12G\n" +
+	"\rtrace_context\x18\x04 \x03(\v2\".p2p.AppResponse.TraceContextEntryR\ftraceContext\x1a?\n" +
+	"\x11TraceContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x88\x01\n" +
 	"\bAppError\x12\x19\n" +
 	"\bchain_id\x18\x01 \x01(\fR\achainId\x12\x1d\n" +
 	"\n" +
@@ -3643,7 +3781,7 @@ func file_p2p_p2p_proto_rawDescGZIP() []byte {
 }
 
 var file_p2p_p2p_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_p2p_p2p_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
+var file_p2p_p2p_proto_msgTypes = make([]protoimpl.MessageInfo, 43)
 var file_p2p_p2p_proto_goTypes = []any{
 	(EngineType)(0),                 // 0: p2p.EngineType
 	(*Message)(nil),                 // 1: p2p.Message
@@ -3687,6 +3825,8 @@ var file_p2p_p2p_proto_goTypes = []any{
 	(*ReplicationRequest)(nil),      // 39: p2p.ReplicationRequest
 	(*ReplicationResponse)(nil),     // 40: p2p.ReplicationResponse
 	(*QuorumRound)(nil),             // 41: p2p.QuorumRound
+	nil,                             // 42: p2p.AppRequest.TraceContextEntry
+	nil,                             // 43: p2p.AppResponse.TraceContextEntry
 }
 var file_p2p_p2p_proto_depIdxs = []int32{
 	2,  // 0: p2p.Message.ping:type_name -> p2p.Ping
@@ -3719,33 +3859,35 @@ var file_p2p_p2p_proto_depIdxs = []int32{
 	6,  // 27: p2p.GetPeerList.known_peers:type_name -> p2p.BloomFilter
 	7,  // 28: p2p.PeerList.claimed_ip_ports:type_name -> p2p.ClaimedIpPort
 	0,  // 29: p2p.GetAncestors.engine_type:type_name -> p2p.EngineType
-	30, // 30: p2p.Simplex.block_proposal:type_name -> p2p.BlockProposal
-	35, // 31: p2p.Simplex.vote:type_name -> p2p.Vote
-	36, // 32: p2p.Simplex.empty_vote:type_name -> p2p.EmptyVote
-	35, // 33: p2p.Simplex.finalize_vote:type_name -> p2p.Vote
-	37, // 34: p2p.Simplex.notarization:type_name -> p2p.QuorumCertificate
-	38, // 35: p2p.Simplex.empty_notarization:type_name -> p2p.EmptyNotarization
-	37, // 36: p2p.Simplex.finalization:type_name -> p2p.QuorumCertificate
-	39, // 37: p2p.Simplex.replication_request:type_name -> p2p.ReplicationRequest
-	40, // 38: p2p.Simplex.replication_response:type_name -> p2p.ReplicationResponse
-	35, // 39: p2p.BlockProposal.vote:type_name -> p2p.Vote
-	31, // 40: p2p.BlockHeader.metadata:type_name -> p2p.ProtocolMetadata
-	33, // 41: p2p.Vote.block_header:type_name -> p2p.BlockHeader
-	34, // 42: p2p.Vote.signature:type_name -> p2p.Signature
-	32, // 43: p2p.EmptyVote.metadata:type_name -> p2p.EmptyVoteMetadata
-	34, // 44: p2p.EmptyVote.signature:type_name -> p2p.Signature
-	33, // 45: p2p.QuorumCertificate.block_header:type_name -> p2p.BlockHeader
-	32, // 46: p2p.EmptyNotarization.metadata:type_name -> p2p.EmptyVoteMetadata
-	41, // 47: p2p.ReplicationResponse.data:type_name -> p2p.QuorumRound
-	41, // 48: p2p.ReplicationResponse.latest_round:type_name -> p2p.QuorumRound
-	37, // 49: p2p.QuorumRound.notarization:type_name -> p2p.QuorumCertificate
-	38, // 50: p2p.QuorumRound.empty_notarization:type_name -> p2p.EmptyNotarization
-	37, // 51: p2p.QuorumRound.finalization:type_name -> p2p.QuorumCertificate
-	52, // [52:52] is the sub-list for method output_type
-	52, // [52:52] is the sub-list for method input_type
-	52, // [52:52] is the sub-list for extension type_name
-	52, // [52:52] is the sub-list for extension extendee
-	0,  // [0:52] is the sub-list for field type_name
+	42, // 30: p2p.AppRequest.trace_context:type_name -> p2p.AppRequest.TraceContextEntry
+	43, // 31: p2p.AppResponse.trace_context:type_name -> p2p.AppResponse.TraceContextEntry
+	30, // 32: p2p.Simplex.block_proposal:type_name -> p2p.BlockProposal
+	35, // 33: p2p.Simplex.vote:type_name -> p2p.Vote
+	36, // 34: p2p.Simplex.empty_vote:type_name -> p2p.EmptyVote
+	35, // 35: p2p.Simplex.finalize_vote:type_name -> p2p.Vote
+	37, // 36: p2p.Simplex.notarization:type_name -> p2p.QuorumCertificate
+	38, // 37: p2p.Simplex.empty_notarization:type_name -> p2p.EmptyNotarization
+	37, // 38: p2p.Simplex.finalization:type_name -> p2p.QuorumCertificate
+	39, // 39: p2p.Simplex.replication_request:type_name -> p2p.ReplicationRequest
+	40, // 40: p2p.Simplex.replication_response:type_name -> p2p.ReplicationResponse
+	35, // 41: p2p.BlockProposal.vote:type_name -> p2p.Vote
+	31, // 42: p2p.BlockHeader.metadata:type_name -> p2p.ProtocolMetadata
+	33, // 43: p2p.Vote.block_header:type_name -> p2p.BlockHeader
+	34, // 44: p2p.Vote.signature:type_name -> p2p.Signature
+	32, // 45: p2p.EmptyVote.metadata:type_name -> p2p.EmptyVoteMetadata
+	34, // 46: p2p.EmptyVote.signature:type_name -> p2p.Signature
+	33, // 47: p2p.QuorumCertificate.block_header:type_name -> p2p.BlockHeader
+	32, // 48: p2p.EmptyNotarization.metadata:type_name -> p2p.EmptyVoteMetadata
+	41, // 49: p2p.ReplicationResponse.data:type_name -> p2p.QuorumRound
+	41, // 50: p2p.ReplicationResponse.latest_round:type_name -> p2p.QuorumRound
+	37, // 51: p2p.QuorumRound.notarization:type_name -> p2p.QuorumCertificate
+	38, // 52: p2p.QuorumRound.empty_notarization:type_name -> p2p.EmptyNotarization
+	37, // 53: p2p.QuorumRound.finalization:type_name -> p2p.QuorumCertificate
+	54, // [54:54] is the sub-list for method output_type
+	54, // [54:54] is the sub-list for method input_type
+	54, // [54:54] is the sub-list for extension type_name
+	54, // [54:54] is the sub-list for extension extendee
+	0,  // [0:54] is the sub-list for field type_name
 }
 
 func init() { file_p2p_p2p_proto_init() }
@@ -3755,6 +3897,9 @@ func file_p2p_p2p_proto_init() {
 	}
 	file_p2p_p2p_proto_msgTypes[0].OneofWrappers = []any{
 		(*Message_CompressedZstd)(nil),
+		(*Message_CompressedSnappy)(nil),
+		(*Message_CompressedLz4)(nil),
+		(*Message_CompressedZstdDict)(nil),
 		(*Message_Ping)(nil),
 		(*Message_Pong)(nil),
 		(*Message_Handshake)(nil),
@@ -3798,7 +3943,7 @@ func file_p2p_p2p_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_p2p_p2p_proto_rawDesc), len(file_p2p_p2p_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   41,
+			NumMessages:   43,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -22,9 +22,13 @@ const (
 )
 
 type PullGossipRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Salt          []byte                 `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
-	Filter        []byte                 `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Salt   []byte                 `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
+	Filter []byte                 `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Invertible bloom lookup table of the gossip known by the requester. If
+	// set, the request is answered by reconciling the sets rather than with the
+	// filter.
+	Table         []byte `protobuf:"bytes,4,opt,name=table,proto3" json:"table,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,9 +77,19 @@ func (x *PullGossipRequest) GetFilter() []byte {
 	return nil
 }
 
+func (x *PullGossipRequest) GetTable() []byte {
+	if x != nil {
+		return x.Table
+	}
+	return nil
+}
+
 type PullGossipResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Gossip        [][]byte               `protobuf:"bytes,1,rep,name=gossip,proto3" json:"gossip,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Gossip [][]byte               `protobuf:"bytes,1,rep,name=gossip,proto3" json:"gossip,omitempty"`
+	// Number of gossipables known by only one of the peers, if the sets were
+	// reconciled.
+	SetDifference uint32 `protobuf:"varint,2,opt,name=set_difference,json=setDifference,proto3" json:"set_difference,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -117,6 +131,13 @@ func (x *PullGossipResponse) GetGossip() [][]byte {
 	return nil
 }
 
+func (x *PullGossipResponse) GetSetDifference() uint32 {
+	if x != nil {
+		return x.SetDifference
+	}
+	return 0
+}
+
 type PushGossip struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Gossip        [][]byte               `protobuf:"bytes,1,rep,name=gossip,proto3" json:"gossip,omitempty"`
@@ -270,12 +291,14 @@ var File_sdk_sdk_proto protoreflect.FileDescriptor
 
 const file_sdk_sdk_proto_rawDesc = "" +
 	"\n" +
-	"\rsdk/sdk.proto\x12\x03sdk\"?\n" +
+	"\rsdk/sdk.proto\x12\x03sdk\"U\n" +
 	"\x11PullGossipRequest\x12\x12\n" +
 	"\x04salt\x18\x02 \x01(\fR\x04salt\x12\x16\n" +
-	"\x06filter\x18\x03 \x01(\fR\x06filter\",\n" +
+	"\x06filter\x18\x03 \x01(\fR\x06filter\x12\x14\n" +
+	"\x05table\x18\x04 \x01(\fR\x05table\"S\n" +
 	"\x12PullGossipResponse\x12\x16\n" +
-	"\x06gossip\x18\x01 \x03(\fR\x06gossip\"$\n" +
+	"\x06gossip\x18\x01 \x03(\fR\x06gossip\x12%\n" +
+	"\x0eset_difference\x18\x02 \x01(\rR\rsetDifference\"$\n" +
 	"\n" +
 	"PushGossip\x12\x16\n" +
 	"\x06gossip\x18\x01 \x03(\fR\x06gossip\"R\n" +
@@ -188,12 +188,14 @@ func (x *ChangeProofRequest) GetBytesLimit() uint32 {
 }
 
 type RangeProofRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RootHash      []byte                 `protobuf:"bytes,1,opt,name=root_hash,json=rootHash,proto3" json:"root_hash,omitempty"`
-	StartKey      *MaybeBytes            `protobuf:"bytes,2,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
-	EndKey        *MaybeBytes            `protobuf:"bytes,3,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
-	KeyLimit      uint32                 `protobuf:"varint,4,opt,name=key_limit,json=keyLimit,proto3" json:"key_limit,omitempty"`
-	BytesLimit    uint32                 `protobuf:"varint,5,opt,name=bytes_limit,json=bytesLimit,proto3" json:"bytes_limit,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	RootHash   []byte                 `protobuf:"bytes,1,opt,name=root_hash,json=rootHash,proto3" json:"root_hash,omitempty"`
+	StartKey   *MaybeBytes            `protobuf:"bytes,2,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
+	EndKey     *MaybeBytes            `protobuf:"bytes,3,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+	KeyLimit   uint32                 `protobuf:"varint,4,opt,name=key_limit,json=keyLimit,proto3" json:"key_limit,omitempty"`
+	BytesLimit uint32                 `protobuf:"varint,5,opt,name=bytes_limit,json=bytesLimit,proto3" json:"bytes_limit,omitempty"`
+	// If true, each value in the proof is replaced by its digest.
+	KeyOnly       bool `protobuf:"varint,6,opt,name=key_only,json=keyOnly,proto3" json:"key_only,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -263,6 +265,13 @@ func (x *RangeProofRequest) GetBytesLimit() uint32 {
 	return 0
 }
 
+func (x *RangeProofRequest) GetKeyOnly() bool {
+	if x != nil {
+		return x.KeyOnly
+	}
+	return false
+}
+
 type ProofResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Response:
@@ -465,6 +474,58 @@ func (x *RangeProof) GetKeyValues() []*KeyValue {
 	return nil
 }
 
+type MultiProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*ProofNode           `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	KeyValues     []*KeyChange           `protobuf:"bytes,2,rep,name=key_values,json=keyValues,proto3" json:"key_values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiProof) Reset() {
+	*x = MultiProof{}
+	mi := &file_sync_sync_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiProof) ProtoMessage() {}
+
+func (x *MultiProof) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_sync_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiProof.ProtoReflect.Descriptor instead.
+func (*MultiProof) Descriptor() ([]byte, []int) {
+	return file_sync_sync_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MultiProof) GetNodes() []*ProofNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *MultiProof) GetKeyValues() []*KeyChange {
+	if x != nil {
+		return x.KeyValues
+	}
+	return nil
+}
+
 type ProofNode struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           *Key                   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -476,7 +537,7 @@ type ProofNode struct {
 
 func (x *ProofNode) Reset() {
 	*x = ProofNode{}
-	mi := &file_sync_sync_proto_msgTypes[6]
+	mi := &file_sync_sync_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -488,7 +549,7 @@ func (x *ProofNode) String() string {
 func (*ProofNode) ProtoMessage() {}
 
 func (x *ProofNode) ProtoReflect() protoreflect.Message {
-	mi := &file_sync_sync_proto_msgTypes[6]
+	mi := &file_sync_sync_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -501,7 +562,7 @@ func (x *ProofNode) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProofNode.ProtoReflect.Descriptor instead.
 func (*ProofNode) Descriptor() ([]byte, []int) {
-	return file_sync_sync_proto_rawDescGZIP(), []int{6}
+	return file_sync_sync_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ProofNode) GetKey() *Key {
@@ -535,7 +596,7 @@ type KeyChange struct {
 
 func (x *KeyChange) Reset() {
 	*x = KeyChange{}
-	mi := &file_sync_sync_proto_msgTypes[7]
+	mi := &file_sync_sync_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -547,7 +608,7 @@ func (x *KeyChange) String() string {
 func (*KeyChange) ProtoMessage() {}
 
 func (x *KeyChange) ProtoReflect() protoreflect.Message {
-	mi := &file_sync_sync_proto_msgTypes[7]
+	mi := &file_sync_sync_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -560,7 +621,7 @@ func (x *KeyChange) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KeyChange.ProtoReflect.Descriptor instead.
 func (*KeyChange) Descriptor() ([]byte, []int) {
-	return file_sync_sync_proto_rawDescGZIP(), []int{7}
+	return file_sync_sync_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *KeyChange) GetKey() []byte {
@@ -587,7 +648,7 @@ type Key struct {
 
 func (x *Key) Reset() {
 	*x = Key{}
-	mi := &file_sync_sync_proto_msgTypes[8]
+	mi := &file_sync_sync_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -599,7 +660,7 @@ func (x *Key) String() string {
 func (*Key) ProtoMessage() {}
 
 func (x *Key) ProtoReflect() protoreflect.Message {
-	mi := &file_sync_sync_proto_msgTypes[8]
+	mi := &file_sync_sync_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -612,7 +673,7 @@ func (x *Key) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Key.ProtoReflect.Descriptor instead.
 func (*Key) Descriptor() ([]byte, []int) {
-	return file_sync_sync_proto_rawDescGZIP(), []int{8}
+	return file_sync_sync_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Key) GetLength() uint64 {
@@ -639,7 +700,7 @@ type MaybeBytes struct {
 
 func (x *MaybeBytes) Reset() {
 	*x = MaybeBytes{}
-	mi := &file_sync_sync_proto_msgTypes[9]
+	mi := &file_sync_sync_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -651,7 +712,7 @@ func (x *MaybeBytes) String() string {
 func (*MaybeBytes) ProtoMessage() {}
 
 func (x *MaybeBytes) ProtoReflect() protoreflect.Message {
-	mi := &file_sync_sync_proto_msgTypes[9]
+	mi := &file_sync_sync_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -664,7 +725,7 @@ func (x *MaybeBytes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MaybeBytes.ProtoReflect.Descriptor instead.
 func (*MaybeBytes) Descriptor() ([]byte, []int) {
-	return file_sync_sync_proto_rawDescGZIP(), []int{9}
+	return file_sync_sync_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *MaybeBytes) GetValue() []byte {
@@ -684,7 +745,7 @@ type KeyValue struct {
 
 func (x *KeyValue) Reset() {
 	*x = KeyValue{}
-	mi := &file_sync_sync_proto_msgTypes[10]
+	mi := &file_sync_sync_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -696,7 +757,7 @@ func (x *KeyValue) String() string {
 func (*KeyValue) ProtoMessage() {}
 
 func (x *KeyValue) ProtoReflect() protoreflect.Message {
-	mi := &file_sync_sync_proto_msgTypes[10]
+	mi := &file_sync_sync_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -709,7 +770,7 @@ func (x *KeyValue) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KeyValue.ProtoReflect.Descriptor instead.
 func (*KeyValue) Descriptor() ([]byte, []int) {
-	return file_sync_sync_proto_rawDescGZIP(), []int{10}
+	return file_sync_sync_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *KeyValue) GetKey() []byte {
@@ -743,14 +804,15 @@ const file_sync_sync_proto_rawDesc = "" +
 	"\aend_key\x18\x04 \x01(\v2\x10.sync.MaybeBytesR\x06endKey\x12\x1b\n" +
 	"\tkey_limit\x18\x05 \x01(\rR\bkeyLimit\x12\x1f\n" +
 	"\vbytes_limit\x18\x06 \x01(\rR\n" +
-	"bytesLimit\"\xc8\x01\n" +
+	"bytesLimit\"\xe3\x01\n" +
 	"\x11RangeProofRequest\x12\x1b\n" +
 	"\troot_hash\x18\x01 \x01(\fR\brootHash\x12-\n" +
 	"\tstart_key\x18\x02 \x01(\v2\x10.sync.MaybeBytesR\bstartKey\x12)\n" +
 	"\aend_key\x18\x03 \x01(\v2\x10.sync.MaybeBytesR\x06endKey\x12\x1b\n" +
 	"\tkey_limit\x18\x04 \x01(\rR\bkeyLimit\x12\x1f\n" +
 	"\vbytes_limit\x18\x05 \x01(\rR\n" +
-	"bytesLimit\"c\n" +
+	"bytesLimit\x12\x19\n" +
+	"\bkey_only\x18\x06 \x01(\bR\akeyOnly\"c\n" +
 	"\rProofResponse\x12#\n" +
 	"\fchange_proof\x18\x01 \x01(\fH\x00R\vchangeProof\x12!\n" +
 	"\vrange_proof\x18\x02 \x01(\fH\x00R\n" +
@@ -769,7 +831,12 @@ const file_sync_sync_proto_rawDesc = "" +
 	"startProof\x12,\n" +
 	"\tend_proof\x18\x02 \x03(\v2\x0f.sync.ProofNodeR\bendProof\x12-\n" +
 	"\n" +
-	"key_values\x18\x03 \x03(\v2\x0e.sync.KeyValueR\tkeyValues\"\xd6\x01\n" +
+	"key_values\x18\x03 \x03(\v2\x0e.sync.KeyValueR\tkeyValues\"c\n" +
+	"\n" +
+	"MultiProof\x12%\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x0f.sync.ProofNodeR\x05nodes\x12.\n" +
+	"\n" +
+	"key_values\x18\x02 \x03(\v2\x0f.sync.KeyChangeR\tkeyValues\"\xd6\x01\n" +
 	"\tProofNode\x12\x1b\n" +
 	"\x03key\x18\x01 \x01(\v2\t.sync.KeyR\x03key\x124\n" +
 	"\rvalue_or_hash\x18\x02 \x01(\v2\x10.sync.MaybeBytesR\vvalueOrHash\x129\n" +
@@ -802,7 +869,7 @@ func file_sync_sync_proto_rawDescGZIP() []byte {
 	return file_sync_sync_proto_rawDescData
 }
 
-var file_sync_sync_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_sync_sync_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_sync_sync_proto_goTypes = []any{
 	(*ProofRequest)(nil),       // 0: sync.ProofRequest
 	(*ChangeProofRequest)(nil), // 1: sync.ChangeProofRequest
@@ -810,35 +877,38 @@ var file_sync_sync_proto_goTypes = []any{
 	(*ProofResponse)(nil),      // 3: sync.ProofResponse
 	(*ChangeProof)(nil),        // 4: sync.ChangeProof
 	(*RangeProof)(nil),         // 5: sync.RangeProof
-	(*ProofNode)(nil),          // 6: sync.ProofNode
-	(*KeyChange)(nil),          // 7: sync.KeyChange
-	(*Key)(nil),                // 8: sync.Key
-	(*MaybeBytes)(nil),         // 9: sync.MaybeBytes
-	(*KeyValue)(nil),           // 10: sync.KeyValue
-	nil,                        // 11: sync.ProofNode.ChildrenEntry
+	(*MultiProof)(nil),         // 6: sync.MultiProof
+	(*ProofNode)(nil),          // 7: sync.ProofNode
+	(*KeyChange)(nil),          // 8: sync.KeyChange
+	(*Key)(nil),                // 9: sync.Key
+	(*MaybeBytes)(nil),         // 10: sync.MaybeBytes
+	(*KeyValue)(nil),           // 11: sync.KeyValue
+	nil,                        // 12: sync.ProofNode.ChildrenEntry
 }
 var file_sync_sync_proto_depIdxs = []int32{
 	1,  // 0: sync.ProofRequest.change_proof:type_name -> sync.ChangeProofRequest
 	2,  // 1: sync.ProofRequest.range_proof:type_name -> sync.RangeProofRequest
-	9,  // 2: sync.ChangeProofRequest.start_key:type_name -> sync.MaybeBytes
-	9,  // 3: sync.ChangeProofRequest.end_key:type_name -> sync.MaybeBytes
-	9,  // 4: sync.RangeProofRequest.start_key:type_name -> sync.MaybeBytes
-	9,  // 5: sync.RangeProofRequest.end_key:type_name -> sync.MaybeBytes
-	6,  // 6: sync.ChangeProof.start_proof:type_name -> sync.ProofNode
-	6,  // 7: sync.ChangeProof.end_proof:type_name -> sync.ProofNode
-	7,  // 8: sync.ChangeProof.key_changes:type_name -> sync.KeyChange
-	6,  // 9: sync.RangeProof.start_proof:type_name -> sync.ProofNode
-	6,  // 10: sync.RangeProof.end_proof:type_name -> sync.ProofNode
-	10, // 11: sync.RangeProof.key_values:type_name -> sync.KeyValue
-	8,  // 12: sync.ProofNode.key:type_name -> sync.Key
-	9,  // 13: sync.ProofNode.value_or_hash:type_name -> sync.MaybeBytes
-	11, // 14: sync.ProofNode.children:type_name -> sync.ProofNode.ChildrenEntry
-	9,  // 15: sync.KeyChange.value:type_name -> sync.MaybeBytes
-	16, // [16:16] is the sub-list for method output_type
-	16, // [16:16] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	10, // 2: sync.ChangeProofRequest.start_key:type_name -> sync.MaybeBytes
+	10, // 3: sync.ChangeProofRequest.end_key:type_name -> sync.MaybeBytes
+	10, // 4: sync.RangeProofRequest.start_key:type_name -> sync.MaybeBytes
+	10, // 5: sync.RangeProofRequest.end_key:type_name -> sync.MaybeBytes
+	7,  // 6: sync.ChangeProof.start_proof:type_name -> sync.ProofNode
+	7,  // 7: sync.ChangeProof.end_proof:type_name -> sync.ProofNode
+	8,  // 8: sync.ChangeProof.key_changes:type_name -> sync.KeyChange
+	7,  // 9: sync.RangeProof.start_proof:type_name -> sync.ProofNode
+	7,  // 10: sync.RangeProof.end_proof:type_name -> sync.ProofNode
+	11, // 11: sync.RangeProof.key_values:type_name -> sync.KeyValue
+	7,  // 12: sync.MultiProof.nodes:type_name -> sync.ProofNode
+	8,  // 13: sync.MultiProof.key_values:type_name -> sync.KeyChange
+	9,  // 14: sync.ProofNode.key:type_name -> sync.Key
+	10, // 15: sync.ProofNode.value_or_hash:type_name -> sync.MaybeBytes
+	12, // 16: sync.ProofNode.children:type_name -> sync.ProofNode.ChildrenEntry
+	10, // 17: sync.KeyChange.value:type_name -> sync.MaybeBytes
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_sync_sync_proto_init() }
@@ -860,7 +930,7 @@ func file_sync_sync_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sync_sync_proto_rawDesc), len(file_sync_sync_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
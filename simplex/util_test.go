@@ -181,6 +181,7 @@ func newNetworkConfigsWithKeyReuse(t *testing.T, numNodes uint64, reuseKeys keyR
 		mc, err := message.NewCreator(
 			prometheus.NewRegistry(),
 			constants.DefaultNetworkCompressionType,
+			constants.DefaultNetworkCompressionZstdLevel,
 			10*time.Second,
 		)
 		require.NoError(t, err)
@@ -11,6 +11,7 @@ import (
 	"github.com/ava-labs/avalanchego/api/metrics"
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/upgrade"
 	"github.com/ava-labs/avalanchego/utils"
@@ -57,6 +58,11 @@ type Context struct {
 
 	// snowman++ attributes
 	ValidatorState validators.State // interface for P-Chain validators
+	// ReputationTracker lowers the reputation of peers that misbehave, such
+	// as by sending malformed gossip. If nil, misbehavior isn't reported.
+	//
+	// Warning: This is not implemented over the rpcchainvm.
+	ReputationTracker reputation.Tracker
 	// Chain-specific directory where arbitrary data can be written
 	ChainDataDir string
 }
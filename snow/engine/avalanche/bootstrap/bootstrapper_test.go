@@ -98,6 +98,7 @@ func newConfig(t *testing.T) (Config, ids.NodeID, *enginetest.Sender, *vertextes
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -85,6 +85,7 @@ func newConfig(t *testing.T) (Config, ids.NodeID, *enginetest.Sender, *blocktest
 		prometheus.NewRegistry(),
 		nil,
 		nil,
+		nil,
 	)
 	require.NoError(err)
 
@@ -139,6 +140,7 @@ func TestBootstrapperStartsOnlyIfEnoughStakeIsConnected(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		nil,
+		nil,
 	)
 	require.NoError(err)
 
@@ -679,6 +681,7 @@ func TestBootstrapNoParseOnNew(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		nil,
+		nil,
 	)
 	require.NoError(err)
 
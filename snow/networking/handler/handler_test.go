@@ -63,6 +63,7 @@ func TestHandlerDropsTimedOutMessages(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -173,6 +174,7 @@ func TestHandlerClosesOnError(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -282,6 +284,7 @@ func TestHandlerDropsGossipDuringBootstrapping(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -375,6 +378,7 @@ func TestHandlerDispatchInternal(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -563,6 +567,7 @@ func TestDynamicEngineTypeDispatch(t *testing.T) {
 				prometheus.NewRegistry(),
 				nil,
 				version.Current,
+				nil,
 			)
 			require.NoError(err)
 
@@ -649,6 +654,7 @@ func TestHandlerStartError(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -80,6 +80,7 @@ func TestHealthCheckSubnet(t *testing.T) {
 				prometheus.NewRegistry(),
 				nil,
 				version.Current,
+				nil,
 			)
 			require.NoError(err)
 
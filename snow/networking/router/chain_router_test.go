@@ -68,6 +68,7 @@ func TestShutdown(t *testing.T) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist,
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -106,6 +107,7 @@ func TestShutdown(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -233,6 +235,7 @@ func TestConnectedAfterShutdownErrorLogRegression(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -330,6 +333,7 @@ func TestShutdownTimesOut(t *testing.T) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist,
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -367,6 +371,7 @@ func TestShutdownTimesOut(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -480,6 +485,7 @@ func TestRouterTimeout(t *testing.T) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist.NewNoBenchlist(),
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -537,6 +543,7 @@ func TestRouterTimeout(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -813,6 +820,7 @@ func TestRouterHonorsRequestedEngine(t *testing.T) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist.NewNoBenchlist(),
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -1022,6 +1030,7 @@ func TestValidatorOnlyMessageDrops(t *testing.T) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist.NewNoBenchlist(),
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -1070,6 +1079,7 @@ func TestValidatorOnlyMessageDrops(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -1185,6 +1195,7 @@ func TestValidatorOnlyAllowedNodeMessageDrops(t *testing.T) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist.NewNoBenchlist(),
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -1237,6 +1248,7 @@ func TestValidatorOnlyAllowedNodeMessageDrops(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -1664,6 +1676,7 @@ func newChainRouterTest(t *testing.T) (*ChainRouter, *enginetest.Engine) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist.NewNoBenchlist(),
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -1706,6 +1719,7 @@ func newChainRouterTest(t *testing.T) (*ChainRouter, *enginetest.Engine) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -17,6 +17,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/snow/networking/timeout"
 	"github.com/ava-labs/avalanchego/subnets"
+	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
 )
@@ -808,6 +809,7 @@ func (s *sender) SendAppRequest(ctx context.Context, nodeIDs set.Set[ids.NodeID]
 		requestID,
 		deadline,
 		bytes,
+		trace.Inject(ctx),
 	)
 	sent := s.sendUnlessError(log, to, msg, err)
 	for nodeID := range nodeIDs {
@@ -860,6 +862,7 @@ func (s *sender) SendAppResponse(ctx context.Context, nodeID ids.NodeID, request
 		s.ctx.ChainID,
 		requestID,
 		bytes,
+		trace.Inject(ctx),
 	)
 	s.sendUnlessError(log, to, msg, err)
 	return nil
@@ -66,6 +66,7 @@ func TestTimeout(t *testing.T) {
 			TimeoutCoefficient: 1.25,
 		},
 		benchlist,
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -78,6 +79,7 @@ func TestTimeout(t *testing.T) {
 	mc, err := message.NewCreator(
 		metrics,
 		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkCompressionZstdLevel,
 		10*time.Second,
 	)
 	require.NoError(err)
@@ -125,6 +127,7 @@ func TestTimeout(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -324,6 +327,7 @@ func TestReliableMessages(t *testing.T) {
 			TimeoutCoefficient: 1.25,
 		},
 		benchlist,
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -337,6 +341,7 @@ func TestReliableMessages(t *testing.T) {
 	mc, err := message.NewCreator(
 		metrics,
 		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkCompressionZstdLevel,
 		10*time.Second,
 	)
 	require.NoError(err)
@@ -384,6 +389,7 @@ func TestReliableMessages(t *testing.T) {
 		prometheus.NewRegistry(),
 		nil,
 		version.Current,
+		nil,
 	)
 	require.NoError(err)
 
@@ -483,6 +489,7 @@ func TestReliableMessagesToMyself(t *testing.T) {
 					TimeoutCoefficient: 1.25,
 				},
 				benchlist,
+				nil,
 				prometheus.NewRegistry(),
 				prometheus.NewRegistry(),
 			)
@@ -496,6 +503,7 @@ func TestReliableMessagesToMyself(t *testing.T) {
 			mc, err := message.NewCreator(
 				metrics,
 				constants.DefaultNetworkCompressionType,
+				constants.DefaultNetworkCompressionZstdLevel,
 				10*time.Second,
 			)
 			require.NoError(err)
@@ -546,6 +554,7 @@ func TestReliableMessagesToMyself(t *testing.T) {
 				prometheus.NewRegistry(),
 				nil,
 				version.Current,
+				nil,
 			)
 			require.NoError(err)
 
@@ -1353,6 +1362,7 @@ func newTimeoutManager(t testing.TB, benchlistMgr benchlist.Manager, initialTime
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlistMgr,
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -12,6 +12,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/utils/timer"
@@ -20,6 +21,7 @@ import (
 func NewManager(
 	timeoutConfig *timer.AdaptiveTimeoutConfig,
 	benchlistMgr benchlist.Manager,
+	reputationTracker reputation.Tracker,
 	requestReg prometheus.Registerer,
 	responseReg prometheus.Registerer,
 ) (*Manager, error) {
@@ -37,9 +39,10 @@ func NewManager(
 	}
 
 	return &Manager{
-		tm:           tm,
-		benchlistMgr: benchlistMgr,
-		metrics:      m,
+		tm:                tm,
+		benchlistMgr:      benchlistMgr,
+		reputationTracker: reputationTracker,
+		metrics:           m,
 	}, nil
 }
 
@@ -47,8 +50,10 @@ func NewManager(
 type Manager struct {
 	tm           timer.AdaptiveTimeoutManager
 	benchlistMgr benchlist.Manager
-	metrics      *timeoutMetrics
-	stopOnce     sync.Once
+	// If non-nil, timeouts lower the reputation of the peer.
+	reputationTracker reputation.Tracker
+	metrics           *timeoutMetrics
+	stopOnce          sync.Once
 }
 
 // Dispatch starts the manager. Must be called before any other method.
@@ -92,6 +97,9 @@ func (m *Manager) RegisterRequest(
 	timeoutHandler func(),
 ) {
 	newTimeoutHandler := func() {
+		if m.reputationTracker != nil {
+			m.reputationTracker.Register(nodeID, reputation.Timeout)
+		}
 		if requestID.Op != byte(message.AppResponseOp) {
 			// If the request timed out and wasn't an AppRequest, tell the
 			// benchlist manager.
@@ -12,12 +12,15 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/networking/benchlist"
 	"github.com/ava-labs/avalanchego/utils/timer"
 )
 
 func TestManagerFire(t *testing.T) {
 	benchlist := benchlist.NewNoBenchlist()
+	reputationTracker, err := reputation.NewTracker(reputation.DefaultConfig, prometheus.NewRegistry())
+	require.NoError(t, err)
 	manager, err := NewManager(
 		&timer.AdaptiveTimeoutConfig{
 			InitialTimeout:     time.Millisecond,
@@ -27,6 +30,7 @@ func TestManagerFire(t *testing.T) {
 			TimeoutHalflife:    5 * time.Minute,
 		},
 		benchlist,
+		reputationTracker,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -46,4 +50,7 @@ func TestManagerFire(t *testing.T) {
 	)
 
 	wg.Wait()
+
+	// The timeout lowered the reputation of the peer.
+	require.Negative(t, reputationTracker.Score(ids.EmptyNodeID))
 }
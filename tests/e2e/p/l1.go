@@ -850,6 +850,7 @@ func wrapWarpSignatureRequest(
 	p2pMessageFactory, err := p2pmessage.NewCreator(
 		prometheus.NewRegistry(),
 		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkCompressionZstdLevel,
 		p2pTimeout,
 	)
 	if err != nil {
@@ -873,6 +874,7 @@ func wrapWarpSignatureRequest(
 			p2psdk.ProtocolPrefix(p2psdk.SignatureRequestHandlerID),
 			requestBytes,
 		),
+		nil,
 	)
 }
 
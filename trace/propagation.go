@@ -0,0 +1,34 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// propagator encodes span contexts in the W3C trace context format.
+var propagator = propagation.TraceContext{}
+
+// Inject returns the trace context of the span in [ctx], which can be sent to
+// another node. Returns nil if [ctx] doesn't have a valid span context.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns [ctx] with the remote span context in [traceContext], so
+// that spans started with the returned context continue the trace of another
+// node. If [traceContext] is invalid, [ctx] is returned.
+func Extract(ctx context.Context, traceContext map[string]string) context.Context {
+	if len(traceContext) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(traceContext))
+}
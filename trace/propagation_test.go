@@ -0,0 +1,47 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package trace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInjectExtract(t *testing.T) {
+	require := require.New(t)
+
+	// Without a span, there is nothing to propagate.
+	require.Nil(Inject(t.Context()))
+	require.Equal(t.Context(), Extract(t.Context(), nil))
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(t.Context(), spanContext)
+
+	traceContext := Inject(ctx)
+	require.Equal(
+		map[string]string{
+			"traceparent": "00-01000000000000000000000000000000-0200000000000000-01",
+		},
+		traceContext,
+	)
+
+	remoteSpanContext := trace.SpanContextFromContext(Extract(t.Context(), traceContext))
+	require.True(remoteSpanContext.IsRemote())
+	require.Equal(spanContext.TraceID(), remoteSpanContext.TraceID())
+	require.Equal(spanContext.SpanID(), remoteSpanContext.SpanID())
+	require.True(remoteSpanContext.IsSampled())
+}
+
+func TestExtractInvalid(t *testing.T) {
+	ctx := Extract(t.Context(), map[string]string{
+		"traceparent": "invalid",
+	})
+	require.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
@@ -25,7 +25,9 @@ var (
 		TypeNone: func(int64) (Compressor, error) { //nolint:unparam // an error is needed to be returned to compile
 			return NewNoCompressor(), nil
 		},
-		TypeZstd: NewZstdCompressor,
+		TypeZstd:   NewZstdCompressor,
+		TypeSnappy: NewSnappyCompressor,
+		TypeLZ4:    NewLZ4Compressor,
 	}
 
 	//go:embed zstd_zip_bomb.bin
@@ -167,18 +169,21 @@ func FuzzZstdCompressor(f *testing.F) {
 	fuzzHelper(f, TypeZstd)
 }
 
+func FuzzSnappyCompressor(f *testing.F) {
+	fuzzHelper(f, TypeSnappy)
+}
+
+func FuzzLZ4Compressor(f *testing.F) {
+	fuzzHelper(f, TypeLZ4)
+}
+
 func fuzzHelper(f *testing.F, compressionType Type) {
-	var (
-		compressor Compressor
-		err        error
-	)
-	switch compressionType {
-	case TypeZstd:
-		compressor, err = NewZstdCompressor(maxMessageSize)
-		require.NoError(f, err)
-	default:
+	newCompressorFunc, ok := newCompressorFuncs[compressionType]
+	if !ok || compressionType == TypeNone {
 		f.Fatal("Unknown compression type")
 	}
+	compressor, err := newCompressorFunc(maxMessageSize)
+	require.NoError(f, err)
 
 	f.Fuzz(func(t *testing.T, data []byte) {
 		require := require.New(t)
@@ -0,0 +1,86 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package compression
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+var (
+	_ Compressor = (*lz4Compressor)(nil)
+
+	errInvalidLZ4Length = errors.New("invalid lz4 decompressed length")
+)
+
+// NewLZ4Compressor returns a compressor that uses the lz4 block format. The
+// compressed messages are prefixed with their decompressed length as a
+// uvarint, because the block format doesn't include it.
+func NewLZ4Compressor(maxSize int64) (Compressor, error) {
+	if maxSize == math.MaxInt64 {
+		return nil, ErrInvalidMaxSizeCompressor
+	}
+	return &lz4Compressor{
+		maxSize: maxSize,
+		compressors: sync.Pool{
+			New: func() any {
+				return &lz4.Compressor{}
+			},
+		},
+	}, nil
+}
+
+type lz4Compressor struct {
+	maxSize     int64
+	compressors sync.Pool
+}
+
+func (l *lz4Compressor) Compress(msg []byte) ([]byte, error) {
+	if int64(len(msg)) > l.maxSize {
+		return nil, fmt.Errorf("%w: (%d) > (%d)", ErrMsgTooLarge, len(msg), l.maxSize)
+	}
+
+	compressed := make([]byte, binary.MaxVarintLen64+lz4.CompressBlockBound(len(msg)))
+	n := binary.PutUvarint(compressed, uint64(len(msg)))
+	if len(msg) == 0 {
+		return compressed[:n], nil
+	}
+
+	compressor := l.compressors.Get().(*lz4.Compressor)
+	defer l.compressors.Put(compressor)
+
+	blockLen, err := compressor.CompressBlock(msg, compressed[n:])
+	if err != nil {
+		return nil, err
+	}
+	return compressed[:n+blockLen], nil
+}
+
+func (l *lz4Compressor) Decompress(msg []byte) ([]byte, error) {
+	decompressedLen, n := binary.Uvarint(msg)
+	if n <= 0 {
+		return nil, errInvalidLZ4Length
+	}
+	if decompressedLen > uint64(l.maxSize) {
+		return nil, fmt.Errorf("%w: (%d) > (%d)", ErrDecompressedMsgTooLarge, decompressedLen, l.maxSize)
+	}
+
+	decompressed := make([]byte, decompressedLen)
+	if decompressedLen == 0 {
+		return decompressed, nil
+	}
+	actualLen, err := lz4.UncompressBlock(msg[n:], decompressed)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(actualLen) != decompressedLen {
+		return nil, fmt.Errorf("%w: expected %d but got %d", errInvalidLZ4Length, decompressedLen, actualLen)
+	}
+	return decompressed, nil
+}
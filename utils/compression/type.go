@@ -15,14 +15,28 @@ type Type byte
 const (
 	TypeNone Type = iota + 1
 	TypeZstd
+	TypeSnappy
+	TypeLZ4
 )
 
+// Types are all the supported compression types.
+var Types = []Type{
+	TypeNone,
+	TypeZstd,
+	TypeSnappy,
+	TypeLZ4,
+}
+
 func (t Type) String() string {
 	switch t {
 	case TypeNone:
 		return "none"
 	case TypeZstd:
 		return "zstd"
+	case TypeSnappy:
+		return "snappy"
+	case TypeLZ4:
+		return "lz4"
 	default:
 		return "unknown"
 	}
@@ -34,6 +48,10 @@ func TypeFromString(s string) (Type, error) {
 		return TypeNone, nil
 	case TypeZstd.String():
 		return TypeZstd, nil
+	case TypeSnappy.String():
+		return TypeSnappy, nil
+	case TypeLZ4.String():
+		return TypeLZ4, nil
 	default:
 		return TypeNone, errUnknownCompressionType
 	}
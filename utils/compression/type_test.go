@@ -12,7 +12,7 @@ import (
 func TestTypeString(t *testing.T) {
 	require := require.New(t)
 
-	for _, compressionType := range []Type{TypeNone, TypeZstd} {
+	for _, compressionType := range Types {
 		s := compressionType.String()
 		parsedType, err := TypeFromString(s)
 		require.NoError(err)
@@ -38,6 +38,14 @@ func TestTypeMarshalJSON(t *testing.T) {
 			Type:     TypeZstd,
 			expected: `"zstd"`,
 		},
+		{
+			Type:     TypeSnappy,
+			expected: `"snappy"`,
+		},
+		{
+			Type:     TypeLZ4,
+			expected: `"lz4"`,
+		},
 		{
 			Type:     Type(0),
 			expected: `"unknown"`,
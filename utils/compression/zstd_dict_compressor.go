@@ -0,0 +1,132 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	_ Compressor = (*zstdDictCompressor)(nil)
+
+	errDuplicateDictionaryID = errors.New("duplicate zstd dictionary ID")
+)
+
+// ZstdDictionaries compresses messages with trained zstd dictionaries, which
+// makes small messages with a known structure compress much better than
+// without a dictionary.
+type ZstdDictionaries struct {
+	maxSize     int64
+	decoder     *zstd.Decoder
+	compressors map[uint32]*zstdDictCompressor
+}
+
+// ZstdDictionaryID returns the ID of [dict], which must be in the zstd
+// dictionary format.
+func ZstdDictionaryID(dict []byte) (uint32, error) {
+	d, err := zstd.InspectDictionary(dict)
+	if err != nil {
+		return 0, err
+	}
+	return d.ID(), nil
+}
+
+// NewZstdDictionaries parses [dicts], which must be in the zstd dictionary
+// format and have unique IDs. Messages are compressed with [level].
+func NewZstdDictionaries(maxSize int64, level int, dicts ...[]byte) (*ZstdDictionaries, error) {
+	if maxSize == math.MaxInt64 {
+		return nil, ErrInvalidMaxSizeCompressor
+	}
+
+	// A single decoder is able to decompress messages compressed with any of
+	// the dictionaries, because the ID of the dictionary is included in the
+	// frame header.
+	decoder, err := zstd.NewReader(
+		nil,
+		zstd.WithDecoderDicts(dicts...),
+		zstd.WithDecoderMaxMemory(uint64(maxSize)),
+		zstd.WithDecoderConcurrency(0),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	z := &ZstdDictionaries{
+		maxSize:     maxSize,
+		decoder:     decoder,
+		compressors: make(map[uint32]*zstdDictCompressor, len(dicts)),
+	}
+	for _, dict := range dicts {
+		id, err := ZstdDictionaryID(dict)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := z.compressors[id]; ok {
+			return nil, fmt.Errorf("%w: %d", errDuplicateDictionaryID, id)
+		}
+
+		encoder, err := zstd.NewWriter(
+			nil,
+			zstd.WithEncoderDict(dict),
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+			// Peer connections already guarantee the integrity of messages.
+			zstd.WithEncoderCRC(false),
+		)
+		if err != nil {
+			return nil, err
+		}
+		z.compressors[id] = &zstdDictCompressor{
+			dictionaries: z,
+			encoder:      encoder,
+		}
+	}
+	return z, nil
+}
+
+// IDs returns the IDs of the dictionaries.
+func (z *ZstdDictionaries) IDs() []uint32 {
+	ids := make([]uint32, 0, len(z.compressors))
+	for id := range z.compressors {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Compressor returns the compressor that compresses messages with the
+// dictionary [id]. The returned compressor decompresses messages compressed
+// with any of the dictionaries.
+func (z *ZstdDictionaries) Compressor(id uint32) (Compressor, bool) {
+	c, ok := z.compressors[id]
+	return c, ok
+}
+
+// Decompress decompresses [msg], which may be compressed with any of the
+// dictionaries.
+func (z *ZstdDictionaries) Decompress(msg []byte) ([]byte, error) {
+	decompressed, err := z.decoder.DecodeAll(msg, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, fmt.Errorf("%w: %w", ErrDecompressedMsgTooLarge, err)
+	}
+	return decompressed, err
+}
+
+type zstdDictCompressor struct {
+	dictionaries *ZstdDictionaries
+	encoder      *zstd.Encoder
+}
+
+func (z *zstdDictCompressor) Compress(msg []byte) ([]byte, error) {
+	if maxSize := z.dictionaries.maxSize; int64(len(msg)) > maxSize {
+		return nil, fmt.Errorf("%w: (%d) > (%d)", ErrMsgTooLarge, len(msg), maxSize)
+	}
+	return z.encoder.EncodeAll(msg, nil), nil
+}
+
+func (z *zstdDictCompressor) Decompress(msg []byte) ([]byte, error) {
+	return z.dictionaries.Decompress(msg)
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package compression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/utils"
+)
+
+func newTestDictionary(t *testing.T, id uint32) []byte {
+	t.Helper()
+
+	samples := make([][]byte, 0, 256)
+	for i := range cap(samples) {
+		sample := fmt.Appendf(nil, `{"id":%d,"type":"vote","chain":"%d","accepted":true}`, i, id)
+		samples = append(samples, sample)
+	}
+	d, err := dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: 4096,
+		HashBytes:   6,
+		ZstdDictID:  id,
+	})
+	require.NoError(t, err)
+	return d
+}
+
+func TestZstdDictionaries(t *testing.T) {
+	require := require.New(t)
+
+	const (
+		id0 = 100_000
+		id1 = 100_001
+	)
+	dicts, err := NewZstdDictionaries(
+		maxMessageSize,
+		3,
+		newTestDictionary(t, id0),
+		newTestDictionary(t, id1),
+	)
+	require.NoError(err)
+	require.ElementsMatch([]uint32{id0, id1}, dicts.IDs())
+
+	_, ok := dicts.Compressor(1)
+	require.False(ok)
+
+	compressor0, ok := dicts.Compressor(id0)
+	require.True(ok)
+	compressor1, ok := dicts.Compressor(id1)
+	require.True(ok)
+
+	msg := []byte(`{"id":1000,"type":"vote","chain":"100000","accepted":true}`)
+	compressed, err := compressor0.Compress(msg)
+	require.NoError(err)
+
+	// The dictionary makes a small message smaller than without it.
+	zstdCompressor, err := NewZstdCompressor(maxMessageSize)
+	require.NoError(err)
+	compressedWithoutDict, err := zstdCompressor.Compress(msg)
+	require.NoError(err)
+	require.Less(len(compressed), len(compressedWithoutDict))
+
+	// Any compressor can decompress messages compressed with any dictionary.
+	for _, compressor := range []Compressor{compressor0, compressor1} {
+		decompressed, err := compressor.Decompress(compressed)
+		require.NoError(err)
+		require.Equal(msg, decompressed)
+	}
+
+	// Large messages that don't match the dictionary are still supported.
+	large := utils.RandomBytes(maxMessageSize)
+	compressed, err = compressor1.Compress(large)
+	require.NoError(err)
+	decompressed, err := compressor0.Decompress(compressed)
+	require.NoError(err)
+	require.Equal(large, decompressed)
+
+	_, err = compressor0.Compress(make([]byte, maxMessageSize+1))
+	require.ErrorIs(err, ErrMsgTooLarge)
+}
+
+func TestZstdDictionariesSizeLimiting(t *testing.T) {
+	require := require.New(t)
+
+	const id = 100_000
+	d := newTestDictionary(t, id)
+	large, err := NewZstdDictionaries(2*maxMessageSize, 3, d)
+	require.NoError(err)
+	small, err := NewZstdDictionaries(maxMessageSize, 3, d)
+	require.NoError(err)
+
+	largeCompressor, _ := large.Compressor(id)
+	smallCompressor, _ := small.Compressor(id)
+
+	compressed, err := largeCompressor.Compress(make([]byte, maxMessageSize+1))
+	require.NoError(err)
+	_, err = smallCompressor.Decompress(compressed)
+	require.ErrorIs(err, ErrDecompressedMsgTooLarge)
+}
+
+func TestZstdDictionariesDuplicateID(t *testing.T) {
+	d := newTestDictionary(t, 100_000)
+	_, err := NewZstdDictionaries(maxMessageSize, 3, d, d)
+	require.ErrorIs(t, err, errDuplicateDictionaryID)
+}
@@ -48,11 +48,18 @@ const (
 	DefaultNoIngressValidatorConnectionGracePeriod = 10 * time.Minute
 
 	DefaultNetworkCompressionType           = compression.TypeZstd
+	DefaultNetworkCompressionZstdLevel      = 5
 	DefaultNetworkMaxClockDifference        = time.Minute
 	DefaultNetworkRequireValidatorToConnect = false
 	DefaultNetworkPeerReadBufferSize        = 8 * units.KiB
 	DefaultNetworkPeerWriteBufferSize       = 8 * units.KiB
 
+	// Send queue priority weights
+	DefaultNetworkSendQueueConsensusWeight = 16
+	DefaultNetworkSendQueueGossipWeight    = 2
+	DefaultNetworkSendQueueBootstrapWeight = 1
+	DefaultNetworkSendQueueAppWeight       = 4
+
 	DefaultNetworkTCPProxyEnabled = false
 
 	// The PROXY protocol specification recommends setting this value to be at
@@ -0,0 +1,249 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package iblt implements invertible bloom lookup tables, which allow two
+// parties to find the difference between their sets with communication
+// proportional to the size of the difference rather than the size of the sets.
+//
+// Keys are 64 bits so that cells are small. Sets of larger values, such as
+// IDs, should be mapped to salted 64-bit keys by the caller, which maps the
+// decoded keys back to its own values.
+package iblt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+const (
+	// NumHashes is the number of cells that every key is added to.
+	NumHashes = 3
+	// CellLen is the number of bytes of a marshalled cell.
+	CellLen = countLen + keyLen + checksumLen
+
+	countLen    = 4
+	keyLen      = 8
+	checksumLen = 4
+)
+
+var (
+	ErrUndecodable = errors.New("table could not be decoded")
+
+	errTooFewCells      = errors.New("too few cells")
+	errInvalidLen       = errors.New("invalid table length")
+	errMismatchedTables = errors.New("mismatched tables")
+	errDuplicateKey     = errors.New("duplicate key")
+)
+
+type cell struct {
+	count    int32
+	keySum   uint64
+	checkSum uint32
+}
+
+func (c *cell) update(key uint64, checksum uint32, count int32) {
+	c.count += count
+	c.keySum ^= key
+	c.checkSum ^= checksum
+}
+
+func (c *cell) isEmpty() bool {
+	return c.count == 0 && c.keySum == 0 && c.checkSum == 0
+}
+
+// Table is an invertible bloom lookup table of 64-bit keys.
+//
+// The cells are split into [NumHashes] partitions, and every key is added to
+// one cell of every partition.
+type Table struct {
+	salt  ids.ID
+	cells []cell
+}
+
+// New returns an empty table with at least [numCells] cells. Keys are hashed
+// with [salt], so only tables with the same salt can be subtracted.
+func New(numCells int, salt ids.ID) (*Table, error) {
+	if numCells < NumHashes {
+		return nil, fmt.Errorf("%w: %d < %d", errTooFewCells, numCells, NumHashes)
+	}
+	// Round up to a multiple of NumHashes so that the partitions are of equal
+	// size.
+	numCells += (NumHashes - numCells%NumHashes) % NumHashes
+	return &Table{
+		salt:  salt,
+		cells: make([]cell, numCells),
+	}, nil
+}
+
+// Parse the output of [Table.Marshal].
+func Parse(bytes []byte, salt ids.ID) (*Table, error) {
+	if len(bytes) == 0 || len(bytes)%(CellLen*NumHashes) != 0 {
+		return nil, fmt.Errorf("%w: %d", errInvalidLen, len(bytes))
+	}
+
+	t := &Table{
+		salt:  salt,
+		cells: make([]cell, len(bytes)/CellLen),
+	}
+	for i := range t.cells {
+		c := &t.cells[i]
+		c.count = int32(binary.BigEndian.Uint32(bytes))
+		bytes = bytes[countLen:]
+		c.keySum = binary.BigEndian.Uint64(bytes)
+		bytes = bytes[keyLen:]
+		c.checkSum = binary.BigEndian.Uint32(bytes)
+		bytes = bytes[checksumLen:]
+	}
+	return t, nil
+}
+
+// Len returns the number of cells in the table.
+func (t *Table) Len() int {
+	return len(t.cells)
+}
+
+// Add adds [key] to the table.
+func (t *Table) Add(key uint64) {
+	t.update(key, 1)
+}
+
+// Remove removes [key] from the table. It is assumed that [key] was previously
+// added to the table.
+func (t *Table) Remove(key uint64) {
+	t.update(key, -1)
+}
+
+func (t *Table) update(key uint64, count int32) {
+	checksum, indices := t.hash(key)
+	for _, i := range indices {
+		t.cells[i].update(key, checksum, count)
+	}
+}
+
+// Subtract removes the keys of [other] from the table. After subtracting, the
+// table only contains the keys that were in exactly one of the tables.
+func (t *Table) Subtract(other *Table) error {
+	if t.salt != other.salt || len(t.cells) != len(other.cells) {
+		return errMismatchedTables
+	}
+	for i := range t.cells {
+		c := &t.cells[i]
+		o := &other.cells[i]
+		c.update(o.keySum, o.checkSum, -o.count)
+	}
+	return nil
+}
+
+// Decode lists the keys in the table. Keys with a positive count were only
+// added to this table, and keys with a negative count were only added to the
+// subtracted table.
+//
+// If the table has too many keys for its size, [ErrUndecodable] is returned.
+// Decoding doesn't modify the table.
+func (t *Table) Decode() ([]uint64, []uint64, error) {
+	var (
+		cells    = make([]cell, len(t.cells))
+		pure     = make([]int, 0, len(t.cells))
+		decoded  set.Set[uint64]
+		positive []uint64
+		negative []uint64
+	)
+	copy(cells, t.cells)
+	for i := range cells {
+		if t.isPure(&cells[i]) {
+			pure = append(pure, i)
+		}
+	}
+
+	for len(pure) > 0 {
+		i := pure[len(pure)-1]
+		pure = pure[:len(pure)-1]
+
+		c := cells[i]
+		if !t.isPure(&c) {
+			continue
+		}
+
+		// A key can only be decoded once from a valid table, so a repeated
+		// key means that the table was maliciously constructed.
+		if decoded.Contains(c.keySum) {
+			return nil, nil, fmt.Errorf("%w: %d", errDuplicateKey, c.keySum)
+		}
+		// Every decoded key empties a cell, so a valid table never contains
+		// more keys than cells.
+		if decoded.Len() == len(cells) {
+			return nil, nil, ErrUndecodable
+		}
+		decoded.Add(c.keySum)
+
+		if c.count > 0 {
+			positive = append(positive, c.keySum)
+		} else {
+			negative = append(negative, c.keySum)
+		}
+
+		_, indices := t.hash(c.keySum)
+		for _, j := range indices {
+			cells[j].update(c.keySum, c.checkSum, -c.count)
+			if t.isPure(&cells[j]) {
+				pure = append(pure, j)
+			}
+		}
+	}
+
+	for i := range cells {
+		if !cells[i].isEmpty() {
+			return nil, nil, ErrUndecodable
+		}
+	}
+	return positive, negative, nil
+}
+
+// Marshal returns the bytes of the cells of the table. The salt is not
+// included.
+func (t *Table) Marshal() []byte {
+	bytes := make([]byte, 0, len(t.cells)*CellLen)
+	for i := range t.cells {
+		c := &t.cells[i]
+		bytes = binary.BigEndian.AppendUint32(bytes, uint32(c.count))
+		bytes = binary.BigEndian.AppendUint64(bytes, c.keySum)
+		bytes = binary.BigEndian.AppendUint32(bytes, c.checkSum)
+	}
+	return bytes
+}
+
+// isPure returns true if the cell contains exactly one key.
+func (t *Table) isPure(c *cell) bool {
+	if c.count != 1 && c.count != -1 {
+		return false
+	}
+	checksum, _ := t.hash(c.keySum)
+	return c.checkSum == checksum
+}
+
+// hash returns the checksum of [key] and the index of its cell in every
+// partition.
+func (t *Table) hash(key uint64) (uint32, [NumHashes]int) {
+	hash := sha256.New()
+	// sha256.Write never returns errors
+	_, _ = hash.Write(binary.BigEndian.AppendUint64(make([]byte, 0, keyLen), key))
+	_, _ = hash.Write(t.salt[:])
+	output := hash.Sum(make([]byte, 0, sha256.Size))
+
+	var (
+		checksum      = binary.BigEndian.Uint32(output)
+		partitionSize = uint64(len(t.cells) / NumHashes)
+		indices       [NumHashes]int
+	)
+	output = output[checksumLen:]
+	for i := range indices {
+		offset := binary.BigEndian.Uint64(output[i*8:]) % partitionSize
+		indices[i] = i*int(partitionSize) + int(offset)
+	}
+	return checksum, indices
+}
@@ -0,0 +1,207 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package iblt
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		numCells    int
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name:        "too few cells",
+			numCells:    NumHashes - 1,
+			expectedErr: errTooFewCells,
+		},
+		{
+			name:        "multiple of num hashes",
+			numCells:    3 * NumHashes,
+			expectedLen: 3 * NumHashes,
+		},
+		{
+			name:        "rounded up",
+			numCells:    3*NumHashes + 1,
+			expectedLen: 4 * NumHashes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := New(tt.numCells, ids.Empty)
+			require.ErrorIs(t, err, tt.expectedErr)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.expectedLen, table.Len())
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name             string
+		numCells         int
+		numShared        int
+		numOnlyLocal     int
+		numOnlyRemote    int
+		expectDecodeable bool
+	}{
+		{
+			name:             "equal sets",
+			numCells:         30,
+			numShared:        1000,
+			expectDecodeable: true,
+		},
+		{
+			name:             "small difference",
+			numCells:         60,
+			numShared:        1000,
+			numOnlyLocal:     10,
+			numOnlyRemote:    10,
+			expectDecodeable: true,
+		},
+		{
+			name:             "difference larger than the table",
+			numCells:         30,
+			numShared:        1000,
+			numOnlyLocal:     100,
+			numOnlyRemote:    100,
+			expectDecodeable: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			salt := ids.ID{1}
+			local, err := New(tt.numCells, salt)
+			require.NoError(err)
+			remote, err := New(tt.numCells, salt)
+			require.NoError(err)
+
+			// IDs are generated deterministically so that the test doesn't
+			// depend on the probability of decoding the table.
+			var nextID uint64
+			newID := func() uint64 {
+				nextID++
+				return nextID
+			}
+			for range tt.numShared {
+				id := newID()
+				local.Add(id)
+				remote.Add(id)
+			}
+			onlyLocal := make([]uint64, tt.numOnlyLocal)
+			for i := range onlyLocal {
+				onlyLocal[i] = newID()
+				local.Add(onlyLocal[i])
+			}
+			onlyRemote := make([]uint64, tt.numOnlyRemote)
+			for i := range onlyRemote {
+				onlyRemote[i] = newID()
+				remote.Add(onlyRemote[i])
+			}
+
+			parsedRemote, err := Parse(remote.Marshal(), salt)
+			require.NoError(err)
+			require.Equal(remote, parsedRemote)
+
+			require.NoError(local.Subtract(parsedRemote))
+			positive, negative, err := local.Decode()
+			if !tt.expectDecodeable {
+				require.ErrorIs(err, ErrUndecodable)
+				return
+			}
+			require.NoError(err)
+			require.ElementsMatch(onlyLocal, positive)
+			require.ElementsMatch(onlyRemote, negative)
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	require := require.New(t)
+
+	table, err := New(NumHashes, ids.Empty)
+	require.NoError(err)
+
+	const key = 1
+	table.Add(key)
+	table.Remove(key)
+
+	empty, err := New(NumHashes, ids.Empty)
+	require.NoError(err)
+	require.Equal(empty, table)
+}
+
+func TestSubtractMismatched(t *testing.T) {
+	tests := []struct {
+		name     string
+		numCells int
+		salt     ids.ID
+	}{
+		{
+			name:     "different size",
+			numCells: 2 * NumHashes,
+		},
+		{
+			name:     "different salt",
+			numCells: NumHashes,
+			salt:     ids.ID{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := New(NumHashes, ids.Empty)
+			require.NoError(t, err)
+			other, err := New(tt.numCells, tt.salt)
+			require.NoError(t, err)
+
+			require.ErrorIs(t, table.Subtract(other), errMismatchedTables)
+		})
+	}
+}
+
+func TestParseInvalidLen(t *testing.T) {
+	for _, numBytes := range []int{0, CellLen, NumHashes*CellLen + 1} {
+		_, err := Parse(make([]byte, numBytes), ids.Empty)
+		require.ErrorIs(t, err, errInvalidLen)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	const (
+		numShared     = 8 * 1024
+		numDifference = 64
+		numCells      = 3 * numDifference
+	)
+	salt := ids.GenerateTestID()
+	local, err := New(numCells, salt)
+	require.NoError(b, err)
+	remote, err := New(numCells, salt)
+	require.NoError(b, err)
+	for range numShared {
+		key := rand.Uint64()
+		local.Add(key)
+		remote.Add(key)
+	}
+	for range numDifference {
+		local.Add(rand.Uint64())
+	}
+	require.NoError(b, local.Subtract(remote))
+
+	for b.Loop() {
+		_, _, err := local.Decode()
+		require.NoError(b, err)
+	}
+}
@@ -22,7 +22,7 @@ func (h *Hash) Set(password string) error {
 		return err
 	}
 	// pw is the salted, hashed password
-	pw := argon2.IDKey([]byte(password), h.Salt[:], 1, 64*1024, 4, 32)
+	pw := DeriveKey(password, h.Salt[:])
 	copy(h.Password[:], pw[:32])
 	return nil
 }
@@ -30,6 +30,11 @@ func (h *Hash) Set(password string) error {
 // Check returns true iff the provided password was the same as the last
 // password set.
 func (h *Hash) Check(password string) bool {
-	pw := argon2.IDKey([]byte(password), h.Salt[:], 1, 64*1024, 4, 32)
+	pw := DeriveKey(password, h.Salt[:])
 	return bytes.Equal(pw, h.Password[:])
 }
+
+// DeriveKey returns the 32 byte key derived from [password] and [salt].
+func DeriveKey(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+}
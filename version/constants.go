@@ -15,7 +15,7 @@ const (
 	// RPCChainVMProtocol should be bumped anytime changes are made which
 	// require the plugin vm to upgrade to latest avalanchego release to be
 	// compatible.
-	RPCChainVMProtocol uint = 45
+	RPCChainVMProtocol uint = 46
 
 	CurrentDatabase = "v1.4.5"
 	PrevDatabase    = "v1.0.0"
@@ -27,7 +27,7 @@ var (
 		Name:  Client,
 		Major: 1,
 		Minor: 14,
-		Patch: 2,
+		Patch: 3,
 	}
 	MinimumCompatibleVersion = &Application{
 		Name:  Client,
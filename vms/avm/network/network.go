@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
 	"github.com/ava-labs/avalanchego/network/p2p/gossip"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -45,6 +46,7 @@ func New(
 	txVerifier TxVerifier,
 	mempool mempool.Mempool[*txs.Tx],
 	appSender common.AppSender,
+	reputationTracker reputation.Tracker,
 	registerer prometheus.Registerer,
 	config Config,
 ) (*Network, error) {
@@ -105,6 +107,7 @@ func New(
 			},
 			DiscardedPushCacheSize: config.PushGossipDiscardedCacheSize,
 			RegossipPeriod:         config.PushGossipMaxRegossipFrequency,
+			ReputationTracker:      reputationTracker,
 		},
 	)
 	if err != nil {
@@ -243,6 +243,7 @@ func TestNetworkIssueTxFromRPC(t *testing.T) {
 				txVerifierFunc(ctrl),
 				tt.mempool,
 				appSenderFunc(ctrl),
+				nil,
 				prometheus.NewRegistry(),
 				testConfig,
 			)
@@ -317,6 +318,7 @@ func TestNetworkIssueTxFromRPCWithoutVerification(t *testing.T) {
 				executormock.NewManager(ctrl), // Should never verify a tx
 				tt.mempool,
 				appSenderFunc(ctrl),
+				nil,
 				prometheus.NewRegistry(),
 				testConfig,
 			)
@@ -398,6 +398,7 @@ func (vm *VM) Linearize(ctx context.Context, stopVertexID ids.ID) error {
 		),
 		mempool,
 		vm.appSender,
+		vm.ctx.ReputationTracker,
 		vm.registerer,
 		vm.networkConfig,
 	)
@@ -174,6 +174,7 @@ func newEnvironment(t *testing.T, f upgradetest.Fork) *environment { //nolint:un
 		&res.ctx.Lock,
 		res.state,
 		res.ctx.WarpSigner,
+		res.ctx.ReputationTracker,
 		registerer,
 		config.DefaultNetwork,
 	)
@@ -63,6 +63,18 @@ func (c *Client) GetBalance(ctx context.Context, addrs []ids.ShortID, options ..
 	return res, err
 }
 
+// GetBalanceAt returns the balance of addrs as of height.
+//
+// Requires the node to have the archive enabled.
+func (c *Client) GetBalanceAt(ctx context.Context, addrs []ids.ShortID, height uint64, options ...rpc.Option) (*GetBalanceResponse, error) {
+	res := &GetBalanceResponse{}
+	err := c.Requester.SendRequest(ctx, "platform.getBalanceAt", &GetBalanceAtRequest{
+		Addresses: ids.ShortIDsToStrings(addrs),
+		Height:    json.Uint64(height),
+	}, res, options...)
+	return res, err
+}
+
 // GetUTXOs returns the byte representation of the UTXOs controlled by addrs.
 func (c *Client) GetUTXOs(
 	ctx context.Context,
@@ -100,7 +112,42 @@ func (c *Client) GetAtomicUTXOs(
 	if err != nil {
 		return nil, ids.ShortID{}, ids.Empty, err
 	}
+	return parseUTXOsReply(res)
+}
+
+// GetUTXOsAt returns the byte representation of the UTXOs controlled by addrs
+// as of height.
+//
+// Requires the node to have the archive enabled.
+func (c *Client) GetUTXOsAt(
+	ctx context.Context,
+	addrs []ids.ShortID,
+	height uint64,
+	limit uint32,
+	startAddress ids.ShortID,
+	startUTXOID ids.ID,
+	options ...rpc.Option,
+) ([][]byte, ids.ShortID, ids.ID, error) {
+	res := &api.GetUTXOsReply{}
+	err := c.Requester.SendRequest(ctx, "platform.getUTXOsAt", &GetUTXOsAtArgs{
+		GetUTXOsArgs: api.GetUTXOsArgs{
+			Addresses: ids.ShortIDsToStrings(addrs),
+			Limit:     json.Uint32(limit),
+			StartIndex: api.Index{
+				Address: startAddress.String(),
+				UTXO:    startUTXOID.String(),
+			},
+			Encoding: formatting.Hex,
+		},
+		Height: json.Uint64(height),
+	}, res, options...)
+	if err != nil {
+		return nil, ids.ShortID{}, ids.Empty, err
+	}
+	return parseUTXOsReply(res)
+}
 
+func parseUTXOsReply(res *api.GetUTXOsReply) ([][]byte, ids.ShortID, ids.ID, error) {
 	utxos := make([][]byte, len(res.UTXOs))
 	for i, utxo := range res.UTXOs {
 		utxoBytes, err := formatting.Decode(res.Encoding, utxo)
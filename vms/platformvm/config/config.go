@@ -26,6 +26,7 @@ var Default = Config{
 	L1InactiveValidatorsCacheSize: 256 * units.KiB,
 	L1SubnetIDNodeIDCacheSize:     16 * units.KiB,
 	ChecksumsEnabled:              false,
+	ArchiveEnabled:                false,
 	MempoolPruneFrequency:         30 * time.Minute,
 	MempoolGasCapacity:            1_000_000,
 }
@@ -46,6 +47,7 @@ type Config struct {
 	L1InactiveValidatorsCacheSize int           `json:"l1-inactive-validators-cache-size"`
 	L1SubnetIDNodeIDCacheSize     int           `json:"l1-subnet-id-node-id-cache-size"`
 	ChecksumsEnabled              bool          `json:"checksums-enabled"`
+	ArchiveEnabled                bool          `json:"archive-enabled"`
 	MempoolPruneFrequency         time.Duration `json:"mempool-prune-frequency"`
 	MempoolGasCapacity            gas.Gas       `json:"mempool-gas-capacity"`
 }
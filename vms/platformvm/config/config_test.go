@@ -70,9 +70,11 @@ func TestConfigUnmarshal(t *testing.T) {
 				PushRegossipNumPeers:                        7,
 				PushGossipDiscardedCacheSize:                8,
 				PushGossipMaxRegossipFrequency:              9,
+				PushGossipAdaptiveFanOut:                    true,
 				PushGossipFrequency:                         10,
 				PullGossipFrequency:                         12,
 				PullGossipThrottlingPeriod:                  13,
+				PullGossipSetReconciliation:                 true,
 				PullGossipRequestsPerValidator:              14,
 				ExpectedBloomFilterElements:                 15,
 				ExpectedBloomFilterFalsePositiveProbability: 16,
@@ -91,6 +93,7 @@ func TestConfigUnmarshal(t *testing.T) {
 			L1InactiveValidatorsCacheSize: 12,
 			L1SubnetIDNodeIDCacheSize:     13,
 			ChecksumsEnabled:              true,
+			ArchiveEnabled:                true,
 			MempoolPruneFrequency:         time.Minute,
 			MempoolGasCapacity:            14,
 		}
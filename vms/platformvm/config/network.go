@@ -19,9 +19,11 @@ var DefaultNetwork = Network{
 	PushRegossipNumPeers:           0,
 	PushGossipDiscardedCacheSize:   16384,
 	PushGossipMaxRegossipFrequency: 30 * time.Second,
+	PushGossipAdaptiveFanOut:       false,
 	PushGossipFrequency:            500 * time.Millisecond,
 	PullGossipFrequency:            1500 * time.Millisecond,
 	PullGossipThrottlingPeriod:     time.Hour,
+	PullGossipSetReconciliation:    false,
 	// PullGossipRequestsPerValidator = PullGossipThrottlingPeriod / PullGossipFrequency =
 	// 3600 seconds/period / 1.5 requests/second = 2400 requests/validator
 	PullGossipRequestsPerValidator:              2400,
@@ -61,6 +63,10 @@ type Network struct {
 	// PushGossipMaxRegossipFrequency is the limit for how frequently a
 	// transaction will be push gossiped.
 	PushGossipMaxRegossipFrequency time.Duration `json:"push-gossip-max-regossip-frequency"`
+	// PushGossipAdaptiveFanOut scales the number of validators and peers that
+	// transactions are pushed to based on how many transactions are already
+	// known by peers and how many are only learned through pull gossip.
+	PushGossipAdaptiveFanOut bool `json:"push-gossip-adaptive-fan-out"`
 	// PushGossipFrequency is how frequently rounds of push gossip are
 	// performed.
 	PushGossipFrequency time.Duration `json:"push-gossip-frequency"`
@@ -70,6 +76,10 @@ type Network struct {
 	// PullGossipThrottlingPeriod is how large of a window the throttler should
 	// use.
 	PullGossipThrottlingPeriod time.Duration `json:"pull-gossip-throttling-period"`
+	// PullGossipSetReconciliation requests transactions by reconciling the
+	// mempool with the mempools of validators rather than by sending a bloom
+	// filter of the mempool.
+	PullGossipSetReconciliation bool `json:"pull-gossip-set-reconciliation"`
 	// PullGossipRequestsPerValidator is the number of pull gossip requests that
 	// a validator is expected to make in a throttling period.
 	PullGossipRequestsPerValidator float64 `json:"pull-gossip-requests-per-validator"`
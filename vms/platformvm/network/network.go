@@ -15,6 +15,7 @@ import (
 	"github.com/ava-labs/avalanchego/network/p2p"
 	"github.com/ava-labs/avalanchego/network/p2p/acp118"
 	"github.com/ava-labs/avalanchego/network/p2p/gossip"
+	"github.com/ava-labs/avalanchego/network/reputation"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -51,6 +52,7 @@ func New(
 	stateLock sync.Locker,
 	state state.Chain,
 	signer warp.Signer,
+	reputationTracker reputation.Tracker,
 	registerer prometheus.Registerer,
 	config config.Network,
 ) (*Network, error) {
@@ -108,8 +110,13 @@ func New(
 				Validators: config.PushRegossipNumValidators,
 				Peers:      config.PushRegossipNumPeers,
 			},
+			AdaptiveFanOut: gossip.AdaptiveFanOutConfig{
+				Enabled: config.PushGossipAdaptiveFanOut,
+			},
+			PullReconciliation:     config.PullGossipSetReconciliation,
 			DiscardedPushCacheSize: config.PushGossipDiscardedCacheSize,
 			RegossipPeriod:         config.PushGossipMaxRegossipFrequency,
+			ReputationTracker:      reputationTracker,
 		},
 	)
 	if err != nil {
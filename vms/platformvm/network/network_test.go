@@ -343,6 +343,7 @@ func TestNetworkIssueTxFromRPC(t *testing.T) {
 				nil,
 				nil,
 				nil,
+				nil,
 				prometheus.NewRegistry(),
 				testConfig,
 			)
@@ -26,6 +26,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/components/gas"
@@ -66,6 +67,7 @@ var (
 	errPrimaryNetworkIsNotASubnet = errors.New("the primary network isn't a subnet")
 	errNoAddresses                = errors.New("no addresses provided")
 	errMissingBlockchainID        = errors.New("argument 'blockchainID' not given")
+	errArchivedAtomicUTXOs        = errors.New("atomic UTXOs are not archived")
 )
 
 // Service defines the API calls that can be made to the platform chain
@@ -156,8 +158,57 @@ func (s *Service) GetBalance(_ *http.Request, args *GetBalanceRequest, response
 		return fmt.Errorf("couldn't get UTXO set of %v: %w", args.Addresses, err)
 	}
 
-	currentTime := s.vm.clock.Unix()
+	s.setBalance(utxos, s.vm.clock.Unix(), response)
+	return nil
+}
+
+// GetBalanceAtRequest is the request for GetBalanceAt
+type GetBalanceAtRequest struct {
+	Addresses []string       `json:"addresses"`
+	Height    avajson.Uint64 `json:"height"`
+}
+
+// GetBalanceAt gets the balance of an address as of the provided height.
+//
+// Requires the archive to be enabled.
+func (s *Service) GetBalanceAt(_ *http.Request, args *GetBalanceAtRequest, response *GetBalanceResponse) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getBalanceAt"),
+		logging.UserStrings("addresses", args.Addresses),
+		zap.Uint64("height", uint64(args.Height)),
+	)
+
+	addrs, err := avax.ParseServiceAddresses(s.addrManager, args.Addresses)
+	if err != nil {
+		return err
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	archive, err := s.vm.state.ArchiveAt(uint64(args.Height))
+	if err != nil {
+		return fmt.Errorf("couldn't get archived state at %d: %w", args.Height, err)
+	}
+
+	utxos, err := avax.GetAllUTXOs(archive, addrs)
+	if err != nil {
+		return fmt.Errorf("couldn't get UTXO set of %v at %d: %w", args.Addresses, args.Height, err)
+	}
+
+	timestamp, err := archive.GetTimestamp()
+	if err != nil {
+		return fmt.Errorf("couldn't get timestamp at %d: %w", args.Height, err)
+	}
 
+	s.setBalance(utxos, uint64(timestamp.Unix()), response)
+	return nil
+}
+
+// setBalance populates [response] with the balances of [utxos], classifying
+// locked outputs relative to [currentTime].
+func (s *Service) setBalance(utxos []*avax.UTXO, currentTime uint64, response *GetBalanceResponse) {
 	unlockeds := map[ids.ID]uint64{}
 	lockedStakeables := map[ids.ID]uint64{}
 	lockedNotStakeables := map[ids.ID]uint64{}
@@ -245,7 +296,6 @@ utxoFor:
 	response.Unlocked = response.Unlockeds[s.vm.ctx.AVAXAssetID]
 	response.LockedStakeable = response.LockedStakeables[s.vm.ctx.AVAXAssetID]
 	response.LockedNotStakeable = response.LockedNotStakeables[s.vm.ctx.AVAXAssetID]
-	return nil
 }
 
 func newJSONBalanceMap(balanceMap map[ids.ID]uint64) map[ids.ID]avajson.Uint64 {
@@ -270,6 +320,33 @@ func (s *Service) GetUTXOs(_ *http.Request, args *api.GetUTXOsArgs, response *ap
 		zap.String("method", "getUTXOs"),
 	)
 
+	return s.getUTXOs(args, maybe.Nothing[uint64](), response)
+}
+
+// GetUTXOsAtArgs are the arguments for GetUTXOsAt
+type GetUTXOsAtArgs struct {
+	api.GetUTXOsArgs
+	Height avajson.Uint64 `json:"height"`
+}
+
+// GetUTXOsAt returns the UTXOs controlled by the given addresses as of the
+// provided height.
+//
+// Requires the archive to be enabled. Atomic UTXOs are not archived, so only
+// UTXOs on the P-Chain can be queried.
+func (s *Service) GetUTXOsAt(_ *http.Request, args *GetUTXOsAtArgs, response *api.GetUTXOsReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getUTXOsAt"),
+		zap.Uint64("height", uint64(args.Height)),
+	)
+
+	return s.getUTXOs(&args.GetUTXOsArgs, maybe.Some(uint64(args.Height)), response)
+}
+
+// getUTXOs returns the UTXOs controlled by the given addresses. If [height] is
+// provided, the UTXOs are read from the archive at that height.
+func (s *Service) getUTXOs(args *api.GetUTXOsArgs, height maybe.Maybe[uint64], response *api.GetUTXOsReply) error {
 	if len(args.Addresses) == 0 {
 		return errNoAddresses
 	}
@@ -287,6 +364,9 @@ func (s *Service) GetUTXOs(_ *http.Request, args *api.GetUTXOsArgs, response *ap
 		}
 		sourceChain = chainID
 	}
+	if sourceChain != s.vm.ctx.ChainID && height.HasValue() {
+		return errArchivedAtomicUTXOs
+	}
 
 	addrSet, err := avax.ParseServiceAddresses(s.addrManager, args.Addresses)
 	if err != nil {
@@ -319,7 +399,21 @@ func (s *Service) GetUTXOs(_ *http.Request, args *api.GetUTXOsArgs, response *ap
 	s.vm.ctx.Lock.Lock()
 	defer s.vm.ctx.Lock.Unlock()
 
-	if sourceChain == s.vm.ctx.ChainID {
+	switch {
+	case height.HasValue():
+		var archive *state.Archive
+		archive, err = s.vm.state.ArchiveAt(height.Value())
+		if err != nil {
+			return fmt.Errorf("couldn't get archived state at %d: %w", height.Value(), err)
+		}
+		utxos, endAddr, endUTXOID, err = avax.GetPaginatedUTXOs(
+			archive,
+			addrSet,
+			startAddr,
+			startUTXO,
+			limit,
+		)
+	case sourceChain == s.vm.ctx.ChainID:
 		utxos, endAddr, endUTXOID, err = avax.GetPaginatedUTXOs(
 			s.vm.state,
 			addrSet,
@@ -327,7 +421,7 @@ func (s *Service) GetUTXOs(_ *http.Request, args *api.GetUTXOsArgs, response *ap
 			startUTXO,
 			limit,
 		)
-	} else {
+	default:
 		utxos, endAddr, endUTXOID, err = avax.GetAtomicUTXOs(
 			s.vm.ctx.SharedMemory,
 			txs.Codec,
@@ -25,6 +25,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/snowtest"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/upgrade/upgradetest"
 	"github.com/ava-labs/avalanchego/utils"
@@ -41,6 +42,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/components/gas"
 	"github.com/ava-labs/avalanchego/vms/platformvm/block"
 	"github.com/ava-labs/avalanchego/vms/platformvm/block/executor/executormock"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
 	"github.com/ava-labs/avalanchego/vms/platformvm/genesis/genesistest"
 	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
 	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
@@ -427,6 +429,130 @@ func TestGetBalance(t *testing.T) {
 	}
 }
 
+func TestGetBalanceAtAndGetUTXOsAt(t *testing.T) {
+	require := require.New(t)
+
+	ctx := snowtest.Context(t, snowtest.PChainID)
+	cfg := config.Default
+	cfg.ArchiveEnabled = true
+	vmState := statetest.New(t, statetest.Config{
+		Config:  cfg,
+		Context: ctx,
+	})
+	service := &Service{
+		vm: &VM{
+			state: vmState,
+			ctx:   ctx,
+		},
+		addrManager: avax.NewAddressManager(ctx),
+	}
+
+	var (
+		addr    = ids.GenerateTestShortID()
+		time1   = genesistest.DefaultValidatorStartTime.Add(time.Hour)
+		time2   = time1.Add(time.Hour)
+		newUTXO = func(amount uint64, locktime time.Time) *avax.UTXO {
+			return &avax.UTXO{
+				UTXOID: avax.UTXOID{
+					TxID: ids.GenerateTestID(),
+				},
+				Asset: avax.Asset{
+					ID: ctx.AVAXAssetID,
+				},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: amount,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Locktime:  uint64(locktime.Unix()),
+						Threshold: 1,
+						Addrs:     []ids.ShortID{addr},
+					},
+				},
+			}
+		}
+	)
+	addrStr, err := address.Format("P", constants.UnitTestHRP, addr.Bytes())
+	require.NoError(err)
+
+	// The locked UTXO unlocks between height 1 and height 2
+	vmState.SetHeight(1)
+	vmState.SetTimestamp(time1)
+	vmState.AddUTXO(newUTXO(1, time2))
+	require.NoError(vmState.Commit())
+
+	vmState.SetHeight(2)
+	vmState.SetTimestamp(time2)
+	vmState.AddUTXO(newUTXO(2, time.Unix(0, 0)))
+	require.NoError(vmState.Commit())
+
+	tests := []struct {
+		height                 uint64
+		wantUnlocked           uint64
+		wantLockedNotStakeable uint64
+		wantNumUTXOs           int
+	}{
+		{
+			height: 0,
+		},
+		{
+			height:                 1,
+			wantLockedNotStakeable: 1,
+			wantNumUTXOs:           1,
+		},
+		{
+			height:       2,
+			wantUnlocked: 3,
+			wantNumUTXOs: 2,
+		},
+	}
+	for _, test := range tests {
+		balanceReply := GetBalanceResponse{}
+		require.NoError(service.GetBalanceAt(nil, &GetBalanceAtRequest{
+			Addresses: []string{addrStr},
+			Height:    avajson.Uint64(test.height),
+		}, &balanceReply))
+		require.Equal(avajson.Uint64(test.wantUnlocked+test.wantLockedNotStakeable), balanceReply.Balance)
+		require.Equal(avajson.Uint64(test.wantUnlocked), balanceReply.Unlocked)
+		require.Equal(avajson.Uint64(test.wantLockedNotStakeable), balanceReply.LockedNotStakeable)
+		require.Len(balanceReply.UTXOIDs, test.wantNumUTXOs)
+
+		utxosReply := api.GetUTXOsReply{}
+		require.NoError(service.GetUTXOsAt(nil, &GetUTXOsAtArgs{
+			GetUTXOsArgs: api.GetUTXOsArgs{
+				Addresses: []string{addrStr},
+				Encoding:  formatting.Hex,
+			},
+			Height: avajson.Uint64(test.height),
+		}, &utxosReply))
+		require.Len(utxosReply.UTXOs, test.wantNumUTXOs)
+	}
+
+	err = service.GetBalanceAt(nil, &GetBalanceAtRequest{
+		Addresses: []string{addrStr},
+		Height:    3,
+	}, &GetBalanceResponse{})
+	require.ErrorIs(err, state.ErrArchiveHeightUnknown)
+
+	err = service.GetUTXOsAt(nil, &GetUTXOsAtArgs{
+		GetUTXOsArgs: api.GetUTXOsArgs{
+			Addresses:   []string{addrStr},
+			SourceChain: "X",
+		},
+	}, &api.GetUTXOsReply{})
+	require.ErrorIs(err, errArchivedAtomicUTXOs)
+}
+
+func TestGetBalanceAtArchiveDisabled(t *testing.T) {
+	service, _ := defaultService(t)
+
+	addrStr, err := address.Format("P", constants.UnitTestHRP, ids.GenerateTestShortID().Bytes())
+	require.NoError(t, err)
+
+	err = service.GetBalanceAt(nil, &GetBalanceAtRequest{
+		Addresses: []string{addrStr},
+	}, &GetBalanceResponse{})
+	require.ErrorIs(t, err, state.ErrArchiveDisabled)
+}
+
 func TestGetStake(t *testing.T) {
 	require := require.New(t)
 	service, _ := defaultService(t)
@@ -0,0 +1,188 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/x/archivedb"
+)
+
+var (
+	_ avax.UTXOReader = (*Archive)(nil)
+
+	ErrArchiveDisabled      = errors.New("archive is disabled")
+	ErrArchiveHeightUnknown = errors.New("height is not archived")
+
+	errArchiveNotInitialized = errors.New("archive can only be enabled on an uninitialized database")
+)
+
+// Archive is a read-only view of the UTXO set and chain time as of an accepted
+// height.
+//
+// Validator sets are not archived, as they can already be computed at any
+// height from the validator diffs. See [State.ApplyValidatorWeightDiffs].
+type Archive struct {
+	reader    *archivedb.Reader
+	addressDB database.Database
+}
+
+// ArchiveAt returns the archived state as of [height].
+//
+// Returns [ErrArchiveDisabled] if the archive is not enabled and
+// [ErrArchiveHeightUnknown] if [height] has not been accepted yet.
+func (s *State) ArchiveAt(height uint64) (*Archive, error) {
+	if s.archiveDB == nil {
+		return nil, ErrArchiveDisabled
+	}
+
+	lastHeight, err := s.archiveDB.Height()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last archived height: %w", err)
+	}
+	if height > lastHeight {
+		return nil, fmt.Errorf("%w: %d > last accepted height %d",
+			ErrArchiveHeightUnknown,
+			height,
+			lastHeight,
+		)
+	}
+	return &Archive{
+		reader:    s.archiveDB.Open(height),
+		addressDB: s.archiveAddressDB,
+	}, nil
+}
+
+// GetTimestamp returns the chain time as of the archived height.
+func (a *Archive) GetTimestamp() (time.Time, error) {
+	return database.GetTimestamp(a.reader, TimestampKey)
+}
+
+// GetUTXO returns the UTXO with [utxoID] if it was unspent as of the archived
+// height.
+func (a *Archive) GetUTXO(utxoID ids.ID) (*avax.UTXO, error) {
+	utxoBytes, err := a.reader.Get(utxoID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	utxo := &avax.UTXO{}
+	if _, err := txs.GenesisCodec.Unmarshal(utxoBytes, utxo); err != nil {
+		return nil, err
+	}
+	return utxo, nil
+}
+
+// UTXOIDs returns up to [limit] IDs of the UTXOs referencing [addr] that were
+// unspent as of the archived height. Only UTXO IDs greater than [start] are
+// returned.
+func (a *Archive) UTXOIDs(addr []byte, start ids.ID, limit int) ([]ids.ID, error) {
+	it := a.addressDB.NewIteratorWithStartAndPrefix(
+		archiveAddressKey(addr, start),
+		addr,
+	)
+	defer it.Release()
+
+	utxoIDs := []ids.ID(nil)
+	for len(utxoIDs) < limit && it.Next() {
+		utxoID, err := ids.ToID(it.Key()[len(addr):])
+		if err != nil {
+			return nil, err
+		}
+		if utxoID == start {
+			continue
+		}
+
+		// The address index includes every UTXO that ever referenced [addr],
+		// so UTXOs that didn't exist at the archived height must be skipped.
+		has, err := a.reader.Has(utxoID[:])
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			utxoIDs = append(utxoIDs, utxoID)
+		}
+	}
+	return utxoIDs, it.Error()
+}
+
+// writeArchive records the modified UTXOs and the chain time at [height]. It
+// must be called before the modified UTXOs are cleared.
+func (s *State) writeArchive(height uint64) error {
+	if s.archiveDB == nil {
+		return nil
+	}
+
+	batch := s.archiveDB.NewBatch(height)
+	for utxoID, utxo := range s.modifiedUTXOs {
+		if utxo == nil {
+			if err := batch.Delete(utxoID[:]); err != nil {
+				return fmt.Errorf("failed to archive UTXO deletion: %w", err)
+			}
+			continue
+		}
+
+		utxoBytes, err := txs.GenesisCodec.Marshal(txs.CodecVersion, utxo)
+		if err != nil {
+			return fmt.Errorf("failed to serialize UTXO: %w", err)
+		}
+		if err := batch.Put(utxoID[:], utxoBytes); err != nil {
+			return fmt.Errorf("failed to archive UTXO: %w", err)
+		}
+
+		addressable, ok := utxo.Out.(avax.Addressable)
+		if !ok {
+			continue
+		}
+		for _, addr := range addressable.Addresses() {
+			if err := s.archiveAddressDB.Put(archiveAddressKey(addr, utxoID), nil); err != nil {
+				return fmt.Errorf("failed to index archived UTXO: %w", err)
+			}
+		}
+	}
+	if err := database.PutTimestamp(batch, TimestampKey, s.timestamp); err != nil {
+		return fmt.Errorf("failed to archive timestamp: %w", err)
+	}
+	return batch.Write()
+}
+
+// initArchive verifies that the archive is consistent with the current
+// configuration. The archive must be enabled when the database is initialized,
+// otherwise earlier heights would be missing. If the archive was disabled, any
+// previously archived state is deleted.
+func (s *State) initArchive(wasInitialized bool) error {
+	hasArchive, err := s.singletonDB.Has(ArchiveEnabledKey)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case s.archiveDB != nil && !hasArchive:
+		if wasInitialized {
+			return errArchiveNotInitialized
+		}
+		return s.singletonDB.Put(ArchiveEnabledKey, nil)
+	case s.archiveDB == nil && hasArchive:
+		return errors.Join(
+			s.singletonDB.Delete(ArchiveEnabledKey),
+			database.DeleteRange(s.archiveBaseDB, nil, nil),
+			database.DeleteRange(s.archiveAddressDB, nil, nil),
+		)
+	default:
+		return nil
+	}
+}
+
+func archiveAddressKey(addr []byte, utxoID ids.ID) []byte {
+	key := make([]byte, len(addr)+ids.IDLen)
+	copy(key, addr)
+	copy(key[len(addr):], utxoID[:])
+	return key
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/upgrade/upgradetest"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/genesis/genesistest"
+	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
+	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func newArchiveTestState(t *testing.T, db database.Database, archiveEnabled bool) (*State, error) {
+	cfg := config.Default
+	cfg.ArchiveEnabled = archiveEnabled
+	return New(
+		db,
+		genesistest.NewBytes(t, genesistest.Config{}),
+		prometheus.NewRegistry(),
+		validators.NewManager(),
+		upgradetest.GetConfig(upgradetest.Latest),
+		&cfg,
+		&snow.Context{
+			NetworkID: constants.UnitTestID,
+			NodeID:    ids.GenerateTestNodeID(),
+			Log:       logging.NoLog{},
+		},
+		metrics.Noop,
+		reward.Config{
+			MaxConsumptionRate: .12 * reward.PercentDenominator,
+			MinConsumptionRate: .1 * reward.PercentDenominator,
+			MintingPeriod:      365 * 24 * time.Hour,
+			SupplyCap:          720 * units.MegaAvax,
+		},
+	)
+}
+
+func newArchiveTestUTXO(addr ids.ShortID) *avax.UTXO {
+	return &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: avax.Asset{
+			ID: ids.GenerateTestID(),
+		},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Avax,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	}
+}
+
+func TestArchive(t *testing.T) {
+	require := require.New(t)
+
+	s, err := newArchiveTestState(t, memdb.New(), true)
+	require.NoError(err)
+
+	var (
+		addr  = ids.GenerateTestShortID()
+		utxo0 = newArchiveTestUTXO(addr)
+		utxo1 = newArchiveTestUTXO(addr)
+		utxo2 = newArchiveTestUTXO(addr)
+		time1 = time.Unix(1_000, 0)
+		time2 = time.Unix(2_000, 0)
+	)
+
+	// Height 1 creates two UTXOs
+	s.SetHeight(1)
+	s.SetTimestamp(time1)
+	s.AddUTXO(utxo0)
+	s.AddUTXO(utxo1)
+	require.NoError(s.Commit())
+
+	// Height 2 consumes one of the UTXOs and creates a new one
+	s.SetHeight(2)
+	s.SetTimestamp(time2)
+	s.DeleteUTXO(utxo0.InputID())
+	s.AddUTXO(utxo2)
+	require.NoError(s.Commit())
+
+	tests := []struct {
+		height        uint64
+		wantTimestamp time.Time
+		wantUTXOs     []*avax.UTXO
+		wantMissing   []*avax.UTXO
+	}{
+		{
+			height:        0,
+			wantTimestamp: genesistest.DefaultValidatorStartTime,
+			wantMissing:   []*avax.UTXO{utxo0, utxo1, utxo2},
+		},
+		{
+			height:        1,
+			wantTimestamp: time1,
+			wantUTXOs:     []*avax.UTXO{utxo0, utxo1},
+			wantMissing:   []*avax.UTXO{utxo2},
+		},
+		{
+			height:        2,
+			wantTimestamp: time2,
+			wantUTXOs:     []*avax.UTXO{utxo1, utxo2},
+			wantMissing:   []*avax.UTXO{utxo0},
+		},
+	}
+	for _, test := range tests {
+		archive, err := s.ArchiveAt(test.height)
+		require.NoError(err)
+
+		timestamp, err := archive.GetTimestamp()
+		require.NoError(err)
+		require.Equal(test.wantTimestamp.Unix(), timestamp.Unix())
+
+		wantUTXOIDs := make([]ids.ID, len(test.wantUTXOs))
+		for i, utxo := range test.wantUTXOs {
+			wantUTXOIDs[i] = utxo.InputID()
+
+			gotUTXO, err := archive.GetUTXO(utxo.InputID())
+			require.NoError(err)
+			require.Equal(utxo.InputID(), gotUTXO.InputID())
+			require.Equal(utxo.Out, gotUTXO.Out)
+		}
+		for _, utxo := range test.wantMissing {
+			_, err := archive.GetUTXO(utxo.InputID())
+			require.ErrorIs(err, database.ErrNotFound)
+		}
+
+		gotUTXOIDs, err := archive.UTXOIDs(addr.Bytes(), ids.Empty, len(wantUTXOIDs)+1)
+		require.NoError(err)
+		require.ElementsMatch(wantUTXOIDs, gotUTXOIDs)
+	}
+
+	_, err = s.ArchiveAt(3)
+	require.ErrorIs(err, ErrArchiveHeightUnknown)
+}
+
+func TestArchiveUTXOIDsPagination(t *testing.T) {
+	require := require.New(t)
+
+	s, err := newArchiveTestState(t, memdb.New(), true)
+	require.NoError(err)
+
+	addr := ids.GenerateTestShortID()
+	s.SetHeight(1)
+	for range 5 {
+		s.AddUTXO(newArchiveTestUTXO(addr))
+	}
+	require.NoError(s.Commit())
+
+	archive, err := s.ArchiveAt(1)
+	require.NoError(err)
+
+	allUTXOIDs, err := archive.UTXOIDs(addr.Bytes(), ids.Empty, 10)
+	require.NoError(err)
+	require.Len(allUTXOIDs, 5)
+
+	var (
+		start     = ids.Empty
+		collected []ids.ID
+	)
+	for {
+		page, err := archive.UTXOIDs(addr.Bytes(), start, 2)
+		require.NoError(err)
+		if len(page) == 0 {
+			break
+		}
+		collected = append(collected, page...)
+		start = page[len(page)-1]
+	}
+	require.Equal(allUTXOIDs, collected)
+}
+
+func TestArchiveDisabled(t *testing.T) {
+	s, err := newArchiveTestState(t, memdb.New(), false)
+	require.NoError(t, err)
+
+	_, err = s.ArchiveAt(0)
+	require.ErrorIs(t, err, ErrArchiveDisabled)
+}
+
+func TestArchiveEnabledAfterInitialization(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	s, err := newArchiveTestState(t, db, false)
+	require.NoError(err)
+	require.NoError(s.Close())
+
+	_, err = newArchiveTestState(t, db, true)
+	require.ErrorIs(err, errArchiveNotInitialized)
+}
+
+func TestArchiveDisabledAfterInitialization(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	s, err := newArchiveTestState(t, db, true)
+	require.NoError(err)
+	s.SetHeight(1)
+	s.AddUTXO(newArchiveTestUTXO(ids.GenerateTestShortID()))
+	require.NoError(s.Commit())
+	require.NoError(s.Close())
+
+	// Disabling the archive deletes the archived state
+	s, err = newArchiveTestState(t, db, false)
+	require.NoError(err)
+	require.NoError(s.Commit())
+	require.NoError(s.Close())
+
+	for _, prefix := range [][]byte{ArchivePrefix, ArchiveAddressPrefix} {
+		it := prefixdb.New(prefix, db).NewIterator()
+		require.False(it.Next())
+		require.NoError(it.Error())
+		it.Release()
+	}
+
+	// The archive can't be re-enabled once heights are missing
+	_, err = newArchiveTestState(t, db, true)
+	require.ErrorIs(err, errArchiveNotInitialized)
+}
@@ -47,6 +47,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
 	"github.com/ava-labs/avalanchego/vms/platformvm/status"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/x/archivedb"
 
 	safemath "github.com/ava-labs/avalanchego/utils/math"
 )
@@ -94,6 +95,8 @@ var (
 	ActivePrefix                            = []byte("active")
 	InactivePrefix                          = []byte("inactive")
 	SingletonPrefix                         = []byte("singleton")
+	ArchivePrefix                           = []byte("archive")
+	ArchiveAddressPrefix                    = []byte("archiveAddress")
 
 	TimestampKey         = []byte("timestamp")
 	FeeStateKey          = []byte("fee state")
@@ -104,6 +107,7 @@ var (
 	HeightsIndexedKey    = []byte("heights indexed")
 	InitializedKey       = []byte("initialized")
 	BlocksReindexedKey   = []byte("blocks reindexed.3")
+	ArchiveEnabledKey    = []byte("archive enabled")
 
 	emptyL1ValidatorCache = &cache.Empty[ids.ID, maybe.Maybe[L1Validator]]{}
 )
@@ -233,9 +237,16 @@ type stateBlk struct {
  * |     '-- txID -> nil
  * |-. expiryReplayProtection
  * | '-- timestamp + validationID -> nil
+ * |-. archive
+ * | '-- archiveDB
+ * |   |-- utxoID -> utxo bytes at each height
+ * |   '-- timestampKey -> timestamp at each height
+ * |-. archiveAddress
+ * | '-- address + utxoID -> nil
  * '-. singletons
  *   |-- initializedKey -> nil
  *   |-- blocksReindexedKey -> nil
+ *   |-- archiveEnabledKey -> nil
  *   |-- timestampKey -> timestamp
  *   |-- feeStateKey -> feeState
  *   |-- l1ValidatorExcessKey -> l1ValidatorExcess
@@ -325,6 +336,11 @@ type State struct {
 	utxoDB        database.Database
 	utxoState     avax.UTXOState
 
+	// archiveDB is nil if the archive is disabled
+	archiveBaseDB    database.Database
+	archiveDB        *archivedb.Database
+	archiveAddressDB database.Database
+
 	cachedSubnetIDs []ids.ID // nil if the subnets haven't been loaded
 	addedSubnetIDs  []ids.ID
 	subnetBaseDB    database.Database
@@ -571,6 +587,15 @@ func New(
 		return nil, err
 	}
 
+	archiveBaseDB := prefixdb.New(ArchivePrefix, baseDB)
+	var archiveDB *archivedb.Database
+	if execCfg.ArchiveEnabled {
+		archiveDB, err = archivedb.New(archiveBaseDB, archivedb.DefaultConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	subnetBaseDB := prefixdb.New(SubnetPrefix, baseDB)
 
 	subnetOwnerDB := prefixdb.New(SubnetOwnerPrefix, baseDB)
@@ -706,6 +731,10 @@ func New(
 		utxoDB:        utxoDB,
 		utxoState:     utxoState,
 
+		archiveBaseDB:    archiveBaseDB,
+		archiveDB:        archiveDB,
+		archiveAddressDB: prefixdb.New(ArchiveAddressPrefix, baseDB),
+
 		subnetBaseDB: subnetBaseDB,
 		subnetDB:     linkeddb.NewDefault(subnetBaseDB),
 
@@ -2286,6 +2315,7 @@ func (s *State) write(updateValidators bool, height uint64) error {
 		s.writeL1Validators(),
 		s.writeTXs(),
 		s.writeRewardUTXOs(),
+		s.writeArchive(height),
 		s.writeUTXOs(),
 		s.writeSubnets(),
 		s.writeSubnetOwners(),
@@ -2330,6 +2360,8 @@ func (s *State) Close() error {
 		s.txDB.Close(),
 		s.rewardUTXODB.Close(),
 		s.utxoDB.Close(),
+		s.archiveBaseDB.Close(),
+		s.archiveAddressDB.Close(),
 		s.subnetBaseDB.Close(),
 		s.subnetToL1ConversionDB.Close(),
 		s.transformedSubnetDB.Close(),
@@ -2350,6 +2382,13 @@ func (s *State) sync(genesis []byte) error {
 		)
 	}
 
+	if err := s.initArchive(wasInitialized); err != nil {
+		return fmt.Errorf(
+			"failed to initialize the archive: %w",
+			err,
+		)
+	}
+
 	// If the database wasn't previously initialized, create the platform chain
 	// anew using the provided genesis state.
 	if !wasInitialized {
@@ -198,6 +198,7 @@ func (vm *VM) Initialize(
 		chainCtx.Lock.RLocker(),
 		vm.state,
 		chainCtx.WarpSigner,
+		chainCtx.ReputationTracker,
 		registerer,
 		execConfig.Network,
 	)
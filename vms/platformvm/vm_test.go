@@ -1202,6 +1202,7 @@ func TestBootstrapPartiallyAccepted(t *testing.T) {
 			TimeoutCoefficient: 1.25,
 		},
 		benchlist.NewNoBenchlist(),
+		nil,
 		prometheus.NewRegistry(),
 		prometheus.NewRegistry(),
 	)
@@ -1227,6 +1228,7 @@ func TestBootstrapPartiallyAccepted(t *testing.T) {
 	mc, err := message.NewCreator(
 		prometheus.NewRegistry(),
 		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkCompressionZstdLevel,
 		10*time.Second,
 	)
 	require.NoError(err)
@@ -1285,6 +1287,7 @@ func TestBootstrapPartiallyAccepted(t *testing.T) {
 		prometheus.NewRegistry(),
 		set.Of(ctx.NodeID),
 		nil,
+		nil,
 	)
 	require.NoError(err)
 
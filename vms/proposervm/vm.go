@@ -61,7 +61,9 @@ var (
 	_ block.BatchedChainVM  = (*VM)(nil)
 	_ block.StateSyncableVM = (*VM)(nil)
 
-	dbPrefix = []byte("proposervm")
+	// DBPrefix is the prefix of the database used by the proposervm within
+	// the database provided to Initialize.
+	DBPrefix = []byte("proposervm")
 )
 
 func cachedBlockSize(_ ids.ID, blk snowman.Block) int {
@@ -155,7 +157,7 @@ func (vm *VM) Initialize(
 	appSender common.AppSender,
 ) error {
 	vm.ctx = chainCtx
-	vm.db = versiondb.New(prefixdb.New(dbPrefix, db))
+	vm.db = versiondb.New(prefixdb.New(DBPrefix, db))
 	baseState, err := state.NewMetered(vm.db, "state", vm.Config.Registerer)
 	if err != nil {
 		return err
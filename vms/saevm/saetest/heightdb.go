@@ -4,6 +4,7 @@
 package saetest
 
 import (
+	"maps"
 	"slices"
 	"sync"
 
@@ -113,3 +114,63 @@ func (h *hIndex) Close() error {
 		return nil
 	})
 }
+
+func (h *hIndex) NewIteratorFromHeight(n uint64) database.HeightIterator {
+	it, err := readHIndex(h, func() (*hIterator, error) {
+		it := new(hIterator)
+		for _, height := range slices.Sorted(maps.Keys(h.data)) {
+			if height >= n {
+				it.heights = append(it.heights, height)
+				it.values = append(it.values, slices.Clone(h.data[height]))
+			}
+		}
+		return it, nil
+	})
+	if err != nil {
+		return &database.HeightIteratorError{Err: err}
+	}
+	return it
+}
+
+func (h *hIndex) Prune(below uint64) error {
+	return h.write(func() error {
+		maps.DeleteFunc(h.data, func(n uint64, _ []byte) bool { return n < below })
+		maps.DeleteFunc(h.pending, func(n uint64, _ bool) bool { return n < below })
+		return nil
+	})
+}
+
+func (h *hIndex) Bounds() (uint64, uint64, error) {
+	type bounds struct{ lowest, highest uint64 }
+	b, err := readHIndex(h, func() (bounds, error) {
+		if len(h.data) == 0 {
+			return bounds{}, database.ErrNotFound
+		}
+		keys := slices.Collect(maps.Keys(h.data))
+		return bounds{slices.Min(keys), slices.Max(keys)}, nil
+	})
+	return b.lowest, b.highest, err
+}
+
+// hIterator iterates over a snapshot of an [hIndex].
+type hIterator struct {
+	heights []uint64
+	values  [][]byte
+	height  uint64
+	value   []byte
+}
+
+func (it *hIterator) Next() bool {
+	if len(it.heights) == 0 {
+		it.value = nil
+		return false
+	}
+	it.height, it.heights = it.heights[0], it.heights[1:]
+	it.value, it.values = it.values[0], it.values[1:]
+	return true
+}
+
+func (*hIterator) Error() error      { return nil }
+func (it *hIterator) Height() uint64 { return it.height }
+func (it *hIterator) Value() []byte  { return it.value }
+func (it *hIterator) Release()       { it.heights, it.values, it.value = nil, nil, nil }
@@ -289,14 +289,14 @@ func TestP2PIntegration(t *testing.T) {
 
 			client := p2ptest.NewClient(
 				t, ctx,
-				recvID, gossip.NewHandler(logger, Marshaller{}, recv.Set, metrics, math.MaxInt),
-				sendID, gossip.NewHandler(logger, Marshaller{}, send.Set, metrics, math.MaxInt),
+				recvID, gossip.NewHandler(logger, Marshaller{}, recv.Set, metrics, math.MaxInt, nil),
+				sendID, gossip.NewHandler(logger, Marshaller{}, send.Set, metrics, math.MaxInt, nil),
 			)
 
 			var gossiper gossip.Gossiper
 			switch tt.dir {
 			case pull:
-				gossiper = gossip.NewPullGossiper(logger, Marshaller{}, recv.Set, client, metrics, 1)
+				gossiper = gossip.NewPullGossiper(logger, Marshaller{}, recv.Set, client, metrics, 1, nil)
 
 			case push:
 				branch := gossip.BranchingFactor{Peers: 1}
@@ -307,6 +307,7 @@ func TestP2PIntegration(t *testing.T) {
 					client,
 					metrics,
 					branch, branch,
+					gossip.AdaptiveFanOutConfig{},
 					0, 1<<20, time.Millisecond,
 				)
 				require.NoError(t, err, "%T.NewPushGossiper()")
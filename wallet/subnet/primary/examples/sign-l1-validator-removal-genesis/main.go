@@ -96,6 +96,7 @@ func main() {
 	messageBuilder, err := p2pmessage.NewCreator(
 		prometheus.NewRegistry(),
 		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		time.Hour,
 	)
 	if err != nil {
@@ -118,6 +119,7 @@ func main() {
 			p2p.ProtocolPrefix(p2p.SignatureRequestHandlerID),
 			appRequestPayload,
 		),
+		nil,
 	)
 	if err != nil {
 		log.Fatalf("failed to create AppRequest: %s\n", err)
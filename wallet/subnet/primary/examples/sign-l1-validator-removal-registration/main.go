@@ -166,6 +166,7 @@ func main() {
 	mesageBuilder, err := p2pmessage.NewCreator(
 		prometheus.NewRegistry(),
 		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		time.Hour,
 	)
 	if err != nil {
@@ -188,6 +189,7 @@ func main() {
 			p2p.ProtocolPrefix(p2p.SignatureRequestHandlerID),
 			appRequestPayload,
 		),
+		nil,
 	)
 	if err != nil {
 		log.Fatalf("failed to create AppRequest: %s\n", err)
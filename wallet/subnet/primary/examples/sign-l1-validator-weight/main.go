@@ -87,6 +87,7 @@ func main() {
 	mesageBuilder, err := p2pmessage.NewCreator(
 		prometheus.NewRegistry(),
 		compression.TypeZstd,
+		constants.DefaultNetworkCompressionZstdLevel,
 		time.Hour,
 	)
 	if err != nil {
@@ -108,6 +109,7 @@ func main() {
 			p2p.ProtocolPrefix(p2p.SignatureRequestHandlerID),
 			appRequestPayload,
 		),
+		nil,
 	)
 	if err != nil {
 		log.Fatalf("failed to create AppRequest: %s\n", err)
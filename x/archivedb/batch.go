@@ -20,6 +20,10 @@ type batch struct {
 }
 
 func (c *batch) Write() error {
+	if c.height < c.db.pruneHeight.Load() {
+		return ErrHeightPruned
+	}
+
 	batch := c.db.db.NewBatch()
 	for _, op := range c.Ops {
 		key, _ := newDBKeyFromUser(op.Key, c.height)
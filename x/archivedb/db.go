@@ -7,6 +7,9 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ava-labs/avalanchego/api/health"
 	"github.com/ava-labs/avalanchego/database"
@@ -15,6 +18,7 @@ import (
 var (
 	ErrNotImplemented = errors.New("feature not implemented")
 	ErrInvalidValue   = errors.New("invalid data value")
+	ErrHeightPruned   = errors.New("height has been pruned")
 
 	_ database.Compacter = (*Database)(nil)
 	_ health.Checker     = (*Database)(nil)
@@ -57,14 +61,60 @@ var (
 // foo was deleted at height 1000. When calling `reader.GetHeight(foo)` at
 // height 99 it will return a tuple `("foo's value is bar", 10)` returning the
 // value of `foo` at height 99 (which was set at height 10).
+//
+// A reader can also iterate over all the keys that existed at its height.
+//
+// History that is only needed to read old heights can be removed with Prune.
+// If a retention window is configured, history outside of the window is pruned
+// periodically in the background.
 type Database struct {
-	db database.Database
+	db     database.Database
+	config Config
+
+	// pruneLock serializes calls to Prune
+	pruneLock sync.Mutex
+	// pruneHeight is the lowest height that can be read
+	pruneHeight atomic.Uint64
+	// pruneErr is the error returned by the last background prune, if any
+	pruneErr atomic.Pointer[error]
+
+	closeOnce sync.Once
+	closing   chan struct{}
+	closed    sync.WaitGroup
+}
+
+type Config struct {
+	// RetentionWindow is the number of most recent heights that can be read.
+	// If zero, history is never pruned in the background.
+	RetentionWindow uint64 `json:"retentionWindow"`
+	// PruneFrequency is how often history outside of the retention window is
+	// pruned.
+	PruneFrequency time.Duration `json:"pruneFrequency"`
+}
+
+var DefaultConfig = Config{
+	RetentionWindow: 0,
+	PruneFrequency:  time.Minute,
 }
 
-func New(db database.Database) *Database {
-	return &Database{
-		db: db,
+func New(db database.Database, config Config) (*Database, error) {
+	pruneHeight, err := database.WithDefault(database.GetUInt64, db, pruneHeightKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveDB := &Database{
+		db:      db,
+		config:  config,
+		closing: make(chan struct{}),
 	}
+	archiveDB.pruneHeight.Store(pruneHeight)
+
+	if config.RetentionWindow > 0 && config.PruneFrequency > 0 {
+		archiveDB.closed.Add(1)
+		go archiveDB.pruneLoop()
+	}
+	return archiveDB, nil
 }
 
 // Height returns the last written height.
@@ -73,6 +123,8 @@ func (db *Database) Height() (uint64, error) {
 }
 
 // Open returns a reader for the state at the given height.
+//
+// If the height has been pruned, reads return ErrHeightPruned.
 func (db *Database) Open(height uint64) *Reader {
 	return &Reader{
 		db:     db,
@@ -97,9 +149,16 @@ func (db *Database) Compact(start []byte, limit []byte) error {
 }
 
 func (db *Database) HealthCheck(ctx context.Context) (interface{}, error) {
+	if err := db.pruneErr.Load(); err != nil {
+		return nil, *err
+	}
 	return db.db.HealthCheck(ctx)
 }
 
 func (db *Database) Close() error {
+	db.closeOnce.Do(func() {
+		close(db.closing)
+	})
+	db.closed.Wait()
 	return db.db.Close()
 }
@@ -12,10 +12,16 @@ import (
 	"github.com/ava-labs/avalanchego/database/memdb"
 )
 
+func newDB(t *testing.T) *Database {
+	db, err := New(memdb.New(), DefaultConfig)
+	require.NoError(t, err)
+	return db
+}
+
 func TestDBEntries(t *testing.T) {
 	require := require.New(t)
 
-	db := New(memdb.New())
+	db := newDB(t)
 
 	batch := db.NewBatch(1)
 	require.NoError(batch.Write())
@@ -104,7 +110,7 @@ func TestDBEntries(t *testing.T) {
 func TestDelete(t *testing.T) {
 	require := require.New(t)
 
-	db := New(memdb.New())
+	db := newDB(t)
 
 	batch := db.NewBatch(1)
 	require.NoError(batch.Put([]byte("key1"), []byte("value1@10")))
@@ -173,7 +179,7 @@ func TestDBKeySpace(t *testing.T) {
 	require.NotEqual(key1, key3)
 	require.NotEqual(key2, key3)
 
-	db := New(memdb.New())
+	db := newDB(t)
 
 	batch := db.NewBatch(1)
 	require.NoError(batch.Put(key1, value1))
@@ -206,7 +212,7 @@ func TestDBKeySpace(t *testing.T) {
 func TestSkipHeight(t *testing.T) {
 	require := require.New(t)
 
-	db := New(memdb.New())
+	db := newDB(t)
 
 	_, err := db.Height()
 	require.ErrorIs(err, database.ErrNotFound)
@@ -0,0 +1,246 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package archivedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+var _ database.Iterator = (*iterator)(nil)
+
+// iterator iterates over the keys that existed at a height in lexicographical
+// order.
+//
+// Database keys are prefixed by the length of the user key, so the keys of
+// each length are stored in a separate range of the database. The iterator
+// merges one iterator per key length.
+type iterator struct {
+	// lengths contains the iterators over each key length that haven't been
+	// exhausted.
+	lengths     []*lengthIterator
+	initialized bool
+
+	key   []byte
+	value []byte
+	err   error
+}
+
+// lengthIterator iterates over the user keys of a single length.
+type lengthIterator struct {
+	it     database.Iterator
+	height uint64
+	start  []byte
+
+	// key is the last user key that was read. value and exists are the value
+	// of key at height.
+	read   bool
+	key    []byte
+	value  []byte
+	exists bool
+}
+
+func (r *Reader) NewIterator() database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (r *Reader) NewIteratorWithStart(start []byte) database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (r *Reader) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix returns an iterator over the keys, and their
+// values, that existed at the reader's height, start with [prefix], and are
+// greater than or equal to [start].
+//
+// Entries written after the iterator is created at heights above the reader's
+// height are never returned.
+func (r *Reader) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	if r.height < r.db.pruneHeight.Load() {
+		return &database.IteratorError{
+			Err: ErrHeightPruned,
+		}
+	}
+
+	keyLengths, err := r.db.keyLengths()
+	if err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
+
+	it := &iterator{}
+	for _, keyLength := range keyLengths {
+		if keyLength < uint64(len(prefix)) {
+			continue
+		}
+
+		lengthPrefix := binary.AppendUvarint(nil, keyLength)
+		it.lengths = append(it.lengths, &lengthIterator{
+			it: r.db.db.NewIteratorWithStartAndPrefix(
+				append(slices.Clone(lengthPrefix), start...),
+				append(lengthPrefix, prefix...),
+			),
+			height: r.height,
+			start:  start,
+		})
+	}
+	return it
+}
+
+// keyLengths returns the distinct lengths of the keys in the database,
+// including metadata keys.
+//
+// Each length is found with a single seek, so this is efficient as long as
+// there are few distinct key lengths.
+func (db *Database) keyLengths() ([]uint64, error) {
+	var (
+		keyLengths []uint64
+		it         = db.db.NewIterator()
+	)
+	defer func() {
+		it.Release()
+	}()
+
+	for it.Next() {
+		lengthPrefix := it.Key()
+		keyLength, offset := binary.Uvarint(lengthPrefix)
+		if offset <= 0 {
+			return nil, ErrParsingKeyLength
+		}
+		keyLengths = append(keyLengths, keyLength)
+
+		// Varints are prefix free, so every key with this length is skipped by
+		// seeking past the length prefix.
+		next := database.PrefixLimit(lengthPrefix[:offset])
+		if err := it.Error(); err != nil {
+			return nil, err
+		}
+		it.Release()
+		if next == nil {
+			return keyLengths, nil
+		}
+		it = db.db.NewIteratorWithStart(next)
+	}
+	return keyLengths, it.Error()
+}
+
+func (it *iterator) Next() bool {
+	it.key = nil
+	it.value = nil
+	if it.err != nil {
+		return false
+	}
+
+	if !it.initialized {
+		it.initialized = true
+		for i := 0; i < len(it.lengths); {
+			if it.advance(i) {
+				i++
+			}
+		}
+	}
+
+	for len(it.lengths) > 0 && it.err == nil {
+		// Keys of different lengths are never equal, so there is a unique
+		// smallest key.
+		minIndex := 0
+		for i, l := range it.lengths[1:] {
+			if bytes.Compare(l.key, it.lengths[minIndex].key) < 0 {
+				minIndex = i + 1
+			}
+		}
+
+		var (
+			l      = it.lengths[minIndex]
+			key    = l.key
+			value  = l.value
+			exists = l.exists
+		)
+		it.advance(minIndex)
+		if exists {
+			it.key = key
+			it.value = value
+			return true
+		}
+	}
+	return false
+}
+
+// advance moves the iterator at [index] to its next key. If the iterator is
+// exhausted, it is released and removed. Returns true if the iterator at
+// [index] wasn't removed.
+func (it *iterator) advance(index int) bool {
+	l := it.lengths[index]
+	ok, err := l.next()
+	if ok {
+		return true
+	}
+	if err != nil {
+		it.err = err
+	}
+
+	l.it.Release()
+	it.lengths = slices.Delete(it.lengths, index, index+1)
+	return false
+}
+
+func (it *iterator) Error() error {
+	return it.err
+}
+
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+func (it *iterator) Release() {
+	for _, l := range it.lengths {
+		l.it.Release()
+	}
+	it.lengths = nil
+	it.key = nil
+	it.value = nil
+}
+
+// next moves to the next user key that has an entry at or below the height.
+// The entry may be a deletion.
+func (l *lengthIterator) next() (bool, error) {
+	for l.it.Next() {
+		key, height, err := parseDBKeyFromUser(l.it.Key())
+		if err != nil {
+			// Metadata keys can't be parsed as user keys.
+			continue
+		}
+		if l.read && bytes.Equal(key, l.key) {
+			// An entry for this key has already been read.
+			continue
+		}
+		if height > l.height {
+			continue
+		}
+		// If [start] is longer than the keys of this length, keys that are a
+		// prefix of [start] are sorted after [start] in the database.
+		if bytes.Compare(key, l.start) < 0 {
+			continue
+		}
+
+		value, exists := parseDBValue(l.it.Value())
+		l.read = true
+		l.key = slices.Clone(key)
+		l.value = slices.Clone(value)
+		l.exists = exists
+		return true, nil
+	}
+	return false, l.it.Error()
+}
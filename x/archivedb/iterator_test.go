@@ -0,0 +1,234 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package archivedb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+func iterate(t *testing.T, it database.Iterator) []keyValue {
+	t.Helper()
+	defer it.Release()
+
+	var kvs []keyValue
+	for it.Next() {
+		kvs = append(kvs, keyValue{
+			key:   string(it.Key()),
+			value: string(it.Value()),
+		})
+	}
+	require.NoError(t, it.Error())
+	return kvs
+}
+
+func TestIterator(t *testing.T) {
+	db := newDB(t)
+
+	writes := []struct {
+		height  uint64
+		puts    []keyValue
+		deletes []string
+	}{
+		{
+			height: 1,
+			puts: []keyValue{
+				{key: "b", value: "b@1"},
+				{key: "ab", value: "ab@1"},
+				{key: "abc", value: "abc@1"},
+				{key: "z", value: "z@1"},
+			},
+		},
+		{
+			height: 2,
+			puts: []keyValue{
+				{key: "a", value: "a@2"},
+				{key: "b", value: "b@2"},
+			},
+			deletes: []string{"abc"},
+		},
+		{
+			height: 3,
+			puts: []keyValue{
+				{key: "abc", value: "abc@3"},
+				{key: "", value: "@3"},
+			},
+			deletes: []string{"z"},
+		},
+	}
+	for _, write := range writes {
+		batch := db.NewBatch(write.height)
+		for _, kv := range write.puts {
+			require.NoError(t, batch.Put([]byte(kv.key), []byte(kv.value)))
+		}
+		for _, key := range write.deletes {
+			require.NoError(t, batch.Delete([]byte(key)))
+		}
+		require.NoError(t, batch.Write())
+	}
+
+	tests := []struct {
+		name   string
+		height uint64
+		start  string
+		prefix string
+		want   []keyValue
+	}{
+		{
+			name:   "before any writes",
+			height: 0,
+		},
+		{
+			name:   "all keys at height 1",
+			height: 1,
+			want: []keyValue{
+				{key: "ab", value: "ab@1"},
+				{key: "abc", value: "abc@1"},
+				{key: "b", value: "b@1"},
+				{key: "z", value: "z@1"},
+			},
+		},
+		{
+			name:   "all keys at height 2",
+			height: 2,
+			want: []keyValue{
+				{key: "a", value: "a@2"},
+				{key: "ab", value: "ab@1"},
+				{key: "b", value: "b@2"},
+				{key: "z", value: "z@1"},
+			},
+		},
+		{
+			name:   "all keys at height 3",
+			height: 3,
+			want: []keyValue{
+				{key: "", value: "@3"},
+				{key: "a", value: "a@2"},
+				{key: "ab", value: "ab@1"},
+				{key: "abc", value: "abc@3"},
+				{key: "b", value: "b@2"},
+			},
+		},
+		{
+			name:   "all keys above the last height",
+			height: 100,
+			want: []keyValue{
+				{key: "", value: "@3"},
+				{key: "a", value: "a@2"},
+				{key: "ab", value: "ab@1"},
+				{key: "abc", value: "abc@3"},
+				{key: "b", value: "b@2"},
+			},
+		},
+		{
+			name:   "prefix",
+			height: 3,
+			prefix: "ab",
+			want: []keyValue{
+				{key: "ab", value: "ab@1"},
+				{key: "abc", value: "abc@3"},
+			},
+		},
+		{
+			name:   "start",
+			height: 3,
+			start:  "ab",
+			want: []keyValue{
+				{key: "ab", value: "ab@1"},
+				{key: "abc", value: "abc@3"},
+				{key: "b", value: "b@2"},
+			},
+		},
+		{
+			name:   "start longer than keys",
+			height: 3,
+			start:  "aba",
+			want: []keyValue{
+				{key: "abc", value: "abc@3"},
+				{key: "b", value: "b@2"},
+			},
+		},
+		{
+			name:   "start and prefix",
+			height: 3,
+			start:  "abb",
+			prefix: "ab",
+			want: []keyValue{
+				{key: "abc", value: "abc@3"},
+			},
+		},
+		{
+			name:   "no matching prefix",
+			height: 3,
+			prefix: "c",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := db.Open(test.height)
+			it := reader.NewIteratorWithStartAndPrefix([]byte(test.start), []byte(test.prefix))
+			require.Equal(t, test.want, iterate(t, it))
+		})
+	}
+}
+
+func TestIteratorIgnoresNewerWrites(t *testing.T) {
+	require := require.New(t)
+
+	db := newDB(t)
+	batch := db.NewBatch(1)
+	require.NoError(batch.Put([]byte("a"), []byte("a@1")))
+	require.NoError(batch.Write())
+
+	it := db.Open(1).NewIterator()
+	defer it.Release()
+
+	batch = db.NewBatch(2)
+	require.NoError(batch.Put([]byte("a"), []byte("a@2")))
+	require.NoError(batch.Put([]byte("b"), []byte("b@2")))
+	require.NoError(batch.Write())
+
+	require.True(it.Next())
+	require.Equal([]byte("a"), it.Key())
+	require.Equal([]byte("a@1"), it.Value())
+	require.False(it.Next())
+	require.NoError(it.Error())
+}
+
+func TestIteratorKeyLengths(t *testing.T) {
+	require := require.New(t)
+
+	// Varint encoded lengths above 127 aren't sorted in the same order as the
+	// lengths themselves.
+	var keys [][]byte
+	for _, length := range []int{1, 127, 128, 255, 256, 1000} {
+		keys = append(keys, bytes.Repeat([]byte{'a'}, length))
+	}
+
+	db := newDB(t)
+	batch := db.NewBatch(1)
+	for _, key := range keys {
+		require.NoError(batch.Put(key, key))
+	}
+	require.NoError(batch.Write())
+
+	it := db.Open(1).NewIterator()
+	defer it.Release()
+	for _, key := range keys {
+		require.True(it.Next())
+		require.Equal(key, it.Key())
+		require.Equal(key, it.Value())
+	}
+	require.False(it.Next())
+	require.NoError(it.Error())
+}
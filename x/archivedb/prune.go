@@ -0,0 +1,149 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package archivedb
+
+import (
+	"bytes"
+	"slices"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+// pruneWriteSize is the size of the batches written while pruning.
+const pruneWriteSize = 4 * 1024 * 1024 // bytes
+
+// PruneHeight returns the lowest height that can be read.
+func (db *Database) PruneHeight() uint64 {
+	return db.pruneHeight.Load()
+}
+
+// Prune removes the history that is only needed to read heights below
+// [below]. After pruning, reads at heights below [below] return
+// ErrHeightPruned and batches can no longer be written below [below].
+//
+// Pruning iterates over every entry in the database, so it should be called
+// infrequently.
+func (db *Database) Prune(below uint64) error {
+	db.pruneLock.Lock()
+	defer db.pruneLock.Unlock()
+
+	if below <= db.pruneHeight.Load() {
+		return nil
+	}
+
+	// The prune height is updated before any entries are removed so that
+	// readers never observe a partially pruned height. If pruning is
+	// interrupted, the remaining entries are removed by the next call.
+	if err := database.PutUInt64(db.db, pruneHeightKey, below); err != nil {
+		return err
+	}
+	db.pruneHeight.Store(below)
+
+	return db.removePrunedEntries(below)
+}
+
+// removePrunedEntries removes every entry that isn't needed to read at heights
+// greater than or equal to [below].
+//
+// For each key, the entries above [below] and the most recent entry at or
+// below [below] are kept. If that entry is a deletion, it is removed as well.
+func (db *Database) removePrunedEntries(below uint64) error {
+	var (
+		batch = db.db.NewBatch()
+		it    = db.db.NewIterator()
+
+		key    []byte // the key whose entries are being processed
+		pinned bool   // true if an entry at or below [below] was kept for [key]
+	)
+	defer func() {
+		it.Release()
+	}()
+
+	for it.Next() {
+		dbKey := it.Key()
+		userKey, height, err := parseDBKeyFromUser(dbKey)
+		if err != nil {
+			// Metadata keys can't be parsed as user keys.
+			continue
+		}
+		if !bytes.Equal(userKey, key) {
+			key = slices.Clone(userKey)
+			pinned = false
+		}
+		if height > below {
+			continue
+		}
+		if !pinned {
+			pinned = true
+			if _, exists := parseDBValue(it.Value()); exists {
+				continue
+			}
+		}
+
+		if err := batch.Delete(dbKey); err != nil {
+			return err
+		}
+		if batch.Size() < pruneWriteSize {
+			continue
+		}
+
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+
+		// Reset the iterator to release references to now deleted keys. The
+		// iterator is restarted at the smallest key after [dbKey].
+		if err := it.Error(); err != nil {
+			return err
+		}
+		start := append(slices.Clone(dbKey), 0)
+		it.Release()
+		it = db.db.NewIteratorWithStart(start)
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// pruneLoop periodically prunes the history outside of the retention window
+// until the database is closed.
+func (db *Database) pruneLoop() {
+	defer db.closed.Done()
+
+	ticker := time.NewTicker(db.config.PruneFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-db.closing:
+			return
+		}
+
+		if err := db.pruneRetentionWindow(); err != nil {
+			db.pruneErr.Store(&err)
+		} else {
+			db.pruneErr.Store(nil)
+		}
+	}
+}
+
+// pruneRetentionWindow prunes every height that is more than the retention
+// window below the last written height.
+func (db *Database) pruneRetentionWindow() error {
+	height, err := db.Height()
+	if err == database.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if height < db.config.RetentionWindow {
+		return nil
+	}
+	return db.Prune(height - db.config.RetentionWindow + 1)
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package archivedb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+// countEntries returns the number of user entries stored in [db].
+func countEntries(t *testing.T, db *Database) int {
+	t.Helper()
+
+	it := db.db.NewIterator()
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		if _, _, err := parseDBKeyFromUser(it.Key()); err == nil {
+			count++
+		}
+	}
+	require.NoError(t, it.Error())
+	return count
+}
+
+func TestPrune(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := New(baseDB, DefaultConfig)
+	require.NoError(err)
+
+	for height := uint64(1); height <= 5; height++ {
+		batch := db.NewBatch(height)
+		require.NoError(batch.Put([]byte("updated"), []byte{byte(height)}))
+		switch height {
+		case 1:
+			require.NoError(batch.Put([]byte("static"), []byte{1}))
+			require.NoError(batch.Put([]byte("deleted"), []byte{1}))
+		case 2:
+			require.NoError(batch.Delete([]byte("deleted")))
+		case 4:
+			require.NoError(batch.Put([]byte("deleted"), []byte{4}))
+		}
+		require.NoError(batch.Write())
+	}
+	require.Equal(9, countEntries(t, db))
+
+	require.NoError(db.Prune(3))
+	require.Equal(uint64(3), db.PruneHeight())
+
+	// updated@3, updated@4, updated@5, static@1, and deleted@4 are kept.
+	require.Equal(5, countEntries(t, db))
+
+	_, err = db.Open(2).Get([]byte("updated"))
+	require.ErrorIs(err, ErrHeightPruned)
+	it := db.Open(2).NewIterator()
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), ErrHeightPruned)
+	it.Release()
+
+	for height := uint64(3); height <= 5; height++ {
+		reader := db.Open(height)
+		value, err := reader.Get([]byte("updated"))
+		require.NoError(err)
+		require.Equal([]byte{byte(height)}, value)
+
+		value, err = reader.Get([]byte("static"))
+		require.NoError(err)
+		require.Equal([]byte{1}, value)
+	}
+
+	_, err = db.Open(3).Get([]byte("deleted"))
+	require.ErrorIs(err, database.ErrNotFound)
+	value, err := db.Open(4).Get([]byte("deleted"))
+	require.NoError(err)
+	require.Equal([]byte{4}, value)
+
+	// Writes below the prune height are rejected
+	err = db.NewBatch(2).Write()
+	require.ErrorIs(err, ErrHeightPruned)
+
+	// Pruning below the prune height is a noop
+	require.NoError(db.Prune(1))
+	require.Equal(uint64(3), db.PruneHeight())
+
+	// The prune height is persisted
+	db, err = New(baseDB, DefaultConfig)
+	require.NoError(err)
+	require.Equal(uint64(3), db.PruneHeight())
+}
+
+func TestPruneRetentionWindow(t *testing.T) {
+	require := require.New(t)
+
+	db, err := New(memdb.New(), Config{
+		RetentionWindow: 2,
+		PruneFrequency:  time.Millisecond,
+	})
+	require.NoError(err)
+
+	for height := uint64(1); height <= 10; height++ {
+		batch := db.NewBatch(height)
+		require.NoError(batch.Put([]byte("key"), []byte{byte(height)}))
+		require.NoError(batch.Write())
+	}
+
+	// Heights 9 and 10 are retained
+	require.Eventually(
+		func() bool {
+			return db.PruneHeight() == 9
+		},
+		time.Second,
+		time.Millisecond,
+	)
+	require.Eventually(
+		func() bool {
+			return countEntries(t, db) == 2
+		},
+		time.Second,
+		time.Millisecond,
+	)
+
+	_, err = db.HealthCheck(t.Context())
+	require.NoError(err)
+	require.NoError(db.Close())
+}
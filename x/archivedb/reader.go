@@ -5,7 +5,10 @@ package archivedb
 
 import "github.com/ava-labs/avalanchego/database"
 
-var _ database.KeyValueReader = (*Reader)(nil)
+var (
+	_ database.KeyValueReader = (*Reader)(nil)
+	_ database.Iteratee       = (*Reader)(nil)
+)
 
 type Reader struct {
 	db     *Database
@@ -35,6 +38,10 @@ func (r *Reader) Get(key []byte) ([]byte, error) {
 // modified at, and a boolean to indicate if the last modification was an
 // insertion. If the key has never been modified, ErrNotFound will be returned.
 func (r *Reader) GetEntry(key []byte) ([]byte, uint64, bool, error) {
+	if r.height < r.db.pruneHeight.Load() {
+		return nil, 0, false, ErrHeightPruned
+	}
+
 	it := r.db.db.NewIteratorWithStartAndPrefix(newDBKeyFromUser(key, r.height))
 	defer it.Release()
 
@@ -4,6 +4,7 @@
 package blockdb
 
 import (
+	"context"
 	"sync/atomic"
 
 	"go.uber.org/zap"
@@ -83,6 +84,51 @@ func (c *cacheDB) Sync(start, end uint64) error {
 	return c.db.Sync(start, end)
 }
 
+func (c *cacheDB) NewIteratorFromHeight(height BlockHeight) database.HeightIterator {
+	if c.closed.Load() {
+		return &database.HeightIteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return c.db.NewIteratorFromHeight(height)
+}
+
+func (c *cacheDB) Prune(below BlockHeight) error {
+	if c.closed.Load() {
+		c.db.log.Error("Failed Prune: database closed", zap.Uint64("below", below))
+		return database.ErrClosed
+	}
+
+	if err := c.db.Prune(below); err != nil {
+		return err
+	}
+
+	// The cache may contain pruned blocks.
+	c.cache.Flush()
+	return nil
+}
+
+func (c *cacheDB) Bounds() (BlockHeight, BlockHeight, error) {
+	if c.closed.Load() {
+		return 0, 0, database.ErrClosed
+	}
+	return c.db.Bounds()
+}
+
+func (c *cacheDB) Verify(ctx context.Context) ([]CorruptRange, error) {
+	if c.closed.Load() {
+		return nil, database.ErrClosed
+	}
+	return c.db.Verify(ctx)
+}
+
+func (c *cacheDB) Compact(ctx context.Context) ([]CorruptRange, error) {
+	if c.closed.Load() {
+		return nil, database.ErrClosed
+	}
+	return c.db.Compact(ctx)
+}
+
 func (c *cacheDB) Close() error {
 	if !c.closed.CompareAndSwap(false, true) {
 		return database.ErrClosed
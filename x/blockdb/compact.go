@@ -0,0 +1,500 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+const (
+	// compactDirName is the directory within the data directory where new
+	// data files are written during compaction.
+	compactDirName = "compact"
+	// compactIndexFileName is the name of the new index file written to the
+	// index directory during compaction.
+	compactIndexFileName = indexFileName + ".compact"
+	// compactMarkerFileName is created in the compaction directory once all
+	// new files have been written. It contains the number of new data files.
+	compactMarkerFileName = "COMPLETE"
+)
+
+// CorruptRange is an inclusive range of consecutive heights whose blocks are
+// corrupt.
+type CorruptRange struct {
+	Start BlockHeight
+	End   BlockHeight
+	// Err describes why the block at Start is corrupt.
+	Err error
+}
+
+func (r CorruptRange) String() string {
+	return fmt.Sprintf("[%d, %d]: %s", r.Start, r.End, r.Err)
+}
+
+type corruptRanges []CorruptRange
+
+// add records that the block at [height] is corrupt. Heights must be added in
+// increasing order.
+func (r *corruptRanges) add(height BlockHeight, err error) {
+	if n := len(*r); n > 0 && (*r)[n-1].End+1 == height {
+		(*r)[n-1].End = height
+		return
+	}
+	*r = append(*r, CorruptRange{
+		Start: height,
+		End:   height,
+		Err:   err,
+	})
+}
+
+// Verify reads every block in the database and verifies its block entry
+// header and checksum. It returns the ranges of heights whose blocks are
+// corrupt.
+//
+// Verify can be called while the database is in use. Blocks written while
+// Verify is running may not be verified.
+func (s *Database) Verify(ctx context.Context) ([]CorruptRange, error) {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.closed {
+		s.log.Error("Failed Verify: database closed")
+		return nil, database.ErrClosed
+	}
+
+	var (
+		corrupt     corruptRanges
+		numVerified int
+	)
+	err := s.scanBlocks(ctx, func(height BlockHeight, entry indexEntry) error {
+		_, _, err := s.readVerifiedBlock(height, entry)
+		if errors.Is(err, ErrCorrupted) {
+			corrupt.add(height, err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		numVerified++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Verified blocks",
+		zap.Int("numVerified", numVerified),
+		zap.Int("numCorruptRanges", len(corrupt)),
+	)
+	return corrupt, nil
+}
+
+// Compact rewrites the data files so that blocks are stored contiguously in
+// increasing height order, reclaiming the space used by overwritten and pruned
+// blocks. Corrupt blocks are dropped and the ranges of their heights are
+// returned.
+//
+// Compact blocks all other operations on the database until it returns. If
+// Compact is interrupted before the new data files are complete, the database
+// is left unchanged. Otherwise, the compaction is completed the next time the
+// database is opened. If Compact fails after it started replacing the data
+// files, the database is closed.
+func (s *Database) Compact(ctx context.Context) ([]CorruptRange, error) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+
+	if s.closed {
+		s.log.Error("Failed Compact: database closed")
+		return nil, database.ErrClosed
+	}
+
+	corrupt, numDataFiles, err := s.writeCompactedFiles(ctx)
+	if err != nil {
+		s.log.Error("Failed to compact: failed to write compacted files", zap.Error(err))
+		return nil, errors.Join(err, s.removeCompactedFiles())
+	}
+
+	// Now that the compacted files are complete, the data files are replaced.
+	s.closeFiles()
+	if err := s.finishCompaction(); err != nil {
+		s.log.Error("Failed to compact: failed to replace data files", zap.Error(err))
+		return nil, errors.Join(err, s.closeAfterFailedCompaction())
+	}
+	if err := s.openAndInitializeIndex(); err != nil {
+		s.log.Error("Failed to compact: failed to reopen index", zap.Error(err))
+		return nil, errors.Join(err, s.closeAfterFailedCompaction())
+	}
+	if err := s.initializeDataFiles(); err != nil {
+		s.log.Error("Failed to compact: failed to reopen data files", zap.Error(err))
+		return nil, errors.Join(err, s.closeAfterFailedCompaction())
+	}
+
+	s.log.Info("Compacted data files",
+		zap.Int("numDataFiles", numDataFiles),
+		zap.Uint64("nextWriteOffset", s.nextDataWriteOffset.Load()),
+		zap.Int("numCorruptRanges", len(corrupt)),
+	)
+	return corrupt, nil
+}
+
+// writeCompactedFiles writes every valid block to new data files in the
+// compaction directory and indexes them in a new index file. Once all files
+// have been synced, the compaction marker is created.
+func (s *Database) writeCompactedFiles(ctx context.Context) (corruptRanges, int, error) {
+	compactDir := filepath.Join(s.config.DataDir, compactDirName)
+	if err := s.removeCompactedFiles(); err != nil {
+		return nil, 0, err
+	}
+	if err := os.MkdirAll(compactDir, 0o755); err != nil {
+		return nil, 0, fmt.Errorf("failed to create compaction directory: %w", err)
+	}
+
+	indexPath := filepath.Join(s.config.IndexDir, compactIndexFileName)
+	indexFile, err := os.OpenFile(indexPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, defaultFilePermissions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create compacted index file: %w", err)
+	}
+	defer indexFile.Close()
+
+	w := &compactWriter{
+		dir:             compactDir,
+		maxDataFileSize: s.header.MaxDataFileSize,
+	}
+	defer func() {
+		_ = w.close()
+	}()
+
+	header := s.header
+	header.MaxHeight = unsetHeight
+	var corrupt corruptRanges
+	err = s.scanBlocks(ctx, func(height BlockHeight, entry indexEntry) error {
+		bh, compressed, err := s.readVerifiedBlock(height, entry)
+		if errors.Is(err, ErrCorrupted) {
+			s.log.Warn("Dropping corrupt block during compaction",
+				zap.Uint64("height", height),
+				zap.Error(err),
+			)
+			corrupt.add(height, err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		headerBytes, err := bh.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to serialize block header: %w", err)
+		}
+		dataOffset, err := w.write(headerBytes, compressed)
+		if err != nil {
+			return err
+		}
+
+		indexOffset, err := s.indexEntryOffset(height)
+		if err != nil {
+			return err
+		}
+		entryBytes, err := indexEntry{Offset: dataOffset, Size: bh.Size}.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to serialize index entry: %w", err)
+		}
+		if _, err := indexFile.WriteAt(entryBytes, int64(indexOffset)); err != nil {
+			return fmt.Errorf("failed to write compacted index entry for height %d: %w", height, err)
+		}
+		header.MaxHeight = height
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := w.close(); err != nil {
+		return nil, 0, err
+	}
+
+	header.NextWriteOffset = w.offset
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to serialize compacted index header: %w", err)
+	}
+	if _, err := indexFile.WriteAt(headerBytes, 0); err != nil {
+		return nil, 0, fmt.Errorf("failed to write compacted index header: %w", err)
+	}
+	if err := indexFile.Sync(); err != nil {
+		return nil, 0, fmt.Errorf("failed to sync compacted index file: %w", err)
+	}
+
+	numDataFiles := w.numFiles()
+	markerPath := filepath.Join(compactDir, compactMarkerFileName)
+	if err := writeFileAndSync(markerPath, []byte(strconv.Itoa(numDataFiles))); err != nil {
+		return nil, 0, fmt.Errorf("failed to write compaction marker: %w", err)
+	}
+	if err := errors.Join(syncDir(compactDir), syncDir(s.config.IndexDir)); err != nil {
+		return nil, 0, fmt.Errorf("failed to sync compacted files: %w", err)
+	}
+	return corrupt, numDataFiles, nil
+}
+
+// finishCompaction replaces the data files and the index file with the files
+// written by a compaction. It is a no-op if no compaction was completed and
+// discards any incomplete compaction. It is safe to call again if it was
+// interrupted.
+func (s *Database) finishCompaction() error {
+	compactDir := filepath.Join(s.config.DataDir, compactDirName)
+	marker, err := os.ReadFile(filepath.Join(compactDir, compactMarkerFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return s.removeCompactedFiles()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read compaction marker: %w", err)
+	}
+	numDataFiles, err := strconv.Atoi(string(marker))
+	if err != nil || numDataFiles < 0 {
+		return fmt.Errorf("%w: invalid compaction marker %q", ErrCorrupted, marker)
+	}
+
+	s.log.Info("Replacing data files with compacted data files",
+		zap.Int("numDataFiles", numDataFiles),
+	)
+
+	// Data files that were already moved by an interrupted call no longer
+	// exist in the compaction directory.
+	for i := range numDataFiles {
+		src := filepath.Join(compactDir, fmt.Sprintf(dataFileNameFormat, i))
+		if err := os.Rename(src, s.dataFilePath(i)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to move compacted data file %d: %w", i, err)
+		}
+	}
+
+	dataFiles, _, err := s.listDataFiles()
+	if err != nil {
+		return err
+	}
+	for index, path := range dataFiles {
+		if index < numDataFiles {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove data file %d: %w", index, err)
+		}
+	}
+
+	src := filepath.Join(s.config.IndexDir, compactIndexFileName)
+	dst := filepath.Join(s.config.IndexDir, indexFileName)
+	if err := os.Rename(src, dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to move compacted index file: %w", err)
+	}
+	if err := errors.Join(syncDir(s.config.DataDir), syncDir(s.config.IndexDir)); err != nil {
+		return fmt.Errorf("failed to sync replaced files: %w", err)
+	}
+
+	// The marker is removed last so that an interrupted call is retried.
+	if err := os.RemoveAll(compactDir); err != nil {
+		return fmt.Errorf("failed to remove compaction directory: %w", err)
+	}
+	return nil
+}
+
+// removeCompactedFiles removes the files of an incomplete compaction.
+func (s *Database) removeCompactedFiles() error {
+	indexPath := filepath.Join(s.config.IndexDir, compactIndexFileName)
+	if err := os.Remove(indexPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove compacted index file: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(s.config.DataDir, compactDirName)); err != nil {
+		return fmt.Errorf("failed to remove compaction directory: %w", err)
+	}
+	return nil
+}
+
+// closeAfterFailedCompaction closes the database after its files were closed
+// during a compaction that could not be completed. The compaction is retried
+// when the database is reopened.
+func (s *Database) closeAfterFailedCompaction() error {
+	s.closed = true
+	s.closeFiles()
+	return s.locks.Release()
+}
+
+// scanBlocks calls [f] with the index entry of every block in the database in
+// increasing height order.
+func (s *Database) scanBlocks(ctx context.Context, f func(BlockHeight, indexEntry) error) error {
+	maxHeight := s.maxBlockHeight.Load()
+	if maxHeight == unsetHeight {
+		return nil
+	}
+
+	var err error
+	start := max(s.header.MinHeight, s.header.PruneHeight)
+	scanErr := s.scanIndexEntries(start, maxHeight, func(height BlockHeight, entry indexEntry) bool {
+		if entry.IsEmpty() {
+			return true
+		}
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		err = f(height, entry)
+		return err == nil
+	})
+	return errors.Join(scanErr, err)
+}
+
+// readVerifiedBlock reads the block at [height] referenced by [entry] and
+// verifies its block entry header and checksum. It returns the block entry
+// header and the compressed block data. If the block is corrupt, the returned
+// error wraps ErrCorrupted.
+func (s *Database) readVerifiedBlock(height BlockHeight, entry indexEntry) (blockEntryHeader, []byte, error) {
+	var bh blockEntryHeader
+	totalSize, err := safemath.Add(uint64(sizeOfBlockEntryHeader), uint64(entry.Size))
+	if err != nil {
+		return bh, nil, fmt.Errorf("failed to compute total read size: %w", err)
+	}
+	buf := make([]byte, totalSize)
+
+	// loop to retry fetching the data file if it got closed between get and read.
+	for {
+		dataFile, localOffset, fileIndex, err := s.getDataFileAndOffset(entry.Offset)
+		if err != nil {
+			return bh, nil, fmt.Errorf("failed to get data file and offset: %w", err)
+		}
+		if _, err := dataFile.ReadAt(buf, int64(localOffset)); err != nil {
+			if errors.Is(err, os.ErrClosed) {
+				s.fileCache.Evict(fileIndex)
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				return bh, nil, fmt.Errorf("%w: block at height %d extends past the end of data file %d", ErrCorrupted, height, fileIndex)
+			}
+			return bh, nil, fmt.Errorf("failed to read block at height %d: %w", height, err)
+		}
+		break
+	}
+
+	if err := bh.UnmarshalBinary(buf[:sizeOfBlockEntryHeader]); err != nil {
+		return bh, nil, err
+	}
+	switch {
+	case bh.Height != height:
+		return bh, nil, fmt.Errorf("%w: block entry header has height %d, expected %d", ErrCorrupted, bh.Height, height)
+	case bh.Size != entry.Size:
+		return bh, nil, fmt.Errorf("%w: block entry header has size %d, index entry has size %d", ErrCorrupted, bh.Size, entry.Size)
+	case bh.Version > BlockEntryVersion:
+		return bh, nil, fmt.Errorf("%w: block entry version %d is greater than the current version %d", ErrCorrupted, bh.Version, BlockEntryVersion)
+	}
+
+	compressed := buf[sizeOfBlockEntryHeader:]
+	decompressed, err := s.compressor.Decompress(compressed)
+	if err != nil {
+		return bh, nil, fmt.Errorf("%w: failed to decompress block: %w", ErrCorrupted, err)
+	}
+	if calculatedChecksum := calculateChecksum(decompressed); calculatedChecksum != bh.Checksum {
+		return bh, nil, fmt.Errorf("%w: checksum mismatch: calculated %d, stored %d", ErrCorrupted, calculatedChecksum, bh.Checksum)
+	}
+	return bh, compressed, nil
+}
+
+// compactWriter appends blocks to new data files, starting a new data file
+// whenever a block would exceed the max data file size.
+type compactWriter struct {
+	dir             string
+	maxDataFileSize uint64
+
+	file      *os.File
+	fileIndex int
+	// offset is the global offset where the next block will be written.
+	offset uint64
+}
+
+// write appends the block entry header and block data and returns the global
+// offset they were written at.
+func (w *compactWriter) write(header []byte, block []byte) (uint64, error) {
+	size := uint64(len(header) + len(block))
+	if w.offset%w.maxDataFileSize+size > w.maxDataFileSize {
+		w.offset += w.maxDataFileSize - w.offset%w.maxDataFileSize
+	}
+
+	fileIndex := int(w.offset / w.maxDataFileSize)
+	if w.file == nil || fileIndex != w.fileIndex {
+		if err := w.close(); err != nil {
+			return 0, err
+		}
+		path := filepath.Join(w.dir, fmt.Sprintf(dataFileNameFormat, fileIndex))
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, defaultFilePermissions)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create compacted data file %d: %w", fileIndex, err)
+		}
+		w.file = file
+		w.fileIndex = fileIndex
+	}
+
+	localOffset := int64(w.offset % w.maxDataFileSize)
+	if _, err := w.file.WriteAt(header, localOffset); err != nil {
+		return 0, fmt.Errorf("failed to write compacted data file %d: %w", fileIndex, err)
+	}
+	if _, err := w.file.WriteAt(block, localOffset+int64(len(header))); err != nil {
+		return 0, fmt.Errorf("failed to write compacted data file %d: %w", fileIndex, err)
+	}
+
+	offset := w.offset
+	w.offset += size
+	return offset, nil
+}
+
+// numFiles returns the number of data files that have been written.
+func (w *compactWriter) numFiles() int {
+	if w.offset == 0 {
+		return 0
+	}
+	return w.fileIndex + 1
+}
+
+// close syncs and closes the current data file, if any.
+func (w *compactWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	file := w.file
+	w.file = nil
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to sync compacted data file %d: %w", w.fileIndex, err)
+	}
+	return file.Close()
+}
+
+func writeFileAndSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePermissions)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
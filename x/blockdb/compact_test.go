@@ -0,0 +1,302 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockdb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/compression"
+)
+
+// corruptBlock flips a byte of the block data stored at [height].
+func corruptBlock(t *testing.T, db *Database, height uint64) {
+	t.Helper()
+
+	entry, err := db.readIndexEntry(height)
+	require.NoError(t, err)
+	dataFile, localOffset, _, err := db.getDataFileAndOffset(entry.Offset)
+	require.NoError(t, err)
+
+	offset := int64(localOffset) + int64(sizeOfBlockEntryHeader)
+	b := make([]byte, 1)
+	_, err = dataFile.ReadAt(b, offset)
+	require.NoError(t, err)
+	b[0] ^= 0xff
+	_, err = dataFile.WriteAt(b, offset)
+	require.NoError(t, err)
+}
+
+func dataFileSizes(t *testing.T, db *Database) map[int]int64 {
+	t.Helper()
+
+	dataFiles, _, err := db.listDataFiles()
+	require.NoError(t, err)
+	sizes := make(map[int]int64, len(dataFiles))
+	for index, path := range dataFiles {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		sizes[index] = info.Size()
+	}
+	return sizes
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		corrupt     []uint64
+		wantCorrupt []CorruptRange
+	}{
+		{
+			name: "no corruption",
+		},
+		{
+			name:    "single corrupt block",
+			corrupt: []uint64{3},
+			wantCorrupt: []CorruptRange{
+				{Start: 3, End: 3},
+			},
+		},
+		{
+			name:    "consecutive corrupt blocks are merged",
+			corrupt: []uint64{2, 3, 4, 7},
+			wantCorrupt: []CorruptRange{
+				{Start: 2, End: 4},
+				{Start: 7, End: 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db := newDatabase(t, DefaultConfig())
+			db.compressor = compression.NewNoCompressor()
+			for i := range uint64(10) {
+				require.NoError(db.Put(i, fixedSizeBlock(t, 1024, i)))
+			}
+			for _, height := range tt.corrupt {
+				corruptBlock(t, db, height)
+			}
+
+			corrupt, err := db.Verify(t.Context())
+			require.NoError(err)
+			require.Len(corrupt, len(tt.wantCorrupt))
+			for i, want := range tt.wantCorrupt {
+				require.Equal(want.Start, corrupt[i].Start)
+				require.Equal(want.End, corrupt[i].End)
+				require.ErrorIs(corrupt[i].Err, ErrCorrupted)
+			}
+		})
+	}
+}
+
+func TestVerify_Canceled(t *testing.T) {
+	db := newDatabase(t, DefaultConfig())
+	require.NoError(t, db.Put(1, randomBlock(t)))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err := db.Verify(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCompact(t *testing.T) {
+	require := require.New(t)
+
+	// Each data file should have enough space for 2 blocks
+	config := DefaultConfig().WithMaxDataFileSize(1024 * 2.5)
+	db := newDatabase(t, config)
+	db.compressor = compression.NewNoCompressor()
+
+	// Write blocks out of order and overwrite some of them, which leaves
+	// unreferenced data in the data files.
+	heights := []uint64{5, 3, 1, 4, 2, 3, 0, 5}
+	for _, height := range heights {
+		require.NoError(db.Put(height, fixedSizeBlock(t, 1024, height)))
+	}
+	require.Len(dataFileSizes(t, db), 4)
+
+	corrupt, err := db.Compact(t.Context())
+	require.NoError(err)
+	require.Empty(corrupt)
+
+	// The 6 remaining blocks are stored in height order in 3 data files.
+	blockSize := int64(1024 + sizeOfBlockEntryHeader)
+	require.Equal(map[int]int64{
+		0: 2 * blockSize,
+		1: 2 * blockSize,
+		2: 2 * blockSize,
+	}, dataFileSizes(t, db))
+
+	var prevOffset uint64
+	for height := range uint64(6) {
+		entry, err := db.readIndexEntry(height)
+		require.NoError(err)
+		if height > 0 {
+			require.Greater(entry.Offset, prevOffset)
+		}
+		prevOffset = entry.Offset
+
+		block, err := db.Get(height)
+		require.NoError(err)
+		require.Equal(fixedSizeBlock(t, 1024, height), block)
+	}
+
+	// Blocks can be written after compaction
+	require.NoError(db.Put(6, fixedSizeBlock(t, 1024, 6)))
+
+	// reopen and verify all blocks are readable
+	require.NoError(db.Close())
+	dir := db.config.DataDir
+	db = newDatabase(t, config.WithIndexDir(dir).WithDataDir(dir))
+	db.compressor = compression.NewNoCompressor()
+	for height := range uint64(7) {
+		block, err := db.Get(height)
+		require.NoError(err)
+		require.Equal(fixedSizeBlock(t, 1024, height), block)
+	}
+}
+
+func TestCompact_DropsCorruptBlocks(t *testing.T) {
+	require := require.New(t)
+
+	db := newDatabase(t, DefaultConfig())
+	db.compressor = compression.NewNoCompressor()
+	for i := range uint64(5) {
+		require.NoError(db.Put(i, fixedSizeBlock(t, 1024, i)))
+	}
+	corruptBlock(t, db, 2)
+
+	corrupt, err := db.Compact(t.Context())
+	require.NoError(err)
+	require.Len(corrupt, 1)
+	require.Equal(uint64(2), corrupt[0].Start)
+	require.Equal(uint64(2), corrupt[0].End)
+
+	_, err = db.Get(2)
+	require.ErrorIs(err, database.ErrNotFound)
+	for _, height := range []uint64{0, 1, 3, 4} {
+		_, err := db.Get(height)
+		require.NoError(err)
+	}
+
+	corrupt, err = db.Verify(t.Context())
+	require.NoError(err)
+	require.Empty(corrupt)
+}
+
+func TestCompact_AfterPrune(t *testing.T) {
+	require := require.New(t)
+
+	db := newDatabase(t, DefaultConfig())
+	for i := range uint64(10) {
+		require.NoError(db.Put(i, randomBlock(t)))
+	}
+	require.NoError(db.Prune(8))
+
+	_, err := db.Compact(t.Context())
+	require.NoError(err)
+
+	lowest, highest, err := db.Bounds()
+	require.NoError(err)
+	require.Equal(uint64(8), lowest)
+	require.Equal(uint64(9), highest)
+
+	// The prune height is preserved
+	err = db.Put(7, randomBlock(t))
+	require.ErrorIs(err, ErrInvalidBlockHeight)
+}
+
+func TestCompact_Empty(t *testing.T) {
+	require := require.New(t)
+
+	db := newDatabase(t, DefaultConfig())
+	corrupt, err := db.Compact(t.Context())
+	require.NoError(err)
+	require.Empty(corrupt)
+	require.Empty(dataFileSizes(t, db))
+
+	require.NoError(db.Put(1, randomBlock(t)))
+	_, err = db.Get(1)
+	require.NoError(err)
+}
+
+func TestCompact_Interrupted(t *testing.T) {
+	tests := []struct {
+		name          string
+		removeMarker  bool
+		wantCompacted bool
+	}{
+		{
+			name:          "interrupted after writing compacted files",
+			wantCompacted: true,
+		},
+		{
+			name:         "interrupted while writing compacted files",
+			removeMarker: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			config := DefaultConfig().WithMaxDataFileSize(1024 * 2.5)
+			db := newDatabase(t, config)
+			db.compressor = compression.NewNoCompressor()
+
+			heights := []uint64{3, 2, 1, 0, 2}
+			for _, height := range heights {
+				require.NoError(db.Put(height, fixedSizeBlock(t, 1024, height)))
+			}
+			sizesBefore := dataFileSizes(t, db)
+
+			// Simulate a compaction that was interrupted before the data files
+			// were replaced.
+			_, _, err := db.writeCompactedFiles(t.Context())
+			require.NoError(err)
+			compactDir := filepath.Join(db.config.DataDir, compactDirName)
+			if tt.removeMarker {
+				require.NoError(os.Remove(filepath.Join(compactDir, compactMarkerFileName)))
+			}
+			require.NoError(db.Close())
+
+			dir := db.config.DataDir
+			db = newDatabase(t, config.WithIndexDir(dir).WithDataDir(dir))
+			db.compressor = compression.NewNoCompressor()
+
+			_, err = os.Stat(compactDir)
+			require.ErrorIs(err, os.ErrNotExist)
+			_, err = os.Stat(filepath.Join(dir, compactIndexFileName))
+			require.ErrorIs(err, os.ErrNotExist)
+
+			if tt.wantCompacted {
+				require.Len(dataFileSizes(t, db), 2)
+			} else {
+				require.Equal(sizesBefore, dataFileSizes(t, db))
+			}
+			for height := range uint64(4) {
+				block, err := db.Get(height)
+				require.NoError(err)
+				require.Equal(fixedSizeBlock(t, 1024, height), block)
+			}
+		})
+	}
+}
+
+func TestVerifyAndCompact_Closed(t *testing.T) {
+	db := newDatabase(t, DefaultConfig())
+	require.NoError(t, db.Close())
+
+	_, err := db.Verify(t.Context())
+	require.ErrorIs(t, err, database.ErrClosed)
+	_, err = db.Compact(t.Context())
+	require.ErrorIs(t, err, database.ErrClosed)
+}
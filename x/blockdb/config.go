@@ -17,28 +17,28 @@ const DefaultBlockCacheSize uint16 = 256
 // DatabaseConfig contains configuration parameters for BlockDB.
 type DatabaseConfig struct {
 	// IndexDir is the directory where the index file is stored.
-	IndexDir string
+	IndexDir string `json:"indexDir"`
 
 	// DataDir is the directory where the data files are stored.
-	DataDir string
+	DataDir string `json:"dataDir"`
 
 	// MinimumHeight is the lowest block height tracked by the database.
-	MinimumHeight uint64
+	MinimumHeight uint64 `json:"minimumHeight"`
 
 	// MaxDataFileSize sets the maximum size of the data block file in bytes.
-	MaxDataFileSize uint64
+	MaxDataFileSize uint64 `json:"maxDataFileSize"`
 
 	// MaxDataFiles is the maximum number of data files descriptors cached.
-	MaxDataFiles int
+	MaxDataFiles int `json:"maxDataFiles"`
 
 	// BlockCacheSize is the size of the block cache (default: 256).
-	BlockCacheSize uint16
+	BlockCacheSize uint16 `json:"blockCacheSize"`
 
 	// CheckpointInterval defines how frequently (in blocks) the index file header is updated (default: 1024).
-	CheckpointInterval uint64
+	CheckpointInterval uint64 `json:"checkpointInterval"`
 
 	// SyncToDisk determines if fsync is called after each write for durability.
-	SyncToDisk bool
+	SyncToDisk bool `json:"syncToDisk"`
 }
 
 // DefaultConfig returns the default options for BlockDB.
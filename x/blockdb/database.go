@@ -136,9 +136,12 @@ type indexFileHeader struct {
 	MinHeight       BlockHeight
 	MaxHeight       BlockHeight
 	NextWriteOffset uint64
-	// reserve remaining 24 bytes for future use while keeping the
+	// PruneHeight is the height below which all blocks have been pruned.
+	// Index files written before pruning was supported store 0 here.
+	PruneHeight BlockHeight
+	// reserve remaining 16 bytes for future use while keeping the
 	// size of the index file header multiple of sizeOfIndexEntry.
-	Reserved [24]byte
+	Reserved [16]byte
 }
 
 // MarshalBinary implements encoding.BinaryMarshaler for indexFileHeader.
@@ -149,6 +152,7 @@ func (h indexFileHeader) MarshalBinary() ([]byte, error) {
 	binary.LittleEndian.PutUint64(buf[16:], h.MinHeight)
 	binary.LittleEndian.PutUint64(buf[24:], h.MaxHeight)
 	binary.LittleEndian.PutUint64(buf[32:], h.NextWriteOffset)
+	binary.LittleEndian.PutUint64(buf[40:], h.PruneHeight)
 	return buf, nil
 }
 
@@ -165,6 +169,7 @@ func (h *indexFileHeader) UnmarshalBinary(data []byte) error {
 	h.MinHeight = binary.LittleEndian.Uint64(data[16:])
 	h.MaxHeight = binary.LittleEndian.Uint64(data[24:])
 	h.NextWriteOffset = binary.LittleEndian.Uint64(data[32:])
+	h.PruneHeight = binary.LittleEndian.Uint64(data[40:])
 	return nil
 }
 
@@ -249,6 +254,11 @@ func New(config DatabaseConfig, log logging.Logger) (_ database.HeightIndex, err
 		}
 	}()
 
+	if err := s.finishCompaction(); err != nil {
+		s.log.Error("Failed to initialize database: failed to finish compaction", zap.Error(err))
+		return nil, err
+	}
+
 	if err := s.openAndInitializeIndex(); err != nil {
 		s.log.Error("Failed to initialize database: failed to initialize index", zap.Error(err))
 		return nil, err
@@ -322,6 +332,14 @@ func (s *Database) Put(height BlockHeight, block BlockData) error {
 		return fmt.Errorf("%w: block size cannot exceed %d bytes", ErrBlockTooLarge, math.MaxUint32)
 	}
 
+	if height < s.header.PruneHeight {
+		s.log.Error("Failed to write block: height has been pruned",
+			zap.Uint64("height", height),
+			zap.Uint64("pruneHeight", s.header.PruneHeight),
+		)
+		return fmt.Errorf("%w: block at height %d is below the prune height %d", ErrInvalidBlockHeight, height, s.header.PruneHeight)
+	}
+
 	indexFileOffset, err := s.indexEntryOffset(height)
 	if err != nil {
 		s.log.Error("Failed to write block: failed to calculate index entry offset",
@@ -424,6 +442,14 @@ func (s *Database) readBlockIndex(height BlockHeight) (indexEntry, error) {
 		)
 		return entry, fmt.Errorf("%w: height %d is beyond max height %d", database.ErrNotFound, height, maxHeight)
 	}
+	if height < s.header.PruneHeight {
+		s.log.Debug("Block not found",
+			zap.Uint64("height", height),
+			zap.Uint64("pruneHeight", s.header.PruneHeight),
+			zap.String("reason", "height has been pruned"),
+		)
+		return entry, fmt.Errorf("%w: height %d is below prune height %d", database.ErrNotFound, height, s.header.PruneHeight)
+	}
 
 	entry, err := s.readIndexEntry(height)
 	if err != nil {
@@ -0,0 +1,89 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockdb
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+var _ database.HeightIterator = (*iterator)(nil)
+
+// iterator iterates over the blocks of a [Database] in increasing height
+// order. Each call to Next reads the next block from disk, so blocks written
+// after the iterator was created may be returned.
+type iterator struct {
+	db *Database
+
+	nextHeight BlockHeight
+
+	height BlockHeight
+	block  BlockData
+	err    error
+}
+
+// NewIteratorFromHeight returns an iterator over the blocks at heights greater
+// than or equal to [height] in increasing height order.
+func (s *Database) NewIteratorFromHeight(height BlockHeight) database.HeightIterator {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.closed {
+		return &database.HeightIteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return &iterator{
+		db:         s,
+		nextHeight: max(height, s.header.MinHeight, s.header.PruneHeight),
+	}
+}
+
+func (it *iterator) Next() bool {
+	it.block = nil
+	if it.err != nil {
+		return false
+	}
+
+	for {
+		maxHeight := it.db.maxBlockHeight.Load()
+		if maxHeight == unsetHeight || it.nextHeight > maxHeight {
+			return false
+		}
+
+		// maxHeight is never the unset height, so nextHeight cannot overflow.
+		height := it.nextHeight
+		it.nextHeight++
+
+		block, err := it.db.Get(height)
+		if errors.Is(err, database.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.height = height
+		it.block = block
+		return true
+	}
+}
+
+func (it *iterator) Error() error {
+	return it.err
+}
+
+func (it *iterator) Height() BlockHeight {
+	return it.height
+}
+
+func (it *iterator) Value() BlockData {
+	return it.block
+}
+
+func (it *iterator) Release() {
+	it.block = nil
+}
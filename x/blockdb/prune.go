@@ -0,0 +1,225 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+// indexScanBatchSize is the number of index entries read or written at once
+// when scanning ranges of the index file.
+const indexScanBatchSize = 4096
+
+// Prune deletes all blocks below the given height.
+//
+// The index entries of pruned blocks are cleared and data files that only
+// contain pruned blocks are truncated to reclaim disk space. Blocks can no
+// longer be written below the prune height. Prune blocks all other operations
+// on the database until it returns.
+func (s *Database) Prune(below BlockHeight) error {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+
+	if s.closed {
+		s.log.Error("Failed Prune: database closed", zap.Uint64("below", below))
+		return database.ErrClosed
+	}
+
+	if below <= s.header.PruneHeight {
+		return nil
+	}
+
+	start := max(s.header.MinHeight, s.header.PruneHeight)
+	end := below
+	maxHeight := s.maxBlockHeight.Load()
+	if maxHeight == unsetHeight {
+		end = start
+	} else if maxHeight < end {
+		end = maxHeight + 1
+	}
+	if err := s.clearIndexEntries(start, end); err != nil {
+		s.log.Error("Failed to prune: failed to clear index entries",
+			zap.Uint64("start", start),
+			zap.Uint64("end", end),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.header.PruneHeight = below
+	if err := s.persistIndexHeader(); err != nil {
+		s.log.Error("Failed to prune: failed to persist index header",
+			zap.Uint64("below", below),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	reclaimed, err := s.reclaimDataFiles()
+	if err != nil {
+		s.log.Error("Failed to prune: failed to reclaim data files",
+			zap.Uint64("below", below),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.log.Info("Pruned blocks",
+		zap.Uint64("below", below),
+		zap.Int("reclaimedDataFiles", reclaimed),
+	)
+	return nil
+}
+
+// Bounds returns the lowest and highest heights that have a block.
+// Returns database.ErrNotFound if the database has no blocks.
+func (s *Database) Bounds() (BlockHeight, BlockHeight, error) {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.closed {
+		s.log.Error("Failed Bounds: database closed")
+		return 0, 0, database.ErrClosed
+	}
+
+	maxHeight := s.maxBlockHeight.Load()
+	if maxHeight == unsetHeight {
+		return 0, 0, fmt.Errorf("%w: no blocks written yet", database.ErrNotFound)
+	}
+
+	var (
+		lowest BlockHeight = unsetHeight
+		start              = max(s.header.MinHeight, s.header.PruneHeight)
+	)
+	err := s.scanIndexEntries(start, maxHeight, func(height BlockHeight, entry indexEntry) bool {
+		if entry.IsEmpty() {
+			return true
+		}
+		lowest = height
+		return false
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if lowest == unsetHeight {
+		return 0, 0, fmt.Errorf("%w: all blocks have been pruned", database.ErrNotFound)
+	}
+	return lowest, maxHeight, nil
+}
+
+// clearIndexEntries zeroes the index entries for heights in [start, end).
+func (s *Database) clearIndexEntries(start, end BlockHeight) error {
+	zeros := make([]byte, indexScanBatchSize*sizeOfIndexEntry)
+	for height := start; height < end; {
+		count := min(end-height, indexScanBatchSize)
+		offset, err := s.indexEntryOffset(height)
+		if err != nil {
+			return err
+		}
+		if _, err := s.indexFile.WriteAt(zeros[:count*sizeOfIndexEntry], int64(offset)); err != nil {
+			return fmt.Errorf("failed to clear index entries at offset %d: %w", offset, err)
+		}
+		height += count
+	}
+	return nil
+}
+
+// scanIndexEntries calls [f] with the index entry of every height in
+// [start, end] in increasing order until [f] returns false.
+func (s *Database) scanIndexEntries(start, end BlockHeight, f func(BlockHeight, indexEntry) bool) error {
+	buf := make([]byte, indexScanBatchSize*sizeOfIndexEntry)
+	// end is never the unset height, so height cannot overflow.
+	for height := start; height <= end; {
+		count := min(end-height+1, indexScanBatchSize)
+		offset, err := s.indexEntryOffset(height)
+		if err != nil {
+			return err
+		}
+
+		batch := buf[:count*sizeOfIndexEntry]
+		n, err := s.indexFile.ReadAt(batch, int64(offset))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read index entries at offset %d: %w", offset, err)
+		}
+		// Entries past the end of the index file have not been written.
+		clear(batch[n:])
+
+		for i := range count {
+			var entry indexEntry
+			if err := entry.UnmarshalBinary(batch[i*sizeOfIndexEntry : (i+1)*sizeOfIndexEntry]); err != nil {
+				return fmt.Errorf("failed to deserialize index entry for height %d: %w", height+i, err)
+			}
+			if !f(height+i, entry) {
+				return nil
+			}
+		}
+		height += count
+	}
+	return nil
+}
+
+// reclaimDataFiles truncates every data file before the first data file
+// referenced by a remaining block. The files are truncated rather than removed
+// so that recovery can still verify that no data files are missing.
+// Returns the number of data files that were truncated.
+func (s *Database) reclaimDataFiles() (int, error) {
+	// All blocks are written to a single data file, so no file can be reclaimed.
+	if s.header.MaxDataFileSize == math.MaxUint64 {
+		return 0, nil
+	}
+
+	dataFiles, maxIndex, err := s.listDataFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	// The data file currently being written to and the last data file are
+	// never reclaimed, as recovery relies on the size of the last data file.
+	firstRetained := min(int(s.nextDataWriteOffset.Load()/s.header.MaxDataFileSize), maxIndex)
+	maxHeight := s.maxBlockHeight.Load()
+	if maxHeight != unsetHeight && maxHeight >= s.header.PruneHeight {
+		// Blocks may be written out of order, so every remaining index entry
+		// must be checked.
+		start := max(s.header.MinHeight, s.header.PruneHeight)
+		err := s.scanIndexEntries(start, maxHeight, func(_ BlockHeight, entry indexEntry) bool {
+			if !entry.IsEmpty() {
+				firstRetained = min(firstRetained, int(entry.Offset/s.header.MaxDataFileSize))
+			}
+			return firstRetained > 0
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var reclaimed int
+	for index, path := range dataFiles {
+		if index >= firstRetained {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return reclaimed, fmt.Errorf("failed to get stats for data file %s: %w", path, err)
+		}
+		if info.Size() == 0 {
+			continue
+		}
+
+		// Close any open handle before truncating the file.
+		s.fileCache.Evict(index)
+		if err := os.Truncate(path, 0); err != nil {
+			return reclaimed, fmt.Errorf("failed to truncate data file %s: %w", path, err)
+		}
+		reclaimed++
+	}
+	return reclaimed, nil
+}
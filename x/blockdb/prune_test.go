@@ -0,0 +1,126 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blockdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/compression"
+)
+
+func TestPrune_ReclaimsDataFiles(t *testing.T) {
+	// Each data file should have enough space for 2 blocks
+	config := DefaultConfig().WithMaxDataFileSize(1024 * 2.5)
+	store := newDatabase(t, config)
+
+	// Override the compressor so we can have fixed size blocks
+	store.compressor = compression.NewNoCompressor()
+
+	// create 11 blocks, 1kb each, spread over 6 data files
+	numBlocks := 11
+	blocks := make([][]byte, numBlocks)
+	for i := range numBlocks {
+		blocks[i] = fixedSizeBlock(t, 1024, uint64(i))
+		require.NoError(t, store.Put(uint64(i), blocks[i]))
+	}
+
+	// Block 5 is stored in the third data file, so only the first two data
+	// files can be reclaimed.
+	require.NoError(t, store.Prune(5))
+	for i := range 6 {
+		info, err := os.Stat(store.dataFilePath(i))
+		require.NoError(t, err)
+		if i < 2 {
+			require.Zero(t, info.Size(), "data file %d should be reclaimed", i)
+		} else {
+			require.NotZero(t, info.Size(), "data file %d should not be reclaimed", i)
+		}
+	}
+
+	// reopen and verify only the remaining blocks are readable
+	require.NoError(t, store.Close())
+	dir := store.config.DataDir
+	db := newDatabase(t, config.WithIndexDir(dir).WithDataDir(dir))
+	db.compressor = compression.NewNoCompressor()
+	for i := range numBlocks {
+		readBlock, err := db.Get(uint64(i))
+		if i < 5 {
+			require.ErrorIs(t, err, database.ErrNotFound)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, blocks[i], readBlock)
+	}
+
+	lowest, highest, err := db.Bounds()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), lowest)
+	require.Equal(t, uint64(numBlocks-1), highest)
+}
+
+func TestPrune_OutOfOrderBlocks(t *testing.T) {
+	config := DefaultConfig().WithMaxDataFileSize(1024 * 2.5)
+	store := newDatabase(t, config)
+	store.compressor = compression.NewNoCompressor()
+
+	// The highest block is written first, so the first data file must be
+	// retained.
+	heights := []uint64{9, 1, 2, 3, 4, 5}
+	for _, height := range heights {
+		require.NoError(t, store.Put(height, fixedSizeBlock(t, 1024, height)))
+	}
+	require.NoError(t, store.Prune(6))
+
+	info, err := os.Stat(store.dataFilePath(0))
+	require.NoError(t, err)
+	require.NotZero(t, info.Size())
+
+	readBlock, err := store.Get(9)
+	require.NoError(t, err)
+	require.Equal(t, fixedSizeBlock(t, 1024, 9), readBlock)
+}
+
+func TestPrune_SingleDataFile(t *testing.T) {
+	store := newDatabase(t, DefaultConfig())
+	for i := range uint64(10) {
+		require.NoError(t, store.Put(i, randomBlock(t)))
+	}
+	require.NoError(t, store.Prune(10))
+
+	// The only data file is still being written to.
+	info, err := os.Stat(store.dataFilePath(0))
+	require.NoError(t, err)
+	require.NotZero(t, info.Size())
+
+	_, _, err = store.Bounds()
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestPrune_PutBelowPruneHeight(t *testing.T) {
+	store := newDatabase(t, DefaultConfig())
+	require.NoError(t, store.Put(10, randomBlock(t)))
+	require.NoError(t, store.Prune(5))
+
+	err := store.Put(4, randomBlock(t))
+	require.ErrorIs(t, err, ErrInvalidBlockHeight)
+	require.NoError(t, store.Put(5, randomBlock(t)))
+}
+
+func TestPrune_ClearsCache(t *testing.T) {
+	db := newCacheDatabase(t, DefaultConfig())
+	require.NoError(t, db.Put(1, randomBlock(t)))
+	require.NoError(t, db.Put(2, randomBlock(t)))
+	require.NoError(t, db.Prune(2))
+
+	_, ok := db.cache.Get(1)
+	require.False(t, ok)
+
+	has, err := db.Has(1)
+	require.NoError(t, err)
+	require.False(t, has)
+}
@@ -17,9 +17,11 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
@@ -44,6 +46,7 @@ var (
 	metadataPrefix         = []byte{0}
 	valueNodePrefix        = []byte{1}
 	intermediateNodePrefix = []byte{2}
+	diskHistoryPrefix      = []byte{3}
 
 	// cleanShutdownKey is used to flag that the database did (or did not)
 	// previously shutdown correctly.
@@ -63,8 +66,13 @@ var (
 	hadCleanShutdown        = []byte{1}
 	didNotHaveCleanShutdown = []byte{0}
 
-	errSameRoot    = errors.New("start and end root are the same")
-	errTooManyKeys = errors.New("response contains more than requested keys")
+	ErrReadOnly = errors.New("database is read-only")
+
+	errSameRoot            = errors.New("start and end root are the same")
+	errTooManyKeys         = errors.New("response contains more than requested keys")
+	errNotCleanlyShutdown  = errors.New("database wasn't shut down cleanly")
+	errNoDiskHistory       = errors.New("no disk history")
+	errDiskHistoryMismatch = errors.New("disk history doesn't match the trie")
 )
 
 type ChangeProofer interface {
@@ -133,6 +141,24 @@ type RangeProofer interface {
 		maxLength int,
 	) (*RangeProof, error)
 
+	// GetKeyOnlyRangeProofAtRoot is the same as GetRangeProofAtRoot, except
+	// that each value in the returned proof is replaced by its digest.
+	// Values shorter than [HashLength] are their own digest; longer values
+	// are replaced by their hash.
+	// The returned proof must be verified with VerifyKeyOnlyRangeProof.
+	//
+	// Because the trie commits to value digests rather than value lengths,
+	// verifying the proof only proves the sizes of values shorter than
+	// [HashLength]. The size of a longer value isn't proven, only that it is
+	// at least [HashLength] bytes.
+	GetKeyOnlyRangeProofAtRoot(
+		ctx context.Context,
+		rootID ids.ID,
+		start maybe.Maybe[[]byte],
+		end maybe.Maybe[[]byte],
+		maxLength int,
+	) (*RangeProof, error)
+
 	// Returns nil iff all the following hold:
 	//   - [start] <= [end].
 	//   - [proof] is non-empty.
@@ -153,6 +179,18 @@ type RangeProofer interface {
 		maxLength int,
 	) error
 
+	// VerifyKeyOnlyRangeProof is the same as VerifyRangeProof, except that
+	// the values in [proof.KeyChanges] are value digests, as returned by
+	// GetKeyOnlyRangeProofAtRoot.
+	VerifyKeyOnlyRangeProof(
+		ctx context.Context,
+		proof *RangeProof,
+		start maybe.Maybe[[]byte],
+		end maybe.Maybe[[]byte],
+		expectedEndRootID ids.ID,
+		maxLength int,
+	) error
+
 	// CommitRangeProof commits the key/value pairs within the [proof] to the db.
 	// [start] is the smallest possible key in the range this [proof] covers.
 	// [end] is the largest possible key in the range this [proof] covers.
@@ -191,6 +229,7 @@ type MerkleDB interface {
 	ProofGetter
 	ChangeProofer
 	RangeProofer
+	Differ
 	Prefetcher
 }
 
@@ -227,6 +266,18 @@ type Config struct {
 	// The number of changes to the database that we store in memory in order to
 	// serve change proofs.
 	HistoryLength uint
+	// The number of committed roots that we store on disk in order to serve
+	// range and change proofs for roots that are no longer in the in-memory
+	// history. If 0, no history is stored on disk.
+	//
+	// When enabled, the entire trie is written to the disk history the first
+	// time the database is opened.
+	DiskHistoryLength uint
+	// If true, the database never writes to the underlying database, so that
+	// it can be inspected offline without modifying it. The database must
+	// have been shut down cleanly, the disk history must match the trie, and
+	// changes can't be committed.
+	ReadOnly bool
 	// The number of bytes used to cache nodes with values.
 	ValueNodeCacheSize uint
 	// The number of bytes used to cache nodes without values.
@@ -243,6 +294,9 @@ type Config struct {
 	Namespace  string
 	TraceLevel TraceLevel
 	Tracer     trace.Tracer
+	// Log reports failures that don't fail the operation, such as the disk
+	// history failing to record changes. If nil, nothing is logged.
+	Log logging.Logger
 }
 
 // merkleDB can only be edited by committing changes from a view.
@@ -266,10 +320,15 @@ type merkleDB struct {
 	// Stores change lists. Used to serve change proofs and construct
 	// historical views of the trie.
 	history *trieHistory
+	// Stores the trie at older roots than [history].
+	// Nil if [Config.DiskHistoryLength] is 0.
+	diskHistory *diskHistory
 
 	// True iff the db has been closed.
 	closed bool
 
+	readOnly bool
+
 	metrics metrics
 
 	debugTracer trace.Tracer
@@ -354,6 +413,7 @@ func newDatabase(
 		hashNodesKeyPool: newBytesPool(rootGenConcurrency),
 		tokenSize:        BranchFactorToTokenSize[config.BranchFactor],
 		hasher:           hasher,
+		readOnly:         config.ReadOnly,
 	}
 
 	shutdownType, err := trieDB.baseDB.Get(cleanShutdownKey)
@@ -366,16 +426,62 @@ func newDatabase(
 	default:
 		return nil, err
 	}
-	if bytes.Equal(shutdownType, didNotHaveCleanShutdown) {
+	hasSubtreeJournal, err := trieDB.baseDB.Has(subtreeJournalKey)
+	if err != nil {
+		return nil, err
+	}
+	// Markers that aren't needed are removed by the next commit.
+	dirtySubtrees, err := trieDB.loadDirtySubtrees()
+	if err != nil {
+		return nil, err
+	}
+	trieDB.intermediateNodeDB.subtrees.marked.Union(dirtySubtrees)
+
+	switch {
+	case bytes.Equal(shutdownType, didNotHaveCleanShutdown) && config.ReadOnly:
+		// Rebuilding the trie would write to the database.
+		return nil, errNotCleanlyShutdown
+	case bytes.Equal(shutdownType, didNotHaveCleanShutdown) && hasSubtreeJournal:
+		if err := trieDB.rebuildIncrementally(ctx, int(config.ValueNodeCacheSize)); err != nil {
+			return nil, err
+		}
+	case bytes.Equal(shutdownType, didNotHaveCleanShutdown):
 		if err := trieDB.rebuild(ctx, int(config.ValueNodeCacheSize)); err != nil {
 			return nil, err
 		}
-	} else {
+	default:
 		if err := trieDB.initializeRoot(); err != nil {
 			return nil, err
 		}
 	}
 
+	if config.DiskHistoryLength > 0 {
+		log := config.Log
+		if log == nil {
+			log = logging.NoLog{}
+		}
+		trieDB.diskHistory, err = newDiskHistory(
+			prefixdb.New(diskHistoryPrefix, db),
+			config.DiskHistoryLength,
+			config.ReadOnly,
+			hasher,
+			trieDB.tokenSize,
+			log,
+			metrics,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if config.ReadOnly {
+			err = trieDB.diskHistory.load(trieDB.rootID)
+		} else {
+			err = trieDB.diskHistory.initialize(trieDB)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// add current root to history (has no changes)
 	trieDB.history.record(&changeSummary{
 		rootID: trieDB.rootID,
@@ -386,14 +492,24 @@ func newDatabase(
 		nodes:      map[Key]*change[*node]{},
 		keyChanges: map[Key]*change[maybe.Maybe[[]byte]]{},
 	})
+	if config.ReadOnly {
+		return trieDB, nil
+	}
 
-	// mark that the db has not yet been cleanly closed
-	err = trieDB.baseDB.Put(cleanShutdownKey, didNotHaveCleanShutdown)
-	return trieDB, err
+	// mark that the db has not yet been cleanly closed and that the dirty
+	// subtrees are being tracked
+	batch := trieDB.baseDB.NewBatch()
+	if err := batch.Put(cleanShutdownKey, didNotHaveCleanShutdown); err != nil {
+		return nil, err
+	}
+	if err := batch.Put(subtreeJournalKey, nil); err != nil {
+		return nil, err
+	}
+	return trieDB, batch.Write()
 }
 
 // Deletes every intermediate node and rebuilds them by re-adding every key/value.
-// TODO: make this more efficient by only clearing out the stale portions of the trie.
+// [rebuildIncrementally] is used instead when the dirty subtrees are known.
 func (db *merkleDB) rebuild(ctx context.Context, cacheSize int) error {
 	db.root = maybe.Nothing[*node]()
 	db.rootID = ids.Empty
@@ -551,6 +667,13 @@ func (db *merkleDB) Close() error {
 
 	db.closed = true
 	db.valueNodeDB.Close()
+	if db.readOnly {
+		if db.diskHistory != nil {
+			return db.diskHistory.close()
+		}
+		return nil
+	}
+
 	// Flush intermediary nodes to disk.
 	if err := db.intermediateNodeDB.Flush(); err != nil {
 		return err
@@ -571,11 +694,28 @@ func (db *merkleDB) Close() error {
 		return err
 	}
 
+	// All intermediate nodes were flushed, so no subtree is dirty.
+	var update markerUpdate
+	if err := db.intermediateNodeDB.subtrees.unmarkClean(batch, &update); err != nil {
+		return err
+	}
+	if err := batch.Delete(subtreeJournalKey); err != nil {
+		return err
+	}
+
 	// Write the clean shutdown marker
 	if err := batch.Put(cleanShutdownKey, hadCleanShutdown); err != nil {
 		return err
 	}
-	return batch.Write()
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	db.intermediateNodeDB.subtrees.apply(&update)
+
+	if db.diskHistory != nil {
+		return db.diskHistory.close()
+	}
+	return nil
 }
 
 func (db *merkleDB) PrefetchPaths(keys [][]byte) error {
@@ -724,6 +864,20 @@ func (db *merkleDB) GetProof(ctx context.Context, key []byte) (*Proof, error) {
 	return getProof(db, key)
 }
 
+func (db *merkleDB) GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	_, span := db.infoTracer.Start(ctx, "MerkleDB.GetMultiProof")
+	defer span.End()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	return getMultiProof(db, keys)
+}
+
 func (db *merkleDB) GetRangeProof(
 	ctx context.Context,
 	start maybe.Maybe[[]byte],
@@ -772,6 +926,23 @@ func (db *merkleDB) GetRangeProofAtRoot(
 	return getRangeProof(historicalTrie, start, end, maxLength)
 }
 
+func (db *merkleDB) GetKeyOnlyRangeProofAtRoot(
+	ctx context.Context,
+	rootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) (*RangeProof, error) {
+	proof, err := db.GetRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+	if err != nil {
+		return nil, err
+	}
+	for i, kv := range proof.KeyChanges {
+		proof.KeyChanges[i].Value = valueDigest(db.hasher, kv.Value)
+	}
+	return proof, nil
+}
+
 func (db *merkleDB) GetChangeProof(
 	ctx context.Context,
 	startRootID ids.ID,
@@ -801,7 +972,7 @@ func (db *merkleDB) GetChangeProof(
 
 	// [valueChanges] contains a subset of the keys that were added or had their
 	// values modified between [startRootID] to [endRootID].
-	valueChanges, err := db.history.getValueChanges(startRootID, endRootID, start, end, maxLength)
+	valueChanges, err := db.getValueChanges(startRootID, endRootID, start, end, maxLength)
 	if err != nil {
 		return nil, err
 	}
@@ -1018,6 +1189,8 @@ func (db *merkleDB) commitView(ctx context.Context, trieToCommit *view) error {
 	switch {
 	case db.closed:
 		return database.ErrClosed
+	case db.readOnly:
+		return ErrReadOnly
 	case trieToCommit == nil:
 		return nil
 	case trieToCommit.isInvalid():
@@ -1050,11 +1223,32 @@ func (db *merkleDB) commitView(ctx context.Context, trieToCommit *view) error {
 		return err
 	}
 
+	// Update the dirty subtree markers atomically with the values so that a
+	// subtree is never considered clean while its intermediate nodes on disk
+	// don't match its values on disk.
+	var (
+		subtrees = set.NewSet[string](len(changes.nodes))
+		update   markerUpdate
+	)
+	for key := range changes.nodes {
+		addSubtree(subtrees, key)
+	}
+	if err := db.intermediateNodeDB.subtrees.mark(valueNodeBatch, subtrees, &update); err != nil {
+		return err
+	}
+	if err := db.intermediateNodeDB.subtrees.unmarkClean(valueNodeBatch, &update); err != nil {
+		return err
+	}
+
 	if err := db.commitValueChanges(ctx, valueNodeBatch); err != nil {
 		return err
 	}
+	db.intermediateNodeDB.subtrees.apply(&update)
 
 	db.history.record(changes)
+	if db.diskHistory != nil {
+		db.diskHistory.record(changes)
+	}
 
 	// Update root in database.
 	db.root = changes.rootChange.after
@@ -1140,6 +1334,21 @@ func (db *merkleDB) VerifyChangeProof(
 	end maybe.Maybe[[]byte],
 	expectedEndRootID ids.ID,
 	maxLength int,
+) error {
+	return db.verifyChangeProof(ctx, proof, start, end, expectedEndRootID, maxLength, false /*keyOnly*/)
+}
+
+// If [keyOnly], the values in [proof.KeyChanges] are treated as value digests
+// rather than values.
+// Assumes [db.lock] isn't held.
+func (db *merkleDB) verifyChangeProof(
+	ctx context.Context,
+	proof *ChangeProof,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	expectedEndRootID ids.ID,
+	maxLength int,
+	keyOnly bool,
 ) error {
 	if proof == nil {
 		return ErrEmptyProof
@@ -1186,6 +1395,7 @@ func (db *merkleDB) VerifyChangeProof(
 		startProofKey,
 		endProofKey,
 		db.hasher,
+		keyOnly,
 	); err != nil {
 		return fmt.Errorf("failed to verify start proof nodes: %w", err)
 	}
@@ -1199,17 +1409,21 @@ func (db *merkleDB) VerifyChangeProof(
 		startProofKey,
 		endProofKey,
 		db.hasher,
+		keyOnly,
 	); err != nil {
 		return fmt.Errorf("failed to validate end proof nodes: %w", err)
 	}
 
 	// Prepare ops for the creation of the view.
-	ops := make([]database.BatchOp, len(proof.KeyChanges))
-	for i, kv := range proof.KeyChanges {
-		ops[i] = database.BatchOp{
-			Key:    kv.Key,
-			Value:  kv.Value.Value(),
-			Delete: kv.Value.IsNothing(),
+	var ops []database.BatchOp
+	if !keyOnly {
+		ops = make([]database.BatchOp, len(proof.KeyChanges))
+		for i, kv := range proof.KeyChanges {
+			ops[i] = database.BatchOp{
+				Key:    kv.Key,
+				Value:  kv.Value.Value(),
+				Delete: kv.Value.IsNothing(),
+			}
 		}
 	}
 
@@ -1219,6 +1433,12 @@ func (db *merkleDB) VerifyChangeProof(
 		return err
 	}
 
+	if keyOnly {
+		if err := addKeyOnlyChanges(view, proof.KeyChanges); err != nil {
+			return fmt.Errorf("failed to add key changes: %w", err)
+		}
+	}
+
 	// For all the nodes along the edges of the proofs, insert the children whose
 	// keys are less than [insertChildrenLessThan] or whose keys are greater
 	// than [insertChildrenGreaterThan] into the trie so that we get the
@@ -1273,6 +1493,25 @@ func (db *merkleDB) VerifyRangeProof(
 	)
 }
 
+func (db *merkleDB) VerifyKeyOnlyRangeProof(
+	ctx context.Context,
+	proof *RangeProof,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	expectedEndRootID ids.ID,
+	maxLength int,
+) error {
+	return proof.VerifyKeyOnly(
+		ctx,
+		start,
+		end,
+		expectedEndRootID,
+		db.tokenSize,
+		db.hasher,
+		maxLength,
+	)
+}
+
 // Invalidates and removes any child views that aren't [exception].
 // Assumes [db.lock] is held.
 func (db *merkleDB) invalidateChildrenExcept(exception *view) {
@@ -1329,6 +1568,24 @@ func (db *merkleDB) initializeRoot() error {
 	return nil
 }
 
+// Returns up to [maxLength] sorted changes with keys in [start, end] that
+// occurred between [startRootID] and [endRootID], using the disk history if
+// the in-memory history is insufficient.
+// Assumes [db.commitLock] is read locked.
+func (db *merkleDB) getValueChanges(
+	startRootID ids.ID,
+	endRootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) ([]valueChange, error) {
+	valueChanges, err := db.history.getValueChanges(startRootID, endRootID, start, end, maxLength)
+	if db.diskHistory != nil && (errors.Is(err, merklesync.ErrInsufficientHistory) || errors.Is(err, merklesync.ErrNoEndRoot)) {
+		return db.diskHistory.getValueChanges(startRootID, endRootID, start, end, maxLength)
+	}
+	return valueChanges, err
+}
+
 // Returns a view of the trie as it was when it had root [rootID] for keys within range [start, end].
 // If [start] is Nothing, there's no lower bound on the range.
 // If [end] is Nothing, there's no upper bound on the range.
@@ -1337,13 +1594,16 @@ func (db *merkleDB) getTrieAtRootForRange(
 	rootID ids.ID,
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
-) (Trie, error) {
+) (provableTrie, error) {
 	// looking for the trie's current root id, so return the trie unmodified
 	if rootID == db.getMerkleRoot() {
 		return db, nil
 	}
 
 	changeHistory, err := db.history.getChangesToGetToRoot(rootID, start, end)
+	if db.diskHistory != nil && errors.Is(err, merklesync.ErrInsufficientHistory) {
+		return db.diskHistory.getTrieAtRoot(rootID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1419,6 +1679,10 @@ func (db *merkleDB) Clear() error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
+	if db.readOnly {
+		return ErrReadOnly
+	}
+
 	// Clear nodes from disk and caches
 	if err := db.valueNodeDB.Clear(); err != nil {
 		return err
@@ -1439,6 +1703,9 @@ func (db *merkleDB) Clear() error {
 		nodes:      map[Key]*change[*node]{},
 		keyChanges: map[Key]*change[maybe.Maybe[[]byte]]{},
 	})
+	if db.diskHistory != nil {
+		return db.diskHistory.reset(db)
+	}
 	return nil
 }
 
@@ -211,6 +211,206 @@ func Test_MerkleDB_DB_Rebuild(t *testing.T) {
 	require.Equal(root, rebuiltRoot)
 }
 
+func Test_MerkleDB_Incremental_Rebuild(t *testing.T) {
+	for _, bf := range validBranchFactors {
+		t.Run(strconv.Itoa(int(bf)), func(t *testing.T) {
+			require := require.New(t)
+
+			config := NewConfig()
+			config.BranchFactor = bf
+			// Keep the changes made after reopening in the write buffer.
+			config.IntermediateWriteBufferSize = units.MiB
+			newTestDB := func(baseDB database.Database) (*merkleDB, *mockMetrics) {
+				metrics := &mockMetrics{}
+				db, err := newDatabase(t.Context(), baseDB, config, metrics)
+				require.NoError(err)
+				return db, metrics
+			}
+
+			var (
+				r      = rand.New(rand.NewSource(int64(bf))) // #nosec G404
+				baseDB = memdb.New()
+				ops    = make([]database.BatchOp, 0, 1_000)
+			)
+			for range cap(ops) {
+				key := make([]byte, 1+r.Intn(8))
+				_, _ = r.Read(key)
+				ops = append(ops, database.BatchOp{
+					Key:   key,
+					Value: key,
+				})
+			}
+			ops = append(ops, database.BatchOp{
+				Key:   nil,
+				Value: []byte("root value"),
+			})
+
+			db, _ := newTestDB(baseDB)
+			view, err := db.NewView(t.Context(), ViewChanges{BatchOps: ops})
+			require.NoError(err)
+			require.NoError(view.CommitToDB(t.Context()))
+			require.NoError(db.Close())
+
+			// Modify a subtree and the root without closing the database. The
+			// new keys share a prefix, so an intermediate node is added to the
+			// subtree.
+			db, _ = newTestDB(baseDB)
+			require.NoError(db.Put([]byte{7, 1, 2, 3}, []byte{1}))
+			require.NoError(db.Put([]byte{7, 1, 2, 4}, []byte{2}))
+			require.NoError(db.Put(nil, []byte("new root value")))
+			expectedRoot, err := db.GetMerkleRoot(t.Context())
+			require.NoError(err)
+
+			db, metrics := newTestDB(baseDB)
+			root, err := db.GetMerkleRoot(t.Context())
+			require.NoError(err)
+			require.Equal(expectedRoot, root)
+
+			require.Equal(int64(1), metrics.subtreesRebuilt)
+			require.Positive(metrics.subtreesReused)
+			require.NoError(db.Close())
+
+			// The markers are removed after a clean shutdown.
+			it := baseDB.NewIteratorWithPrefix(dirtySubtreePrefix)
+			require.False(it.Next())
+			require.NoError(it.Error())
+			it.Release()
+
+			// The intermediate nodes must match the ones of a database
+			// populated from scratch.
+			expectedDB := memdb.New()
+			db, _ = newTestDB(expectedDB)
+			valueIt := baseDB.NewIteratorWithPrefix(valueNodePrefix)
+			batch := db.NewBatch()
+			for valueIt.Next() {
+				n, err := parseNode(db.hasher, Key{}, valueIt.Value())
+				require.NoError(err)
+				require.NoError(batch.Put(valueIt.Key()[len(valueNodePrefix):], n.value.Value()))
+			}
+			require.NoError(valueIt.Error())
+			valueIt.Release()
+			require.NoError(batch.Write())
+			require.NoError(db.Close())
+
+			require.Equal(
+				getPrefixedKVs(t, expectedDB, intermediateNodePrefix),
+				getPrefixedKVs(t, baseDB, intermediateNodePrefix),
+			)
+		})
+	}
+}
+
+func Test_MerkleDB_Rebuild_Without_Journal(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDatabase(t.Context(), baseDB, NewConfig(), &mockMetrics{})
+	require.NoError(err)
+	writeBasicBatch(t, db)
+	require.NoError(db.Close())
+
+	// Simulate a crash of a version that didn't track dirty subtrees.
+	require.NoError(baseDB.Put(cleanShutdownKey, didNotHaveCleanShutdown))
+	require.NoError(baseDB.Delete(subtreeJournalKey))
+	require.NoError(database.ClearPrefix(baseDB, intermediateNodePrefix, units.KiB))
+
+	metrics := &mockMetrics{}
+	db, err = newDatabase(t.Context(), baseDB, NewConfig(), metrics)
+	require.NoError(err)
+	require.Zero(metrics.subtreesReused)
+	require.Zero(metrics.subtreesRebuilt)
+
+	expectedDB, err := getBasicDB()
+	require.NoError(err)
+	writeBasicBatch(t, expectedDB)
+	expectedRoot, err := expectedDB.GetMerkleRoot(t.Context())
+	require.NoError(err)
+	root, err := db.GetMerkleRoot(t.Context())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+}
+
+func Test_MerkleDB_Incremental_Rebuild_Random(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		r      = rand.New(rand.NewSource(time.Now().Unix())) // #nosec G404
+		baseDB = memdb.New()
+		config = NewConfig()
+	)
+	// Keep some intermediate nodes in the write buffer and evict others.
+	config.IntermediateWriteBufferSize = 8 * units.KiB
+	config.IntermediateWriteBatchSize = units.KiB
+
+	for range 10 {
+		db, err := newDatabase(t.Context(), baseDB, config, &mockMetrics{})
+		require.NoError(err)
+
+		for range 1 + r.Intn(5) {
+			ops := make([]database.BatchOp, 0, 100)
+			for range r.Intn(cap(ops)) {
+				key := make([]byte, r.Intn(4))
+				_, _ = r.Read(key)
+				ops = append(ops, database.BatchOp{
+					Key:    key,
+					Value:  key,
+					Delete: r.Intn(4) == 0,
+				})
+			}
+			view, err := db.NewView(t.Context(), ViewChanges{BatchOps: ops})
+			require.NoError(err)
+			require.NoError(view.CommitToDB(t.Context()))
+		}
+		expectedRoot, err := db.GetMerkleRoot(t.Context())
+		require.NoError(err)
+
+		// Either crash or shutdown cleanly.
+		if r.Intn(2) == 0 {
+			require.NoError(db.Close())
+		}
+
+		db, err = newDatabase(t.Context(), baseDB, config, &mockMetrics{})
+		require.NoError(err)
+		root, err := db.GetMerkleRoot(t.Context())
+		require.NoError(err)
+		require.Equal(expectedRoot, root)
+		require.NoError(db.Close())
+
+		// The intermediate nodes must match the ones of a database populated
+		// from scratch.
+		expectedDB := memdb.New()
+		db, err = newDatabase(t.Context(), expectedDB, config, &mockMetrics{})
+		require.NoError(err)
+		valueIt := baseDB.NewIteratorWithPrefix(valueNodePrefix)
+		batch := db.NewBatch()
+		for valueIt.Next() {
+			n, err := parseNode(db.hasher, Key{}, valueIt.Value())
+			require.NoError(err)
+			require.NoError(batch.Put(valueIt.Key()[len(valueNodePrefix):], n.value.Value()))
+		}
+		require.NoError(valueIt.Error())
+		valueIt.Release()
+		require.NoError(batch.Write())
+		require.NoError(db.Close())
+		require.Equal(
+			getPrefixedKVs(t, expectedDB, intermediateNodePrefix),
+			getPrefixedKVs(t, baseDB, intermediateNodePrefix),
+		)
+	}
+}
+
+func getPrefixedKVs(t *testing.T, db database.Iteratee, prefix []byte) map[string][]byte {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	kvs := make(map[string][]byte)
+	for it.Next() {
+		kvs[string(it.Key())] = slices.Clone(it.Value())
+	}
+	require.NoError(t, it.Error())
+	return kvs
+}
+
 func Test_MerkleDB_Failed_Batch_Commit(t *testing.T) {
 	require := require.New(t)
 
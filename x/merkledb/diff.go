@@ -0,0 +1,222 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+// The maximum number of changes read from the history at a time by Diff.
+const diffBatchSize = 1024
+
+// DiffType describes how the value of a key differs between two tries.
+type DiffType byte
+
+const (
+	DiffAdded DiffType = iota + 1
+	DiffRemoved
+	DiffModified
+)
+
+func (t DiffType) String() string {
+	switch t {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffModified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyDiff is a key whose value differs between two tries.
+type KeyDiff struct {
+	Key []byte
+	// Nothing if the key isn't in the first trie.
+	Before maybe.Maybe[[]byte]
+	// Nothing if the key isn't in the second trie.
+	After maybe.Maybe[[]byte]
+}
+
+func (d KeyDiff) Type() DiffType {
+	switch {
+	case d.Before.IsNothing():
+		return DiffAdded
+	case d.After.IsNothing():
+		return DiffRemoved
+	default:
+		return DiffModified
+	}
+}
+
+type Differ interface {
+	// Diff calls [onDiff] with each key in [start, end] whose value when the
+	// trie had root [startRootID] differs from its value when the trie had
+	// root [endRootID], in order of increasing key.
+	// If [start] is Nothing, there's no lower bound on the range.
+	// If [end] is Nothing, there's no upper bound on the range.
+	// Returns the first error returned by [onDiff].
+	// Returns [sync.ErrInsufficientHistory] if the history doesn't contain
+	// [startRootID] before [endRootID].
+	// Returns [sync.ErrNoEndRoot] if the history doesn't contain [endRootID].
+	Diff(
+		ctx context.Context,
+		startRootID ids.ID,
+		endRootID ids.ID,
+		start maybe.Maybe[[]byte],
+		end maybe.Maybe[[]byte],
+		onDiff func(KeyDiff) error,
+	) error
+}
+
+func (db *merkleDB) Diff(
+	ctx context.Context,
+	startRootID ids.ID,
+	endRootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	onDiff func(KeyDiff) error,
+) error {
+	ctx, span := db.infoTracer.Start(ctx, "MerkleDB.Diff")
+	defer span.End()
+
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) == 1 {
+		return ErrStartAfterEnd
+	}
+
+	for {
+		changes, err := db.getValueChangesBatch(startRootID, endRootID, start, end)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			if err := onDiff(KeyDiff{
+				Key:    slices.Clone(change.key.Bytes()),
+				Before: maybe.Bind(change.change.before, slices.Clone[[]byte]),
+				After:  maybe.Bind(change.change.after, slices.Clone[[]byte]),
+			}); err != nil {
+				return err
+			}
+		}
+		if len(changes) < diffBatchSize {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// The next batch starts at the smallest key after the last change.
+		lastKey := changes[len(changes)-1].key.Bytes()
+		nextKey := make([]byte, len(lastKey)+1)
+		copy(nextKey, lastKey)
+		start = maybe.Some(nextKey)
+	}
+}
+
+// Returns up to [diffBatchSize] sorted changes with keys in [start, end] that
+// occurred between [startRootID] and [endRootID].
+// Assumes [db.commitLock] isn't held.
+func (db *merkleDB) getValueChangesBatch(
+	startRootID ids.ID,
+	endRootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+) ([]valueChange, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	return db.getValueChanges(startRootID, endRootID, start, end, diffBatchSize)
+}
+
+// DiffTries calls [onDiff] with each key in [start, end] whose value in
+// [before] differs from its value in [after], in order of increasing key.
+// If [start] is Nothing, there's no lower bound on the range.
+// If [end] is Nothing, there's no upper bound on the range.
+//
+// Unlike Diff, the tries don't need to share a history, so they can be from
+// different databases, but every key in the range is read from both tries.
+// Assumes [before] and [after] don't change while this function is running.
+func DiffTries(
+	ctx context.Context,
+	before database.Iteratee,
+	after database.Iteratee,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	onDiff func(KeyDiff) error,
+) error {
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) == 1 {
+		return ErrStartAfterEnd
+	}
+
+	beforeIt := before.NewIteratorWithStart(start.Value())
+	defer beforeIt.Release()
+	afterIt := after.NewIteratorWithStart(start.Value())
+	defer afterIt.Release()
+
+	// Returns true if [it] is at a key in the range.
+	next := func(it database.Iterator) bool {
+		return it.Next() && (end.IsNothing() || bytes.Compare(it.Key(), end.Value()) <= 0)
+	}
+
+	var (
+		hasBefore = next(beforeIt)
+		hasAfter  = next(afterIt)
+	)
+	for numKeys := 0; hasBefore || hasAfter; numKeys++ {
+		if numKeys%diffBatchSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		var diff KeyDiff
+		switch {
+		case !hasAfter || (hasBefore && bytes.Compare(beforeIt.Key(), afterIt.Key()) < 0):
+			diff = KeyDiff{
+				Key:    slices.Clone(beforeIt.Key()),
+				Before: maybe.Some(slices.Clone(beforeIt.Value())),
+			}
+			hasBefore = next(beforeIt)
+		case !hasBefore || bytes.Compare(beforeIt.Key(), afterIt.Key()) > 0:
+			diff = KeyDiff{
+				Key:   slices.Clone(afterIt.Key()),
+				After: maybe.Some(slices.Clone(afterIt.Value())),
+			}
+			hasAfter = next(afterIt)
+		default:
+			if bytes.Equal(beforeIt.Value(), afterIt.Value()) {
+				hasBefore = next(beforeIt)
+				hasAfter = next(afterIt)
+				continue
+			}
+			diff = KeyDiff{
+				Key:    slices.Clone(beforeIt.Key()),
+				Before: maybe.Some(slices.Clone(beforeIt.Value())),
+				After:  maybe.Some(slices.Clone(afterIt.Value())),
+			}
+			hasBefore = next(beforeIt)
+			hasAfter = next(afterIt)
+		}
+		if err := onDiff(diff); err != nil {
+			return err
+		}
+	}
+
+	if err := beforeIt.Error(); err != nil {
+		return err
+	}
+	return afterIt.Error()
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/merkle/sync"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+// Returns the diffs between [before] and [after] with keys in [start, end].
+func expectedDiffs(before, after map[string][]byte, start, end maybe.Maybe[[]byte]) []KeyDiff {
+	keys := slices.Collect(maps.Keys(before))
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var diffs []KeyDiff
+	for _, key := range keys {
+		if start.HasValue() && key < string(start.Value()) {
+			continue
+		}
+		if end.HasValue() && key > string(end.Value()) {
+			continue
+		}
+
+		diff := KeyDiff{Key: []byte(key)}
+		if value, ok := before[key]; ok {
+			diff.Before = maybe.Some(value)
+		}
+		if value, ok := after[key]; ok {
+			diff.After = maybe.Some(value)
+		}
+		if !maybe.Equal(diff.Before, diff.After, bytes.Equal) {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+func applyOps(kvs map[string][]byte, ops []database.BatchOp) map[string][]byte {
+	kvs = maps.Clone(kvs)
+	for _, op := range ops {
+		if op.Delete {
+			delete(kvs, string(op.Key))
+		} else {
+			kvs[string(op.Key)] = op.Value
+		}
+	}
+	return kvs
+}
+
+func Test_Diff(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	var (
+		roots = []ids.ID{db.getMerkleRoot()}
+		kvs   = []map[string][]byte{{}}
+	)
+	for range 3 {
+		ops := newRandomBatchOps(r, 1000)
+		roots = append(roots, commitOps(t, db, ops))
+		kvs = append(kvs, applyOps(kvs[len(kvs)-1], ops))
+	}
+
+	tests := []struct {
+		name  string
+		start maybe.Maybe[[]byte]
+		end   maybe.Maybe[[]byte]
+	}{
+		{
+			name: "no bounds",
+		},
+		{
+			name:  "start bound",
+			start: maybe.Some([]byte{0x80}),
+		},
+		{
+			name: "end bound",
+			end:  maybe.Some([]byte{0x80}),
+		},
+		{
+			name:  "both bounds",
+			start: maybe.Some([]byte{0x40}),
+			end:   maybe.Some([]byte{0xc0, 0x00}),
+		},
+	}
+	for _, test := range tests {
+		for _, startIndex := range []int{0, 1} {
+			endIndex := len(roots) - 1
+			expected := expectedDiffs(kvs[startIndex], kvs[endIndex], test.start, test.end)
+
+			var diffs []KeyDiff
+			require.NoError(db.Diff(t.Context(), roots[startIndex], roots[endIndex], test.start, test.end, func(diff KeyDiff) error {
+				diffs = append(diffs, diff)
+				return nil
+			}), test.name)
+			require.Equal(expected, diffs, test.name)
+		}
+	}
+
+	// More than one batch of changes is read from the history.
+	var numDiffs int
+	require.NoError(db.Diff(t.Context(), roots[0], roots[len(roots)-1], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), func(KeyDiff) error {
+		numDiffs++
+		return nil
+	}))
+	require.Greater(numDiffs, diffBatchSize)
+
+	// The same root has no diffs.
+	require.NoError(db.Diff(t.Context(), roots[1], roots[1], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), func(KeyDiff) error {
+		require.FailNow("unexpected diff")
+		return nil
+	}))
+
+	err = db.Diff(t.Context(), roots[2], roots[1], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), func(KeyDiff) error {
+		return nil
+	})
+	require.ErrorIs(err, sync.ErrInsufficientHistory)
+	err = db.Diff(t.Context(), roots[0], ids.GenerateTestID(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), func(KeyDiff) error {
+		return nil
+	})
+	require.ErrorIs(err, sync.ErrNoEndRoot)
+
+	errStop := errors.New("stop")
+	err = db.Diff(t.Context(), roots[0], roots[1], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), func(KeyDiff) error {
+		return errStop
+	})
+	require.ErrorIs(err, errStop)
+}
+
+func Test_DiffTries(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	beforeOps := newRandomBatchOps(r, 1000)
+	afterOps := append(slices.Clone(beforeOps), newRandomBatchOps(r, 200)...)
+
+	before, err := newDB(t.Context(), memdb.New(), NewConfig())
+	require.NoError(err)
+	commitOps(t, before, beforeOps)
+	after, err := newDB(t.Context(), memdb.New(), NewConfig())
+	require.NoError(err)
+	commitOps(t, after, afterOps)
+
+	var (
+		start    = maybe.Some([]byte{0x10})
+		end      = maybe.Some([]byte{0xf0})
+		expected = expectedDiffs(applyOps(map[string][]byte{}, beforeOps), applyOps(map[string][]byte{}, afterOps), start, end)
+		diffs    []KeyDiff
+		types    = map[DiffType]int{}
+	)
+	require.NoError(DiffTries(t.Context(), before, after, start, end, func(diff KeyDiff) error {
+		diffs = append(diffs, diff)
+		types[diff.Type()]++
+		return nil
+	}))
+	require.Equal(expected, diffs)
+	require.Len(types, 3)
+
+	// A trie has no diffs with itself.
+	require.NoError(DiffTries(t.Context(), after, after, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), func(KeyDiff) error {
+		require.FailNow("unexpected diff")
+		return nil
+	}))
+
+	err = DiffTries(t.Context(), before, after, end, start, func(KeyDiff) error {
+		return nil
+	})
+	require.ErrorIs(err, ErrStartAfterEnd)
+}
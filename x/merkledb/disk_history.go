@@ -0,0 +1,764 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/merkle/sync"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/heap"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/x/archivedb"
+)
+
+// diskHistorySnapshotWriteSize is the size of the batches written while
+// snapshotting the trie into the disk history.
+const diskHistorySnapshotWriteSize = units.MiB
+
+var (
+	_ provableTrie      = (*historicalTrie)(nil)
+	_ database.Iterator = (*historicalIterator)(nil)
+
+	// The archive stores the nodes of the trie at every height. Value nodes
+	// and intermediate nodes are stored under [valueNodePrefix] and
+	// [intermediateNodePrefix] respectively.
+	historyArchivePrefix = []byte{0}
+	// height --> the root of the trie at that height
+	historyHeightPrefix = []byte{1}
+	// rootID + height --> nil, for each height the trie had the root ID
+	historyRootPrefix = []byte{2}
+	// height --> the keys whose values were changed at that height
+	historyKeysPrefix = []byte{3}
+
+	errDiskHistoryFailed = errors.New("disk history failed to record changes")
+)
+
+// diskHistory stores the trie at the last [maxHistoryLen] committed roots on
+// disk. Each commit is written to an archive at the next height, so the trie
+// at a height is read directly from disk, rather than by reverting the changes
+// made since then.
+//
+// The trie is snapshotted into the archive when the history is created, or if
+// the history doesn't match the trie when the database is opened.
+type diskHistory struct {
+	db            database.Database
+	archive       *archivedb.Database
+	maxHistoryLen uint64
+	// readOnly disables pruning of the archive.
+	readOnly  bool
+	hasher    Hasher
+	tokenSize int
+	log       logging.Logger
+	metrics   metrics
+
+	// height of the last recorded root
+	height uint64
+	// err is set if changes failed to be recorded. Once set, the history is
+	// no longer used because it may be missing changes. It is replaced by a
+	// new snapshot when the database is next opened.
+	err error
+}
+
+func newDiskHistory(
+	db database.Database,
+	maxHistoryLen uint,
+	readOnly bool,
+	hasher Hasher,
+	tokenSize int,
+	log logging.Logger,
+	metrics metrics,
+) (*diskHistory, error) {
+	h := &diskHistory{
+		db:            db,
+		maxHistoryLen: uint64(maxHistoryLen),
+		readOnly:      readOnly,
+		hasher:        hasher,
+		tokenSize:     tokenSize,
+		log:           log,
+		metrics:       metrics,
+	}
+	return h, h.openArchive()
+}
+
+func (h *diskHistory) openArchive() error {
+	config := archivedb.Config{
+		RetentionWindow: h.maxHistoryLen,
+		PruneFrequency:  archivedb.DefaultConfig.PruneFrequency,
+	}
+	if h.readOnly {
+		config.PruneFrequency = 0
+	}
+	archive, err := archivedb.New(
+		prefixdb.New(historyArchivePrefix, h.db),
+		config,
+	)
+	h.archive = archive
+	return err
+}
+
+// initialize verifies that the last recorded root is [rootID]. Otherwise the
+// history is cleared and [db]'s trie is snapshotted.
+//
+// Assumes [db.lock] is held or [db] isn't otherwise being accessed.
+func (h *diskHistory) initialize(db *merkleDB) error {
+	height, err := h.archive.Height()
+	switch {
+	case err == nil:
+		rootID, _, err := h.getRoot(height)
+		switch {
+		case err == nil && rootID == db.rootID:
+			h.height = height
+			return nil
+		case err != nil && !errors.Is(err, database.ErrNotFound):
+			return err
+		}
+	case !errors.Is(err, database.ErrNotFound):
+		return err
+	}
+	return h.reset(db)
+}
+
+// load verifies that the last recorded root is [rootID] without modifying the
+// history.
+func (h *diskHistory) load(rootID ids.ID) error {
+	height, err := h.archive.Height()
+	if errors.Is(err, database.ErrNotFound) {
+		return errNoDiskHistory
+	}
+	if err != nil {
+		return err
+	}
+	lastRootID, _, err := h.getRoot(height)
+	if err != nil {
+		return err
+	}
+	if lastRootID != rootID {
+		return fmt.Errorf("%w: last recorded root %s at height %d, expected %s",
+			errDiskHistoryMismatch,
+			lastRootID,
+			height,
+			rootID,
+		)
+	}
+	h.height = height
+	return nil
+}
+
+// reset clears the history and snapshots [db]'s trie at height 0.
+//
+// Assumes [db.lock] is held or [db] isn't otherwise being accessed.
+func (h *diskHistory) reset(db *merkleDB) error {
+	if err := h.archive.Close(); err != nil {
+		return err
+	}
+	if err := database.ClearPrefix(h.db, nil, diskHistorySnapshotWriteSize); err != nil {
+		return err
+	}
+	if err := h.openArchive(); err != nil {
+		return err
+	}
+	h.height = 0
+	h.err = nil
+
+	batch := h.archive.NewBatch(0)
+	if db.root.HasValue() {
+		if err := h.snapshot(db, batch, db.root.Value()); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	indexBatch := h.db.NewBatch()
+	if err := h.writeRoot(indexBatch, 0, db.rootID, db.root); err != nil {
+		return err
+	}
+	return indexBatch.Write()
+}
+
+// snapshot writes [n] and its descendants to [batch].
+//
+// Assumes [db.lock] is held or [db] isn't otherwise being accessed.
+func (h *diskHistory) snapshot(db *merkleDB, batch database.Batch, n *node) error {
+	if err := batch.Put(archiveNodeKey(n.key, n.hasValue()), n.bytes()); err != nil {
+		return err
+	}
+	if batch.Size() >= diskHistorySnapshotWriteSize {
+		// Multiple batches may be written at the same height.
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+
+	for index, entry := range n.children {
+		childKey := n.key.Extend(ToToken(index, h.tokenSize), entry.compressedKey)
+		child, err := db.getNode(childKey, entry.hasValue)
+		if err != nil {
+			return err
+		}
+		if err := h.snapshot(db, batch, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record writes the trie after [changes] at the next height and removes the
+// height that is no longer in the history.
+//
+// The changes have already been committed when this is called, so failing to
+// record them disables the history rather than failing the commit.
+func (h *diskHistory) record(changes *changeSummary) {
+	if h.err != nil {
+		return
+	}
+	if err := h.recordChanges(changes); err != nil {
+		h.err = fmt.Errorf("%w: %w", errDiskHistoryFailed, err)
+		h.log.Error("disabling disk history",
+			zap.Uint64("height", h.height+1),
+			zap.Error(err),
+		)
+		h.metrics.DiskHistoryFailed()
+	}
+}
+
+func (h *diskHistory) recordChanges(changes *changeSummary) error {
+	height := h.height + 1
+
+	batch := h.archive.NewBatch(height)
+	for key, nodeChange := range changes.nodes {
+		before, after := nodeChange.before, nodeChange.after
+		if before != nil && (after == nil || before.hasValue() != after.hasValue()) {
+			if err := batch.Delete(archiveNodeKey(key, before.hasValue())); err != nil {
+				return err
+			}
+		}
+		if after != nil {
+			if err := batch.Put(archiveNodeKey(key, after.hasValue()), after.bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	indexBatch := h.db.NewBatch()
+	if err := h.writeRoot(indexBatch, height, changes.rootID, changes.rootChange.after); err != nil {
+		return err
+	}
+	w := codecWriter{}
+	w.Uvarint(uint64(len(changes.sortedKeys)))
+	for _, key := range changes.sortedKeys {
+		w.Key(key)
+	}
+	if err := indexBatch.Put(heightKey(historyKeysPrefix, height), w.b); err != nil {
+		return err
+	}
+
+	if height >= h.maxHistoryLen {
+		if err := h.removeHeight(indexBatch, height-h.maxHistoryLen); err != nil {
+			return err
+		}
+	}
+	if err := indexBatch.Write(); err != nil {
+		return err
+	}
+	h.height = height
+	return nil
+}
+
+func (h *diskHistory) writeRoot(
+	batch database.KeyValueWriter,
+	height uint64,
+	rootID ids.ID,
+	root maybe.Maybe[*node],
+) error {
+	w := codecWriter{}
+	w.ID(rootID)
+	w.Bool(root.HasValue())
+	if root.HasValue() {
+		w.Key(root.Value().key)
+		w.Bool(root.Value().hasValue())
+	}
+	if err := batch.Put(heightKey(historyHeightPrefix, height), w.b); err != nil {
+		return err
+	}
+	return batch.Put(rootHeightKey(rootID, height), nil)
+}
+
+// removeHeight removes the indices of [height]. The archive removes the nodes
+// that are no longer needed in the background.
+func (h *diskHistory) removeHeight(batch database.KeyValueDeleter, height uint64) error {
+	rootID, _, err := h.getRoot(height)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := batch.Delete(heightKey(historyHeightPrefix, height)); err != nil {
+		return err
+	}
+	if err := batch.Delete(rootHeightKey(rootID, height)); err != nil {
+		return err
+	}
+	return batch.Delete(heightKey(historyKeysPrefix, height))
+}
+
+// rootKey is the key of the root node at a height. If the trie is empty, key
+// is Nothing.
+type rootKey struct {
+	key      maybe.Maybe[Key]
+	hasValue bool
+}
+
+// getRoot returns the root ID and root key of the trie at [height].
+func (h *diskHistory) getRoot(height uint64) (ids.ID, rootKey, error) {
+	rootBytes, err := h.db.Get(heightKey(historyHeightPrefix, height))
+	if err != nil {
+		return ids.Empty, rootKey{}, err
+	}
+
+	r := codecReader{
+		b:    rootBytes,
+		copy: true,
+	}
+	rootID, err := r.ID()
+	if err != nil {
+		return ids.Empty, rootKey{}, err
+	}
+	hasRoot, err := r.Bool()
+	if err != nil || !hasRoot {
+		return rootID, rootKey{}, err
+	}
+	key, err := r.Key()
+	if err != nil {
+		return ids.Empty, rootKey{}, err
+	}
+	hasValue, err := r.Bool()
+	return rootID, rootKey{
+		key:      maybe.Some(key),
+		hasValue: hasValue,
+	}, err
+}
+
+// getHeight returns the greatest height, no greater than [maxHeight], at
+// which the trie had root [rootID].
+func (h *diskHistory) getHeight(rootID ids.ID, maxHeight uint64) (uint64, bool, error) {
+	it := h.db.NewIteratorWithPrefix(rootHeightKey(rootID, 0)[:len(historyRootPrefix)+ids.IDLen])
+	defer it.Release()
+
+	var (
+		height uint64
+		found  bool
+	)
+	for it.Next() {
+		// Heights are sorted in increasing order.
+		next, err := database.ParseUInt64(it.Key()[len(historyRootPrefix)+ids.IDLen:])
+		if err != nil {
+			return 0, false, err
+		}
+		if next > maxHeight {
+			break
+		}
+		height = next
+		found = true
+	}
+	return height, found, it.Error()
+}
+
+// getTrieAtRoot returns the trie at the last height with root [rootID].
+//
+// Returns [sync.ErrInsufficientHistory] if [rootID] isn't in the history.
+func (h *diskHistory) getTrieAtRoot(rootID ids.ID) (*historicalTrie, error) {
+	if h.err != nil {
+		return nil, fmt.Errorf("%w: %w", sync.ErrInsufficientHistory, h.err)
+	}
+
+	height, ok, err := h.getHeight(rootID, h.height)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: root %s not found", sync.ErrInsufficientHistory, rootID)
+	}
+	return h.getTrieAtHeight(height)
+}
+
+func (h *diskHistory) getTrieAtHeight(height uint64) (*historicalTrie, error) {
+	_, rootKey, err := h.getRoot(height)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &historicalTrie{
+		history: h,
+		reader:  h.archive.Open(height),
+	}
+	if rootKey.key.HasValue() {
+		root, err := t.getNode(rootKey.key.Value(), rootKey.hasValue)
+		if err != nil {
+			return nil, err
+		}
+		t.root = maybe.Some(root)
+	}
+	return t, nil
+}
+
+// getValueChanges returns up to [maxLength] sorted changes with keys in
+// [start, end] that occurred between [startRoot] and [endRoot].
+//
+// Returns [sync.ErrNoEndRoot] if [endRoot] isn't in the history.
+// Returns [sync.ErrInsufficientHistory] if [startRoot] isn't in the history
+// before [endRoot].
+func (h *diskHistory) getValueChanges(
+	startRoot ids.ID,
+	endRoot ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) ([]valueChange, error) {
+	if h.err != nil {
+		return nil, fmt.Errorf("%w: %w", sync.ErrInsufficientHistory, h.err)
+	}
+
+	endHeight, ok, err := h.getHeight(endRoot, h.height)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", sync.ErrNoEndRoot, endRoot)
+	}
+	startHeight, ok, err := h.getHeight(startRoot, endHeight)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || startHeight == endHeight {
+		return nil, fmt.Errorf(
+			"%w: start root %s not found before end root %s",
+			sync.ErrInsufficientHistory, startRoot, endRoot,
+		)
+	}
+
+	startTrie, err := h.getTrieAtHeight(startHeight)
+	if err != nil {
+		return nil, err
+	}
+	endTrie, err := h.getTrieAtHeight(endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := h.newChangedKeyIterator(startHeight+1, endHeight, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []valueChange
+	for len(changes) < maxLength {
+		key, ok, err := keys.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		before, err := getMaybeValue(startTrie, key)
+		if err != nil {
+			return nil, err
+		}
+		after, err := getMaybeValue(endTrie, key)
+		if err != nil {
+			return nil, err
+		}
+		if maybe.Equal(before, after, bytes.Equal) {
+			continue
+		}
+
+		changes = append(changes, valueChange{
+			key: key,
+			change: &change[maybe.Maybe[[]byte]]{
+				before: before,
+				after:  after,
+			},
+		})
+	}
+	return changes, nil
+}
+
+// newChangedKeyIterator returns an iterator over the keys in [start, end] that
+// were changed at heights in [minHeight, maxHeight].
+func (h *diskHistory) newChangedKeyIterator(
+	minHeight uint64,
+	maxHeight uint64,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+) (*changedKeyIterator, error) {
+	it := &changedKeyIterator{
+		streams: heap.NewQueue(func(a, b *changedKeyStream) bool {
+			return a.key.Less(b.key)
+		}),
+		endKey: maybe.Bind(end, ToKey),
+	}
+	startKey := maybe.Bind(start, ToKey)
+	for height := minHeight; height <= maxHeight; height++ {
+		keysBytes, err := h.db.Get(heightKey(historyKeysPrefix, height))
+		if err != nil {
+			return nil, err
+		}
+
+		s := &changedKeyStream{
+			reader: codecReader{
+				b:    keysBytes,
+				copy: true,
+			},
+		}
+		s.remaining, err = s.reader.Uvarint()
+		if err != nil {
+			return nil, err
+		}
+
+		// Push the first key in [start, end] changed at this height.
+		for {
+			ok, err := s.next()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			if startKey.HasValue() && s.key.Less(startKey.Value()) {
+				continue
+			}
+			if !it.endKey.HasValue() || !s.key.Greater(it.endKey.Value()) {
+				it.streams.Push(s)
+			}
+			break
+		}
+	}
+	return it, nil
+}
+
+// changedKeyIterator merges the sorted keys changed at each height so that
+// every changed key is returned once, in order, without collecting and sorting
+// every changed key in the range up front.
+type changedKeyIterator struct {
+	// streams is ordered by the current key of each stream.
+	streams heap.Queue[*changedKeyStream]
+	endKey  maybe.Maybe[Key]
+	// last is the last key returned by next.
+	last maybe.Maybe[Key]
+}
+
+// next returns the next changed key, or false if there are no more keys in
+// the range.
+func (it *changedKeyIterator) next() (Key, bool, error) {
+	for {
+		s, ok := it.streams.Pop()
+		if !ok {
+			return Key{}, false, nil
+		}
+
+		key := s.key
+		if it.endKey.HasValue() && key.Greater(it.endKey.Value()) {
+			// Every remaining stream is after [endKey].
+			return Key{}, false, nil
+		}
+
+		hasNext, err := s.next()
+		if err != nil {
+			return Key{}, false, err
+		}
+		if hasNext {
+			it.streams.Push(s)
+		}
+
+		if it.last.HasValue() && it.last.Value() == key {
+			// The key was also changed at another height.
+			continue
+		}
+		it.last = maybe.Some(key)
+		return key, true, nil
+	}
+}
+
+// changedKeyStream reads the sorted keys changed at a single height.
+type changedKeyStream struct {
+	reader    codecReader
+	remaining uint64
+	// key is the last key read from [reader].
+	key Key
+}
+
+func (s *changedKeyStream) next() (bool, error) {
+	if s.remaining == 0 {
+		return false, nil
+	}
+	key, err := s.reader.Key()
+	if err != nil {
+		return false, err
+	}
+	s.remaining--
+	s.key = key
+	return true, nil
+}
+
+func getMaybeValue(t *historicalTrie, key Key) (maybe.Maybe[[]byte], error) {
+	value, err := t.getValue(key)
+	switch {
+	case err == nil:
+		return maybe.Some(value), nil
+	case errors.Is(err, database.ErrNotFound):
+		return maybe.Nothing[[]byte](), nil
+	default:
+		return maybe.Nothing[[]byte](), err
+	}
+}
+
+func (h *diskHistory) close() error {
+	return h.archive.Close()
+}
+
+func archiveNodeKey(key Key, hasValue bool) []byte {
+	if hasValue {
+		return slices.Concat(valueNodePrefix, key.Bytes())
+	}
+	return slices.Concat(intermediateNodePrefix, encodeKey(key))
+}
+
+func heightKey(prefix []byte, height uint64) []byte {
+	return slices.Concat(prefix, database.PackUInt64(height))
+}
+
+func rootHeightKey(rootID ids.ID, height uint64) []byte {
+	return slices.Concat(historyRootPrefix, rootID[:], database.PackUInt64(height))
+}
+
+// historicalTrie is a read-only view of the trie at a height in the disk
+// history.
+type historicalTrie struct {
+	history *diskHistory
+	reader  *archivedb.Reader
+	root    maybe.Maybe[*node]
+}
+
+func (t *historicalTrie) getValue(key Key) ([]byte, error) {
+	n, err := t.getNode(key, true /* hasValue */)
+	if err != nil {
+		return nil, err
+	}
+	return n.value.Value(), nil
+}
+
+func (t *historicalTrie) getEditableNode(key Key, hasValue bool) (*node, error) {
+	n, err := t.getNode(key, hasValue)
+	if err != nil {
+		return nil, err
+	}
+	return n.clone(), nil
+}
+
+func (t *historicalTrie) getNode(key Key, hasValue bool) (*node, error) {
+	nodeBytes, err := t.reader.Get(archiveNodeKey(key, hasValue))
+	if errors.Is(err, archivedb.ErrHeightPruned) {
+		return nil, fmt.Errorf("%w: %w", sync.ErrInsufficientHistory, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseNode(t.history.hasher, key, nodeBytes)
+}
+
+func (t *historicalTrie) getRoot() maybe.Maybe[*node] {
+	return t.root
+}
+
+func (t *historicalTrie) getTokenSize() int {
+	return t.history.tokenSize
+}
+
+func (t *historicalTrie) NewIterator() database.Iterator {
+	return t.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (t *historicalTrie) NewIteratorWithStart(start []byte) database.Iterator {
+	return t.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (t *historicalTrie) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return t.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (t *historicalTrie) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return &historicalIterator{
+		it: t.reader.NewIteratorWithStartAndPrefix(
+			slices.Concat(valueNodePrefix, start),
+			slices.Concat(valueNodePrefix, prefix),
+		),
+		hasher: t.history.hasher,
+	}
+}
+
+// historicalIterator iterates over the values of a [historicalTrie].
+type historicalIterator struct {
+	it     database.Iterator
+	hasher Hasher
+
+	key   []byte
+	value []byte
+	err   error
+}
+
+func (it *historicalIterator) Next() bool {
+	it.key = nil
+	it.value = nil
+	if it.err != nil || !it.it.Next() {
+		return false
+	}
+
+	key := it.it.Key()[len(valueNodePrefix):]
+	n, err := parseNode(it.hasher, ToKey(key), it.it.Value())
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.key = key
+	it.value = n.value.Value()
+	return true
+}
+
+func (it *historicalIterator) Key() []byte {
+	return it.key
+}
+
+func (it *historicalIterator) Value() []byte {
+	return it.value
+}
+
+func (it *historicalIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Error()
+}
+
+func (it *historicalIterator) Release() {
+	it.key = nil
+	it.value = nil
+	it.it.Release()
+}
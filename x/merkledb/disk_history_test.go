@@ -0,0 +1,329 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/merkle/sync"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func newRandomBatchOps(r *rand.Rand, numOps int) []database.BatchOp {
+	ops := make([]database.BatchOp, 0, numOps)
+	for range numOps {
+		key := make([]byte, r.Intn(4))
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(40))
+		_, _ = r.Read(value)
+		ops = append(ops, database.BatchOp{
+			Key:    key,
+			Value:  value,
+			Delete: r.Intn(5) == 0,
+		})
+	}
+	return ops
+}
+
+func commitOps(t *testing.T, db *merkleDB, ops []database.BatchOp) ids.ID {
+	require := require.New(t)
+
+	view, err := db.NewView(t.Context(), ViewChanges{BatchOps: ops})
+	require.NoError(err)
+	require.NoError(view.CommitToDB(t.Context()))
+	root, err := db.GetMerkleRoot(t.Context())
+	require.NoError(err)
+	return root
+}
+
+func Test_DiskHistory_Proofs(t *testing.T) {
+	require := require.New(t)
+
+	const (
+		numCommits        = 20
+		diskHistoryLength = 10
+	)
+
+	config := NewConfig()
+	config.HistoryLength = 2
+	config.DiskHistoryLength = diskHistoryLength
+	baseDB := memdb.New()
+	db, err := newDB(t.Context(), baseDB, config)
+	require.NoError(err)
+
+	// The expected proofs are generated from the in-memory history.
+	expectedConfig := NewConfig()
+	expectedConfig.HistoryLength = numCommits + 1
+	expectedDB, err := newDB(t.Context(), memdb.New(), expectedConfig)
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	roots := make([]ids.ID, 0, numCommits)
+	for range numCommits {
+		ops := newRandomBatchOps(r, 50)
+		root := commitOps(t, db, ops)
+		require.Equal(commitOps(t, expectedDB, ops), root)
+		roots = append(roots, root)
+	}
+
+	// Roots are still available after reopening the database.
+	require.NoError(db.Close())
+	db, err = newDB(t.Context(), baseDB, config)
+	require.NoError(err)
+
+	var (
+		start = maybe.Some([]byte{0x40})
+		end   = maybe.Some([]byte{0xc0})
+	)
+	for i, root := range roots[:numCommits-diskHistoryLength] {
+		_, err := db.GetRangeProofAtRoot(t.Context(), root, start, end, 100)
+		require.ErrorIs(err, sync.ErrInsufficientHistory, "root %d", i)
+	}
+	for i, root := range roots[numCommits-diskHistoryLength:] {
+		proof, err := db.GetRangeProofAtRoot(t.Context(), root, start, end, 100)
+		require.NoError(err, "root %d", i)
+		expectedProof, err := expectedDB.GetRangeProofAtRoot(t.Context(), root, start, end, 100)
+		require.NoError(err)
+		require.Equal(expectedProof, proof)
+		require.NoError(proof.Verify(t.Context(), start, end, root, db.tokenSize, db.hasher, len(proof.KeyChanges)))
+	}
+
+	startRoot := roots[numCommits-diskHistoryLength]
+	for _, endRoot := range roots[numCommits-diskHistoryLength+1:] {
+		for _, maxLength := range []int{1, 5, 100} {
+			proof, err := db.GetChangeProof(t.Context(), startRoot, endRoot, start, end, maxLength)
+			require.NoError(err)
+			expectedProof, err := expectedDB.GetChangeProof(t.Context(), startRoot, endRoot, start, end, maxLength)
+			require.NoError(err)
+			require.Equal(expectedProof, proof)
+		}
+	}
+
+	_, err = db.GetChangeProof(t.Context(), roots[0], roots[numCommits-1], start, end, 100)
+	require.ErrorIs(err, sync.ErrInsufficientHistory)
+	_, err = db.GetChangeProof(t.Context(), startRoot, ids.GenerateTestID(), start, end, 100)
+	require.ErrorIs(err, sync.ErrNoEndRoot)
+}
+
+func Test_DiskHistory_Reset(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	baseDB := memdb.New()
+	db, err := newDB(t.Context(), baseDB, NewConfig())
+	require.NoError(err)
+	oldRoot := commitOps(t, db, newRandomBatchOps(r, 50))
+	require.NoError(db.Close())
+
+	// Enabling the disk history snapshots the current trie.
+	config := NewConfig()
+	config.HistoryLength = 1
+	config.DiskHistoryLength = 5
+	db, err = newDB(t.Context(), baseDB, config)
+	require.NoError(err)
+	snapshotRoot := commitOps(t, db, newRandomBatchOps(r, 50))
+	require.NoError(db.Close())
+
+	// Changes committed while the disk history is disabled invalidate it.
+	db, err = newDB(t.Context(), baseDB, NewConfig())
+	require.NoError(err)
+	commitOps(t, db, newRandomBatchOps(r, 50))
+	require.NoError(db.Close())
+
+	db, err = newDB(t.Context(), baseDB, config)
+	require.NoError(err)
+	newRoot := commitOps(t, db, newRandomBatchOps(r, 50))
+	require.NotEqual(oldRoot, newRoot)
+
+	for _, root := range []ids.ID{oldRoot, snapshotRoot} {
+		_, err = db.GetRangeProofAtRoot(t.Context(), root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+		require.ErrorIs(err, sync.ErrInsufficientHistory)
+	}
+	_, err = db.GetRangeProofAtRoot(t.Context(), newRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+
+	// Clearing the database clears the disk history.
+	require.NoError(db.Clear())
+	_, err = db.GetRangeProofAtRoot(t.Context(), newRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.ErrorIs(err, sync.ErrInsufficientHistory)
+	require.NoError(db.Close())
+}
+
+func Test_DiskHistory_ReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	config := NewConfig()
+	config.HistoryLength = 1
+	config.DiskHistoryLength = 5
+	readOnlyConfig := config
+	readOnlyConfig.ReadOnly = true
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	baseDB := memdb.New()
+	db, err := newDB(t.Context(), baseDB, config)
+	require.NoError(err)
+	oldRoot := commitOps(t, db, newRandomBatchOps(r, 50))
+	newRoot := commitOps(t, db, newRandomBatchOps(r, 50))
+
+	// The database must be shut down cleanly to be opened read-only.
+	_, err = newDB(t.Context(), baseDB, readOnlyConfig)
+	require.ErrorIs(err, errNotCleanlyShutdown)
+	require.NoError(db.Close())
+
+	before := dumpDB(t, baseDB)
+	db, err = newDB(t.Context(), baseDB, readOnlyConfig)
+	require.NoError(err)
+
+	root, err := db.GetMerkleRoot(t.Context())
+	require.NoError(err)
+	require.Equal(newRoot, root)
+	_, err = db.GetRangeProofAtRoot(t.Context(), oldRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+
+	view, err := db.NewView(t.Context(), ViewChanges{BatchOps: newRandomBatchOps(r, 50)})
+	require.NoError(err)
+	require.ErrorIs(view.CommitToDB(t.Context()), ErrReadOnly)
+	require.ErrorIs(db.Clear(), ErrReadOnly)
+	require.NoError(db.Close())
+	require.Equal(before, dumpDB(t, baseDB))
+
+	// Changes committed while the disk history is disabled invalidate it.
+	db, err = newDB(t.Context(), baseDB, NewConfig())
+	require.NoError(err)
+	commitOps(t, db, newRandomBatchOps(r, 50))
+	require.NoError(db.Close())
+
+	_, err = newDB(t.Context(), baseDB, readOnlyConfig)
+	require.ErrorIs(err, errDiskHistoryMismatch)
+
+	// The disk history isn't created by a read-only database.
+	emptyDB := memdb.New()
+	db, err = newDB(t.Context(), emptyDB, NewConfig())
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	_, err = newDB(t.Context(), emptyDB, readOnlyConfig)
+	require.ErrorIs(err, errNoDiskHistory)
+}
+
+func dumpDB(t *testing.T, db database.Database) map[string][]byte {
+	it := db.NewIterator()
+	defer it.Release()
+
+	kvs := make(map[string][]byte)
+	for it.Next() {
+		kvs[string(it.Key())] = it.Value()
+	}
+	require.NoError(t, it.Error())
+	return kvs
+}
+
+func Test_DiskHistory_ChangedKeyIterator(t *testing.T) {
+	h, err := newDiskHistory(memdb.New(), 10, false, DefaultHasher, BranchFactorToTokenSize[BranchFactor16], logging.NoLog{}, &mockMetrics{})
+	require.NoError(t, err)
+
+	changedKeys := [][]string{
+		1: {"a", "c", "e"},
+		2: {},
+		3: {"b", "c", "f"},
+		4: {"a", "f", "g"},
+	}
+	for height, keys := range changedKeys[1:] {
+		w := codecWriter{}
+		w.Uvarint(uint64(len(keys)))
+		for _, key := range keys {
+			w.Key(ToKey([]byte(key)))
+		}
+		require.NoError(t, h.db.Put(heightKey(historyKeysPrefix, uint64(height+1)), w.b))
+	}
+
+	tests := []struct {
+		name      string
+		minHeight uint64
+		maxHeight uint64
+		start     maybe.Maybe[[]byte]
+		end       maybe.Maybe[[]byte]
+		expected  []string
+	}{
+		{
+			name:      "all heights",
+			minHeight: 1,
+			maxHeight: 4,
+			expected:  []string{"a", "b", "c", "e", "f", "g"},
+		},
+		{
+			name:      "single height",
+			minHeight: 3,
+			maxHeight: 3,
+			expected:  []string{"b", "c", "f"},
+		},
+		{
+			name:      "no changes",
+			minHeight: 2,
+			maxHeight: 2,
+		},
+		{
+			name:      "bounded",
+			minHeight: 1,
+			maxHeight: 4,
+			start:     maybe.Some([]byte("b")),
+			end:       maybe.Some([]byte("f")),
+			expected:  []string{"b", "c", "e", "f"},
+		},
+		{
+			name:      "bounds between keys",
+			minHeight: 1,
+			maxHeight: 3,
+			start:     maybe.Some([]byte("bb")),
+			end:       maybe.Some([]byte("d")),
+			expected:  []string{"c"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			it, err := h.newChangedKeyIterator(test.minHeight, test.maxHeight, test.start, test.end)
+			require.NoError(err)
+
+			var keys []string
+			for {
+				key, ok, err := it.next()
+				require.NoError(err)
+				if !ok {
+					break
+				}
+				keys = append(keys, string(key.Bytes()))
+			}
+			require.Equal(test.expected, keys)
+		})
+	}
+	require.NoError(t, h.close())
+}
+
+func Test_DiskHistory_RecordFailure(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	metrics := &mockMetrics{}
+	h, err := newDiskHistory(baseDB, 10, false, DefaultHasher, BranchFactorToTokenSize[BranchFactor16], logging.NoLog{}, metrics)
+	require.NoError(err)
+	require.NoError(baseDB.Close())
+
+	h.record(newChangeSummary(0))
+	require.ErrorIs(h.err, errDiskHistoryFailed)
+	require.ErrorIs(h.err, database.ErrClosed)
+	require.Equal(int64(1), metrics.diskHistoryFailures)
+
+	// The history is disabled, so later changes aren't recorded.
+	h.record(newChangeSummary(0))
+	require.Equal(int64(1), metrics.diskHistoryFailures)
+}
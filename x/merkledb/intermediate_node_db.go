@@ -8,6 +8,7 @@ import (
 	"github.com/ava-labs/avalanchego/cache/lru"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/set"
 )
 
 // Holds intermediate nodes. That is, those without values.
@@ -35,6 +36,10 @@ type intermediateNodeDB struct {
 	metrics           metrics
 	tokenSize         int
 	hasher            Hasher
+
+	// Tracks the subtrees whose intermediate nodes are in [writeBuffer] and
+	// are marked as dirty on disk.
+	subtrees *subtreeTracker
 }
 
 func newIntermediateNodeDB(
@@ -55,6 +60,7 @@ func newIntermediateNodeDB(
 		tokenSize:         tokenSize,
 		hasher:            hasher,
 		nodeCache:         lru.NewSizedCache(cacheSize, cacheEntrySize),
+		subtrees:          newSubtreeTracker(),
 	}
 	result.writeBuffer = newOnEvictCache(
 		writeBufferSize,
@@ -67,8 +73,13 @@ func newIntermediateNodeDB(
 
 // A non-nil error is considered fatal and closes [db.baseDB].
 func (db *intermediateNodeDB) onEviction(key Key, n *node) error {
-	writeBatch := db.baseDB.NewBatch()
-	totalSize := cacheEntrySize(key, n)
+	var (
+		writeBatch  = db.baseDB.NewBatch()
+		totalSize   = cacheEntrySize(key, n)
+		writtenKeys = []Key{key}
+		subtrees    set.Set[string]
+	)
+	addSubtree(subtrees, key)
 	if err := db.addToBatch(writeBatch, key, n); err != nil {
 		_ = db.baseDB.Close()
 		return err
@@ -86,15 +97,27 @@ func (db *intermediateNodeDB) onEviction(key Key, n *node) error {
 			break
 		}
 		totalSize += cacheEntrySize(key, n)
+		writtenKeys = append(writtenKeys, key)
+		addSubtree(subtrees, key)
 		if err := db.addToBatch(writeBatch, key, n); err != nil {
 			_ = db.baseDB.Close()
 			return err
 		}
 	}
+
+	// The subtrees must be marked as dirty before their intermediate nodes
+	// are written, because their values may not have been written yet.
+	var update markerUpdate
+	if err := db.subtrees.mark(writeBatch, subtrees, &update); err != nil {
+		_ = db.baseDB.Close()
+		return err
+	}
 	if err := writeBatch.Write(); err != nil {
 		_ = db.baseDB.Close()
 		return err
 	}
+	db.subtrees.apply(&update)
+	db.subtrees.remove(writtenKeys)
 	return nil
 }
 
@@ -170,6 +193,7 @@ func (db *intermediateNodeDB) constructDBKey(key Key) *[]byte {
 
 func (db *intermediateNodeDB) Put(key Key, n *node) error {
 	db.nodeCache.Put(key, n)
+	db.trackBuffered(key)
 	return db.writeBuffer.Put(key, n)
 }
 
@@ -180,9 +204,18 @@ func (db *intermediateNodeDB) Flush() error {
 
 func (db *intermediateNodeDB) Delete(key Key) error {
 	db.nodeCache.Put(key, nil)
+	db.trackBuffered(key)
 	return db.writeBuffer.Put(key, nil)
 }
 
+// trackBuffered records that [key] is about to be added to the write buffer.
+// This must happen before the key is added, because adding it may evict it.
+func (db *intermediateNodeDB) trackBuffered(key Key) {
+	if _, ok := db.writeBuffer.Get(key); !ok {
+		db.subtrees.add(key)
+	}
+}
+
 func (db *intermediateNodeDB) Clear() error {
 	db.nodeCache.Flush()
 
@@ -193,5 +226,9 @@ func (db *intermediateNodeDB) Clear() error {
 		db.writeBuffer.size,
 		db.writeBuffer.onEviction,
 	)
-	return database.AtomicClearPrefix(db.baseDB, db.baseDB, intermediateNodePrefix)
+	db.subtrees.reset()
+	if err := database.AtomicClearPrefix(db.baseDB, db.baseDB, intermediateNodePrefix); err != nil {
+		return err
+	}
+	return database.AtomicClearPrefix(db.baseDB, db.baseDB, dirtySubtreePrefix)
 }
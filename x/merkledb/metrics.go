@@ -26,6 +26,10 @@ const (
 	lookupResult = "result"
 	hitResult    = "hit"
 	missResult   = "miss"
+
+	rebuildResult = "result"
+	reusedResult  = "reused"
+	rebuiltResult = "rebuilt"
 )
 
 var (
@@ -73,6 +77,14 @@ var (
 		lookupType:   viewChangesNodeType,
 		lookupResult: missResult,
 	}
+
+	rebuildLabels        = []string{rebuildResult}
+	subtreesReusedLabels = prometheus.Labels{
+		rebuildResult: reusedResult,
+	}
+	subtreesRebuiltLabels = prometheus.Labels{
+		rebuildResult: rebuiltResult,
+	}
 )
 
 type metrics interface {
@@ -87,12 +99,21 @@ type metrics interface {
 	ViewChangesValueMiss()
 	ViewChangesNodeHit()
 	ViewChangesNodeMiss()
+	SubtreesReused(count int)
+	SubtreesRebuilt(count int)
+	DiskHistoryFailed()
 }
 
 type prometheusMetrics struct {
 	hashes prometheus.Counter
 	io     *prometheus.CounterVec
 	lookup *prometheus.CounterVec
+	// The number of subtrees whose intermediate nodes were reused or rebuilt
+	// after an unclean shutdown.
+	rebuild *prometheus.CounterVec
+	// The number of times the disk history failed to record changes and was
+	// disabled.
+	diskHistoryFailures prometheus.Counter
 }
 
 func newMetrics(prefix string, reg prometheus.Registerer) (metrics, error) {
@@ -118,11 +139,23 @@ func newMetrics(prefix string, reg prometheus.Registerer) (metrics, error) {
 			Name:      "lookup",
 			Help:      "cumulative number of in-memory lookups performed",
 		}, lookupLabels),
+		rebuild: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rebuild_subtrees",
+			Help:      "cumulative number of subtrees reused or rebuilt after an unclean shutdown",
+		}, rebuildLabels),
+		diskHistoryFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "disk_history_failures",
+			Help:      "cumulative number of times the disk history failed to record changes and was disabled",
+		}),
 	}
 	err := errors.Join(
 		reg.Register(m.hashes),
 		reg.Register(m.io),
 		reg.Register(m.lookup),
+		reg.Register(m.rebuild),
+		reg.Register(m.diskHistoryFailures),
 	)
 	return &m, err
 }
@@ -171,6 +204,18 @@ func (m *prometheusMetrics) ViewChangesNodeMiss() {
 	m.lookup.With(viewChangesNodeMissLabels).Inc()
 }
 
+func (m *prometheusMetrics) SubtreesReused(count int) {
+	m.rebuild.With(subtreesReusedLabels).Add(float64(count))
+}
+
+func (m *prometheusMetrics) SubtreesRebuilt(count int) {
+	m.rebuild.With(subtreesRebuiltLabels).Add(float64(count))
+}
+
+func (m *prometheusMetrics) DiskHistoryFailed() {
+	m.diskHistoryFailures.Inc()
+}
+
 type mockMetrics struct {
 	lock                      sync.Mutex
 	hashCount                 int64
@@ -184,6 +229,9 @@ type mockMetrics struct {
 	viewChangesValueMiss      int64
 	viewChangesNodeHit        int64
 	viewChangesNodeMiss       int64
+	subtreesReused            int64
+	subtreesRebuilt           int64
+	diskHistoryFailures       int64
 }
 
 func (m *mockMetrics) HashCalculated() {
@@ -262,3 +310,24 @@ func (m *mockMetrics) ViewChangesNodeMiss() {
 
 	m.viewChangesNodeMiss++
 }
+
+func (m *mockMetrics) SubtreesReused(count int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.subtreesReused += int64(count)
+}
+
+func (m *mockMetrics) SubtreesRebuilt(count int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.subtreesRebuilt += int64(count)
+}
+
+func (m *mockMetrics) DiskHistoryFailed() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.diskHistoryFailures++
+}
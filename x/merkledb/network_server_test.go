@@ -17,6 +17,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/maybe"
 
 	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
 )
@@ -30,6 +31,7 @@ func Test_Server_GetRangeProof(t *testing.T) {
 	require.NoError(t, err)
 	smallTrieRoot, err := smallTrieDB.GetMerkleRoot(t.Context())
 	require.NoError(t, err)
+	fakeRootID := ids.GenerateTestID()
 
 	tests := []struct {
 		name                     string
@@ -48,7 +50,7 @@ func Test_Server_GetRangeProof(t *testing.T) {
 				BytesLimit: 1000,
 			},
 			proofNil:    true,
-			expectedErr: p2p.ErrUnexpected,
+			expectedErr: sync.ErrRangeTooLarge,
 		},
 		{
 			name: "byteslimit is 0",
@@ -100,6 +102,26 @@ func Test_Server_GetRangeProof(t *testing.T) {
 			},
 			expectedMaxResponseBytes: sync.DefaultRequestByteSizeLimit,
 		},
+		{
+			name: "key-only proof",
+			request: &pb.RangeProofRequest{
+				RootHash:   smallTrieRoot[:],
+				KeyLimit:   sync.DefaultRequestKeyLimit,
+				BytesLimit: sync.DefaultRequestByteSizeLimit,
+				KeyOnly:    true,
+			},
+			expectedResponseLen: sync.DefaultRequestKeyLimit,
+		},
+		{
+			name: "root not available",
+			request: &pb.RangeProofRequest{
+				RootHash:   fakeRootID[:],
+				KeyLimit:   sync.DefaultRequestKeyLimit,
+				BytesLimit: sync.DefaultRequestByteSizeLimit,
+			},
+			proofNil:    true,
+			expectedErr: sync.ErrRootNotAvailable,
+		},
 		{
 			name: "empty proof",
 			request: &pb.RangeProofRequest{
@@ -139,6 +161,16 @@ func Test_Server_GetRangeProof(t *testing.T) {
 			if test.expectedResponseLen > 0 {
 				require.LessOrEqual(len(proof.KeyChanges), test.expectedResponseLen)
 			}
+			if test.request.KeyOnly {
+				require.NoError(smallTrieDB.VerifyKeyOnlyRangeProof(
+					t.Context(),
+					proof,
+					maybe.Nothing[[]byte](),
+					maybe.Nothing[[]byte](),
+					smallTrieRoot,
+					len(proof.KeyChanges),
+				))
+			}
 
 			bytes, err := rangeProofMarshaler.Marshal(proof)
 			require.NoError(err)
@@ -321,7 +353,7 @@ func Test_Server_GetChangeProof(t *testing.T) {
 				BytesLimit:    sync.DefaultRequestByteSizeLimit,
 			},
 			expectedMaxResponseBytes: sync.DefaultRequestByteSizeLimit,
-			expectedErr:              p2p.ErrUnexpected,
+			expectedErr:              sync.ErrRootNotAvailable,
 		},
 		{
 			name: "empty proof",
@@ -71,12 +71,18 @@ func (n *node) setValue(hasher Hasher, val maybe.Maybe[[]byte]) {
 }
 
 func (n *node) setValueDigest(hasher Hasher) {
-	if n.value.IsNothing() || len(n.value.Value()) < HashLength {
-		n.valueDigest = n.value
-	} else {
-		hash := hasher.HashValue(n.value.Value())
-		n.valueDigest = maybe.Some(hash[:])
+	n.valueDigest = valueDigest(hasher, n.value)
+}
+
+// Returns the digest of [value] that is included in the hash of the node
+// storing it.
+// Values shorter than [HashLength] are their own digest.
+func valueDigest(hasher Hasher, value maybe.Maybe[[]byte]) maybe.Maybe[[]byte] {
+	if value.IsNothing() || len(value.Value()) < HashLength {
+		return value
 	}
+	hash := hasher.HashValue(value.Value())
+	return maybe.Some(hash[:])
 }
 
 // Adds [child] as a child of [n].
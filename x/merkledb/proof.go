@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 
 	"google.golang.org/protobuf/proto"
 
@@ -49,6 +50,10 @@ var (
 	ErrProofNodeHasUnincludedValue   = errors.New("the provided proof has a value for a key within the range that is not present in the provided key/values")
 	ErrUnexpectedEndProof            = errors.New("end proof should be empty")
 	ErrUnexpectedStartProof          = errors.New("start proof should be empty")
+	ErrUnsortedProofNodes            = errors.New("proof nodes are not sorted by increasing key")
+	ErrDisconnectedProofNode         = errors.New("proof node is not a descendant of the root")
+	ErrConflictingProofNodeChildren  = errors.New("multiple children at the same index of a proof node")
+	ErrMissingProofNode              = errors.New("proof is missing a node on the path to a key")
 	errNilProofNode                  = errors.New("proof node is nil")
 	errNilKey                        = errors.New("key is nil")
 	errInvalidKeyLength              = errors.New("key length doesn't match bytes length, check specified branchFactor")
@@ -181,6 +186,226 @@ func (proof *Proof) Verify(
 	return nil
 }
 
+// MultiProof represents an inclusion/exclusion proof of a set of keys.
+// Nodes that are on the paths to multiple keys are only included once.
+type MultiProof struct {
+	// The nodes in the proof paths of [KeyValues], sorted by increasing key and
+	// with no duplicate keys.
+	// Always contains at least the root.
+	//
+	// The children of a node that are themselves in [Nodes] are omitted from its
+	// [ProofNode.Children], since their IDs are calculated during verification.
+	Nodes []ProofNode
+
+	// The keys that this is a proof of, sorted by increasing key and with no
+	// duplicate keys.
+	// [KeyChange.Value] is Nothing if the key isn't in the trie.
+	// Otherwise, it's the value corresponding to the key.
+	KeyValues []KeyChange
+}
+
+// Verify returns nil if the trie given in [proof] has root [expectedRootID].
+// That is, this is a valid proof that each key in [proof.KeyValues]
+// exists/doesn't exist in the trie with root [expectedRootID].
+func (proof *MultiProof) Verify(
+	expectedRootID ids.ID,
+	tokenSize int,
+	hasher Hasher,
+) error {
+	// Make sure the proof is well-formed.
+	if len(proof.Nodes) == 0 {
+		return ErrEmptyProof
+	}
+
+	// [children][i] maps a child index of [proof.Nodes][i] to the index of the
+	// child in [proof.Nodes], if the child is in the proof.
+	var (
+		children = make([]map[byte]int, len(proof.Nodes))
+		// Stack of the indices of the ancestors of the current node.
+		ancestors = make([]int, 0, len(proof.Nodes))
+	)
+	for i, proofNode := range proof.Nodes {
+		// Because the interface only supports []byte keys,
+		// a key with a partial byte may not store a value
+		if proofNode.Key.hasPartialByte() && proofNode.ValueOrHash.HasValue() {
+			return ErrPartialByteLengthWithValue
+		}
+		if i > 0 && proof.Nodes[i-1].Key.Compare(proofNode.Key) >= 0 {
+			return ErrUnsortedProofNodes
+		}
+
+		// The nodes are sorted, so the parent of [proofNode] is its closest
+		// ancestor on the stack.
+		for len(ancestors) > 0 && !proofNode.Key.HasStrictPrefix(proof.Nodes[ancestors[len(ancestors)-1]].Key) {
+			ancestors = ancestors[:len(ancestors)-1]
+		}
+		if len(ancestors) == 0 && i > 0 {
+			return ErrDisconnectedProofNode
+		}
+		if len(ancestors) > 0 {
+			parentIndex := ancestors[len(ancestors)-1]
+			parent := proof.Nodes[parentIndex]
+			index := proofNode.Key.Token(parent.Key.length, tokenSize)
+			if _, ok := parent.Children[index]; ok {
+				return ErrConflictingProofNodeChildren
+			}
+			if _, ok := children[parentIndex][index]; ok {
+				return ErrConflictingProofNodeChildren
+			}
+			if children[parentIndex] == nil {
+				children[parentIndex] = make(map[byte]int)
+			}
+			children[parentIndex][index] = i
+		}
+		ancestors = append(ancestors, i)
+	}
+
+	// Calculate the node IDs from the bottom up. Each node is after its
+	// ancestors in [proof.Nodes].
+	nodeIDs := make([]ids.ID, len(proof.Nodes))
+	for i := len(proof.Nodes) - 1; i >= 0; i-- {
+		proofNode := proof.Nodes[i]
+		n := newNode(proofNode.Key)
+		n.valueDigest = proofNode.ValueOrHash
+		for index, childID := range proofNode.Children {
+			n.children[index] = &child{id: childID}
+		}
+		for index, childIndex := range children[i] {
+			n.children[index] = &child{id: nodeIDs[childIndex]}
+		}
+		nodeIDs[i] = hasher.HashNode(n)
+	}
+	if gotRootID := nodeIDs[0]; expectedRootID != gotRootID {
+		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, gotRootID, expectedRootID)
+	}
+
+	// [visited][i] is true if [proof.Nodes][i] is needed to prove a key.
+	visited := make([]bool, len(proof.Nodes))
+	visited[0] = true
+	for i, keyValue := range proof.KeyValues {
+		if i > 0 && bytes.Compare(proof.KeyValues[i-1].Key, keyValue.Key) >= 0 {
+			return ErrNonIncreasingValues
+		}
+
+		valueOrHash, included, err := proof.getValueOrHash(ToKey(keyValue.Key), children, visited, tokenSize)
+		if err != nil {
+			return err
+		}
+		if included && !valueOrHashMatches(hasher, keyValue.Value, valueOrHash) {
+			return ErrProofValueDoesntMatch
+		}
+		if !included && keyValue.Value.HasValue() {
+			return ErrExclusionProofUnexpectedValue
+		}
+	}
+
+	if slices.Contains(visited, false) {
+		return ErrExtraProofNodes
+	}
+	return nil
+}
+
+// getValueOrHash returns the [ProofNode.ValueOrHash] of the node with [key]
+// and true, or false if [proof] shows that there is no node with [key].
+// Marks the nodes on the path to [key] in [visited].
+// [children] is the child index to node index map of each node in [proof].
+func (proof *MultiProof) getValueOrHash(
+	key Key,
+	children []map[byte]int,
+	visited []bool,
+	tokenSize int,
+) (maybe.Maybe[[]byte], bool, error) {
+	// No key in the trie is a prefix of [key].
+	if !key.HasPrefix(proof.Nodes[0].Key) {
+		return maybe.Nothing[[]byte](), false, nil
+	}
+
+	// Walk from the root toward [key]. Each node's key is a prefix of [key].
+	current := 0
+	for {
+		proofNode := proof.Nodes[current]
+		if proofNode.Key == key {
+			return proofNode.ValueOrHash, true, nil
+		}
+
+		index := key.Token(proofNode.Key.length, tokenSize)
+		childIndex, ok := children[current][index]
+		if !ok {
+			if _, ok := proofNode.Children[index]; ok {
+				// The child along the path to [key] must be in the proof.
+				return maybe.Nothing[[]byte](), false, ErrMissingProofNode
+			}
+			// There's no child along the path to [key].
+			return maybe.Nothing[[]byte](), false, nil
+		}
+
+		visited[childIndex] = true
+		if !key.HasPrefix(proof.Nodes[childIndex].Key) {
+			// The child along the path to [key] diverges from [key].
+			return maybe.Nothing[[]byte](), false, nil
+		}
+		current = childIndex
+	}
+}
+
+type MultiProofMarshaler struct{}
+
+func (MultiProofMarshaler) Marshal(proof *MultiProof) ([]byte, error) {
+	return proto.Marshal(proof.toProto())
+}
+
+func (MultiProofMarshaler) Unmarshal(data []byte) (*MultiProof, error) {
+	var pbMultiProof pb.MultiProof
+	if err := proto.Unmarshal(data, &pbMultiProof); err != nil {
+		return nil, err
+	}
+
+	var proof MultiProof
+	if err := proof.unmarshalProto(&pbMultiProof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+func (proof *MultiProof) toProto() *pb.MultiProof {
+	nodes := make([]*pb.ProofNode, len(proof.Nodes))
+	for i, node := range proof.Nodes {
+		nodes[i] = node.toProto()
+	}
+
+	keyValues := make([]*pb.KeyChange, len(proof.KeyValues))
+	for i, kv := range proof.KeyValues {
+		keyValues[i] = &pb.KeyChange{
+			Key:   kv.Key,
+			Value: protoutils.MaybeToProto(kv.Value),
+		}
+	}
+
+	return &pb.MultiProof{
+		Nodes:     nodes,
+		KeyValues: keyValues,
+	}
+}
+
+func (proof *MultiProof) unmarshalProto(pbProof *pb.MultiProof) error {
+	proof.Nodes = make([]ProofNode, len(pbProof.Nodes))
+	for i, protoNode := range pbProof.Nodes {
+		if err := proof.Nodes[i].unmarshalProto(protoNode); err != nil {
+			return err
+		}
+	}
+
+	proof.KeyValues = make([]KeyChange, len(pbProof.KeyValues))
+	for i, kv := range pbProof.KeyValues {
+		proof.KeyValues[i] = KeyChange{
+			Key:   kv.Key,
+			Value: protoutils.ProtoToMaybe(kv.Value),
+		}
+	}
+
+	return nil
+}
+
 type RangeProof ChangeProof
 
 type RangeProofMarshaler struct{}
@@ -316,6 +541,41 @@ func (r *RangeProof) Verify(
 	tokenSize int,
 	hasher Hasher,
 	maxLength int,
+) error {
+	return r.verify(ctx, start, end, expectedRootID, tokenSize, hasher, maxLength, false /*keyOnly*/)
+}
+
+// VerifyKeyOnly is the same as Verify, except that the values in
+// [proof.KeyValues] are value digests rather than values.
+//
+// A value shorter than [HashLength] is its own digest. Otherwise its digest
+// is its hash. This allows the keys in a range, and the sizes of the values
+// which are their own digests, to be verified without transferring the
+// larger values.
+//
+// The sizes of values that are at least [HashLength] bytes are not verified,
+// because their digests don't commit to their lengths.
+func (r *RangeProof) VerifyKeyOnly(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	expectedRootID ids.ID,
+	tokenSize int,
+	hasher Hasher,
+	maxLength int,
+) error {
+	return r.verify(ctx, start, end, expectedRootID, tokenSize, hasher, maxLength, true /*keyOnly*/)
+}
+
+func (r *RangeProof) verify(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	expectedRootID ids.ID,
+	tokenSize int,
+	hasher Hasher,
+	maxLength int,
+	keyOnly bool,
 ) error {
 	db, err := newDatabase(
 		ctx,
@@ -335,7 +595,7 @@ func (r *RangeProof) Verify(
 		return err
 	}
 
-	return db.VerifyChangeProof(ctx, (*ChangeProof)(r), start, end, expectedRootID, maxLength)
+	return db.verifyChangeProof(ctx, (*ChangeProof)(r), start, end, expectedRootID, maxLength, keyOnly)
 }
 
 type KeyChange struct {
@@ -479,7 +739,8 @@ func (c *ChangeProof) unmarshalProto(pbProof *pb.ChangeProof) error {
 
 // Verifies that the given [proofNodes]:
 // - if the node's key is within the key range, that has a value that matches the value passed in the change list or in the db.
-func verifyChangeProofKeyValues(ctx context.Context, db *merkleDB, keyChanges []KeyChange, proofNodes []ProofNode, start maybe.Maybe[Key], end maybe.Maybe[Key], hasher Hasher) error {
+// If [keyOnly], the values in [keyChanges] are value digests.
+func verifyChangeProofKeyValues(ctx context.Context, db *merkleDB, keyChanges []KeyChange, proofNodes []ProofNode, start maybe.Maybe[Key], end maybe.Maybe[Key], hasher Hasher, keyOnly bool) error {
 	keyChangesMap := map[Key]maybe.Maybe[[]byte]{}
 	for _, kc := range keyChanges {
 		keyChangesMap[ToKey(kc.Key)] = kc.Value
@@ -500,6 +761,9 @@ func verifyChangeProofKeyValues(ctx context.Context, db *merkleDB, keyChanges []
 			return maybe.Nothing[[]byte](), err
 		}
 
+		if keyOnly {
+			return valueDigest(hasher, maybe.Some(dbValue)), nil
+		}
 		return maybe.Some(dbValue), nil
 	}
 
@@ -518,6 +782,13 @@ func verifyChangeProofKeyValues(ctx context.Context, db *merkleDB, keyChanges []
 				return ErrProofNodeHasUnincludedValue
 			}
 
+			if keyOnly {
+				if !maybe.Equal(value, proofNode.ValueOrHash, bytes.Equal) {
+					return ErrProofValueDoesntMatch
+				}
+				continue
+			}
+
 			if value.HasValue() && !valueOrHashMatches(hasher, value, proofNode.ValueOrHash) {
 				return ErrProofValueDoesntMatch
 			}
@@ -729,6 +1000,29 @@ func addPathInfo(
 	return nil
 }
 
+// Inserts each key in [keyChanges] into [v] with the value digest given in
+// [keyChanges], since the values themselves aren't known.
+// Assumes [v.lock] is held.
+func addKeyOnlyChanges(v *view, keyChanges []KeyChange) error {
+	for _, kc := range keyChanges {
+		if kc.Value.IsNothing() {
+			// There is nothing to delete from the standalone view.
+			continue
+		}
+		digest := kc.Value.Value()
+		if len(digest) > HashLength {
+			return fmt.Errorf("%w: digest length %d", ErrProofValueDoesntMatch, len(digest))
+		}
+
+		n, err := v.insert(ToKey(kc.Key), maybe.Nothing[[]byte]())
+		if err != nil {
+			return err
+		}
+		n.valueDigest = maybe.Some(digest)
+	}
+	return nil
+}
+
 // getStandaloneView returns a new view that has nothing in it besides the changes due to [ops]
 func getStandaloneView(ctx context.Context, ops []database.BatchOp, size int) (*view, error) {
 	db, err := newDatabase(
@@ -6,6 +6,7 @@ package merkledb
 import (
 	"bytes"
 	"math/rand"
+	"slices"
 	"testing"
 	"time"
 
@@ -224,6 +225,260 @@ func Test_Proof_ValueOrHashMatches(t *testing.T) {
 	require.False(valueOrHashMatches(SHA256Hasher, maybe.Some(hashing.ComputeHash256([]byte{0})), maybe.Nothing[[]byte]()))
 }
 
+func Test_MultiProof(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	writeBasicBatch(t, db)
+
+	keys := [][]byte{{4}, {0}, {5}, {2}, {0}, {0, 1}, {255}}
+	proof, err := db.GetMultiProof(t.Context(), keys)
+	require.NoError(err)
+	require.NoError(proof.Verify(db.getMerkleRoot(), db.tokenSize, db.hasher))
+
+	// The keys are sorted and deduplicated.
+	require.Equal(
+		[]KeyChange{
+			{Key: []byte{0}, Value: maybe.Some([]byte{0})},
+			{Key: []byte{0, 1}, Value: maybe.Nothing[[]byte]()},
+			{Key: []byte{2}, Value: maybe.Some([]byte{2})},
+			{Key: []byte{4}, Value: maybe.Some([]byte{4})},
+			{Key: []byte{5}, Value: maybe.Nothing[[]byte]()},
+			{Key: []byte{255}, Value: maybe.Nothing[[]byte]()},
+		},
+		proof.KeyValues,
+	)
+
+	// Each node of the single key proofs is included once.
+	pathLen := 0
+	nodeKeys := set.Set[Key]{}
+	for _, kv := range proof.KeyValues {
+		keyProof, err := db.GetProof(t.Context(), kv.Key)
+		require.NoError(err)
+		pathLen += len(keyProof.Path)
+		for _, proofNode := range keyProof.Path {
+			nodeKeys.Add(proofNode.Key)
+		}
+	}
+	require.Len(proof.Nodes, nodeKeys.Len())
+	require.Less(len(proof.Nodes), pathLen)
+	for _, proofNode := range proof.Nodes {
+		require.Contains(nodeKeys, proofNode.Key)
+	}
+
+	// The root alone is a proof of no keys.
+	proof, err = db.GetMultiProof(t.Context(), nil)
+	require.NoError(err)
+	require.Len(proof.Nodes, 1)
+	require.NoError(proof.Verify(db.getMerkleRoot(), db.tokenSize, db.hasher))
+}
+
+func Test_MultiProof_Empty(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	_, err = db.GetMultiProof(t.Context(), [][]byte{{0}})
+	require.ErrorIs(err, ErrEmptyProof)
+
+	proof := &MultiProof{}
+	require.ErrorIs(proof.Verify(ids.Empty, db.tokenSize, db.hasher), ErrEmptyProof)
+}
+
+func Test_MultiProof_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    [][]byte
+		modify  func(*MultiProof)
+		wantErr error
+	}{
+		{
+			name:   "happy path",
+			keys:   [][]byte{{0}, {1}, {5}},
+			modify: func(*MultiProof) {},
+		},
+		{
+			name: "wrong value",
+			keys: [][]byte{{0}, {1}},
+			modify: func(p *MultiProof) {
+				p.KeyValues[1].Value = maybe.Some([]byte{5})
+			},
+			wantErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "missing value",
+			keys: [][]byte{{0}, {1}},
+			modify: func(p *MultiProof) {
+				p.KeyValues[1].Value = maybe.Nothing[[]byte]()
+			},
+			wantErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "value for excluded key",
+			keys: [][]byte{{0}, {5}},
+			modify: func(p *MultiProof) {
+				p.KeyValues[1].Value = maybe.Some([]byte{5})
+			},
+			wantErr: ErrExclusionProofUnexpectedValue,
+		},
+		{
+			name: "unsorted keys",
+			keys: [][]byte{{0}, {1}},
+			modify: func(p *MultiProof) {
+				p.KeyValues[0], p.KeyValues[1] = p.KeyValues[1], p.KeyValues[0]
+			},
+			wantErr: ErrNonIncreasingValues,
+		},
+		{
+			name: "unsorted nodes",
+			keys: [][]byte{{0}, {1}},
+			modify: func(p *MultiProof) {
+				p.Nodes[1], p.Nodes[2] = p.Nodes[2], p.Nodes[1]
+			},
+			wantErr: ErrUnsortedProofNodes,
+		},
+		{
+			name: "key not in proof",
+			keys: [][]byte{{0}},
+			modify: func(p *MultiProof) {
+				p.KeyValues[0].Key = []byte{1}
+			},
+			wantErr: ErrMissingProofNode,
+		},
+		{
+			name: "key not proven",
+			keys: [][]byte{{0}, {1}},
+			modify: func(p *MultiProof) {
+				p.KeyValues = p.KeyValues[:1]
+			},
+			wantErr: ErrExtraProofNodes,
+		},
+		{
+			name: "child included twice",
+			keys: [][]byte{{0}},
+			modify: func(p *MultiProof) {
+				index := p.Nodes[1].Key.Token(p.Nodes[0].Key.length, 4)
+				p.Nodes[0].Children[index] = ids.GenerateTestID()
+			},
+			wantErr: ErrConflictingProofNodeChildren,
+		},
+		{
+			name: "node not under root",
+			keys: [][]byte{{0}},
+			modify: func(p *MultiProof) {
+				p.Nodes = append(p.Nodes, ProofNode{Key: ToKey([]byte{255})})
+			},
+			wantErr: ErrDisconnectedProofNode,
+		},
+		{
+			name: "modified node",
+			keys: [][]byte{{0}, {1}},
+			modify: func(p *MultiProof) {
+				p.Nodes[1].ValueOrHash = maybe.Some([]byte{1})
+				p.KeyValues[0].Value = maybe.Some([]byte{1})
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "missing node",
+			keys: [][]byte{{0}, {1}},
+			modify: func(p *MultiProof) {
+				p.Nodes = p.Nodes[:2]
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "partial byte key with value",
+			keys: [][]byte{{0}},
+			modify: func(p *MultiProof) {
+				p.Nodes[0].ValueOrHash = maybe.Some([]byte{0})
+			},
+			wantErr: ErrPartialByteLengthWithValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db, err := getBasicDB()
+			require.NoError(err)
+			writeBasicBatch(t, db)
+
+			proof, err := db.GetMultiProof(t.Context(), tt.keys)
+			require.NoError(err)
+
+			tt.modify(proof)
+
+			err = proof.Verify(db.getMerkleRoot(), db.tokenSize, db.hasher)
+			require.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func Test_RangeProof_KeyOnly(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	var (
+		shortValue = []byte{1}
+		longValue  = make([]byte, 2*HashLength)
+		longHash   = db.hasher.HashValue(longValue)
+	)
+	writer := db.NewBatch()
+	require.NoError(writer.Put([]byte{0}, shortValue))
+	require.NoError(writer.Put([]byte{0, 5}, longValue))
+	require.NoError(writer.Put([]byte{1}, longValue))
+	require.NoError(writer.Put([]byte{1, 2}, longValue))
+	require.NoError(writer.Put([]byte{2}, shortValue))
+	require.NoError(writer.Write())
+	root := db.getMerkleRoot()
+
+	var (
+		start = maybe.Some([]byte{0})
+		end   = maybe.Some([]byte{1, 2})
+	)
+	proof, err := db.GetKeyOnlyRangeProofAtRoot(t.Context(), root, start, end, 10)
+	require.NoError(err)
+	require.Equal(
+		[]KeyChange{
+			{Key: []byte{0}, Value: maybe.Some(shortValue)},
+			{Key: []byte{0, 5}, Value: maybe.Some(longHash[:])},
+			{Key: []byte{1}, Value: maybe.Some(longHash[:])},
+			{Key: []byte{1, 2}, Value: maybe.Some(longHash[:])},
+		},
+		proof.KeyChanges,
+	)
+	require.NoError(db.VerifyKeyOnlyRangeProof(t.Context(), proof, start, end, root, 10))
+
+	// The digests aren't the values.
+	err = db.VerifyRangeProof(t.Context(), proof, start, end, root, 10)
+	require.ErrorIs(err, ErrProofValueDoesntMatch)
+
+	// The values aren't the digests.
+	fullProof, err := db.GetRangeProofAtRoot(t.Context(), root, start, end, 10)
+	require.NoError(err)
+	require.NoError(db.VerifyRangeProof(t.Context(), fullProof, start, end, root, 10))
+	err = db.VerifyKeyOnlyRangeProof(t.Context(), fullProof, start, end, root, 10)
+	require.ErrorIs(err, ErrProofValueDoesntMatch)
+
+	// A modified digest is detected.
+	badDigest := slices.Clone(longHash[:])
+	badDigest[0]++
+	proof.KeyChanges[1].Value = maybe.Some(badDigest)
+	err = db.VerifyKeyOnlyRangeProof(t.Context(), proof, start, end, root, 10)
+	require.ErrorIs(err, ErrInvalidProof)
+
+	// A modified key set is detected.
+	proof.KeyChanges[1].Value = maybe.Some(longHash[:])
+	proof.KeyChanges = slices.Delete(proof.KeyChanges, 1, 2)
+	err = db.VerifyKeyOnlyRangeProof(t.Context(), proof, start, end, root, 10)
+	require.ErrorIs(err, ErrInvalidProof)
+}
+
 func Test_RangeProof_Extra_Value(t *testing.T) {
 	require := require.New(t)
 
@@ -1664,6 +1919,36 @@ func FuzzChangeProofProtoMarshalUnmarshal(f *testing.F) {
 	})
 }
 
+func FuzzMultiProofProtoMarshalUnmarshal(f *testing.F) {
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+	) {
+		require := require.New(t)
+		rand := rand.New(rand.NewSource(randSeed))
+
+		// Make a random multi-proof.
+		numNodes := rand.Intn(32)
+		nodes := make([]ProofNode, numNodes)
+		for i := 0; i < numNodes; i++ {
+			nodes[i] = newRandomProofNode(rand)
+		}
+
+		proof := &MultiProof{
+			Nodes:     nodes,
+			KeyValues: generateKeyChanges(rand, rand.Intn(128), true),
+		}
+
+		// Marshal and unmarshal it.
+		// Assert the unmarshaled one is the same as the original.
+		originalBytes, err := MultiProofMarshaler{}.Marshal(proof)
+		require.NoError(err)
+		unmarshaledProof, err := MultiProofMarshaler{}.Unmarshal(originalBytes)
+		require.NoError(err)
+		require.Equal(proof, unmarshaledProof)
+	})
+}
+
 func generateKeyChanges(rand *rand.Rand, numKeyChanges int, includeNone bool) []KeyChange {
 	keyChanges := make([]KeyChange, numKeyChanges)
 	for i := 0; i < numKeyChanges; i++ {
@@ -1887,6 +2172,68 @@ func FuzzProofVerification(f *testing.F) {
 	})
 }
 
+// Generate multi-proofs and verify that they are valid and prove the same
+// values as the single key proofs.
+func FuzzMultiProofVerification(f *testing.F) {
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+		numKeyValues uint,
+		numKeys uint,
+	) {
+		rand := rand.New(rand.NewSource(randSeed))
+		require := require.New(t)
+		db, err := getBasicDB()
+		require.NoError(err)
+
+		// Insert a bunch of random key values.
+		insertRandomKeyValues(
+			require,
+			rand,
+			[]database.Database{db},
+			numKeyValues%512,
+			0.25,
+		)
+
+		rootID, err := db.GetMerkleRoot(t.Context())
+		require.NoError(err)
+		if rootID == ids.Empty {
+			return
+		}
+
+		// Prove a mix of keys that are and aren't in the trie.
+		var keys [][]byte
+		iter := db.NewIterator()
+		for iter.Next() && uint(len(keys)) < numKeys%64 {
+			if rand.Intn(2) == 0 {
+				keys = append(keys, iter.Key())
+			} else {
+				key := make([]byte, rand.Intn(4))
+				_, _ = rand.Read(key)
+				keys = append(keys, key)
+			}
+		}
+		iter.Release()
+		require.NoError(iter.Error())
+
+		proof, err := db.GetMultiProof(t.Context(), keys)
+		require.NoError(err)
+
+		// The proof should survive a round trip through its encoding.
+		proofBytes, err := MultiProofMarshaler{}.Marshal(proof)
+		require.NoError(err)
+		proof, err = MultiProofMarshaler{}.Unmarshal(proofBytes)
+		require.NoError(err)
+		require.NoError(proof.Verify(rootID, db.tokenSize, db.hasher))
+
+		for _, kv := range proof.KeyValues {
+			keyProof, err := db.GetProof(t.Context(), kv.Key)
+			require.NoError(err)
+			require.True(maybe.Equal(keyProof.Value, kv.Value, bytes.Equal))
+		}
+	})
+}
+
 // Generate change proofs and verify that they are valid.
 func FuzzChangeProofVerification(f *testing.F) {
 	f.Fuzz(func(
@@ -0,0 +1,498 @@
+// Copyright (C) 2019, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// subtreeKeyLen is the number of key bytes that identify a subtree. Nodes
+// whose keys are shorter than this aren't part of any subtree and are always
+// recomputed when rebuilding.
+const subtreeKeyLen = 1
+
+var (
+	// subtreeJournalKey is written when the database is opened and removed
+	// when it is closed. If the database wasn't shutdown cleanly, its presence
+	// indicates that the dirty subtree markers are accurate and that only the
+	// dirty subtrees need to be rebuilt.
+	//
+	// A database that was last opened by a version that doesn't maintain the
+	// markers won't have this key, so all of its subtrees are rebuilt.
+	subtreeJournalKey = []byte(string(metadataPrefix) + "subtreeJournal")
+
+	// dirtySubtreePrefix is the prefix of the markers of subtrees whose
+	// intermediate nodes on disk may not match the values on disk.
+	dirtySubtreePrefix = []byte(string(metadataPrefix) + "dirtySubtree")
+)
+
+// subtreeOf returns the subtree that [key] belongs to, if any.
+func subtreeOf(key Key) (string, bool) {
+	if key.length < 8*subtreeKeyLen {
+		return "", false
+	}
+	return key.value[:subtreeKeyLen], true
+}
+
+func dirtySubtreeKey(subtree string) []byte {
+	return []byte(string(dirtySubtreePrefix) + subtree)
+}
+
+// subtreeTracker tracks which subtrees have intermediate nodes in the write
+// buffer and which subtrees are marked as dirty on disk.
+//
+// A subtree is marked as dirty before any of its intermediate nodes are
+// written to disk, and before any of its values are written to disk while it
+// has intermediate nodes in the write buffer. The marker is only removed
+// alongside a value commit once none of the subtree's intermediate nodes are
+// buffered.
+//
+// It isn't safe for concurrent use. All of its callers hold [merkleDB.lock]
+// or have exclusive access to the database.
+type subtreeTracker struct {
+	// buffered is the number of intermediate nodes in the write buffer for
+	// each subtree.
+	buffered map[string]int
+	// marked is the set of subtrees that are marked as dirty on disk.
+	marked set.Set[string]
+}
+
+func newSubtreeTracker() *subtreeTracker {
+	return &subtreeTracker{
+		buffered: make(map[string]int),
+	}
+}
+
+// add records that [key] was added to the write buffer.
+func (t *subtreeTracker) add(key Key) {
+	if subtree, ok := subtreeOf(key); ok {
+		t.buffered[subtree]++
+	}
+}
+
+// remove records that [keys] were removed from the write buffer.
+func (t *subtreeTracker) remove(keys []Key) {
+	for _, key := range keys {
+		subtree, ok := subtreeOf(key)
+		if !ok {
+			continue
+		}
+		t.buffered[subtree]--
+		if t.buffered[subtree] <= 0 {
+			delete(t.buffered, subtree)
+		}
+	}
+}
+
+// markerUpdate is a set of marker changes that have been written to a batch
+// but not yet applied to the tracker.
+type markerUpdate struct {
+	marked   []string
+	unmarked []string
+}
+
+// mark writes a marker to [w] for each subtree in [subtrees] that has
+// buffered intermediate nodes and isn't already marked.
+func (t *subtreeTracker) mark(w database.KeyValueWriter, subtrees set.Set[string], update *markerUpdate) error {
+	for subtree := range subtrees {
+		if t.buffered[subtree] == 0 || t.marked.Contains(subtree) {
+			continue
+		}
+		if err := w.Put(dirtySubtreeKey(subtree), nil); err != nil {
+			return err
+		}
+		update.marked = append(update.marked, subtree)
+	}
+	return nil
+}
+
+// unmarkClean removes the marker of each marked subtree that doesn't have any
+// buffered intermediate nodes.
+//
+// This must only be called when the values being written to [w] are the
+// latest values of the trie, otherwise the subtree's intermediate nodes may
+// be ahead of the values on disk.
+func (t *subtreeTracker) unmarkClean(w database.KeyValueDeleter, update *markerUpdate) error {
+	for subtree := range t.marked {
+		if t.buffered[subtree] != 0 {
+			continue
+		}
+		if err := w.Delete(dirtySubtreeKey(subtree)); err != nil {
+			return err
+		}
+		update.unmarked = append(update.unmarked, subtree)
+	}
+	return nil
+}
+
+// apply updates the tracker after the batch containing [update] was written.
+func (t *subtreeTracker) apply(update *markerUpdate) {
+	t.marked.Add(update.marked...)
+	t.marked.Remove(update.unmarked...)
+}
+
+func (t *subtreeTracker) reset() {
+	clear(t.buffered)
+	t.marked.Clear()
+}
+
+// addSubtree adds the subtree of [key] to [subtrees], if it has one.
+func addSubtree(subtrees set.Set[string], key Key) {
+	if subtree, ok := subtreeOf(key); ok {
+		subtrees.Add(subtree)
+	}
+}
+
+// subtreeRoot is the root node of a subtree that was reused during a rebuild.
+type subtreeRoot struct {
+	node *node
+	id   ids.ID
+}
+
+// rebuildIncrementally rebuilds the intermediate nodes of the subtrees that
+// are marked as dirty and the nodes above the subtrees. The intermediate
+// nodes of the other subtrees are reused.
+//
+// Assumes [subtreeJournalKey] is present.
+func (db *merkleDB) rebuildIncrementally(ctx context.Context, cacheSize int) error {
+	db.root = maybe.Nothing[*node]()
+	db.rootID = ids.Empty
+
+	dirty, err := db.loadDirtySubtrees()
+	if err != nil {
+		return err
+	}
+	// Loaded markers are removed once their subtrees have been rebuilt.
+	db.intermediateNodeDB.subtrees.marked.Union(dirty)
+
+	roots, err := db.findCleanSubtreeRoots(dirty)
+	if err != nil {
+		return err
+	}
+	if err := db.removeStaleIntermediateNodes(dirty); err != nil {
+		return err
+	}
+
+	if len(roots) > 0 {
+		root, err := db.buildSubtreeParents(roots)
+		if err != nil {
+			return err
+		}
+		db.root = maybe.Some(root.node)
+		db.rootID = root.id
+	}
+
+	rebuilt, err := db.reinsertDirtyValues(ctx, cacheSize, dirty)
+	if err != nil {
+		return err
+	}
+	db.metrics.SubtreesReused(len(roots))
+	db.metrics.SubtreesRebuilt(rebuilt)
+
+	// Persist the rebuilt nodes so that the markers can be removed.
+	if err := db.intermediateNodeDB.Flush(); err != nil {
+		return err
+	}
+	var (
+		batch  = db.baseDB.NewBatch()
+		update markerUpdate
+	)
+	if err := db.intermediateNodeDB.subtrees.unmarkClean(batch, &update); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	db.intermediateNodeDB.subtrees.apply(&update)
+	return nil
+}
+
+func (db *merkleDB) loadDirtySubtrees() (set.Set[string], error) {
+	it := db.baseDB.NewIteratorWithPrefix(dirtySubtreePrefix)
+	defer it.Release()
+
+	var dirty set.Set[string]
+	for it.Next() {
+		dirty.Add(string(it.Key()[len(dirtySubtreePrefix):]))
+	}
+	return dirty, it.Error()
+}
+
+// findCleanSubtreeRoots returns the roots of the subtrees that contain values
+// and aren't in [dirty], sorted by key.
+//
+// If the root of a subtree can't be found, the subtree is added to [dirty].
+func (db *merkleDB) findCleanSubtreeRoots(dirty set.Set[string]) ([]subtreeRoot, error) {
+	var (
+		roots []subtreeRoot
+		start []byte
+	)
+	for {
+		first, ok, err := db.firstValueKey(start, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return roots, nil
+		}
+		if len(first) < subtreeKeyLen {
+			// Values above the subtrees are always re-inserted.
+			start = append(first, 0)
+			continue
+		}
+
+		subtree := first[:subtreeKeyLen]
+		if !dirty.Contains(string(subtree)) {
+			root, err := db.getSubtreeRoot(subtree, first)
+			switch {
+			case err == nil:
+				roots = append(roots, root)
+			case errors.Is(err, database.ErrNotFound):
+				dirty.Add(string(subtree))
+			default:
+				return nil, err
+			}
+		}
+
+		start = database.PrefixLimit(subtree)
+		if start == nil {
+			return roots, nil
+		}
+	}
+}
+
+// getSubtreeRoot returns the root of [subtree], whose smallest key is
+// [first]. The root is the node at the longest prefix shared by every key in
+// the subtree.
+func (db *merkleDB) getSubtreeRoot(subtree []byte, first []byte) (subtreeRoot, error) {
+	var (
+		firstKey = ToKey(first)
+		// The root is between [low] and [high] tokens long.
+		low  = 8 * subtreeKeyLen / db.tokenSize
+		high = firstKey.length / db.tokenSize
+	)
+	for low < high {
+		mid := (low + high + 1) / 2
+		// The keys in the subtree are sorted, and [first] is the smallest, so
+		// every key has the prefix iff no key is after the prefix's range.
+		limit := prefixRangeLimit(firstKey.Take(mid * db.tokenSize))
+		_, hasKeyAfter, err := db.firstValueKey(limit, subtree)
+		if err != nil {
+			return subtreeRoot{}, err
+		}
+		if hasKeyAfter {
+			high = mid - 1
+		} else {
+			low = mid
+		}
+	}
+
+	rootKey := firstKey.Take(low * db.tokenSize)
+	// Only [first] can be a value node with a key that prefixes every key in
+	// the subtree.
+	root, err := db.getEditableNode(rootKey, rootKey == firstKey)
+	if err != nil {
+		return subtreeRoot{}, err
+	}
+	db.metrics.HashCalculated()
+	return subtreeRoot{
+		node: root,
+		id:   db.hasher.HashNode(root),
+	}, nil
+}
+
+// prefixRangeLimit returns the smallest key that is greater than every key
+// with the bit prefix [prefix], or nil if there is no such key.
+func prefixRangeLimit(prefix Key) []byte {
+	prefixBytes := slices.Clone(prefix.Bytes())
+	if remainder := prefix.length % 8; remainder != 0 {
+		prefixBytes[len(prefixBytes)-1] |= 0xFF >> remainder
+	}
+	return database.PrefixLimit(prefixBytes)
+}
+
+// firstValueKey returns the first key that is at least [start] and has
+// [prefix].
+func (db *merkleDB) firstValueKey(start, prefix []byte) ([]byte, bool, error) {
+	it := db.NewIteratorWithStartAndPrefix(start, prefix)
+	defer it.Release()
+
+	if !it.Next() {
+		return nil, false, it.Error()
+	}
+	return slices.Clone(it.Key()), true, nil
+}
+
+// removeStaleIntermediateNodes deletes the intermediate nodes above the
+// subtrees and in the [dirty] subtrees.
+func (db *merkleDB) removeStaleIntermediateNodes(dirty set.Set[string]) error {
+	for subtree := range dirty {
+		prefix := make([]byte, 0, len(intermediateNodePrefix)+len(subtree))
+		prefix = append(prefix, intermediateNodePrefix...)
+		prefix = append(prefix, subtree...)
+		if err := database.ClearPrefix(db.baseDB, prefix, rebuildIntermediateDeletionWriteSize); err != nil {
+			return err
+		}
+	}
+
+	// The intermediate nodes above the subtrees are sorted before the nodes of
+	// each subtree, so the nodes in each subtree are skipped.
+	var (
+		batch = db.baseDB.NewBatch()
+		start []byte
+	)
+	for {
+		it := db.baseDB.NewIteratorWithStartAndPrefix(start, intermediateNodePrefix)
+		hasNext := it.Next()
+		dbKey := slices.Clone(it.Key())
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return err
+		}
+		if !hasNext {
+			return batch.Write()
+		}
+
+		keyBytes := dbKey[len(intermediateNodePrefix):]
+		if db.isAboveSubtrees(keyBytes) {
+			if err := batch.Delete(dbKey); err != nil {
+				return err
+			}
+			start = append(dbKey, 0)
+			continue
+		}
+
+		start = database.PrefixLimit(dbKey[:len(intermediateNodePrefix)+subtreeKeyLen])
+		if start == nil {
+			return batch.Write()
+		}
+	}
+}
+
+// isAboveSubtrees returns true if the intermediate node stored with the
+// unprefixed key [keyBytes] isn't part of any subtree.
+func (db *merkleDB) isAboveSubtrees(keyBytes []byte) bool {
+	if db.tokenSize == 8 {
+		return len(keyBytes) < subtreeKeyLen
+	}
+	// The key includes a padding token, so a node in a subtree needs more
+	// than [subtreeKeyLen] bytes.
+	return len(keyBytes) <= subtreeKeyLen
+}
+
+// buildSubtreeParents writes the intermediate nodes that connect the sorted
+// subtree [roots] and returns the root of the resulting trie.
+func (db *merkleDB) buildSubtreeParents(roots []subtreeRoot) (subtreeRoot, error) {
+	if len(roots) == 1 {
+		return roots[0], nil
+	}
+
+	// The roots are sorted and none of their keys prefix another, so the
+	// longest common prefix of the first and last keys is shared by all of
+	// them.
+	var (
+		firstKey     = roots[0].node.key
+		commonLength = getLengthOfCommonPrefix(
+			firstKey,
+			roots[len(roots)-1].node.key,
+			0,
+			db.tokenSize,
+		)
+		parent = newNode(firstKey.Take(commonLength))
+	)
+	for len(roots) > 0 {
+		token := roots[0].node.key.Token(commonLength, db.tokenSize)
+		end := 1
+		for end < len(roots) && roots[end].node.key.Token(commonLength, db.tokenSize) == token {
+			end++
+		}
+
+		child, err := db.buildSubtreeParents(roots[:end])
+		if err != nil {
+			return subtreeRoot{}, err
+		}
+		parent.addChildWithID(child.node, db.tokenSize, child.id)
+		roots = roots[end:]
+	}
+
+	if err := db.intermediateNodeDB.Put(parent.key, parent); err != nil {
+		return subtreeRoot{}, err
+	}
+	db.metrics.HashCalculated()
+	return subtreeRoot{
+		node: parent,
+		id:   db.hasher.HashNode(parent),
+	}, nil
+}
+
+// reinsertDirtyValues re-inserts the values above the subtrees and in the
+// [dirty] subtrees. Returns the number of dirty subtrees that contain values.
+func (db *merkleDB) reinsertDirtyValues(ctx context.Context, cacheSize int, dirty set.Set[string]) (int, error) {
+	var (
+		opsSizeLimit = max(
+			cacheSize/rebuildViewSizeFractionOfCacheSize,
+			minRebuildViewSizePerCommit,
+		)
+		currentOps = make([]database.BatchOp, 0, opsSizeLimit)
+		rebuilt    set.Set[string]
+		start      []byte
+	)
+	commit := func() error {
+		view, err := newView(db, db, ViewChanges{BatchOps: currentOps, ConsumeBytes: true})
+		if err != nil {
+			return err
+		}
+		currentOps = make([]database.BatchOp, 0, opsSizeLimit)
+		return view.commitToDB(ctx)
+	}
+
+	valueIt := db.NewIterator()
+	// ensure valueIt is captured and release gets called on the latest copy of valueIt
+	defer func() { valueIt.Release() }()
+	for valueIt.Next() {
+		key := valueIt.Key()
+		if len(key) >= subtreeKeyLen {
+			subtree := string(key[:subtreeKeyLen])
+			if !dirty.Contains(subtree) {
+				// Skip the values of the reused subtree.
+				start = database.PrefixLimit(key[:subtreeKeyLen])
+				if start == nil {
+					break
+				}
+				valueIt.Release()
+				valueIt = db.NewIteratorWithStart(start)
+				continue
+			}
+			rebuilt.Add(subtree)
+		}
+
+		currentOps = append(currentOps, database.BatchOp{
+			Key:   key,
+			Value: valueIt.Value(),
+		})
+		if len(currentOps) < opsSizeLimit {
+			continue
+		}
+
+		if err := commit(); err != nil {
+			return 0, err
+		}
+		// reset the iterator to prevent memory bloat
+		start = append(slices.Clone(key), 0)
+		valueIt.Release()
+		valueIt = db.NewIteratorWithStart(start)
+	}
+	if err := valueIt.Error(); err != nil {
+		return 0, err
+	}
+	return rebuilt.Len(), commit()
+}
@@ -33,6 +33,11 @@ type ProofGetter interface {
 	// or a proof of its absence from the trie
 	// Returns ErrEmptyProof if the trie is empty.
 	GetProof(ctx context.Context, keyBytes []byte) (*Proof, error)
+
+	// GetMultiProof generates a proof of the values associated with [keys],
+	// or of their absence from the trie.
+	// Returns ErrEmptyProof if the trie is empty.
+	GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error)
 }
 
 type trieInternals interface {
@@ -55,6 +60,12 @@ type trieInternals interface {
 	getTokenSize() int
 }
 
+// provableTrie is the subset of [Trie] needed to generate proofs.
+type provableTrie interface {
+	trieInternals
+	database.Iteratee
+}
+
 type Trie interface {
 	trieInternals
 	MerkleRootGetter
@@ -97,7 +108,7 @@ type View interface {
 // given [key], if it's in the trie, or the node with the largest prefix of
 // the [key] if it isn't in the trie.
 // Assumes [t] doesn't change while this function is running.
-func visitPathToKey(t Trie, key Key, visitNode func(*node) error) error {
+func visitPathToKey(t provableTrie, key Key, visitNode func(*node) error) error {
 	maybeRoot := t.getRoot()
 	if maybeRoot.IsNothing() {
 		return nil
@@ -138,7 +149,7 @@ func visitPathToKey(t Trie, key Key, visitNode func(*node) error) error {
 
 // Returns a proof that [key] is in or not in trie [t].
 // Assumes [t] doesn't change while this function is running.
-func getProof(t Trie, key []byte) (*Proof, error) {
+func getProof(t provableTrie, key []byte) (*Proof, error) {
 	root := t.getRoot()
 	if root.IsNothing() {
 		return nil, ErrEmptyProof
@@ -191,12 +202,69 @@ func getProof(t Trie, key []byte) (*Proof, error) {
 	return proof, nil
 }
 
+// Returns a proof that each of [keys] is in or not in trie [t].
+// Assumes [t] doesn't change while this function is running.
+func getMultiProof(t provableTrie, keys [][]byte) (*MultiProof, error) {
+	root := t.getRoot()
+	if root.IsNothing() {
+		return nil, ErrEmptyProof
+	}
+
+	keys = slices.Clone(keys)
+	slices.SortFunc(keys, bytes.Compare)
+	keys = slices.CompactFunc(keys, bytes.Equal)
+
+	var (
+		tokenSize = t.getTokenSize()
+		proof     = &MultiProof{
+			KeyValues: make([]KeyChange, len(keys)),
+		}
+		// The root is always in the proof, even if there are no keys.
+		nodes = map[Key]ProofNode{
+			root.Value().key: root.Value().asProofNode(),
+		}
+	)
+	for i, key := range keys {
+		keyProof, err := getProof(t, key)
+		if err != nil {
+			return nil, err
+		}
+		proof.KeyValues[i] = KeyChange{
+			Key:   slices.Clone(key),
+			Value: keyProof.Value,
+		}
+
+		for j, proofNode := range keyProof.Path {
+			if _, ok := nodes[proofNode.Key]; !ok {
+				nodes[proofNode.Key] = proofNode
+			}
+			if j == 0 {
+				continue
+			}
+
+			// The ID of a child that is in the proof is calculated during
+			// verification, so it doesn't need to be included in its parent.
+			parent := nodes[keyProof.Path[j-1].Key]
+			delete(parent.Children, proofNode.Key.Token(parent.Key.length, tokenSize))
+		}
+	}
+
+	proof.Nodes = make([]ProofNode, 0, len(nodes))
+	for _, proofNode := range nodes {
+		proof.Nodes = append(proof.Nodes, proofNode)
+	}
+	slices.SortFunc(proof.Nodes, func(a, b ProofNode) int {
+		return a.Key.Compare(b.Key)
+	})
+	return proof, nil
+}
+
 // getRangeProof returns a range proof for (at least part of) the key range [start, end].
 // The returned proof's [KeyValues] has at most [maxLength] values.
 // [maxLength] must be > 0.
 // Assumes [t] doesn't change while this function is running.
 func getRangeProof(
-	t Trie,
+	t provableTrie,
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
@@ -491,6 +491,25 @@ func (v *view) GetProof(ctx context.Context, key []byte) (*Proof, error) {
 	return result, nil
 }
 
+// GetMultiProof returns a proof that each of [keys] is in or not in trie [t].
+func (v *view) GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	_, span := v.db.infoTracer.Start(ctx, "MerkleDB.view.GetMultiProof")
+	defer span.End()
+
+	if err := v.applyValueChanges(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := getMultiProof(v, keys)
+	if err != nil {
+		return nil, err
+	}
+	if v.isInvalid() {
+		return nil, ErrInvalid
+	}
+	return result, nil
+}
+
 // GetRangeProof returns a range proof for (at least part of) the key range [start, end].
 // The returned proof's [KeyValues] has at most [maxLength] values.
 // [maxLength] must be > 0.